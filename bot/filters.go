@@ -3,6 +3,9 @@ package bot
 import (
 	"github.com/gofrs/uuid"
 	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/rbac"
+	"github.com/traPtitech/traQ/repository"
+	"go.uber.org/zap"
 )
 
 type filterFunc func(p *Processor, bot *model.Bot) bool
@@ -43,3 +46,22 @@ func botUserIDNotEqualsFilter(id uuid.UUID) filterFunc {
 		return id != bot.BotUserID
 	}
 }
+
+// channelPermissionFilter チャンネルで権限permを許可されているBotのみを通します
+//
+// チャンネル毎の権限を要求していないBotは常に通します。
+func channelPermissionFilter(channelID uuid.UUID, perm rbac.Permission) filterFunc {
+	return func(p *Processor, bot *model.Bot) bool {
+		if !bot.ChannelScoped {
+			return true
+		}
+		j, err := p.repo.GetBotJoinChannel(bot.ID, channelID)
+		if err != nil {
+			if err != repository.ErrNotFound {
+				p.logger.Error("failed to GetBotJoinChannel", zap.Error(err), zap.Stringer("botId", bot.ID), zap.Stringer("channelId", channelID))
+			}
+			return false
+		}
+		return j.Permissions.Contains(perm)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/leandro-lugaresi/hub"
 	"github.com/traPtitech/traQ/event"
 	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/rbac/permission"
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/utils/message"
 	"go.uber.org/zap"
@@ -15,14 +16,31 @@ import (
 type eventHandler func(p *Processor, event string, fields hub.Fields)
 
 var eventHandlerSet = map[string]eventHandler{
-	event.BotJoined:           botJoinedAndLeftHandler,
-	event.BotLeft:             botJoinedAndLeftHandler,
-	event.BotPingRequest:      botPingRequestHandler,
-	event.MessageCreated:      messageCreatedHandler,
-	event.UserCreated:         userCreatedHandler,
-	event.ChannelCreated:      channelCreatedHandler,
-	event.ChannelTopicUpdated: channelTopicUpdatedHandler,
-	event.StampCreated:        stampCreatedHandler,
+	event.BotJoined:                botJoinedAndLeftHandler,
+	event.BotLeft:                  botJoinedAndLeftHandler,
+	event.BotPingRequest:           botPingRequestHandler,
+	event.BotTestEventRequest:      botTestEventRequestHandler,
+	event.MessageCreated:           messageCreatedHandler,
+	event.UserCreated:              userCreatedHandler,
+	event.ChannelCreated:           channelCreatedHandler,
+	event.ChannelTopicUpdated:      channelTopicUpdatedHandler,
+	event.StampCreated:             stampCreatedHandler,
+	event.MessageUpdated:           messageUpdatedHandler,
+	event.MessageDeleted:           messageDeletedHandler,
+	event.MessageStamped:           messageStampedHandler,
+	event.MessageUnstamped:         messageUnstampedHandler,
+	event.MessagePinned:            messagePinnedAndUnpinnedHandler,
+	event.MessageUnpinned:          messagePinnedAndUnpinnedHandler,
+	event.ChannelNameUpdated:       channelNameUpdatedHandler,
+	event.ChannelParentUpdated:     channelParentUpdatedHandler,
+	event.ChannelVisibilityUpdated: channelVisibilityUpdatedHandler,
+	event.UserGroupMemberAdded:     userGroupMemberAddedAndRemovedHandler,
+	event.UserGroupMemberRemoved:   userGroupMemberAddedAndRemovedHandler,
+	event.UserTagAdded:             userTagHandler,
+	event.UserTagUpdated:           userTagHandler,
+	event.UserTagRemoved:           userTagHandler,
+	event.BotCommandInvoked:        botCommandInvokedHandler,
+	event.BotInteraction:           botInteractionHandler,
 }
 
 func messageCreatedHandler(p *Processor, _ string, fields hub.Fields) {
@@ -103,7 +121,7 @@ func messageCreatedHandler(p *Processor, _ string, fields hub.Fields) {
 			}
 		}
 
-		bots = filterBots(p, bots, stateFilter(model.BotActive), botUserIDNotEqualsFilter(m.UserID))
+		bots = filterBots(p, bots, stateFilter(model.BotActive), botUserIDNotEqualsFilter(m.UserID), channelPermissionFilter(m.ChannelID, permission.GetMessage))
 		if len(bots) == 0 {
 			return
 		}
@@ -166,6 +184,94 @@ func botJoinedAndLeftHandler(p *Processor, ev string, fields hub.Fields) {
 	}
 }
 
+func botCommandInvokedHandler(p *Processor, _ string, fields hub.Fields) {
+	botID := fields["bot_id"].(uuid.UUID)
+	channelID := fields["channel_id"].(uuid.UUID)
+	userID := fields["user_id"].(uuid.UUID)
+	messageID := fields["message_id"].(uuid.UUID)
+	cmd := fields["command"].(*model.BotCommand)
+	args := fields["arguments"].(map[string]interface{})
+	text := fields["text"].(string)
+
+	// コマンドを登録したBotには購読イベントに関わらず送信する
+	bot, err := p.repo.GetBotByID(botID)
+	if err != nil {
+		p.logger.Error("failed to GetBotByID", zap.Error(err), zap.Stringer("id", botID))
+		return
+	}
+	if bot.State != model.BotActive {
+		return
+	}
+
+	user, err := p.repo.GetUser(userID, false)
+	if err != nil {
+		p.logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("id", userID))
+		return
+	}
+
+	payload := commandInvokedPayload{
+		basePayload: makeBasePayload(),
+		Command:     cmd.Name,
+		Arguments:   args,
+		Text:        text,
+		ChannelID:   channelID,
+		User:        makeUserPayload(user),
+	}
+	if messageID != uuid.Nil {
+		payload.MessageID = &messageID
+	}
+
+	buf, release, err := p.makePayloadJSON(&payload)
+	if err != nil {
+		p.logger.Error("unexpected json encode error", zap.Error(err))
+		return
+	}
+	defer release()
+
+	p.sendEvent(bot, model.BotEventCommandInvoked, buf)
+}
+
+func botInteractionHandler(p *Processor, _ string, fields hub.Fields) {
+	botID := fields["bot_id"].(uuid.UUID)
+	userID := fields["user_id"].(uuid.UUID)
+	m := fields["message"].(*model.Message)
+	component := fields["component"].(*model.MessageComponent)
+	value := fields["value"].(string)
+
+	// メッセージを投稿したBotには購読イベントに関わらず送信する
+	bot, err := p.repo.GetBotByID(botID)
+	if err != nil {
+		p.logger.Error("failed to GetBotByID", zap.Error(err), zap.Stringer("id", botID))
+		return
+	}
+	if bot.State != model.BotActive {
+		return
+	}
+
+	user, err := p.repo.GetUser(userID, false)
+	if err != nil {
+		p.logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("id", userID))
+		return
+	}
+
+	buf, release, err := p.makePayloadJSON(&interactionPayload{
+		basePayload:   makeBasePayload(),
+		MessageID:     m.ID,
+		ChannelID:     m.ChannelID,
+		ComponentID:   component.ID,
+		ComponentType: component.Type,
+		Value:         value,
+		User:          makeUserPayload(user),
+	})
+	if err != nil {
+		p.logger.Error("unexpected json encode error", zap.Error(err))
+		return
+	}
+	defer release()
+
+	p.sendEvent(bot, model.BotEventInteraction, buf)
+}
+
 func userCreatedHandler(p *Processor, _ string, fields hub.Fields) {
 	user := fields["user"].(model.UserInfo)
 
@@ -312,6 +418,415 @@ func botPingRequestHandler(p *Processor, _ string, fields hub.Fields) {
 	}
 }
 
+func botTestEventRequestHandler(p *Processor, _ string, fields hub.Fields) {
+	bot := fields["bot"].(*model.Bot)
+	requestID := fields["request_id"].(uuid.UUID)
+	ev := fields["event"].(model.BotEvent)
+	body := fields["body"].(string)
+	userID := fields["user_id"].(uuid.UUID)
+
+	if len(body) > 0 {
+		p.sendTestEvent(bot, requestID, ev, []byte(body))
+		return
+	}
+
+	user, err := p.repo.GetUser(userID, false)
+	if err != nil {
+		p.logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("id", userID))
+		return
+	}
+	payload, ok := makeSamplePayload(ev, user)
+	if !ok {
+		p.logger.Warn("unsupported test event", zap.String("event", ev.String()))
+		return
+	}
+	buf, release, err := p.makePayloadJSON(payload)
+	if err != nil {
+		p.logger.Error("unexpected json encode error", zap.Error(err))
+		return
+	}
+	defer release()
+
+	p.sendTestEvent(bot, requestID, ev, buf)
+}
+
+func messageUpdatedHandler(p *Processor, _ string, fields hub.Fields) {
+	m := fields["message"].(*model.Message)
+
+	bots, err := getChannelBots(p, m.ChannelID, model.BotEventMessageUpdated)
+	if err != nil {
+		p.logger.Error("failed to getChannelBots", zap.Error(err), zap.Stringer("id", m.ChannelID))
+		return
+	}
+	bots = filterBots(p, bots, botUserIDNotEqualsFilter(m.UserID))
+	if len(bots) == 0 {
+		return
+	}
+
+	user, err := p.repo.GetUser(m.UserID, false)
+	if err != nil {
+		p.logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("id", m.UserID))
+		return
+	}
+	embedded, plain := message.Parse(m.Text)
+
+	multicast(p, model.BotEventMessageUpdated, &messageUpdatedPayload{
+		basePayload: makeBasePayload(),
+		Message:     makeMessagePayload(m, user, embedded, plain),
+	}, bots)
+}
+
+func messageDeletedHandler(p *Processor, _ string, fields hub.Fields) {
+	m := fields["message"].(*model.Message)
+
+	bots, err := getChannelBots(p, m.ChannelID, model.BotEventMessageDeleted)
+	if err != nil {
+		p.logger.Error("failed to getChannelBots", zap.Error(err), zap.Stringer("id", m.ChannelID))
+		return
+	}
+	if m.DeletedBy.Valid {
+		bots = filterBots(p, bots, botUserIDNotEqualsFilter(m.DeletedBy.UUID))
+	}
+
+	multicast(p, model.BotEventMessageDeleted, &messageDeletedPayload{
+		basePayload: makeBasePayload(),
+		MessageID:   m.ID,
+		ChannelID:   m.ChannelID,
+	}, bots)
+}
+
+func messageStampedHandler(p *Processor, _ string, fields hub.Fields) {
+	messageID := fields["message_id"].(uuid.UUID)
+	stampID := fields["stamp_id"].(uuid.UUID)
+	userID := fields["user_id"].(uuid.UUID)
+	count := fields["count"].(int)
+
+	m, stamp, user, bots, ok := getMessageStampEventTargets(p, messageID, stampID, userID, model.BotEventMessageStamped)
+	if !ok {
+		return
+	}
+
+	multicast(p, model.BotEventMessageStamped, &messageStampedPayload{
+		basePayload: makeBasePayload(),
+		MessageID:   m.ID,
+		ChannelID:   m.ChannelID,
+		StampID:     stamp.ID,
+		StampName:   stamp.Name,
+		User:        makeUserPayload(user),
+		Count:       count,
+	}, bots)
+}
+
+func messageUnstampedHandler(p *Processor, _ string, fields hub.Fields) {
+	messageID := fields["message_id"].(uuid.UUID)
+	stampID := fields["stamp_id"].(uuid.UUID)
+	userID := fields["user_id"].(uuid.UUID)
+
+	m, stamp, user, bots, ok := getMessageStampEventTargets(p, messageID, stampID, userID, model.BotEventMessageUnstamped)
+	if !ok {
+		return
+	}
+
+	multicast(p, model.BotEventMessageUnstamped, &messageUnstampedPayload{
+		basePayload: makeBasePayload(),
+		MessageID:   m.ID,
+		ChannelID:   m.ChannelID,
+		StampID:     stamp.ID,
+		StampName:   stamp.Name,
+		User:        makeUserPayload(user),
+	}, bots)
+}
+
+// getMessageStampEventTargets スタンプイベントの対象のメッセージ・スタンプ・ユーザーと、イベントを送信するBotを取得します
+//
+// 送信するBotが存在しない場合や、取得に失敗した場合はokがfalseになります。
+func getMessageStampEventTargets(p *Processor, messageID, stampID, userID uuid.UUID, ev model.BotEvent) (m *model.Message, stamp *model.Stamp, user model.UserInfo, bots []*model.Bot, ok bool) {
+	m, err := p.repo.GetMessageByID(messageID)
+	if err != nil {
+		p.logger.Error("failed to GetMessageByID", zap.Error(err), zap.Stringer("id", messageID))
+		return
+	}
+
+	bots, err = getChannelBots(p, m.ChannelID, ev)
+	if err != nil {
+		p.logger.Error("failed to getChannelBots", zap.Error(err), zap.Stringer("id", m.ChannelID))
+		return
+	}
+	bots = filterBots(p, bots, botUserIDNotEqualsFilter(userID))
+	if len(bots) == 0 {
+		return
+	}
+
+	stamp, err = p.repo.GetStamp(stampID)
+	if err != nil {
+		p.logger.Error("failed to GetStamp", zap.Error(err), zap.Stringer("id", stampID))
+		return
+	}
+	user, err = p.repo.GetUser(userID, false)
+	if err != nil {
+		p.logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("id", userID))
+		return
+	}
+	return m, stamp, user, bots, true
+}
+
+func messagePinnedAndUnpinnedHandler(p *Processor, ev string, fields hub.Fields) {
+	messageID := fields["message_id"].(uuid.UUID)
+	channelID := fields["channel_id"].(uuid.UUID)
+	userID := fields["user_id"].(uuid.UUID)
+
+	var botEvent model.BotEvent
+	switch ev {
+	case event.MessagePinned:
+		botEvent = model.BotEventMessagePinned
+	case event.MessageUnpinned:
+		botEvent = model.BotEventMessageUnpinned
+	}
+
+	bots, err := getChannelBots(p, channelID, botEvent)
+	if err != nil {
+		p.logger.Error("failed to getChannelBots", zap.Error(err), zap.Stringer("id", channelID))
+		return
+	}
+	bots = filterBots(p, bots, botUserIDNotEqualsFilter(userID))
+	if len(bots) == 0 {
+		return
+	}
+
+	user, err := p.repo.GetUser(userID, false)
+	if err != nil {
+		p.logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("id", userID))
+		return
+	}
+
+	multicast(p, botEvent, &messagePinnedAndUnpinnedPayload{
+		basePayload: makeBasePayload(),
+		MessageID:   messageID,
+		ChannelID:   channelID,
+		User:        makeUserPayload(user),
+	}, bots)
+}
+
+func channelNameUpdatedHandler(p *Processor, _ string, fields hub.Fields) {
+	chID := fields["channel_id"].(uuid.UUID)
+	oldName := fields["old_name"].(string)
+	updaterID := fields["updater_id"].(uuid.UUID)
+
+	bots, ch, updater, ok := getChannelUpdateEventTargets(p, chID, updaterID, model.BotEventChannelRenamed)
+	if !ok {
+		return
+	}
+
+	multicast(p, model.BotEventChannelRenamed, &channelRenamedPayload{
+		basePayload: makeBasePayload(),
+		Channel:     ch,
+		OldName:     oldName,
+		Updater:     updater,
+	}, bots)
+}
+
+func channelParentUpdatedHandler(p *Processor, _ string, fields hub.Fields) {
+	chID := fields["channel_id"].(uuid.UUID)
+	oldParentID := fields["old_parent_id"].(uuid.UUID)
+	updaterID := fields["updater_id"].(uuid.UUID)
+
+	bots, ch, updater, ok := getChannelUpdateEventTargets(p, chID, updaterID, model.BotEventChannelMoved)
+	if !ok {
+		return
+	}
+
+	multicast(p, model.BotEventChannelMoved, &channelMovedPayload{
+		basePayload: makeBasePayload(),
+		Channel:     ch,
+		OldParentID: oldParentID,
+		Updater:     updater,
+	}, bots)
+}
+
+func channelVisibilityUpdatedHandler(p *Processor, _ string, fields hub.Fields) {
+	chID := fields["channel_id"].(uuid.UUID)
+	visibility := fields["visibility"].(bool)
+	updaterID := fields["updater_id"].(uuid.UUID)
+
+	bots, ch, updater, ok := getChannelUpdateEventTargets(p, chID, updaterID, model.BotEventChannelVisibilityChanged)
+	if !ok {
+		return
+	}
+
+	multicast(p, model.BotEventChannelVisibilityChanged, &channelVisibilityChangedPayload{
+		basePayload: makeBasePayload(),
+		Channel:     ch,
+		Visibility:  visibility,
+		Updater:     updater,
+	}, bots)
+}
+
+// getChannelUpdateEventTargets チャンネル更新イベントを送信するBotと、チャンネル・更新者のペイロードを取得します
+//
+// 送信するBotが存在しない場合や、取得に失敗した場合はokがfalseになります。
+func getChannelUpdateEventTargets(p *Processor, chID, updaterID uuid.UUID, ev model.BotEvent) (bots []*model.Bot, ch channelPayload, updater userPayload, ok bool) {
+	bots, err := p.repo.GetBots(repository.BotsQuery{}.CMemberOf(chID).Active().Subscribe(ev))
+	if err != nil {
+		p.logger.Error("failed to GetBots", zap.Error(err))
+		return
+	}
+	bots = filterBots(p, bots, botUserIDNotEqualsFilter(updaterID))
+	if len(bots) == 0 {
+		return
+	}
+
+	c, err := p.repo.GetChannel(chID)
+	if err != nil {
+		p.logger.Error("failed to GetChannel", zap.Error(err), zap.Stringer("id", chID))
+		return
+	}
+	path, err := p.repo.GetChannelPath(c.ID)
+	if err != nil {
+		p.logger.Error("failed to GetChannelPath", zap.Error(err), zap.Stringer("id", c.ID))
+		return
+	}
+	chCreator, err := p.repo.GetUser(c.CreatorID, false)
+	if err != nil && err != repository.ErrNotFound {
+		p.logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("id", c.CreatorID))
+		return
+	}
+	user, err := p.repo.GetUser(updaterID, false)
+	if err != nil {
+		p.logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("id", updaterID))
+		return
+	}
+	return bots, makeChannelPayload(c, path, chCreator), makeUserPayload(user), true
+}
+
+func userGroupMemberAddedAndRemovedHandler(p *Processor, ev string, fields hub.Fields) {
+	groupID := fields["group_id"].(uuid.UUID)
+	userID := fields["user_id"].(uuid.UUID)
+
+	var botEvent model.BotEvent
+	switch ev {
+	case event.UserGroupMemberAdded:
+		botEvent = model.BotEventUserGroupMemberAdded
+	case event.UserGroupMemberRemoved:
+		botEvent = model.BotEventUserGroupMemberRemoved
+	}
+
+	bots, err := p.repo.GetBots(repository.BotsQuery{}.Privileged().Active().Subscribe(botEvent))
+	if err != nil {
+		p.logger.Error("failed to GetBots", zap.Error(err))
+		return
+	}
+	if len(bots) == 0 {
+		return
+	}
+
+	g, err := p.repo.GetUserGroup(groupID)
+	if err != nil {
+		p.logger.Error("failed to GetUserGroup", zap.Error(err), zap.Stringer("id", groupID))
+		return
+	}
+	user, err := p.repo.GetUser(userID, false)
+	if err != nil {
+		p.logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("id", userID))
+		return
+	}
+
+	multicast(p, botEvent, &userGroupMemberAddedAndRemovedPayload{
+		basePayload: makeBasePayload(),
+		Group: userGroupPayload{
+			ID:   g.ID,
+			Name: g.Name,
+			Type: g.Type,
+		},
+		User: makeUserPayload(user),
+	}, bots)
+}
+
+func userTagHandler(p *Processor, ev string, fields hub.Fields) {
+	userID := fields["user_id"].(uuid.UUID)
+	tagID := fields["tag_id"].(uuid.UUID)
+
+	var botEvent model.BotEvent
+	switch ev {
+	case event.UserTagAdded:
+		botEvent = model.BotEventUserTagAdded
+	case event.UserTagUpdated:
+		botEvent = model.BotEventUserTagUpdated
+	case event.UserTagRemoved:
+		botEvent = model.BotEventUserTagRemoved
+	}
+
+	bots, err := p.repo.GetBots(repository.BotsQuery{}.Privileged().Active().Subscribe(botEvent))
+	if err != nil {
+		p.logger.Error("failed to GetBots", zap.Error(err))
+		return
+	}
+	if len(bots) == 0 {
+		return
+	}
+
+	tag, err := p.repo.GetTagByID(tagID)
+	if err != nil {
+		p.logger.Error("failed to GetTagByID", zap.Error(err), zap.Stringer("id", tagID))
+		return
+	}
+	user, err := p.repo.GetUser(userID, false)
+	if err != nil {
+		p.logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("id", userID))
+		return
+	}
+
+	payload := &userTagPayload{
+		basePayload: makeBasePayload(),
+		User:        makeUserPayload(user),
+		TagID:       tag.ID,
+		Tag:         tag.Name,
+	}
+	if botEvent != model.BotEventUserTagRemoved {
+		ut, err := p.repo.GetUserTag(userID, tagID)
+		if err != nil {
+			p.logger.Error("failed to GetUserTag", zap.Error(err), zap.Stringer("userId", userID), zap.Stringer("tagId", tagID))
+			return
+		}
+		payload.IsLocked = ut.IsLocked
+	}
+
+	multicast(p, botEvent, payload, bots)
+}
+
+// getChannelBots 指定したチャンネルのメッセージイベントevを受け取る有効なBotを取得します
+//
+// ダイレクトメッセージチャンネルの場合は、相手のBotを返します。
+func getChannelBots(p *Processor, channelID uuid.UUID, ev model.BotEvent) ([]*model.Bot, error) {
+	ch, err := p.repo.GetChannel(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if !ch.IsDMChannel() {
+		bots, err := p.repo.GetBots(repository.BotsQuery{}.CMemberOf(channelID).Active().Subscribe(ev))
+		if err != nil {
+			return nil, err
+		}
+		return filterBots(p, bots, channelPermissionFilter(channelID, permission.GetMessage)), nil
+	}
+
+	ids, err := p.repo.GetPrivateChannelMemberIDs(channelID)
+	if err != nil {
+		return nil, err
+	}
+	bots := make([]*model.Bot, 0)
+	for _, id := range ids {
+		b, err := p.repo.GetBotByBotUserID(id)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		bots = append(bots, b)
+	}
+	return filterBots(p, bots, stateFilter(model.BotActive), eventFilter(ev)), nil
+}
+
 func multicast(p *Processor, ev model.BotEvent, payload interface{}, targets []*model.Bot) {
 	if len(targets) == 0 {
 		return
@@ -130,3 +130,103 @@ type stampCreatedPayload struct {
 	FileID  uuid.UUID   `json:"fileId"`
 	Creator userPayload `json:"creator"`
 }
+
+type messageUpdatedPayload struct {
+	basePayload
+	Message messagePayload `json:"message"`
+}
+
+type messageDeletedPayload struct {
+	basePayload
+	MessageID uuid.UUID `json:"messageId"`
+	ChannelID uuid.UUID `json:"channelId"`
+}
+
+type messageStampedPayload struct {
+	basePayload
+	MessageID uuid.UUID   `json:"messageId"`
+	ChannelID uuid.UUID   `json:"channelId"`
+	StampID   uuid.UUID   `json:"stampId"`
+	StampName string      `json:"stampName"`
+	User      userPayload `json:"user"`
+	Count     int         `json:"count"`
+}
+
+type messageUnstampedPayload struct {
+	basePayload
+	MessageID uuid.UUID   `json:"messageId"`
+	ChannelID uuid.UUID   `json:"channelId"`
+	StampID   uuid.UUID   `json:"stampId"`
+	StampName string      `json:"stampName"`
+	User      userPayload `json:"user"`
+}
+
+type messagePinnedAndUnpinnedPayload struct {
+	basePayload
+	MessageID uuid.UUID   `json:"messageId"`
+	ChannelID uuid.UUID   `json:"channelId"`
+	User      userPayload `json:"user"`
+}
+
+type channelRenamedPayload struct {
+	basePayload
+	Channel channelPayload `json:"channel"`
+	OldName string         `json:"oldName"`
+	Updater userPayload    `json:"updater"`
+}
+
+type channelMovedPayload struct {
+	basePayload
+	Channel     channelPayload `json:"channel"`
+	OldParentID uuid.UUID      `json:"oldParentId"`
+	Updater     userPayload    `json:"updater"`
+}
+
+type channelVisibilityChangedPayload struct {
+	basePayload
+	Channel    channelPayload `json:"channel"`
+	Visibility bool           `json:"visibility"`
+	Updater    userPayload    `json:"updater"`
+}
+
+type userGroupPayload struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Type string    `json:"type"`
+}
+
+type userGroupMemberAddedAndRemovedPayload struct {
+	basePayload
+	Group userGroupPayload `json:"group"`
+	User  userPayload      `json:"user"`
+}
+
+type userTagPayload struct {
+	basePayload
+	User  userPayload `json:"user"`
+	TagID uuid.UUID   `json:"tagId"`
+	Tag   string      `json:"tag"`
+	// IsLocked USER_TAG_REMOVEDの場合は常にfalse
+	IsLocked bool `json:"isLocked"`
+}
+
+type commandInvokedPayload struct {
+	basePayload
+	Command   string                 `json:"command"`
+	Arguments map[string]interface{} `json:"arguments"`
+	Text      string                 `json:"text"`
+	ChannelID uuid.UUID              `json:"channelId"`
+	// MessageID コマンドのメッセージが投稿されなかった場合はnull
+	MessageID *uuid.UUID  `json:"messageId"`
+	User      userPayload `json:"user"`
+}
+
+type interactionPayload struct {
+	basePayload
+	MessageID     uuid.UUID                  `json:"messageId"`
+	ChannelID     uuid.UUID                  `json:"channelId"`
+	ComponentID   string                     `json:"componentId"`
+	ComponentType model.MessageComponentType `json:"componentType"`
+	Value         string                     `json:"value"`
+	User          userPayload                `json:"user"`
+}
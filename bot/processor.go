@@ -1,18 +1,22 @@
 package bot
 
 import (
-	"bytes"
 	"github.com/gofrs/uuid"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/labstack/echo/v4"
 	"github.com/leandro-lugaresi/hub"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/traPtitech/traQ/bot/signature"
+	"github.com/traPtitech/traQ/bot/ws"
+	"github.com/traPtitech/traQ/event"
 	"github.com/traPtitech/traQ/model"
 	"github.com/traPtitech/traQ/repository"
 	"go.uber.org/zap"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -32,19 +36,28 @@ type Processor struct {
 	repo   repository.Repository
 	logger *zap.Logger
 	hub    *hub.Hub
+	ws     *ws.Streamer
 	client http.Client
+
+	closer chan struct{}
+	wg     sync.WaitGroup
 }
 
 // NewProcessor ボットプロセッサーを生成し、起動します
-func NewProcessor(repo repository.Repository, hub *hub.Hub, logger *zap.Logger) *Processor {
+//
+// WebSocketモードのBotへのイベントはwsを介して配送されます。
+// 配送に失敗したイベントは間隔を空けて再送されます。
+func NewProcessor(repo repository.Repository, hub *hub.Hub, ws *ws.Streamer, logger *zap.Logger) *Processor {
 	p := &Processor{
 		repo:   repo,
 		logger: logger,
 		hub:    hub,
+		ws:     ws,
 		client: http.Client{
 			Timeout:       10 * time.Second,
 			CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse },
 		},
+		closer: make(chan struct{}),
 	}
 	go func() {
 		events := make([]string, 0, len(eventHandlerSet))
@@ -54,59 +67,129 @@ func NewProcessor(repo repository.Repository, hub *hub.Hub, logger *zap.Logger)
 
 		sub := hub.Subscribe(100, events...)
 		for ev := range sub.Receiver {
+			if event.IsRemote(ev) {
+				// 他インスタンスで発生したイベントはそのインスタンスで配送される
+				continue
+			}
 			h, ok := eventHandlerSet[ev.Name]
 			if ok {
 				go h(p, ev.Name, ev.Fields)
 			}
 		}
 	}()
+	p.wg.Add(1)
+	go p.runRetry()
 	return p
 }
 
+// Close イベントの再送を停止します
+func (p *Processor) Close() {
+	close(p.closer)
+	p.wg.Wait()
+}
+
 func (p *Processor) sendEvent(b *model.Bot, event model.BotEvent, body []byte) (ok bool) {
-	reqID := uuid.Must(uuid.NewV4())
+	l := &model.BotEventLog{
+		RequestID: uuid.Must(uuid.NewV4()),
+		BotID:     b.ID,
+		Event:     event,
+		Body:      string(body),
+	}
+	ok, retry := p.deliver(b, l)
+	// Pingの結果はBotの状態に直接反映されるため再送しない
+	if retry && event != model.BotEventPing {
+		p.scheduleRetry(b, l)
+	}
+	p.writeLog(l)
+	return ok
+}
+
+// sendTestEvent Botにテストイベントを送信します
+//
+// テストイベントは失敗しても再送せず、Botの状態も変更しません。
+func (p *Processor) sendTestEvent(b *model.Bot, requestID uuid.UUID, event model.BotEvent, body []byte) {
+	l := &model.BotEventLog{
+		RequestID: requestID,
+		BotID:     b.ID,
+		Event:     event,
+		Body:      string(body),
+		Test:      true,
+	}
+	p.deliver(b, l)
+	p.writeLog(l)
+}
+
+// deliver Botにイベントを送信し、結果をlに記録します
+//
+// retryは配送に失敗し、再送すべき場合にtrueになります。
+func (p *Processor) deliver(b *model.Bot, l *model.BotEventLog) (ok bool, retry bool) {
+	l.Attempts++
+	if b.Mode == model.BotModeWebSocket {
+		ok = p.sendEventWS(b, l)
+		return ok, !ok
+	}
+	ok = p.sendEventHTTP(b, l)
+	return ok, l.Code < 200 || l.Code >= 300
+}
+
+func (p *Processor) writeLog(l *model.BotEventLog) {
+	if err := p.repo.WriteBotEventLog(l); err != nil {
+		p.logger.Error("failed to WriteBotEventLog", zap.Error(err), zap.Stringer("requestId", l.RequestID))
+	}
+}
+
+// sendEventWS WebSocketでBotにイベントを送信します
+//
+// 送信バッファに書き込めた時点で成功とみなし、ログのCodeは0になります。
+func (p *Processor) sendEventWS(b *model.Bot, l *model.BotEventLog) (ok bool) {
+	start := time.Now()
+	err := p.ws.WriteMessage(b.ID, l.Event, l.RequestID, []byte(l.Body))
+	stop := time.Now()
+
+	l.Latency = stop.Sub(start).Nanoseconds()
+	l.DateTime = time.Now()
+	if err != nil {
+		l.Error = err.Error()
+		l.Code = -1
+	} else {
+		l.Error = ""
+		l.Code = 0
+	}
+	eventSendCounter.WithLabelValues(b.ID.String(), strconv.Itoa(l.Code)).Inc()
+	return err == nil
+}
 
-	req, _ := http.NewRequest(http.MethodPost, b.PostURL, bytes.NewReader(body))
+// sendEventHTTP PostURLにHTTP POSTでBotにイベントを送信します
+func (p *Processor) sendEventHTTP(b *model.Bot, l *model.BotEventLog) (ok bool) {
+	req, _ := http.NewRequest(http.MethodPost, b.PostURL, strings.NewReader(l.Body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
-	req.Header.Set(headerTRAQBotEvent, event.String())
-	req.Header.Set(headerTRAQBotRequestID, reqID.String())
+	req.Header.Set(headerTRAQBotEvent, l.Event.String())
+	req.Header.Set(headerTRAQBotRequestID, l.RequestID.String())
 	req.Header.Set(headerTRAQBotVerificationToken, b.VerificationToken)
+	if len(b.SigningSecret) > 0 {
+		// 再送時も送信時刻で署名し直す
+		ts := time.Now().Unix()
+		req.Header.Set(signature.HeaderTimestamp, strconv.FormatInt(ts, 10))
+		req.Header.Set(signature.HeaderSignature, signature.Sign(b.SigningSecret, ts, []byte(l.Body)))
+	}
 
 	start := time.Now()
 	res, err := p.client.Do(req)
 	stop := time.Now()
 
+	l.Latency = stop.Sub(start).Nanoseconds()
+	l.DateTime = time.Now()
 	if err != nil {
 		eventSendCounter.WithLabelValues(b.ID.String(), "-1").Inc()
-		if err := p.repo.WriteBotEventLog(&model.BotEventLog{
-			RequestID: reqID,
-			BotID:     b.ID,
-			Event:     event,
-			Body:      string(body),
-			Error:     err.Error(),
-			Code:      -1,
-			Latency:   stop.Sub(start).Nanoseconds(),
-			DateTime:  time.Now(),
-		}); err != nil {
-			p.logger.Error("failed to WriteBotEventLog", zap.Error(err), zap.Stringer("requestId", reqID))
-		}
+		l.Error = err.Error()
+		l.Code = -1
 		return false
 	}
 	_ = res.Body.Close()
 
 	eventSendCounter.WithLabelValues(b.ID.String(), strconv.Itoa(res.StatusCode)).Inc()
-	if err := p.repo.WriteBotEventLog(&model.BotEventLog{
-		RequestID: reqID,
-		BotID:     b.ID,
-		Event:     event,
-		Body:      string(body),
-		Code:      res.StatusCode,
-		Latency:   stop.Sub(start).Nanoseconds(),
-		DateTime:  time.Now(),
-	}); err != nil {
-		p.logger.Error("failed to WriteBotEventLog", zap.Error(err), zap.Stringer("requestId", reqID))
-	}
-
+	l.Error = ""
+	l.Code = res.StatusCode
 	return res.StatusCode == http.StatusNoContent
 }
 
@@ -0,0 +1,157 @@
+package bot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// retryMaxAttempts イベント配送の最大試行回数
+	retryMaxAttempts = 8
+	// retryBaseInterval 最初の再送までの間隔 以降は再送毎に倍になる
+	retryBaseInterval = 30 * time.Second
+	// retryMaxInterval 再送間隔の上限
+	retryMaxInterval = time.Hour
+	// retryCheckInterval 再送予定のイベントの確認間隔
+	retryCheckInterval = 10 * time.Second
+	// retryBatchSize 一度に再送するイベントの最大数
+	retryBatchSize = 100
+	// retryLease 再送を獲得してから、他のインスタンスが再び再送を獲得できるようになるまでの時間
+	retryLease = time.Minute
+)
+
+// retryInterval attempts回目の試行に失敗した後、次に再送するまでの間隔を返します
+func retryInterval(attempts int) time.Duration {
+	d := retryBaseInterval
+	for i := 1; i < attempts && d < retryMaxInterval; i++ {
+		d *= 2
+	}
+	if d > retryMaxInterval {
+		d = retryMaxInterval
+	}
+	return d
+}
+
+// scheduleRetry 配送に失敗したイベントの再送をlに設定します
+//
+// 最大試行回数に達した場合は再送を諦め、Botを一時停止します。
+func (p *Processor) scheduleRetry(b *model.Bot, l *model.BotEventLog) {
+	if l.Attempts < retryMaxAttempts {
+		t := time.Now().Add(retryInterval(l.Attempts))
+		l.NextRetryAt = &t
+		l.DeadLetter = false
+		return
+	}
+
+	l.NextRetryAt = nil
+	l.DeadLetter = true
+	// 手動で再送したイベントが失敗した場合はBotを停止しない
+	if l.Attempts == retryMaxAttempts {
+		p.pauseBot(b.ID)
+	}
+}
+
+// pauseBot 配送に失敗し続けているBotを一時停止し、作成者に通知します
+func (p *Processor) pauseBot(botID uuid.UUID) {
+	b, err := p.repo.GetBotByID(botID)
+	if err != nil {
+		if err != repository.ErrNotFound {
+			p.logger.Error("failed to GetBotByID", zap.Error(err), zap.Stringer("id", botID))
+		}
+		return
+	}
+	if b.State != model.BotActive {
+		return
+	}
+	if err := p.repo.ChangeBotState(b.ID, model.BotPaused); err != nil {
+		p.logger.Error("failed to ChangeBotState", zap.Error(err))
+		return
+	}
+	b.State = model.BotPaused
+	p.hub.Publish(hub.Message{
+		Name: event.BotAutoPaused,
+		Fields: hub.Fields{
+			"bot_id": b.ID,
+			"bot":    b,
+		},
+	})
+}
+
+func (p *Processor) runRetry() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(retryCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.retry()
+		case <-p.closer:
+			return
+		}
+	}
+}
+
+// retry 再送予定日時を過ぎたイベントを再送します
+//
+// 複数のインスタンスが同じイベントを再送しないように、再送を獲得できたイベントのみを再送します。
+// 獲得したまま再送の結果が記録されなかった場合、retryLease後に再び再送の対象になります。
+func (p *Processor) retry() {
+	logs, err := p.repo.GetRetryableBotEventLogs(time.Now(), retryBatchSize)
+	if err != nil {
+		p.logger.Error("failed to GetRetryableBotEventLogs", zap.Error(err))
+		return
+	}
+
+	var wg sync.WaitGroup
+	bots := make(map[uuid.UUID]*model.Bot)
+	for _, l := range logs {
+		claimed, err := p.repo.ClaimBotEventRetry(l.RequestID, *l.NextRetryAt, time.Now().Add(retryLease))
+		if err != nil {
+			p.logger.Error("failed to ClaimBotEventRetry", zap.Error(err), zap.Stringer("requestId", l.RequestID))
+			continue
+		}
+		if !claimed {
+			// 他のインスタンスが再送中
+			continue
+		}
+
+		b, ok := bots[l.BotID]
+		if !ok {
+			b, err = p.repo.GetBotByID(l.BotID)
+			if err != nil && err != repository.ErrNotFound {
+				p.logger.Error("failed to GetBotByID", zap.Error(err), zap.Stringer("id", l.BotID))
+				continue
+			}
+			bots[l.BotID] = b
+		}
+
+		if b == nil || b.State != model.BotActive {
+			// 削除・停止されたBotへの再送は諦める
+			l.NextRetryAt = nil
+			l.DeadLetter = true
+			p.writeLog(l)
+			continue
+		}
+
+		l, b := l, b
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, retry := p.deliver(b, l); retry {
+				p.scheduleRetry(b, l)
+			} else {
+				l.NextRetryAt = nil
+				l.DeadLetter = false
+			}
+			p.writeLog(l)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,218 @@
+package bot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"go.uber.org/zap"
+)
+
+// retryRepository 再送のテスト用Repository
+type retryRepository struct {
+	repository.Repository
+	bots    map[uuid.UUID]*model.Bot
+	logs    []*model.BotEventLog
+	claimed map[uuid.UUID]bool
+	written map[uuid.UUID]*model.BotEventLog
+	mu      sync.Mutex
+}
+
+func newRetryRepository() *retryRepository {
+	return &retryRepository{
+		bots:    map[uuid.UUID]*model.Bot{},
+		claimed: map[uuid.UUID]bool{},
+		written: map[uuid.UUID]*model.BotEventLog{},
+	}
+}
+
+func (repo *retryRepository) GetBotByID(id uuid.UUID) (*model.Bot, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	b, ok := repo.bots[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	c := *b
+	return &c, nil
+}
+
+func (repo *retryRepository) ChangeBotState(id uuid.UUID, state model.BotState) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.bots[id].State = state
+	return nil
+}
+
+func (repo *retryRepository) GetRetryableBotEventLogs(before time.Time, _ int) ([]*model.BotEventLog, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var result []*model.BotEventLog
+	for _, l := range repo.logs {
+		if !l.NextRetryAt.After(before) {
+			c := *l
+			result = append(result, &c)
+		}
+	}
+	return result, nil
+}
+
+func (repo *retryRepository) ClaimBotEventRetry(requestID uuid.UUID, _, _ time.Time) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if repo.claimed[requestID] {
+		return false, nil
+	}
+	repo.claimed[requestID] = true
+	return true, nil
+}
+
+func (repo *retryRepository) WriteBotEventLog(l *model.BotEventLog) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	c := *l
+	repo.written[l.RequestID] = &c
+	return nil
+}
+
+func (repo *retryRepository) addBot(state model.BotState, postURL string) *model.Bot {
+	b := &model.Bot{ID: uuid.Must(uuid.NewV4()), Mode: model.BotModeHTTP, State: state, PostURL: postURL}
+	repo.bots[b.ID] = b
+	return b
+}
+
+func (repo *retryRepository) addLog(botID uuid.UUID) *model.BotEventLog {
+	at := time.Now().Add(-time.Second)
+	l := &model.BotEventLog{
+		RequestID:   uuid.Must(uuid.NewV4()),
+		BotID:       botID,
+		Event:       model.BotEventPing,
+		Attempts:    1,
+		NextRetryAt: &at,
+	}
+	repo.logs = append(repo.logs, l)
+	return l
+}
+
+func newTestProcessor(repo repository.Repository) *Processor {
+	return &Processor{
+		repo:   repo,
+		logger: zap.NewNop(),
+		hub:    hub.New(),
+		client: http.Client{Timeout: 3 * time.Second},
+		closer: make(chan struct{}),
+	}
+}
+
+func TestRetryInterval(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, retryBaseInterval, retryInterval(1))
+	assert.Equal(t, 2*retryBaseInterval, retryInterval(2))
+	assert.Equal(t, 4*retryBaseInterval, retryInterval(3))
+	assert.Equal(t, retryMaxInterval, retryInterval(retryMaxAttempts))
+	assert.Equal(t, retryMaxInterval, retryInterval(100))
+}
+
+func TestProcessor_scheduleRetry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("retry", func(t *testing.T) {
+		t.Parallel()
+		repo := newRetryRepository()
+		p := newTestProcessor(repo)
+		b := repo.addBot(model.BotActive, "")
+
+		l := &model.BotEventLog{Attempts: 1, DeadLetter: true}
+		p.scheduleRetry(b, l)
+		if assert.NotNil(t, l.NextRetryAt) {
+			assert.WithinDuration(t, time.Now().Add(retryBaseInterval), *l.NextRetryAt, time.Second)
+		}
+		assert.False(t, l.DeadLetter)
+		assert.Equal(t, model.BotActive, repo.bots[b.ID].State)
+	})
+
+	t.Run("dead letter", func(t *testing.T) {
+		t.Parallel()
+		repo := newRetryRepository()
+		p := newTestProcessor(repo)
+		b := repo.addBot(model.BotActive, "")
+
+		// 最大試行回数に達したらBotを一時停止する
+		l := &model.BotEventLog{Attempts: retryMaxAttempts}
+		p.scheduleRetry(b, l)
+		assert.Nil(t, l.NextRetryAt)
+		assert.True(t, l.DeadLetter)
+		assert.Equal(t, model.BotPaused, repo.bots[b.ID].State)
+	})
+
+	t.Run("manual redeliver", func(t *testing.T) {
+		t.Parallel()
+		repo := newRetryRepository()
+		p := newTestProcessor(repo)
+		b := repo.addBot(model.BotActive, "")
+
+		// 手動で再送したイベントが失敗してもBotを停止しない
+		l := &model.BotEventLog{Attempts: retryMaxAttempts + 1}
+		p.scheduleRetry(b, l)
+		assert.Nil(t, l.NextRetryAt)
+		assert.True(t, l.DeadLetter)
+		assert.Equal(t, model.BotActive, repo.bots[b.ID].State)
+	})
+}
+
+func TestProcessor_retry(t *testing.T) {
+	t.Parallel()
+
+	var (
+		received = map[string]int{}
+		mu       sync.Mutex
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received[r.Header.Get(headerTRAQBotRequestID)]++
+		mu.Unlock()
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	repo := newRetryRepository()
+	active := repo.addBot(model.BotActive, server.URL)
+	inactive := repo.addBot(model.BotInactive, server.URL)
+	delivered := repo.addLog(active.ID)
+	dropped := repo.addLog(inactive.ID)
+	deleted := repo.addLog(uuid.Must(uuid.NewV4()))
+
+	// 複数のインスタンスが同時に再送しても1度しか配送されない
+	p1 := newTestProcessor(repo)
+	p2 := newTestProcessor(repo)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); p1.retry() }()
+	go func() { defer wg.Done(); p2.retry() }()
+	wg.Wait()
+
+	assert.Equal(t, map[string]int{delivered.RequestID.String(): 1}, received)
+	if l, ok := repo.written[delivered.RequestID]; assert.True(t, ok) {
+		assert.Equal(t, 2, l.Attempts)
+		assert.Nil(t, l.NextRetryAt)
+		assert.False(t, l.DeadLetter)
+	}
+
+	// 停止・削除されたBotへの再送は諦める
+	for _, id := range []uuid.UUID{dropped.RequestID, deleted.RequestID} {
+		l, ok := repo.written[id]
+		require.True(t, ok)
+		assert.Nil(t, l.NextRetryAt)
+		assert.True(t, l.DeadLetter)
+		assert.Equal(t, 1, l.Attempts)
+	}
+}
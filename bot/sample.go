@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils/message"
+)
+
+const sampleText = "This is a sample message."
+
+// makeSamplePayload テストイベント用のサンプルペイロードを生成します
+//
+// userはイベントを起こしたユーザーとして使用されます。IDなどはランダムに生成されるため、実在するものではありません。
+// 対応していないイベントの場合、okがfalseになります。
+func makeSamplePayload(ev model.BotEvent, user model.UserInfo) (payload interface{}, ok bool) {
+	now := time.Now()
+	base := makeBasePayload()
+	u := makeUserPayload(user)
+	channelID := uuid.Must(uuid.NewV4())
+	messageID := uuid.Must(uuid.NewV4())
+	stampID := uuid.Must(uuid.NewV4())
+	msg := messagePayload{
+		ID:        messageID,
+		User:      u,
+		ChannelID: channelID,
+		Text:      sampleText,
+		PlainText: sampleText,
+		Embedded:  []*message.EmbeddedInfo{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	ch := channelPayload{
+		ID:        channelID,
+		Name:      "sample",
+		Path:      "#sample",
+		ParentID:  uuid.Nil,
+		Creator:   u,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	switch ev {
+	case model.BotEventPing:
+		return &pingPayload{basePayload: base}, true
+	case model.BotEventJoined, model.BotEventLeft:
+		return &joinAndLeftPayload{basePayload: base, Channel: ch}, true
+	case model.BotEventMessageCreated, model.BotEventMentionMessageCreated:
+		return &messageCreatedPayload{basePayload: base, Message: msg}, true
+	case model.BotEventDirectMessageCreated:
+		return &directMessageCreatedPayload{basePayload: base, Message: msg}, true
+	case model.BotEventMessageUpdated:
+		return &messageUpdatedPayload{basePayload: base, Message: msg}, true
+	case model.BotEventMessageDeleted:
+		return &messageDeletedPayload{basePayload: base, MessageID: messageID, ChannelID: channelID}, true
+	case model.BotEventMessageStamped:
+		return &messageStampedPayload{basePayload: base, MessageID: messageID, ChannelID: channelID, StampID: stampID, StampName: "sample", User: u, Count: 1}, true
+	case model.BotEventMessageUnstamped:
+		return &messageUnstampedPayload{basePayload: base, MessageID: messageID, ChannelID: channelID, StampID: stampID, StampName: "sample", User: u}, true
+	case model.BotEventMessagePinned, model.BotEventMessageUnpinned:
+		return &messagePinnedAndUnpinnedPayload{basePayload: base, MessageID: messageID, ChannelID: channelID, User: u}, true
+	case model.BotEventChannelCreated:
+		return &channelCreatedPayload{basePayload: base, Channel: ch}, true
+	case model.BotEventChannelTopicChanged:
+		return &channelTopicChangedPayload{basePayload: base, Channel: ch, Topic: "sample topic", Updater: u}, true
+	case model.BotEventChannelRenamed:
+		return &channelRenamedPayload{basePayload: base, Channel: ch, OldName: "old-sample", Updater: u}, true
+	case model.BotEventChannelMoved:
+		return &channelMovedPayload{basePayload: base, Channel: ch, OldParentID: uuid.Must(uuid.NewV4()), Updater: u}, true
+	case model.BotEventChannelVisibilityChanged:
+		return &channelVisibilityChangedPayload{basePayload: base, Channel: ch, Visibility: true, Updater: u}, true
+	case model.BotEventUserCreated:
+		return &userCreatedPayload{basePayload: base, User: u}, true
+	case model.BotEventStampCreated:
+		return &stampCreatedPayload{basePayload: base, ID: stampID, Name: "sample", FileID: uuid.Must(uuid.NewV4()), Creator: u}, true
+	case model.BotEventUserGroupMemberAdded, model.BotEventUserGroupMemberRemoved:
+		return &userGroupMemberAddedAndRemovedPayload{
+			basePayload: base,
+			Group:       userGroupPayload{ID: uuid.Must(uuid.NewV4()), Name: "sample", Type: ""},
+			User:        u,
+		}, true
+	case model.BotEventUserTagAdded, model.BotEventUserTagUpdated, model.BotEventUserTagRemoved:
+		return &userTagPayload{basePayload: base, User: u, TagID: uuid.Must(uuid.NewV4()), Tag: "sample"}, true
+	case model.BotEventCommandInvoked:
+		return &commandInvokedPayload{
+			basePayload: base,
+			Command:     "sample",
+			Arguments:   map[string]interface{}{},
+			Text:        "/sample",
+			ChannelID:   channelID,
+			MessageID:   &messageID,
+			User:        u,
+		}, true
+	case model.BotEventInteraction:
+		return &interactionPayload{
+			basePayload:   base,
+			MessageID:     messageID,
+			ChannelID:     channelID,
+			ComponentID:   "sample",
+			ComponentType: model.MessageComponentButton,
+			Value:         "sample",
+			User:          u,
+		}, true
+	default:
+		return nil, false
+	}
+}
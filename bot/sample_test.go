@@ -0,0 +1,26 @@
+package bot
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/traPtitech/traQ/model"
+)
+
+func TestMakeSamplePayload(t *testing.T) {
+	t.Parallel()
+
+	user := &model.User{ID: uuid.Must(uuid.NewV4()), Name: "sample"}
+	for ev := range model.BotEventSet {
+		payload, ok := makeSamplePayload(ev, user)
+		if assert.True(t, ok, ev) {
+			_, err := json.Marshal(payload)
+			assert.NoError(t, err, ev)
+		}
+	}
+
+	_, ok := makeSamplePayload("UNKNOWN", user)
+	assert.False(t, ok)
+}
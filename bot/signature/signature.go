@@ -0,0 +1,88 @@
+// Package signature BOTイベントリクエストの署名
+//
+// traQはHTTP Modeのbotにイベントを送信する際、リクエストに以下のヘッダーを付与します。
+//
+//	X-TRAQ-BOT-TIMESTAMP: 送信時刻(UNIX時間, 秒)
+//	X-TRAQ-BOT-SIGNATURE: "sha256=" + hex(HMAC-SHA-256(署名シークレット, タイムスタンプ + "." + リクエストボディ))
+//
+// bot側はVerifyRequestで署名とタイムスタンプを検証することで、リクエストがtraQから送信されたものであり、
+// 改竄・再送されていないことを確認できます。
+package signature
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	hmacutil "github.com/traPtitech/traQ/utils/hmac"
+)
+
+const (
+	// HeaderSignature 署名ヘッダー
+	HeaderSignature = "X-TRAQ-BOT-SIGNATURE"
+	// HeaderTimestamp タイムスタンプヘッダー
+	HeaderTimestamp = "X-TRAQ-BOT-TIMESTAMP"
+
+	// DefaultTolerance 許容するタイムスタンプのずれのデフォルト値
+	DefaultTolerance = 5 * time.Minute
+
+	signaturePrefix = "sha256="
+)
+
+var (
+	// ErrNoSignature 署名・タイムスタンプヘッダーがありません
+	ErrNoSignature = errors.New("no signature")
+	// ErrInvalidTimestamp タイムスタンプが不正か、許容範囲外です
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+	// ErrInvalidSignature 署名が一致しません
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Sign タイムスタンプとリクエストボディから署名ヘッダーの値を計算します
+func Sign(secret string, timestamp int64, body []byte) string {
+	data := append([]byte(strconv.FormatInt(timestamp, 10)+"."), body...)
+	return signaturePrefix + hex.EncodeToString(hmacutil.SHA256(data, secret))
+}
+
+// Verify 署名ヘッダー・タイムスタンプヘッダーの値を検証します
+//
+// タイムスタンプがnowからtolerance以上ずれている場合、ErrInvalidTimestampを返します。
+// 署名が一致しない場合、ErrInvalidSignatureを返します。
+func Verify(secret, signature, timestamp string, body []byte, now time.Time, tolerance time.Duration) error {
+	if len(signature) == 0 || len(timestamp) == 0 {
+		return ErrNoSignature
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	if d := now.Sub(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+		return ErrInvalidTimestamp
+	}
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, ts, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyRequest traQから送信されたBOTイベントリクエストを検証し、リクエストボディを返します
+//
+// リクエストボディは読み込まれます。タイムスタンプはDefaultToleranceまでのずれを許容します。
+func VerifyRequest(r *http.Request, secret string) ([]byte, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := Verify(secret, r.Header.Get(HeaderSignature), r.Header.Get(HeaderTimestamp), body, time.Now(), DefaultTolerance); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
@@ -0,0 +1,61 @@
+package signature
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+
+	s := Sign("secret", 1600000000, []byte(`{"eventTime":"2020-09-13T12:26:40Z"}`))
+	assert.True(t, strings.HasPrefix(s, "sha256="))
+	assert.Len(t, s, len("sha256=")+64)
+	assert.Equal(t, s, Sign("secret", 1600000000, []byte(`{"eventTime":"2020-09-13T12:26:40Z"}`)))
+	assert.NotEqual(t, s, Sign("secret", 1600000001, []byte(`{"eventTime":"2020-09-13T12:26:40Z"}`)))
+	assert.NotEqual(t, s, Sign("secret2", 1600000000, []byte(`{"eventTime":"2020-09-13T12:26:40Z"}`)))
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"eventTime":"2020-09-13T12:26:40Z"}`)
+	now := time.Unix(1600000000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := Sign("secret", now.Unix(), body)
+
+	assert.NoError(t, Verify("secret", sig, ts, body, now, DefaultTolerance))
+	assert.NoError(t, Verify("secret", sig, ts, body, now.Add(DefaultTolerance), DefaultTolerance))
+	assert.Equal(t, ErrNoSignature, Verify("secret", "", ts, body, now, DefaultTolerance))
+	assert.Equal(t, ErrNoSignature, Verify("secret", sig, "", body, now, DefaultTolerance))
+	assert.Equal(t, ErrInvalidTimestamp, Verify("secret", sig, "abc", body, now, DefaultTolerance))
+	assert.Equal(t, ErrInvalidTimestamp, Verify("secret", sig, ts, body, now.Add(DefaultTolerance+time.Second), DefaultTolerance))
+	assert.Equal(t, ErrInvalidTimestamp, Verify("secret", sig, ts, body, now.Add(-DefaultTolerance-time.Second), DefaultTolerance))
+	assert.Equal(t, ErrInvalidSignature, Verify("wrong", sig, ts, body, now, DefaultTolerance))
+	assert.Equal(t, ErrInvalidSignature, Verify("secret", sig, ts, []byte(`{}`), now, DefaultTolerance))
+	assert.Equal(t, ErrInvalidSignature, Verify("secret", strings.TrimPrefix(sig, "sha256="), ts, body, now, DefaultTolerance))
+}
+
+func TestVerifyRequest(t *testing.T) {
+	t.Parallel()
+
+	body := `{"eventTime":"2020-09-13T12:26:40Z"}`
+	now := time.Now().Unix()
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now, 10))
+	req.Header.Set(HeaderSignature, Sign("secret", now, []byte(body)))
+	b, err := VerifyRequest(req, "secret")
+	if assert.NoError(t, err) {
+		assert.Equal(t, body, string(b))
+	}
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader(body))
+	_, err = VerifyRequest(req, "secret")
+	assert.Equal(t, ErrNoSignature, err)
+}
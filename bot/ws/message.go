@@ -0,0 +1,19 @@
+package ws
+
+import (
+	"github.com/gofrs/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/traPtitech/traQ/model"
+)
+
+// message BOTに送信するイベントメッセージ
+type message struct {
+	Type  model.BotEvent      `json:"type"`
+	ReqID uuid.UUID           `json:"reqId"`
+	Body  jsoniter.RawMessage `json:"body"`
+}
+
+func makeMessage(event model.BotEvent, reqID uuid.UUID, body []byte) []byte {
+	b, _ := json.Marshal(&message{Type: event, ReqID: reqID, Body: body})
+	return b
+}
@@ -0,0 +1,94 @@
+package ws
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/websocket"
+	"sync"
+	"time"
+)
+
+type rawMessage struct {
+	t    int
+	data []byte
+}
+
+type session struct {
+	conn  *websocket.Conn
+	botID uuid.UUID
+	send  chan *rawMessage
+	open  bool
+	sync.RWMutex
+}
+
+func (s *session) readLoop() {
+	s.conn.SetReadLimit(maxReadMessageSize)
+	_ = s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	s.conn.SetPongHandler(func(string) error {
+		_ = s.conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	for {
+		// BOTからのメッセージは読み捨てる
+		if _, _, err := s.conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+}
+
+func (s *session) writeLoop() {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-s.send:
+			if !ok {
+				return
+			}
+
+			if err := s.write(msg.t, msg.data); err != nil {
+				return
+			}
+
+			if msg.t == websocket.CloseMessage {
+				// 読み込みループを終了させる
+				_ = s.conn.Close()
+				return
+			}
+
+		case <-ticker.C:
+			_ = s.write(websocket.PingMessage, []byte{})
+		}
+	}
+}
+
+func (s *session) writeMessage(msg *rawMessage) error {
+	s.RLock()
+	defer s.RUnlock()
+	if !s.open {
+		return ErrAlreadyClosed
+	}
+
+	select {
+	case s.send <- msg:
+	default:
+		return ErrBufferIsFull
+	}
+	return nil
+}
+
+func (s *session) write(messageType int, data []byte) error {
+	_ = s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return s.conn.WriteMessage(messageType, data)
+}
+
+func (s *session) close() {
+	s.Lock()
+	defer s.Unlock()
+	if s.open {
+		s.open = false
+		s.conn.Close()
+		close(s.send)
+	}
+}
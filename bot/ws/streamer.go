@@ -0,0 +1,170 @@
+package ws
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/traPtitech/traQ/model"
+	"go.uber.org/zap"
+	"net/http"
+	"sync"
+)
+
+var (
+	// ErrAlreadyClosed 既に閉じられています
+	ErrAlreadyClosed = errors.New("already closed")
+	// ErrBufferIsFull 送信バッファが溢れました
+	ErrBufferIsFull = errors.New("buffer is full")
+	// ErrNotConnected BOTがWebSocketに接続していません
+	ErrNotConnected = errors.New("bot is not connected")
+
+	wsConnectionCounter = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "traq",
+		Name:      "bot_ws_connections",
+	})
+)
+
+// Streamer BOTイベント配送用WebSocketストリーマー
+//
+// HTTPのエンドポイントを公開できないBOTは、このストリーマーに接続してイベントを受信します。
+// 同じBOTが複数接続している場合、イベントは全ての接続に送信されます。
+type Streamer struct {
+	logger   *zap.Logger
+	sessions map[uuid.UUID]map[*session]struct{}
+	open     bool
+	mu       sync.RWMutex
+}
+
+// NewStreamer BOT用WebSocketストリーマーを生成します
+func NewStreamer(logger *zap.Logger) *Streamer {
+	return &Streamer{
+		logger:   logger,
+		sessions: make(map[uuid.UUID]map[*session]struct{}),
+		open:     true,
+	}
+}
+
+// ServeHTTP 指定したBOTとしてWebSocket接続を開始し、切断されるまでブロックします
+//
+// 呼び出し元でリクエストがBOTユーザーによるものであることを確認する必要があります。
+func (s *Streamer) ServeHTTP(rw http.ResponseWriter, r *http.Request, botID uuid.UUID) {
+	if s.IsClosed() {
+		http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(rw, r, rw.Header())
+	if err != nil {
+		return
+	}
+
+	session := &session{
+		conn:  conn,
+		botID: botID,
+		send:  make(chan *rawMessage, messageBufferSize),
+		open:  true,
+	}
+	if !s.register(session) {
+		session.close()
+		return
+	}
+	wsConnectionCounter.Inc()
+
+	go session.writeLoop()
+	session.readLoop()
+
+	wsConnectionCounter.Dec()
+	s.unregister(session)
+	session.close()
+}
+
+func (s *Streamer) register(ss *session) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.open {
+		return false
+	}
+	if _, ok := s.sessions[ss.botID]; !ok {
+		s.sessions[ss.botID] = make(map[*session]struct{})
+	}
+	s.sessions[ss.botID][ss] = struct{}{}
+	return true
+}
+
+func (s *Streamer) unregister(ss *session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions[ss.botID], ss)
+	if len(s.sessions[ss.botID]) == 0 {
+		delete(s.sessions, ss.botID)
+	}
+}
+
+// WriteMessage 指定したBOTの全ての接続にイベントを送信します
+//
+// 一つ以上の接続に送信できた場合、nilを返します。
+// BOTが接続していない場合、ErrNotConnectedを返します。
+func (s *Streamer) WriteMessage(botID uuid.UUID, event model.BotEvent, reqID uuid.UUID, body []byte) error {
+	m := &rawMessage{
+		t:    websocket.TextMessage,
+		data: makeMessage(event, reqID, body),
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sessions := s.sessions[botID]
+	if len(sessions) == 0 {
+		return ErrNotConnected
+	}
+	err := ErrNotConnected
+	for session := range sessions {
+		if e := session.writeMessage(m); e != nil {
+			if e == ErrBufferIsFull {
+				s.logger.Warn("Discard a message because the session's buffer is full.",
+					zap.Stringer("botId", botID), zap.Stringer("requestId", reqID))
+				err = e
+			}
+			continue
+		}
+		err = nil
+	}
+	return err
+}
+
+// IsConnected 指定したBOTが接続しているかどうか
+func (s *Streamer) IsConnected(botID uuid.UUID) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.sessions[botID]) > 0
+}
+
+// IsClosed ストリーマーが停止しているかどうか
+func (s *Streamer) IsClosed() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !s.open
+}
+
+// Close ストリーマーを停止し、全ての接続を切断します
+func (s *Streamer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.open {
+		return ErrAlreadyClosed
+	}
+	s.open = false
+
+	m := &rawMessage{
+		t:    websocket.CloseMessage,
+		data: websocket.FormatCloseMessage(websocket.CloseServiceRestart, "Server is stopping..."),
+	}
+	for _, sessions := range s.sessions {
+		for session := range sessions {
+			_ = session.writeMessage(m)
+		}
+	}
+	s.sessions = make(map[uuid.UUID]map[*session]struct{})
+	return nil
+}
@@ -3,8 +3,13 @@ package cmd
 import (
 	"fmt"
 	"github.com/jinzhu/gorm"
+	"github.com/leandro-lugaresi/hub"
 	"github.com/spf13/viper"
+	"github.com/traPtitech/traQ/event/bridge"
+	"github.com/traPtitech/traQ/router/middlewares"
+	"github.com/traPtitech/traQ/search"
 	"github.com/traPtitech/traQ/utils/storage"
+	"go.uber.org/zap"
 	"time"
 )
 
@@ -92,6 +97,61 @@ type Config struct {
 		} `mapstructure:"swift" yaml:"swift"`
 	} `mapstructure:"storage" yaml:"storage"`
 
+	// Message メッセージ設定
+	Message struct {
+		// EditLimit メッセージを投稿後に編集可能な期間(秒). 0は無制限 (default: 0)
+		EditLimit int `mapstructure:"editLimit" yaml:"editLimit"`
+		// TrashRetention 削除されたメッセージの保存期間(日). 経過したメッセージは完全に削除されます. 0は無期限 (default: 0)
+		TrashRetention int `mapstructure:"trashRetention" yaml:"trashRetention"`
+	} `mapstructure:"message" yaml:"message"`
+
+	// RateLimit Bot・Webhookのレート制限設定
+	RateLimit struct {
+		// Enabled 有効かどうか (default: true)
+		Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+		// Bot Botのレート制限 (default: messagesPerChannel: 30, stamps: 120, apiCalls: 600)
+		Bot rateLimitConfig `mapstructure:"bot" yaml:"bot"`
+		// PrivilegedBot 特権Botのレート制限. 特権BotにはBotの代わりにこちらが適用されます (default: 全て0)
+		PrivilegedBot rateLimitConfig `mapstructure:"privilegedBot" yaml:"privilegedBot"`
+		// Webhook Webhookのレート制限
+		Webhook struct {
+			// MessagesPerChannel 1分あたりのチャンネル毎のメッセージ投稿数. 0は無制限 (default: 30)
+			MessagesPerChannel int `mapstructure:"messagesPerChannel" yaml:"messagesPerChannel"`
+		} `mapstructure:"webhook" yaml:"webhook"`
+	} `mapstructure:"rateLimit" yaml:"rateLimit"`
+
+	// Search メッセージ検索設定
+	Search struct {
+		// Engine 検索エンジン (default: memory)
+		// 	memory: プロセス内インデックス
+		// 	none: 検索を無効化
+		Engine string `mapstructure:"engine" yaml:"engine"`
+
+		// Memory プロセス内インデックス設定
+		Memory struct {
+			// IndexFile インデックスファイルパス. 空の場合はファイルに保存しない (default: ./search.idx)
+			IndexFile string `mapstructure:"indexFile" yaml:"indexFile"`
+		} `mapstructure:"memory" yaml:"memory"`
+	} `mapstructure:"search" yaml:"search"`
+
+	// Cluster 複数インスタンス構成設定
+	Cluster struct {
+		// Bridge インスタンス間イベントブリッジ (default: none)
+		// 	none: 単一インスタンス構成
+		// 	tcp: 他インスタンスとTCPで直接接続
+		Bridge string `mapstructure:"bridge" yaml:"bridge"`
+
+		// TCP TCPブリッジ設定
+		TCP struct {
+			// Listen 待ち受けアドレス (default: 127.0.0.1:3001)
+			Listen string `mapstructure:"listen" yaml:"listen"`
+			// Peers 他インスタンスのアドレス. 自インスタンスのアドレスが含まれていても構いません
+			Peers []string `mapstructure:"peers" yaml:"peers"`
+			// Secret フレーム認証用の共有シークレット. 全インスタンスで同じ値を設定してください (必須)
+			Secret string `mapstructure:"secret" yaml:"secret"`
+		} `mapstructure:"tcp" yaml:"tcp"`
+	} `mapstructure:"cluster" yaml:"cluster"`
+
 	// GCP Google Cloud Platform設定
 	GCP struct {
 		// ServiceAccount サービスアカウント設定
@@ -184,6 +244,24 @@ type Config struct {
 	} `mapstructure:"externalAuth" yaml:"externalAuth"`
 }
 
+// rateLimitConfig Botのレート制限設定
+type rateLimitConfig struct {
+	// MessagesPerChannel 1分あたりのチャンネル毎のメッセージ投稿数. 0は無制限
+	MessagesPerChannel int `mapstructure:"messagesPerChannel" yaml:"messagesPerChannel"`
+	// Stamps 1分あたりのスタンプ付与数. 0は無制限
+	Stamps int `mapstructure:"stamps" yaml:"stamps"`
+	// APICalls 1分あたりのAPI呼び出し数. 0は無制限
+	APICalls int `mapstructure:"apiCalls" yaml:"apiCalls"`
+}
+
+func (c rateLimitConfig) toMiddlewareConfig() middlewares.RateLimitConfig {
+	return middlewares.RateLimitConfig{
+		MessagesPerChannel: c.MessagesPerChannel,
+		Stamps:             c.Stamps,
+		APICalls:           c.APICalls,
+	}
+}
+
 // Configのデフォルト値設定
 func init() {
 	viper.SetDefault("dev", false)
@@ -212,6 +290,22 @@ func init() {
 	viper.SetDefault("storage.swift.authUrl", "")
 	viper.SetDefault("storage.swift.tempUrlKey", "")
 	viper.SetDefault("storage.swift.cacheDir", "")
+	viper.SetDefault("message.editLimit", 0)
+	viper.SetDefault("message.trashRetention", 0)
+	viper.SetDefault("rateLimit.enabled", true)
+	viper.SetDefault("rateLimit.bot.messagesPerChannel", 30)
+	viper.SetDefault("rateLimit.bot.stamps", 120)
+	viper.SetDefault("rateLimit.bot.apiCalls", 600)
+	viper.SetDefault("rateLimit.privilegedBot.messagesPerChannel", 0)
+	viper.SetDefault("rateLimit.privilegedBot.stamps", 0)
+	viper.SetDefault("rateLimit.privilegedBot.apiCalls", 0)
+	viper.SetDefault("rateLimit.webhook.messagesPerChannel", 30)
+	viper.SetDefault("search.engine", "memory")
+	viper.SetDefault("search.memory.indexFile", "./search.idx")
+	viper.SetDefault("cluster.bridge", "none")
+	viper.SetDefault("cluster.tcp.listen", "127.0.0.1:3001")
+	viper.SetDefault("cluster.tcp.peers", []string{})
+	viper.SetDefault("cluster.tcp.secret", "")
 	viper.SetDefault("gcp.serviceAccount.projectId", "")
 	viper.SetDefault("gcp.serviceAccount.file", "")
 	viper.SetDefault("gcp.stackdriver.profiler.enabled", false)
@@ -274,6 +368,28 @@ func (c Config) getFileStorage() (storage.FileStorage, error) {
 	}
 }
 
+func (c Config) getSearchEngine(hub *hub.Hub, logger *zap.Logger) (search.Engine, error) {
+	switch c.Search.Engine {
+	case "none":
+		return search.NewNullEngine(), nil
+	default:
+		return search.NewMemoryEngine(hub, logger, c.Search.Memory.IndexFile)
+	}
+}
+
+func (c Config) getEventBridge(hub *hub.Hub, logger *zap.Logger) (*bridge.Bridge, error) {
+	switch c.Cluster.Bridge {
+	case "tcp":
+		t, err := bridge.NewTCPTransport(c.Cluster.TCP.Listen, c.Cluster.TCP.Peers, c.Cluster.TCP.Secret, logger.Named("tcp"))
+		if err != nil {
+			return nil, err
+		}
+		return bridge.New(hub, t, logger), nil
+	default:
+		return nil, nil
+	}
+}
+
 func (c Config) getDatabase() (*gorm.DB, error) {
 	engine, err := gorm.Open("mysql", fmt.Sprintf(
 		"%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&collation=utf8mb4_general_ci&parseTime=true",
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/spf13/cobra"
+	"github.com/traPtitech/traQ/exporter"
+	"github.com/traPtitech/traQ/repository"
+	"go.uber.org/zap"
+)
+
+var exportOutput string
+
+func init() {
+	flags := exportChannelCommand.Flags()
+	flags.StringVarP(&exportOutput, "output", "o", "export.zip", "output file path")
+}
+
+var exportChannelCommand = &cobra.Command{
+	Use:   "export-channel <channelID>",
+	Short: "Export channel history as a zip archive",
+	Long:  "Export the history of the channel and its descendant channels, including stamps, pins, edit history, users and attached files, as a zip archive.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		channelID, err := uuid.FromString(args[0])
+		if err != nil {
+			return err
+		}
+
+		logger := getLogger()
+		defer logger.Sync()
+
+		// Database
+		engine, err := c.getDatabase()
+		if err != nil {
+			return err
+		}
+		defer engine.Close()
+
+		// FileStorage
+		fs, err := c.getFileStorage()
+		if err != nil {
+			return err
+		}
+
+		// Repository
+		repo, err := repository.NewGormRepository(engine, fs, hub.New(), logger.Named("repository"))
+		if err != nil {
+			return err
+		}
+		if _, err := repo.Sync(); err != nil {
+			return err
+		}
+
+		f, err := os.Create(exportOutput)
+		if err != nil {
+			return err
+		}
+		if err := exporter.Export(repo, channelID, uuid.Nil, f); err != nil {
+			f.Close()
+			os.Remove(exportOutput)
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		logger.Info("channel exported", zap.Stringer("channelId", channelID), zap.String("file", exportOutput))
+		return nil
+	},
+}
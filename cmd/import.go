@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/spf13/cobra"
+	"github.com/traPtitech/traQ/importer"
+	"github.com/traPtitech/traQ/repository"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	importDryRun     bool
+	importParent     string
+	importUserMap    string
+	importSlackToken string
+)
+
+func init() {
+	flags := importCommand.Flags()
+	flags.BoolVar(&importDryRun, "dry-run", false, "print the import report without writing anything")
+	flags.StringVar(&importParent, "parent", "", "path of the public channel under which imported channels are created")
+	flags.StringVar(&importUserMap, "user-map", "", "yaml file mapping source user names to traQ user names")
+	flags.StringVar(&importSlackToken, "slack-token", "", "slack token used to download files not included in the archive")
+}
+
+var importCommand = &cobra.Command{
+	Use:   "import <slack|mattermost> <file>",
+	Short: "Import workspace from Slack or Mattermost export file",
+	Long: `Import channels, users, messages, reactions and files from a Slack export archive (.zip) or a Mattermost bulk export (.jsonl, or .zip containing it and attachments).
+Source users are mapped to traQ users with the same name (or as specified by --user-map), otherwise deactivated placeholder users are created.
+Reactions are imported as stamps with the same name. Run with --dry-run first to check the report.
+Importing the same file twice duplicates messages. Rebuild the search index after importing.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger := getLogger()
+		defer logger.Sync()
+
+		userMap := map[string]string{}
+		if len(importUserMap) > 0 {
+			f, err := os.Open(importUserMap)
+			if err != nil {
+				return err
+			}
+			err = yaml.NewDecoder(f).Decode(&userMap)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("invalid user map: %w", err)
+			}
+		}
+
+		// Source
+		var ws *importer.Workspace
+		switch args[0] {
+		case importer.SlackSource:
+			r, err := zip.OpenReader(args[1])
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			ws, err = importer.ReadSlack(&r.Reader, importSlackToken)
+			if err != nil {
+				return err
+			}
+		case importer.MattermostSource:
+			var err error
+			ws, err = readMattermostFile(args[1])
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown source: %s", args[0])
+		}
+
+		// Database
+		engine, err := c.getDatabase()
+		if err != nil {
+			return err
+		}
+		defer engine.Close()
+
+		// FileStorage
+		fs, err := c.getFileStorage()
+		if err != nil {
+			return err
+		}
+
+		// Repository
+		repo, err := repository.NewGormRepository(engine, fs, hub.New(), logger.Named("repository"))
+		if err != nil {
+			return err
+		}
+		if _, err := repo.Sync(); err != nil {
+			return err
+		}
+
+		parentID := uuid.Nil
+		if len(importParent) > 0 {
+			parentID, err = findChannelByPath(repo, importParent)
+			if err != nil {
+				return err
+			}
+		}
+
+		report, err := importer.Import(repo, ws, importer.Options{
+			ParentID: parentID,
+			UserMap:  userMap,
+			DryRun:   importDryRun,
+		})
+		if report != nil {
+			if err := report.Print(os.Stdout); err != nil {
+				return err
+			}
+		}
+		return err
+	},
+}
+
+// readMattermostFile Mattermostのバルクエクスポートファイルを読み込みます
+func readMattermostFile(name string) (*importer.Workspace, error) {
+	if path.Ext(name) != ".zip" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return importer.ReadMattermost(f, nil)
+	}
+
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range r.File {
+		if path.Ext(f.Name) != ".jsonl" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		// 添付ファイルはインポート時に読み込むため、アーカイブは閉じない
+		return importer.ReadMattermost(rc, &r.Reader)
+	}
+	r.Close()
+	return nil, fmt.Errorf("no .jsonl file is found in %s", name)
+}
+
+// findChannelByPath パブリックチャンネルのパスからチャンネルUUIDを取得します
+func findChannelByPath(repo repository.Repository, channelPath string) (uuid.UUID, error) {
+	channels, err := repo.GetChannelsByUserID(uuid.Nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	for _, ch := range channels {
+		p, err := repo.GetChannelPath(ch.ID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if strings.EqualFold(p, strings.Trim(channelPath, "/#")) {
+			return ch.ID, nil
+		}
+	}
+	return uuid.Nil, fmt.Errorf("channel %s is not found", channelPath)
+}
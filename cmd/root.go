@@ -46,6 +46,9 @@ func init() {
 	rootCommand.AddCommand(migrateCommand)
 	rootCommand.AddCommand(confCommand)
 	rootCommand.AddCommand(versionCommand)
+	rootCommand.AddCommand(searchIndexCommand)
+	rootCommand.AddCommand(exportChannelCommand)
+	rootCommand.AddCommand(importCommand)
 
 	flags := rootCommand.PersistentFlags()
 	flags.StringVarP(&configFile, "config", "c", "", "config file path")
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/spf13/cobra"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/search"
+	"go.uber.org/zap"
+)
+
+var searchIndexCommand = &cobra.Command{
+	Use:   "search-index",
+	Short: "Rebuild message search index from database",
+	Long:  "Rebuild message search index from database. Stop the traQ server before running this command, or the rebuilt index will be overwritten.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if c.Search.Engine != "memory" {
+			return fmt.Errorf("search engine '%s' does not support rebuilding index", c.Search.Engine)
+		}
+		if len(c.Search.Memory.IndexFile) == 0 {
+			return fmt.Errorf("search.memory.indexFile is not specified")
+		}
+
+		logger := getLogger()
+		defer logger.Sync()
+
+		// Database
+		engine, err := c.getDatabase()
+		if err != nil {
+			return err
+		}
+		defer engine.Close()
+
+		// FileStorage
+		fs, err := c.getFileStorage()
+		if err != nil {
+			return err
+		}
+
+		// Repository
+		repo, err := repository.NewGormRepository(engine, fs, hub.New(), logger.Named("repository"))
+		if err != nil {
+			return err
+		}
+		if _, err := repo.Sync(); err != nil {
+			return err
+		}
+
+		n, err := search.RebuildMemoryIndex(repo, c.Search.Memory.IndexFile)
+		if err != nil {
+			return err
+		}
+		logger.Info("search index rebuilt", zap.String("file", c.Search.Memory.IndexFile), zap.Int("messages", n))
+		return nil
+	},
+}
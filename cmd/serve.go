@@ -12,6 +12,8 @@ import (
 	"github.com/leandro-lugaresi/hub"
 	"github.com/spf13/cobra"
 	"github.com/traPtitech/traQ/bot"
+	botws "github.com/traPtitech/traQ/bot/ws"
+	"github.com/traPtitech/traQ/exporter"
 	"github.com/traPtitech/traQ/notification"
 	"github.com/traPtitech/traQ/notification/fcm"
 	rbac "github.com/traPtitech/traQ/rbac/impl"
@@ -21,7 +23,10 @@ import (
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router"
 	"github.com/traPtitech/traQ/router/auth"
+	"github.com/traPtitech/traQ/router/middlewares"
 	"github.com/traPtitech/traQ/router/sessions"
+	"github.com/traPtitech/traQ/scheduler"
+	"github.com/traPtitech/traQ/trash"
 	"github.com/traPtitech/traQ/utils/gormzap"
 	"github.com/traPtitech/traQ/utils/jwt"
 	"go.uber.org/zap"
@@ -109,7 +114,8 @@ var serveCommand = &cobra.Command{
 		}
 
 		// Bot Processor
-		bot.NewProcessor(repo, hub, logger.Named("bot_processor"))
+		bws := botws.NewStreamer(logger.Named("bot_ws"))
+		bp := bot.NewProcessor(repo, hub, bws, logger.Named("bot_processor"))
 
 		// JWT for QRCode
 		if priv := c.JWT.Keys.Private; priv != "" {
@@ -133,12 +139,33 @@ var serveCommand = &cobra.Command{
 
 		// Realtime Service
 		rt := realtime.NewService(hub)
-		wss := ws.NewStreamer(hub, rt, logger.Named("ws"))
+		wss := ws.NewStreamer(hub, rt, repo, logger.Named("ws"))
 		sses := sse.NewStreamer(hub)
 
+		// Search Engine
+		se, err := c.getSearchEngine(hub, logger.Named("search"))
+		if err != nil {
+			logger.Fatal("failed to setup search engine", zap.Error(err))
+		}
+
 		// Notification Service
 		notification.StartService(repo, hub, logger.Named("notification"), fcmClient, sses, wss, rt, c.Origin)
 
+		// Event Bridge
+		eb, err := c.getEventBridge(hub, logger.Named("bridge"))
+		if err != nil {
+			logger.Fatal("failed to setup event bridge", zap.Error(err))
+		}
+
+		// Scheduled Message Dispatcher
+		sd := scheduler.NewDispatcher(repo, logger.Named("scheduler"), c.Origin)
+
+		// Channel Export Worker
+		ew := exporter.NewWorker(repo, logger.Named("exporter"))
+
+		// Deleted Message Purger
+		tp := trash.NewPurger(repo, logger.Named("trash"), time.Duration(c.Message.TrashRetention)*24*time.Hour)
+
 		// HTTP Router
 		e := router.Setup(&router.Config{
 			Development:      c.DevMode,
@@ -150,13 +177,22 @@ var serveCommand = &cobra.Command{
 			AccessTokenExp:   c.OAuth2.AccessTokenExpire,
 			IsRefreshEnabled: c.OAuth2.IsRefreshEnabled,
 			SkyWaySecretKey:  c.SkyWay.SecretKey,
+			MessageEditLimit: time.Duration(c.Message.EditLimit) * time.Second,
 			Hub:              hub,
 			Repository:       repo,
 			RBAC:             r,
 			WS:               wss,
+			BotWS:            bws,
 			SSE:              sses,
 			Realtime:         rt,
+			SearchEngine:     se,
 			RootLogger:       logger,
+			RateLimit: router.RateLimitConfig{
+				Enabled:       c.RateLimit.Enabled,
+				Bot:           c.RateLimit.Bot.toMiddlewareConfig(),
+				PrivilegedBot: c.RateLimit.PrivilegedBot.toMiddlewareConfig(),
+				Webhook:       middlewares.RateLimitConfig{MessagesPerChannel: c.RateLimit.Webhook.MessagesPerChannel},
+			},
 			ExternalAuth: router.ExternalAuthConfig{
 				GitHub: auth.GithubProviderConfig{
 					ClientID:               c.ExternalAuth.GitHub.ClientID,
@@ -202,10 +238,21 @@ var serveCommand = &cobra.Command{
 		defer cancel()
 		sses.Dispose()
 		wss.Close()
+		bws.Close()
 		if err := e.Shutdown(ctx); err != nil {
 			logger.Warn("abnormal shutdown", zap.Error(err))
 		}
+		sd.Close()
+		ew.Close()
+		tp.Close()
+		bp.Close()
+		if eb != nil {
+			_ = eb.Close()
+		}
 		sessions.PurgeCache()
+		if err := se.Close(); err != nil {
+			logger.Warn("failed to close search engine", zap.Error(err))
+		}
 		logger.Info("traQ shutdown")
 	},
 }
@@ -0,0 +1,197 @@
+package bridge
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/traPtitech/traQ/event"
+	"go.uber.org/zap"
+)
+
+// ErrClosed 既に閉じられています
+var ErrClosed = errors.New("bridge is closed")
+
+// Transport インスタンス間のメッセージ転送路
+type Transport interface {
+	// Send 他の全インスタンスにデータを送信します
+	Send(data []byte) error
+	// Receive 他インスタンスから受信したデータを流すチャネルを返します
+	//
+	// Transportが閉じられた場合、チャネルは閉じられます
+	Receive() <-chan []byte
+	// Close 転送路を閉じます
+	Close() error
+}
+
+// DefaultTopics インスタンス間で転送するイベントのトピック
+//
+// 各インスタンスに接続しているクライアントへの通知と、インスタンス毎に持っているキャッシュ・インデックスの更新に必要なイベントです
+var DefaultTopics = []string{
+	event.UserCreated,
+	event.UserUpdated,
+	event.UserIconUpdated,
+	event.UserOnline,
+	event.UserOffline,
+	event.UserTagAdded,
+	event.UserTagUpdated,
+	event.UserTagRemoved,
+	event.UserGroupCreated,
+	event.UserGroupDeleted,
+	event.UserGroupMemberAdded,
+	event.UserGroupMemberRemoved,
+	event.MessageCreated,
+	event.MessageUpdated,
+	event.MessageDeleted,
+	event.MessageRestored,
+	event.MessageComponentsUpdated,
+	event.MessageStamped,
+	event.MessageUnstamped,
+	event.MessagePinned,
+	event.MessageUnpinned,
+	event.ThreadRead,
+	event.ChannelCreated,
+	event.ChannelUpdated,
+	event.ChannelDeleted,
+	event.ChannelRead,
+	event.ChannelStared,
+	event.ChannelUnstared,
+	event.ChannelViewersChanged,
+	event.ChannelMemberAdded,
+	event.ChannelMemberRemoved,
+	event.ChannelExportUpdated,
+	event.StampCreated,
+	event.StampUpdated,
+	event.StampDeleted,
+	event.StampPaletteCreated,
+	event.StampPaletteUpdated,
+	event.StampPaletteDeleted,
+	event.BotAutoPaused,
+	event.UserWebRTCStateChanged,
+	event.UserTyping,
+	event.ClipFolderCreated,
+	event.ClipFolderUpdated,
+	event.ClipFolderDeleted,
+	event.ClipFolderMessageDeleted,
+	event.ClipFolderMessageAdded,
+	event.DraftUpdated,
+}
+
+// syncTopics インスタンス間の状態同期に使うトピック
+var syncTopics = []string{
+	event.OnlineUsersSynced,
+	event.ChannelViewersSynced,
+	event.WebRTCStatesSynced,
+}
+
+// Bridge インスタンス間イベントブリッジ
+//
+// 自インスタンスのHubに発行されたイベントを他インスタンスに転送し、
+// 他インスタンスから届いたイベントを転送元インスタンスIDを付与して自インスタンスのHubに発行します
+type Bridge struct {
+	id        string
+	hub       *hub.Hub
+	transport Transport
+	logger    *zap.Logger
+	sub       hub.Subscription
+	closer    chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// New インスタンス間イベントブリッジを生成し起動します
+//
+// topicsが空の場合はDefaultTopicsを転送します
+func New(h *hub.Hub, transport Transport, logger *zap.Logger, topics ...string) *Bridge {
+	if len(topics) == 0 {
+		topics = DefaultTopics
+	}
+	b := &Bridge{
+		id:        uuid.Must(uuid.NewV4()).String(),
+		hub:       h,
+		transport: transport,
+		logger:    logger,
+		sub:       h.Subscribe(1000, append(topics, syncTopics...)...),
+		closer:    make(chan struct{}),
+	}
+
+	b.wg.Add(2)
+	go b.forwardLoop()
+	go b.receiveLoop()
+
+	// 既に起動している他インスタンスに状態を要求
+	b.send(event.InstanceSyncRequested, hub.Fields{})
+	return b
+}
+
+// ID 自インスタンスのIDを返します
+func (b *Bridge) ID() string {
+	return b.id
+}
+
+// Close ブリッジを停止します
+func (b *Bridge) Close() error {
+	err := ErrClosed
+	b.closeOnce.Do(func() {
+		b.send(event.InstanceStopped, hub.Fields{})
+		b.hub.Unsubscribe(b.sub)
+		close(b.closer)
+		err = b.transport.Close()
+		b.wg.Wait()
+	})
+	return err
+}
+
+func (b *Bridge) forwardLoop() {
+	defer b.wg.Done()
+	for {
+		select {
+		case msg := <-b.sub.Receiver:
+			if event.IsRemote(msg) {
+				// 他インスタンスから届いたイベントは再転送しない
+				continue
+			}
+			b.send(msg.Topic(), msg.Fields)
+		case <-b.closer:
+			return
+		}
+	}
+}
+
+func (b *Bridge) receiveLoop() {
+	defer b.wg.Done()
+	for data := range b.transport.Receive() {
+		e, err := decode(data)
+		if err != nil {
+			b.logger.Warn("failed to decode bridged event", zap.Error(err))
+			continue
+		}
+		if len(e.Origin) == 0 || e.Origin == b.id {
+			continue
+		}
+		if e.Fields == nil {
+			e.Fields = hub.Fields{}
+		}
+		e.Fields[event.FieldOrigin] = e.Origin
+		b.hub.Publish(hub.Message{
+			Name:   e.Topic,
+			Fields: e.Fields,
+		})
+	}
+}
+
+func (b *Bridge) send(topic string, fields hub.Fields) {
+	data, err := encode(&envelope{
+		Origin: b.id,
+		Topic:  topic,
+		Fields: fields,
+	})
+	if err != nil {
+		b.logger.Warn("failed to encode event", zap.Error(err), zap.String("topic", topic))
+		return
+	}
+	if err := b.transport.Send(data); err != nil {
+		b.logger.Warn("failed to send event", zap.Error(err), zap.String("topic", topic))
+	}
+}
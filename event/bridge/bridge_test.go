@@ -0,0 +1,255 @@
+package bridge
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/realtime/viewer"
+	"github.com/traPtitech/traQ/utils/message"
+	"github.com/traPtitech/traQ/utils/set"
+	"go.uber.org/zap"
+)
+
+func receive(t *testing.T, sub hub.Subscription) hub.Message {
+	t.Helper()
+	select {
+	case msg := <-sub.Receiver:
+		return msg
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout")
+		return hub.Message{}
+	}
+}
+
+func noReceive(t *testing.T, sub hub.Subscription) {
+	t.Helper()
+	select {
+	case msg := <-sub.Receiver:
+		t.Fatalf("unexpected message: %s", msg.Topic())
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestCodec(t *testing.T) {
+	t.Parallel()
+
+	mid := uuid.Must(uuid.NewV4())
+	uid := uuid.Must(uuid.NewV4())
+	now := time.Now().Truncate(time.Second)
+	e := &envelope{
+		Origin: "a",
+		Topic:  event.MessageCreated,
+		Fields: hub.Fields{
+			"message_id": mid,
+			"message": &model.Message{
+				ID:        mid,
+				UserID:    uid,
+				Text:      "test",
+				CreatedAt: now,
+			},
+			"embedded": []*message.EmbeddedInfo{{Raw: "@a", Type: "user", ID: uid.String()}},
+			"plain":    "test",
+			"state":    set.StringSetFromArray([]string{"joined", "micmuted"}),
+			"viewers":  map[uuid.UUID]viewer.StateWithTime{uid: {State: viewer.StateEditing, Time: now}},
+		},
+	}
+
+	data, err := encode(e)
+	require.NoError(t, err)
+	d, err := decode(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "a", d.Origin)
+	assert.Equal(t, event.MessageCreated, d.Topic)
+	assert.Equal(t, mid, d.Fields["message_id"])
+	if m, ok := d.Fields["message"].(*model.Message); assert.True(t, ok) {
+		assert.Equal(t, uid, m.UserID)
+		assert.Equal(t, "test", m.Text)
+		assert.True(t, now.Equal(m.CreatedAt))
+	}
+	assert.Equal(t, "user", d.Fields["embedded"].([]*message.EmbeddedInfo)[0].Type)
+	assert.Equal(t, "test", d.Fields["plain"])
+	assert.True(t, d.Fields["state"].(set.StringSet).Contains("micmuted"))
+	assert.Equal(t, viewer.StateEditing, d.Fields["viewers"].(map[uuid.UUID]viewer.StateWithTime)[uid].State)
+}
+
+func TestCodec_Models(t *testing.T) {
+	t.Parallel()
+
+	for _, v := range []interface{}{
+		&model.User{},
+		&model.UserGroup{},
+		&model.Message{},
+		&model.Channel{},
+		&model.Stamp{},
+		&model.StampPalette{},
+		&model.ClipFolder{},
+		&model.ClipFolderMessage{},
+		&model.Bot{},
+		model.ChannelExportStatus("done"),
+	} {
+		_, err := encode(&envelope{Origin: "a", Topic: "test", Fields: hub.Fields{"v": v}})
+		assert.NoError(t, err, "%T", v)
+	}
+}
+
+func TestBridge(t *testing.T) {
+	t.Parallel()
+
+	network := NewLoopbackNetwork()
+	hubA := hub.New()
+	hubB := hub.New()
+	subA := hubA.Subscribe(10, event.UserUpdated, event.UserCreated)
+	subB := hubB.Subscribe(10, event.UserUpdated, event.UserCreated)
+	syncA := hubA.Subscribe(10, event.InstanceSyncRequested)
+	syncB := hubB.Subscribe(10, event.InstanceSyncRequested)
+
+	a := New(hubA, network.NewTransport(), zap.NewNop(), event.UserUpdated)
+	b := New(hubB, network.NewTransport(), zap.NewNop(), event.UserUpdated)
+	defer b.Close()
+
+	// 後から起動したインスタンスは既に起動しているインスタンスに同期を要求する
+	assert.Equal(t, b.ID(), event.Origin(receive(t, syncA)))
+
+	uid := uuid.Must(uuid.NewV4())
+	hubA.Publish(hub.Message{
+		Name:   event.UserUpdated,
+		Fields: hub.Fields{"user_id": uid},
+	})
+
+	// 発行元にはそのまま届く
+	msg := receive(t, subA)
+	assert.False(t, event.IsRemote(msg))
+
+	// 他インスタンスには転送元が付与されて届く
+	msg = receive(t, subB)
+	assert.Equal(t, event.UserUpdated, msg.Topic())
+	assert.Equal(t, uid, msg.Fields["user_id"])
+	assert.Equal(t, a.ID(), event.Origin(msg))
+
+	// 転送されたイベントは送り返されない
+	noReceive(t, subA)
+
+	// 転送対象外のトピックは転送されない
+	hubA.Publish(hub.Message{
+		Name:   event.UserCreated,
+		Fields: hub.Fields{"user_id": uid},
+	})
+	_ = receive(t, subA)
+	noReceive(t, subB)
+
+	noReceive(t, syncB)
+	assert.NoError(t, a.Close())
+	assert.Equal(t, ErrClosed, a.Close())
+}
+
+func TestTCPTransport(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewTCPTransport("127.0.0.1:0", nil, "", zap.NewNop())
+	assert.Error(t, err)
+
+	ta, err := NewTCPTransport("127.0.0.1:0", nil, "secret", zap.NewNop())
+	require.NoError(t, err)
+	tb, err := NewTCPTransport("127.0.0.1:0", []string{ta.Addr().String()}, "secret", zap.NewNop())
+	require.NoError(t, err)
+	ta.AddPeer(tb.Addr().String())
+
+	hubA := hub.New()
+	hubB := hub.New()
+	subA := hubA.Subscribe(10, event.UserUpdated)
+	subB := hubB.Subscribe(10, event.UserUpdated)
+	a := New(hubA, ta, zap.NewNop(), event.UserUpdated)
+	b := New(hubB, tb, zap.NewNop(), event.UserUpdated)
+
+	u1 := uuid.Must(uuid.NewV4())
+	hubA.Publish(hub.Message{
+		Name:   event.UserUpdated,
+		Fields: hub.Fields{"user_id": u1},
+	})
+	_ = receive(t, subA)
+	if msg := receive(t, subB); assert.True(t, event.IsRemote(msg)) {
+		assert.Equal(t, u1, msg.Fields["user_id"])
+	}
+
+	u2 := uuid.Must(uuid.NewV4())
+	hubB.Publish(hub.Message{
+		Name:   event.UserUpdated,
+		Fields: hub.Fields{"user_id": u2},
+	})
+	_ = receive(t, subB)
+	if msg := receive(t, subA); assert.True(t, event.IsRemote(msg)) {
+		assert.Equal(t, u2, msg.Fields["user_id"])
+		assert.Equal(t, b.ID(), event.Origin(msg))
+	}
+
+	assert.NoError(t, a.Close())
+	assert.NoError(t, b.Close())
+}
+
+func TestTCPTransport_Authentication(t *testing.T) {
+	t.Parallel()
+
+	ta, err := NewTCPTransport("127.0.0.1:0", nil, "secret", zap.NewNop())
+	require.NoError(t, err)
+	defer ta.Close()
+
+	send := func(t *testing.T, frame []byte) {
+		t.Helper()
+		conn, err := net.Dial("tcp", ta.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = conn.Write(frame)
+		require.NoError(t, err)
+		// 検証に失敗した場合は切断される
+		_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+		_, err = conn.Read(make([]byte, 1))
+		assert.Equal(t, io.EOF, err)
+	}
+
+	// シークレットが異なる
+	other, err := NewTCPTransport("127.0.0.1:0", nil, "other", zap.NewNop())
+	require.NoError(t, err)
+	defer other.Close()
+	frame := make([]byte, tcpHeaderSize+1)
+	binary.BigEndian.PutUint32(frame, 1)
+	binary.BigEndian.PutUint64(frame[4:], uint64(time.Now().UnixNano()))
+	send(t, other.sign(frame))
+
+	// 署名なし
+	send(t, append(frame, make([]byte, tcpMACSize)...))
+
+	// タイムスタンプが古い
+	frame = make([]byte, tcpHeaderSize+1)
+	binary.BigEndian.PutUint32(frame, 1)
+	binary.BigEndian.PutUint64(frame[4:], uint64(time.Now().Add(-time.Hour).UnixNano()))
+	send(t, ta.sign(frame))
+
+	assert.Empty(t, ta.Receive())
+
+	// 正しいフレーム
+	frame = make([]byte, tcpHeaderSize+1)
+	binary.BigEndian.PutUint32(frame, 1)
+	binary.BigEndian.PutUint64(frame[4:], uint64(time.Now().UnixNano()))
+	frame[tcpHeaderSize] = 'a'
+	conn, err := net.Dial("tcp", ta.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write(ta.sign(frame))
+	require.NoError(t, err)
+	select {
+	case data := <-ta.Receive():
+		assert.Equal(t, []byte("a"), data)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout")
+	}
+}
@@ -0,0 +1,61 @@
+package bridge
+
+import (
+	"bytes"
+	"encoding/gob"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/realtime/viewer"
+	"github.com/traPtitech/traQ/realtime/webrtc"
+	"github.com/traPtitech/traQ/utils/message"
+	"github.com/traPtitech/traQ/utils/set"
+)
+
+// envelope 転送されるイベント
+type envelope struct {
+	Origin string
+	Topic  string
+	Fields hub.Fields
+}
+
+func init() {
+	// イベントのフィールドに入る型
+	gob.Register(uuid.UUID{})
+	gob.Register(time.Time{})
+	gob.Register(set.StringSet{})
+	gob.Register(set.UUIDSet{})
+	gob.Register(map[uuid.UUID]bool{})
+	gob.Register(map[uuid.UUID]viewer.StateWithTime{})
+	gob.Register(map[uuid.UUID]map[uuid.UUID]viewer.StateWithTime{})
+	gob.Register([]*webrtc.UserState{})
+	gob.Register([]*message.EmbeddedInfo{})
+	gob.Register(model.ChannelExportStatus(""))
+	gob.Register(&model.User{})
+	gob.Register(&model.UserGroup{})
+	gob.Register(&model.Message{})
+	gob.Register(&model.Channel{})
+	gob.Register(&model.Stamp{})
+	gob.Register(&model.StampPalette{})
+	gob.Register(&model.ClipFolder{})
+	gob.Register(&model.ClipFolderMessage{})
+	gob.Register(&model.Bot{})
+}
+
+func encode(e *envelope) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(e); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decode(data []byte) (*envelope, error) {
+	var e envelope
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
@@ -0,0 +1,64 @@
+package bridge
+
+import "sync"
+
+// LoopbackNetwork プロセス内でTransportを相互に接続するネットワーク
+//
+// 同一プロセス内で複数インスタンスを動かすテスト用です
+type LoopbackNetwork struct {
+	transports map[*loopbackTransport]struct{}
+	mu         sync.RWMutex
+}
+
+// NewLoopbackNetwork プロセス内ネットワークを生成します
+func NewLoopbackNetwork() *LoopbackNetwork {
+	return &LoopbackNetwork{
+		transports: map[*loopbackTransport]struct{}{},
+	}
+}
+
+// NewTransport ネットワークに接続したTransportを生成します
+func (n *LoopbackNetwork) NewTransport() Transport {
+	t := &loopbackTransport{
+		network: n,
+		recv:    make(chan []byte, 1000),
+	}
+	n.mu.Lock()
+	n.transports[t] = struct{}{}
+	n.mu.Unlock()
+	return t
+}
+
+type loopbackTransport struct {
+	network *LoopbackNetwork
+	recv    chan []byte
+}
+
+func (t *loopbackTransport) Send(data []byte) error {
+	t.network.mu.RLock()
+	defer t.network.mu.RUnlock()
+	if _, ok := t.network.transports[t]; !ok {
+		return ErrClosed
+	}
+	for dst := range t.network.transports {
+		if dst != t {
+			dst.recv <- data
+		}
+	}
+	return nil
+}
+
+func (t *loopbackTransport) Receive() <-chan []byte {
+	return t.recv
+}
+
+func (t *loopbackTransport) Close() error {
+	t.network.mu.Lock()
+	defer t.network.mu.Unlock()
+	if _, ok := t.network.transports[t]; !ok {
+		return ErrClosed
+	}
+	delete(t.network.transports, t)
+	close(t.recv)
+	return nil
+}
@@ -0,0 +1,243 @@
+package bridge
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	tcpDialTimeout   = 3 * time.Second
+	tcpWriteTimeout  = 5 * time.Second
+	tcpRedialBackoff = 5 * time.Second
+	tcpMaxFrameSize  = 16 << 20
+	// tcpMaxClockSkew フレームのタイムスタンプとして許容する時刻のずれ
+	tcpMaxClockSkew = 30 * time.Second
+
+	tcpHeaderSize = 4 + 8
+	tcpMACSize    = sha256.Size
+)
+
+var (
+	errFrameTooLarge = errors.New("frame too large")
+	errEmptySecret   = errors.New("secret must not be empty")
+)
+
+// TCPTransport TCPで他インスタンスと直接接続するTransport
+//
+// 全てのピアに対して接続し、データを長さプレフィックス付きのフレームで送信します。
+// 各フレームには共有シークレットによるHMAC-SHA-256とタイムスタンプが付与され、
+// 検証に失敗したフレームを送ってきた接続は切断されます。
+type TCPTransport struct {
+	logger   *zap.Logger
+	secret   []byte
+	listener net.Listener
+	peers    map[string]*tcpPeer
+	inbound  map[net.Conn]struct{}
+	recv     chan []byte
+	closed   bool
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+}
+
+type tcpPeer struct {
+	conn       net.Conn
+	lastFailed time.Time
+}
+
+// NewTCPTransport listenで待ち受け、peersに接続するTCPTransportを生成します
+//
+// secretは全てのインスタンスで共通の値を指定する必要があります。
+func NewTCPTransport(listen string, peers []string, secret string, logger *zap.Logger) (*TCPTransport, error) {
+	if len(secret) == 0 {
+		return nil, errEmptySecret
+	}
+	l, err := net.Listen("tcp", listen)
+	if err != nil {
+		return nil, err
+	}
+	t := &TCPTransport{
+		logger:   logger,
+		secret:   []byte(secret),
+		listener: l,
+		peers:    map[string]*tcpPeer{},
+		inbound:  map[net.Conn]struct{}{},
+		recv:     make(chan []byte, 1000),
+	}
+	for _, p := range peers {
+		t.peers[p] = &tcpPeer{}
+	}
+
+	t.wg.Add(1)
+	go t.acceptLoop()
+	return t, nil
+}
+
+// Addr 待ち受けアドレスを返します
+func (t *TCPTransport) Addr() net.Addr {
+	return t.listener.Addr()
+}
+
+// AddPeer 送信先のピアを追加します
+func (t *TCPTransport) AddPeer(addr string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.peers[addr]; !ok {
+		t.peers[addr] = &tcpPeer{}
+	}
+}
+
+// Send Transportインターフェイスの実装
+func (t *TCPTransport) Send(data []byte) error {
+	if len(data) > tcpMaxFrameSize {
+		return errFrameTooLarge
+	}
+	frame := make([]byte, tcpHeaderSize+len(data), tcpHeaderSize+len(data)+tcpMACSize)
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	binary.BigEndian.PutUint64(frame[4:], uint64(time.Now().UnixNano()))
+	copy(frame[tcpHeaderSize:], data)
+	frame = t.sign(frame)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return ErrClosed
+	}
+
+	var lastErr error
+	for addr, p := range t.peers {
+		if p.conn == nil {
+			if time.Since(p.lastFailed) < tcpRedialBackoff {
+				continue
+			}
+			conn, err := net.DialTimeout("tcp", addr, tcpDialTimeout)
+			if err != nil {
+				p.lastFailed = time.Now()
+				lastErr = err
+				continue
+			}
+			p.conn = conn
+		}
+
+		_ = p.conn.SetWriteDeadline(time.Now().Add(tcpWriteTimeout))
+		if _, err := p.conn.Write(frame); err != nil {
+			_ = p.conn.Close()
+			p.conn = nil
+			p.lastFailed = time.Now()
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// Receive Transportインターフェイスの実装
+func (t *TCPTransport) Receive() <-chan []byte {
+	return t.recv
+}
+
+// Close Transportインターフェイスの実装
+func (t *TCPTransport) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return ErrClosed
+	}
+	t.closed = true
+	err := t.listener.Close()
+	for _, p := range t.peers {
+		if p.conn != nil {
+			_ = p.conn.Close()
+			p.conn = nil
+		}
+	}
+	for conn := range t.inbound {
+		_ = conn.Close()
+	}
+	t.mu.Unlock()
+
+	t.wg.Wait()
+	close(t.recv)
+	return err
+}
+
+func (t *TCPTransport) acceptLoop() {
+	defer t.wg.Done()
+	for {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		t.mu.Lock()
+		if t.closed {
+			t.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		t.inbound[conn] = struct{}{}
+		t.mu.Unlock()
+
+		t.wg.Add(1)
+		go t.readLoop(conn)
+	}
+}
+
+func (t *TCPTransport) readLoop(conn net.Conn) {
+	defer t.wg.Done()
+	defer func() {
+		t.mu.Lock()
+		delete(t.inbound, conn)
+		t.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	header := make([]byte, tcpHeaderSize)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return
+		}
+		size := binary.BigEndian.Uint32(header)
+		if size > tcpMaxFrameSize {
+			t.logger.Warn("discard a connection sending too large frame", zap.Stringer("remote", conn.RemoteAddr()))
+			return
+		}
+		frame := make([]byte, tcpHeaderSize+int(size)+tcpMACSize)
+		copy(frame, header)
+		if _, err := io.ReadFull(r, frame[tcpHeaderSize:]); err != nil {
+			return
+		}
+		if !t.verify(frame) {
+			t.logger.Warn("discard a connection sending unauthenticated frame", zap.Stringer("remote", conn.RemoteAddr()))
+			return
+		}
+		t.recv <- frame[tcpHeaderSize : tcpHeaderSize+int(size)]
+	}
+}
+
+// sign フレームにMACを付与します
+func (t *TCPTransport) sign(frame []byte) []byte {
+	mac := hmac.New(sha256.New, t.secret)
+	_, _ = mac.Write(frame)
+	return mac.Sum(frame)
+}
+
+// verify フレームのMACとタイムスタンプを検証します
+func (t *TCPTransport) verify(frame []byte) bool {
+	body := frame[:len(frame)-tcpMACSize]
+	mac := hmac.New(sha256.New, t.secret)
+	_, _ = mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), frame[len(body):]) {
+		return false
+	}
+	skew := time.Since(time.Unix(0, int64(binary.BigEndian.Uint64(frame[4:]))))
+	return -tcpMaxClockSkew < skew && skew < tcpMaxClockSkew
+}
@@ -0,0 +1,17 @@
+package event
+
+import "github.com/leandro-lugaresi/hub"
+
+// FieldOrigin 他インスタンスから転送されたイベントに付与される、転送元インスタンスIDのフィールド名
+const FieldOrigin = "_origin"
+
+// Origin イベントの転送元インスタンスIDを返します。自インスタンスで発生したイベントの場合は空文字を返します
+func Origin(m hub.Message) string {
+	origin, _ := m.Fields[FieldOrigin].(string)
+	return origin
+}
+
+// IsRemote イベントが他インスタンスから転送されたものかどうか
+func IsRemote(m hub.Message) bool {
+	return len(Origin(m)) > 0
+}
@@ -79,6 +79,21 @@ const (
 	// 		message_id: uuid.UUID
 	//  	message: *model.Message
 	MessageDeleted = "message.deleted"
+	// MessageRestored 削除されたメッセージが復元された
+	// 	Fields:
+	// 		message_id: uuid.UUID
+	//  	message: *model.Message
+	MessageRestored = "message.restored"
+	// MessageComponentsUpdated メッセージのコンポーネントが更新された
+	// 	Fields:
+	// 		message_id: uuid.UUID
+	//  	message: *model.Message
+	MessageComponentsUpdated = "message.components_updated"
+	// ThreadRead スレッドのメッセージが既読された
+	// 	Fields:
+	// 		user_id: uuid.UUID
+	// 		thread_id: uuid.UUID
+	ThreadRead = "thread.read"
 	// MessageStamped メッセージにスタンプが押された
 	// 	Fields:
 	// 		message_id: uuid.UUID
@@ -98,12 +113,14 @@ const (
 	// 		message_id: uuid.UUID
 	// 		channel_id: uuid.UUID
 	// 		pin_id: uuid.UUID
+	// 		user_id: uuid.UUID
 	MessagePinned = "message.pinned"
 	// MessageUnpinned メッセージがピンから外れた
 	// 	Fields:
 	// 		message_id: uuid.UUID
 	// 		channel_id: uuid.UUID
 	// 		pin_id: uuid.UUID
+	// 		user_id: uuid.UUID
 	MessageUnpinned = "message.unpinned"
 
 	// ChannelCreated チャンネルが作成された
@@ -123,6 +140,26 @@ const (
 	// 		topic: string
 	// 		updater_id: uuid.UUID
 	ChannelTopicUpdated = "channel.topic.updated"
+	// ChannelNameUpdated チャンネル名が更新された
+	// 	Fields:
+	// 		channel_id: uuid.UUID
+	// 		name: string
+	// 		old_name: string
+	// 		updater_id: uuid.UUID
+	ChannelNameUpdated = "channel.name.updated"
+	// ChannelParentUpdated チャンネルの親チャンネルが更新された
+	// 	Fields:
+	// 		channel_id: uuid.UUID
+	// 		parent_id: uuid.UUID
+	// 		old_parent_id: uuid.UUID
+	// 		updater_id: uuid.UUID
+	ChannelParentUpdated = "channel.parent.updated"
+	// ChannelVisibilityUpdated チャンネルの可視状態が更新された
+	// 	Fields:
+	// 		channel_id: uuid.UUID
+	// 		visibility: bool
+	// 		updater_id: uuid.UUID
+	ChannelVisibilityUpdated = "channel.visibility.updated"
 	// ChannelDeleted チャンネルが削除された
 	// 	Fields:
 	// 		channel_id: uuid.UUID
@@ -148,6 +185,24 @@ const (
 	// 		channel_id: uuid.UUID
 	// 		viewers: map[uuid.UUID]realtime.ViewState
 	ChannelViewersChanged = "channel.viewers_changed"
+	// ChannelMemberAdded プライベートチャンネルにメンバーが追加された
+	// 	Fields:
+	// 		channel_id: uuid.UUID
+	// 		user_id: uuid.UUID
+	// 		updater_id: uuid.UUID
+	ChannelMemberAdded = "channel.member.added"
+	// ChannelMemberRemoved プライベートチャンネルからメンバーが削除された
+	// 	Fields:
+	// 		channel_id: uuid.UUID
+	// 		user_id: uuid.UUID
+	// 		updater_id: uuid.UUID
+	ChannelMemberRemoved = "channel.member.removed"
+	// ChannelExportUpdated チャンネルエクスポートの状態が変化した
+	// 	Fields:
+	// 		export_id: uuid.UUID
+	// 		user_id: uuid.UUID
+	// 		status: model.ChannelExportStatus
+	ChannelExportUpdated = "channel.export.updated"
 
 	// StampCreated スタンプが作成された
 	// 	Fields:
@@ -212,11 +267,25 @@ const (
 	// 		bot_id: uuid.UUID
 	// 		state: model.BotState
 	BotStateChanged = "bot.state_changed"
+	// BotAutoPaused Botがイベントの配送失敗が続いたため一時停止された
+	// 	Fields:
+	// 		bot_id: uuid.UUID
+	// 		bot: *model.Bot
+	BotAutoPaused = "bot.auto_paused"
 	// BotPingRequest BotのPingがリクエストされた
 	// 	Fields:
 	// 		bot_id: uuid.UUID
 	// 		bot: *model.Bot
 	BotPingRequest = "bot.ping"
+	// BotTestEventRequest Botへのテストイベントの送信がリクエストされた
+	// 	Fields:
+	// 		bot_id: uuid.UUID
+	// 		bot: *model.Bot
+	// 		request_id: uuid.UUID
+	// 		event: model.BotEvent
+	// 		body: string (空の場合はサンプルのペイロードを送信)
+	// 		user_id: uuid.UUID
+	BotTestEventRequest = "bot.test_event"
 	// BotJoined Botがチャンネルに参加した
 	// 	Fields:
 	// 		bot_id: uuid.UUID
@@ -227,13 +296,37 @@ const (
 	// 		bot_id: uuid.UUID
 	// 		channel_id: uuid.UUID
 	BotLeft = "bot.left"
+	// BotCommandInvoked Botのコマンドが実行された
+	// 	Fields:
+	// 		bot_id: uuid.UUID
+	// 		channel_id: uuid.UUID
+	// 		user_id: uuid.UUID
+	// 		message_id: uuid.UUID (メッセージを投稿しなかった場合はuuid.Nil)
+	// 		command: *model.BotCommand
+	// 		arguments: map[string]interface{}
+	// 		text: string
+	BotCommandInvoked = "bot.command_invoked"
+	// BotInteraction Botが投稿したメッセージのコンポーネントが操作された
+	// 	Fields:
+	// 		bot_id: uuid.UUID
+	// 		user_id: uuid.UUID
+	// 		message: *model.Message
+	// 		component: *model.MessageComponent
+	// 		value: string
+	BotInteraction = "bot.interaction"
 
 	// UserWebRTCStateChanged ユーザーのWebRTCの状態が変化した
 	// 	Fields:
 	// 		user_id: uuid.UUID
 	// 		channel_id: uuid.UUID
-	// 		state: string
+	// 		state: set.StringSet
 	UserWebRTCStateChanged = "user.webrtc.state_changed"
+	// UserTyping ユーザーのメッセージ入力状態が変化した
+	// 	Fields:
+	// 		user_id: uuid.UUID
+	// 		channel_id: uuid.UUID
+	// 		typing: bool
+	UserTyping = "user.typing"
 
 	// SSEConnected ユーザーがSSEストリームに接続した
 	// 	Fields:
@@ -290,4 +383,32 @@ const (
 	//		clip_folder_message_id: uuid.UUID
 	//		clip_folder_message: *model.ClipFolderMessage
 	ClipFolderMessageAdded = "clip_folder_message.added"
+
+	// DraftUpdated メッセージの下書きが更新・削除された
+	// 	Fields:
+	// 		user_id: uuid.UUID
+	//		channel_id: uuid.UUID
+	//		client_key: string
+	DraftUpdated = "draft.updated"
+
+	// InstanceSyncRequested 他インスタンスからリアルタイム状態の同期が要求された
+	// 	Fields:
+	InstanceSyncRequested = "instance.sync_requested"
+	// InstanceStopped 他インスタンスが停止した
+	// 	Fields:
+	InstanceStopped = "instance.stopped"
+	// OnlineUsersSynced インスタンスのオンラインユーザー状態が同期された
+	// 	Fields:
+	// 		users: map[uuid.UUID]bool (trueはオンライン、falseはオフライン)
+	// 		full: bool (trueの場合はインスタンスの全状態)
+	OnlineUsersSynced = "instance.online_users_synced"
+	// ChannelViewersSynced インスタンスのチャンネル閲覧者状態が同期された
+	// 	Fields:
+	// 		channels: map[uuid.UUID]map[uuid.UUID]viewer.StateWithTime
+	// 		full: bool (trueの場合はインスタンスの全状態)
+	ChannelViewersSynced = "instance.channel_viewers_synced"
+	// WebRTCStatesSynced インスタンスのWebRTC状態が同期された
+	// 	Fields:
+	// 		states: []*webrtc.UserState
+	WebRTCStatesSynced = "instance.webrtc_states_synced"
 )
@@ -0,0 +1,381 @@
+package exporter
+
+import (
+	"archive/zip"
+	"io"
+	"path"
+	"time"
+
+	"github.com/gofrs/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/message"
+	"github.com/traPtitech/traQ/utils/set"
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	// messagesBatchSize メッセージ取得単位
+	messagesBatchSize = 500
+)
+
+var json = jsoniter.ConfigFastest
+
+type channelEntry struct {
+	ID        uuid.UUID     `json:"id"`
+	Name      string        `json:"name"`
+	Path      string        `json:"path"`
+	ParentID  uuid.NullUUID `json:"parentId"`
+	Topic     string        `json:"topic"`
+	Private   bool          `json:"private"`
+	Archived  bool          `json:"archived"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+type messageEntry struct {
+	ID        uuid.UUID            `json:"id"`
+	UserID    uuid.UUID            `json:"userId"`
+	ChannelID uuid.UUID            `json:"channelId"`
+	ThreadID  uuid.NullUUID        `json:"threadId"`
+	Content   string               `json:"content"`
+	CreatedAt time.Time            `json:"createdAt"`
+	UpdatedAt time.Time            `json:"updatedAt"`
+	Stamps    []model.MessageStamp `json:"stamps"`
+	Pin       *pinEntry            `json:"pin"`
+	History   []historyEntry       `json:"history"`
+}
+
+type pinEntry struct {
+	UserID   uuid.UUID `json:"userId"`
+	PinnedAt time.Time `json:"pinnedAt"`
+}
+
+type historyEntry struct {
+	UserID   uuid.UUID `json:"userId"`
+	Content  string    `json:"content"`
+	DateTime time.Time `json:"datetime"`
+}
+
+type userEntry struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"displayName"`
+	IconFileID  uuid.UUID `json:"iconFileId"`
+	Bot         bool      `json:"bot"`
+	State       int       `json:"state"`
+}
+
+type fileEntry struct {
+	ID        uuid.UUID     `json:"id"`
+	Name      string        `json:"name"`
+	Mime      string        `json:"mime"`
+	Size      int64         `json:"size"`
+	CreatorID uuid.NullUUID `json:"creatorId"`
+	CreatedAt time.Time     `json:"createdAt"`
+	Path      string        `json:"path"`
+}
+
+// Export channelIDのチャンネルとその子孫チャンネルの履歴をzipアーカイブとしてwに書き出します
+//
+// アーカイブには以下が含まれます。
+//
+//	channels.json             チャンネル情報
+//	messages/<channelId>.json チャンネルのメッセージ (スタンプ・ピン・編集履歴を含む)
+//	users.json                メッセージに関わるユーザーの情報
+//	files.json                添付ファイルの情報
+//	files/<fileId>/<name>     添付ファイル
+//
+// userIDがuuid.Nilでない場合、そのユーザーがアクセス可能なチャンネル・ファイルのみを含めます。
+// 存在しないチャンネルを指定した場合、repository.ErrNotFoundを返します。
+func Export(repo repository.Repository, channelID, userID uuid.UUID, w io.Writer) error {
+	channels, err := collectChannels(repo, channelID, userID)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	users := set.UUIDSet{}
+	files := set.UUIDSet{}
+
+	channelEntries := make([]*channelEntry, 0, len(channels))
+	for _, ch := range channels {
+		p, err := repo.GetChannelPath(ch.ID)
+		if err != nil {
+			return err
+		}
+		channelEntries = append(channelEntries, &channelEntry{
+			ID:        ch.ID,
+			Name:      ch.Name,
+			Path:      p,
+			ParentID:  uuid.NullUUID{UUID: ch.ParentID, Valid: ch.ParentID != uuid.Nil},
+			Topic:     ch.Topic,
+			Private:   !ch.IsPublic,
+			Archived:  ch.IsArchived,
+			CreatedAt: ch.CreatedAt,
+		})
+
+		if err := writeMessages(repo, zw, ch.ID, users, files); err != nil {
+			return err
+		}
+	}
+	if err := writeJSON(zw, "channels.json", channelEntries); err != nil {
+		return err
+	}
+	if err := writeUsers(repo, zw, users); err != nil {
+		return err
+	}
+	if err := writeFiles(repo, zw, files, userID); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// collectChannels 指定したチャンネルとその子孫チャンネルのうちuserIDのユーザーがアクセス可能なものを取得します
+func collectChannels(repo repository.Repository, channelID, userID uuid.UUID) ([]*model.Channel, error) {
+	result := make([]*model.Channel, 0)
+	queue := []uuid.UUID{channelID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if userID != uuid.Nil {
+			if ok, err := repo.IsChannelAccessibleToUser(userID, id); err != nil {
+				return nil, err
+			} else if !ok {
+				if id == channelID {
+					return nil, repository.ErrNotFound
+				}
+				continue
+			}
+		}
+		ch, err := repo.GetChannel(id)
+		if err != nil {
+			if err == repository.ErrNotFound && id != channelID {
+				continue
+			}
+			return nil, err
+		}
+		result = append(result, ch)
+
+		children, err := repo.GetChildrenChannelIDs(id)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, children...)
+	}
+	return result, nil
+}
+
+// writeMessages チャンネルの全メッセージをmessages/<channelId>.jsonに書き出します
+//
+// 書き出したメッセージに関わるユーザーと添付ファイルのUUIDをそれぞれusers, filesに追加します。
+func writeMessages(repo repository.Repository, zw *zip.Writer, channelID uuid.UUID, users, files set.UUIDSet) error {
+	f, err := zw.Create(path.Join("messages", channelID.String()+".json"))
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, "["); err != nil {
+		return err
+	}
+
+	first := true
+	since := null.Time{}
+	written := set.UUIDSet{} // 時刻sinceのメッセージのうち書き出し済みのもの
+	for {
+		messages, more, err := repo.GetMessages(repository.MessagesQuery{
+			Channel:   channelID,
+			Since:     since,
+			Inclusive: true,
+			Limit:     messagesBatchSize,
+			Asc:       true,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, m := range messages {
+			if since.Valid && m.CreatedAt.Equal(since.Time) && written.Contains(m.ID) {
+				// 前回のバッチで書き出し済み
+				continue
+			}
+
+			e, err := makeMessageEntry(repo, m, users, files)
+			if err != nil {
+				return err
+			}
+			b, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			if !first {
+				if _, err := io.WriteString(f, ","); err != nil {
+					return err
+				}
+			}
+			if _, err := f.Write(b); err != nil {
+				return err
+			}
+			first = false
+		}
+		if !more || len(messages) == 0 {
+			break
+		}
+
+		last := messages[len(messages)-1].CreatedAt
+		if since.Valid && !last.After(since.Time) {
+			// 同一時刻のメッセージがバッチサイズ以上存在する
+			since = null.TimeFrom(last.Add(time.Microsecond))
+		} else {
+			since = null.TimeFrom(last)
+		}
+		written = set.UUIDSet{}
+		for _, m := range messages {
+			if m.CreatedAt.Equal(since.Time) {
+				written.Add(m.ID)
+			}
+		}
+	}
+
+	_, err = io.WriteString(f, "]")
+	return err
+}
+
+func makeMessageEntry(repo repository.Repository, m *model.Message, users, files set.UUIDSet) (*messageEntry, error) {
+	e := &messageEntry{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		ChannelID: m.ChannelID,
+		ThreadID:  m.ThreadID,
+		Content:   m.Text,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+		Stamps:    m.Stamps,
+		History:   make([]historyEntry, 0),
+	}
+	if e.Stamps == nil {
+		e.Stamps = make([]model.MessageStamp, 0)
+	}
+	users.Add(m.UserID)
+	for _, s := range m.Stamps {
+		users.Add(s.UserID)
+	}
+	if m.Pin != nil {
+		e.Pin = &pinEntry{UserID: m.Pin.UserID, PinnedAt: m.Pin.CreatedAt}
+		users.Add(m.Pin.UserID)
+	}
+
+	// 編集履歴
+	if m.UpdatedAt.After(m.CreatedAt) {
+		archived, err := repo.GetArchivedMessagesByID(m.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, am := range archived {
+			e.History = append(e.History, historyEntry{UserID: am.UserID, Content: am.Text, DateTime: am.DateTime})
+			addEmbeddedFiles(am.Text, files)
+		}
+	}
+	addEmbeddedFiles(m.Text, files)
+	return e, nil
+}
+
+// addEmbeddedFiles メッセージ本文に埋め込まれているファイルのUUIDをfilesに追加します
+func addEmbeddedFiles(text string, files set.UUIDSet) {
+	embedded, _ := message.Parse(text)
+	for _, v := range embedded {
+		if v.Type != "file" {
+			continue
+		}
+		if id, err := uuid.FromString(v.ID); err == nil {
+			files.Add(id)
+		}
+	}
+}
+
+func writeUsers(repo repository.Repository, zw *zip.Writer, users set.UUIDSet) error {
+	entries := make([]*userEntry, 0, len(users))
+	for id := range users {
+		u, err := repo.GetUser(id, false)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				continue
+			}
+			return err
+		}
+		entries = append(entries, &userEntry{
+			ID:          u.GetID(),
+			Name:        u.GetName(),
+			DisplayName: u.GetResponseDisplayName(),
+			IconFileID:  u.GetIconFileID(),
+			Bot:         u.IsBot(),
+			State:       u.GetState().Int(),
+		})
+	}
+	return writeJSON(zw, "users.json", entries)
+}
+
+// writeFiles 添付ファイルをfiles/<fileId>/<name>に書き出し、その情報をfiles.jsonに書き出します
+//
+// userIDがuuid.Nilでない場合、そのユーザーがアクセス可能なファイルのみを書き出します。
+func writeFiles(repo repository.Repository, zw *zip.Writer, files set.UUIDSet, userID uuid.UUID) error {
+	entries := make([]*fileEntry, 0, len(files))
+	for id := range files {
+		meta, err := repo.GetFileMeta(id)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				continue
+			}
+			return err
+		}
+		if userID != uuid.Nil {
+			if ok, err := repo.IsFileAccessible(id, userID); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+		}
+
+		p := path.Join("files", id.String(), path.Base(meta.GetFileName()))
+		if err := copyFile(zw, p, meta); err != nil {
+			return err
+		}
+		entries = append(entries, &fileEntry{
+			ID:        id,
+			Name:      meta.GetFileName(),
+			Mime:      meta.GetMIMEType(),
+			Size:      meta.GetFileSize(),
+			CreatorID: meta.GetCreatorID(),
+			CreatedAt: meta.GetCreatedAt(),
+			Path:      p,
+		})
+	}
+	return writeJSON(zw, "files.json", entries)
+}
+
+func copyFile(zw *zip.Writer, name string, meta model.FileMeta) error {
+	src, err := meta.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store, // 圧縮済みのファイルが多いため
+		Modified: meta.GetCreatedAt(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(f).Encode(v)
+}
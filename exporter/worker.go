@@ -0,0 +1,197 @@
+package exporter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	// processInterval 生成待ちのチャンネルエクスポートの確認間隔
+	processInterval = 10 * time.Second
+	// processBatchSize 一度に取得するチャンネルエクスポートの最大数
+	processBatchSize = 10
+	// processLease 生成中のチャンネルエクスポートが更新されない場合に、ワーカーが停止したとみなすまでの時間
+	processLease = 2 * time.Minute
+	// processHeartbeatInterval 生成中のチャンネルエクスポートの更新間隔
+	processHeartbeatInterval = 30 * time.Second
+)
+
+// Worker チャンネルエクスポート生成ワーカー
+//
+// 生成待ちのチャンネルエクスポートを順番に生成し、要求したユーザーのみがアクセス可能なファイルとして保存します。
+// 複数のインスタンスで動作させても、各チャンネルエクスポートは獲得したワーカーのみが生成します。
+// 生成中は定期的に更新日時を更新し、一定時間更新されなくなった(生成中にワーカーが停止した)場合は、他のワーカーが最初から生成し直します。
+type Worker struct {
+	repo   repository.Repository
+	logger *zap.Logger
+
+	closer chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewWorker チャンネルエクスポート生成ワーカーを生成し、起動します
+func NewWorker(repo repository.Repository, logger *zap.Logger) *Worker {
+	w := &Worker{
+		repo:   repo,
+		logger: logger,
+		closer: make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.run()
+	return w
+}
+
+// Close ワーカーを停止します
+//
+// 生成中のエクスポートがある場合、その完了を待ちます。
+func (w *Worker) Close() {
+	close(w.closer)
+	w.wg.Wait()
+}
+
+func (w *Worker) run() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(processInterval)
+	defer ticker.Stop()
+	for {
+		w.processAll()
+		select {
+		case <-ticker.C:
+		case <-w.closer:
+			return
+		}
+	}
+}
+
+// processAll 生成待ちのチャンネルエクスポートを全て生成します
+func (w *Worker) processAll() {
+	for {
+		exports, err := w.repo.GetUnfinishedChannelExports(time.Now().Add(-processLease), processBatchSize)
+		if err != nil {
+			w.logger.Error("failed to GetUnfinishedChannelExports", zap.Error(err))
+			return
+		}
+		if len(exports) == 0 {
+			return
+		}
+
+		for _, e := range exports {
+			select {
+			case <-w.closer:
+				return
+			default:
+			}
+
+			claimed, err := w.repo.ClaimChannelExport(e.ID, time.Now().Add(-processLease))
+			if err != nil {
+				w.logger.Error("failed to ClaimChannelExport", zap.Error(err), zap.Stringer("exportId", e.ID))
+				return
+			}
+			if !claimed {
+				// 他のワーカーが生成中
+				continue
+			}
+
+			args := repository.UpdateChannelExportArgs{Status: model.ChannelExportCompleted}
+			stop := w.heartbeat(e.ID)
+			fileID, err := w.process(e)
+			stop()
+			if err != nil {
+				w.logger.Warn("failed to export channel", zap.Error(err), zap.Stringer("exportId", e.ID))
+				args = repository.UpdateChannelExportArgs{Status: model.ChannelExportFailed, Error: null.StringFrom(err.Error())}
+			} else {
+				args.FileID = uuid.NullUUID{UUID: fileID, Valid: true}
+			}
+			if err := w.repo.UpdateChannelExport(e.ID, args); err != nil {
+				w.logger.Error("failed to UpdateChannelExport", zap.Error(err), zap.Stringer("exportId", e.ID))
+				return
+			}
+		}
+	}
+}
+
+// heartbeat 生成中であることを示すために、返り値の関数が呼ばれるまで定期的にチャンネルエクスポートを更新します
+func (w *Worker) heartbeat(id uuid.UUID) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(processHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := w.repo.TouchChannelExport(id); err != nil {
+					w.logger.Error("failed to TouchChannelExport", zap.Error(err), zap.Stringer("exportId", id))
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// process エクスポートを生成し、ファイルとして保存します
+//
+// 成功した場合、保存したファイルのUUIDとnilを返します。
+func (w *Worker) process(e *model.ChannelExport) (uuid.UUID, error) {
+	tmp, err := ioutil.TempFile("", "traq-export-*.zip")
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := Export(w.repo, e.ChannelID, e.UserID, tmp); err != nil {
+		return uuid.Nil, err
+	}
+	size, err := tmp.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if _, err := tmp.Seek(0, os.SEEK_SET); err != nil {
+		return uuid.Nil, err
+	}
+
+	name, err := w.fileName(e)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	args := repository.SaveFileArgs{
+		FileName: name,
+		FileSize: size,
+		MimeType: "application/zip",
+		FileType: model.FileTypeExport,
+		ACL:      repository.ACL{e.UserID: true},
+		Src:      tmp,
+	}
+	args.SetCreator(e.UserID)
+	f, err := w.repo.SaveFile(args)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return f.GetID(), nil
+}
+
+// fileName エクスポートファイル名を生成します
+func (w *Worker) fileName(e *model.ChannelExport) (string, error) {
+	p, err := w.repo.GetChannelPath(e.ChannelID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%s.zip", strings.ReplaceAll(p, "/", "_"), e.CreatedAt.Format("20060102150405")), nil
+}
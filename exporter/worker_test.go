@@ -0,0 +1,53 @@
+package exporter
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"go.uber.org/zap"
+)
+
+// claimedRepository 全てのチャンネルエクスポートが他のワーカーに獲得済みのRepository
+type claimedRepository struct {
+	repository.Repository
+	exports []*model.ChannelExport
+	claims  int
+	mu      sync.Mutex
+}
+
+func (repo *claimedRepository) GetUnfinishedChannelExports(_ time.Time, limit int) ([]*model.ChannelExport, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if repo.claims > 0 {
+		return nil, nil
+	}
+	return repo.exports, nil
+}
+
+func (repo *claimedRepository) ClaimChannelExport(uuid.UUID, time.Time) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.claims++
+	return false, nil
+}
+
+func TestWorker_processAll(t *testing.T) {
+	t.Parallel()
+
+	repo := &claimedRepository{
+		exports: []*model.ChannelExport{
+			{ID: uuid.Must(uuid.NewV4()), Status: model.ChannelExportPending},
+			{ID: uuid.Must(uuid.NewV4()), Status: model.ChannelExportRunning},
+		},
+	}
+	w := &Worker{repo: repo, logger: zap.NewNop(), closer: make(chan struct{})}
+
+	// 獲得できなかったものは生成しない (生成・更新するとRepositoryが未実装のためpanicする)
+	assert.NotPanics(t, w.processAll)
+	assert.Equal(t, 2, repo.claims)
+}
@@ -0,0 +1,657 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/rbac/role"
+	"github.com/traPtitech/traQ/repository"
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	// maxUserNameLength ユーザー名の最大長
+	maxUserNameLength = 32
+	// maxChannelNameLength チャンネル名の最大長
+	maxChannelNameLength = 20
+)
+
+var (
+	json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+	invalidNameCharRegex = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+	skinToneRegex        = regexp.MustCompile(`::skin-tone-\d+$`)
+)
+
+// Options インポートオプション
+type Options struct {
+	// ParentID インポートしたパブリックチャンネルを作成する親チャンネル uuid.Nilの場合はルートに作成します
+	//
+	// プライベートチャンネルは常にルートに作成します。
+	ParentID uuid.UUID
+	// UserMap インポート元のユーザー名 -> traQのユーザー名
+	//
+	// 指定されていないユーザーは同名のtraQユーザーに対応付け、存在しない場合は凍結済みのユーザーを作成します。
+	UserMap map[string]string
+	// DryRun trueの場合、書き込みを行わずにレポートのみを作成します
+	DryRun bool
+}
+
+type importedUser struct {
+	id   uuid.UUID
+	name string
+}
+
+type importedChannel struct {
+	id   uuid.UUID
+	path string
+}
+
+// importer インポート処理の状態
+type importer struct {
+	repo repository.Repository
+	ws   *Workspace
+	opts Options
+
+	report     *Report
+	stamps     map[string]uuid.UUID          // スタンプ名 -> スタンプUUID
+	users      map[string]*importedUser      // インポート元ユーザーID -> ユーザー
+	channels   map[string]*importedChannel   // インポート元チャンネルID -> チャンネル
+	groups     map[string]*importedChannel   // グループ名 -> 親チャンネル
+	siblings   map[uuid.UUID]map[string]bool // 親チャンネルUUID -> 子チャンネル名(lower-case)
+	reserved   map[string]bool               // 作成予定のユーザー名(lower-case)
+	parentPath string
+}
+
+// Import wsをインポートし、その結果を返します
+//
+// opts.DryRunがtrueの場合、書き込みを行わずにインポートした場合の結果を返します。
+// インポートは冪等ではありません。同じデータを再度インポートするとメッセージが重複します。
+// インポート中にエラーが発生した場合、それまでの結果とエラーを返します。
+func Import(repo repository.Repository, ws *Workspace, opts Options) (*Report, error) {
+	im := &importer{
+		repo: repo,
+		ws:   ws,
+		opts: opts,
+		report: &Report{
+			Source:        ws.Source,
+			DryRun:        opts.DryRun,
+			MappedUsers:   map[string]string{},
+			CreatedUsers:  map[string]string{},
+			UnknownEmojis: map[string]int{},
+			Skipped:       append([]string{}, ws.Skipped...),
+		},
+		stamps:   map[string]uuid.UUID{},
+		users:    map[string]*importedUser{},
+		channels: map[string]*importedChannel{},
+		groups:   map[string]*importedChannel{},
+		siblings: map[uuid.UUID]map[string]bool{},
+		reserved: map[string]bool{},
+	}
+	if err := im.run(); err != nil {
+		return im.report, err
+	}
+	return im.report, nil
+}
+
+func (im *importer) run() error {
+	if im.opts.ParentID != uuid.Nil {
+		p, err := im.repo.GetChannelPath(im.opts.ParentID)
+		if err != nil {
+			return fmt.Errorf("failed to get parent channel: %w", err)
+		}
+		im.parentPath = p
+	}
+
+	stamps, err := im.repo.GetAllStamps(false)
+	if err != nil {
+		return err
+	}
+	for _, s := range stamps {
+		im.stamps[strings.ToLower(s.Name)] = s.ID
+	}
+
+	for _, u := range im.ws.Users {
+		if err := im.importUser(u); err != nil {
+			return fmt.Errorf("failed to import user %s: %w", u.Name, err)
+		}
+	}
+	for _, c := range im.ws.Channels {
+		if err := im.importChannel(c); err != nil {
+			return fmt.Errorf("failed to import channel %s: %w", c.Name, err)
+		}
+	}
+	for _, c := range im.ws.Channels {
+		ch, ok := im.channels[c.ID]
+		if !ok {
+			continue
+		}
+		acl, err := im.fileACL(ch.id)
+		if err != nil {
+			return fmt.Errorf("failed to get members of channel %s: %w", c.Name, err)
+		}
+		if err := im.importMessages(ch.id, acl, c.Messages); err != nil {
+			return fmt.Errorf("failed to import messages of channel %s: %w", c.Name, err)
+		}
+		if c.Archived {
+			if err := im.archiveChannel(ch, c); err != nil {
+				return fmt.Errorf("failed to archive channel %s: %w", c.Name, err)
+			}
+		}
+	}
+	for _, dm := range im.ws.DirectMessages {
+		if err := im.importDirectMessage(dm); err != nil {
+			return fmt.Errorf("failed to import direct messages between %s and %s: %w", dm.UserIDs[0], dm.UserIDs[1], err)
+		}
+	}
+	return nil
+}
+
+// importUser インポート元のユーザーをtraQのユーザーに対応付けます
+func (im *importer) importUser(u *User) error {
+	if name, ok := im.opts.UserMap[u.Name]; ok {
+		user, err := im.repo.GetUserByName(name, false)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return fmt.Errorf("mapped user %s is not found", name)
+			}
+			return err
+		}
+		im.users[u.ID] = &importedUser{id: user.GetID(), name: user.GetName()}
+		im.report.MappedUsers[u.Name] = user.GetName()
+		return nil
+	}
+
+	name := sanitizeName(u.Name, maxUserNameLength, "user")
+	user, err := im.repo.GetUserByName(name, false)
+	if err == nil {
+		im.users[u.ID] = &importedUser{id: user.GetID(), name: user.GetName()}
+		im.report.MappedUsers[u.Name] = user.GetName()
+		return nil
+	} else if err != repository.ErrNotFound {
+		return err
+	}
+
+	// 対応するユーザーが存在しないので凍結済みのユーザーを作成
+	name, err = im.uniqueUserName(name)
+	if err != nil {
+		return err
+	}
+	displayName := u.DisplayName
+	if len(displayName) == 0 {
+		displayName = u.Name
+	}
+	id := uuid.Must(uuid.NewV4())
+	if !im.opts.DryRun {
+		user, err := im.repo.CreateUser(repository.CreateUserArgs{
+			Name:        name,
+			DisplayName: truncate(displayName, 64),
+			Role:        role.User,
+		})
+		if err != nil {
+			return err
+		}
+		args := repository.UpdateUserArgs{}
+		args.UserState.Valid = true
+		args.UserState.State = model.UserAccountStatusDeactivated
+		if err := im.repo.UpdateUser(user.GetID(), args); err != nil {
+			return err
+		}
+		id = user.GetID()
+	}
+	im.users[u.ID] = &importedUser{id: id, name: name}
+	im.report.CreatedUsers[u.Name] = name
+	return nil
+}
+
+// uniqueUserName 既存のユーザー・作成予定のユーザーと重複しないユーザー名を返します
+func (im *importer) uniqueUserName(name string) (string, error) {
+	for i := 1; ; i++ {
+		candidate := name
+		if i > 1 {
+			suffix := fmt.Sprintf("_%d", i)
+			candidate = truncate(name, maxUserNameLength-len(suffix)) + suffix
+		}
+		if im.reserved[strings.ToLower(candidate)] {
+			continue
+		}
+		if _, err := im.repo.GetUserByName(candidate, false); err == nil {
+			continue
+		} else if err != repository.ErrNotFound {
+			return "", err
+		}
+		im.reserved[strings.ToLower(candidate)] = true
+		return candidate, nil
+	}
+}
+
+// importChannel インポート元のチャンネルを作成します
+//
+// 同名のパブリックチャンネルが既に存在する場合、パブリックチャンネルはそのチャンネルにまとめてインポートします。
+func (im *importer) importChannel(c *Channel) error {
+	parent := importedChannel{id: im.opts.ParentID, path: im.parentPath}
+	if c.Private {
+		// プライベートチャンネルはパブリックチャンネルの子にできないのでルートに作成
+		parent = importedChannel{}
+	} else if len(c.Group) > 0 {
+		g, err := im.groupChannel(c.Group)
+		if err != nil {
+			return err
+		}
+		parent = *g
+	}
+
+	name := sanitizeName(c.Name, maxChannelNameLength, "channel")
+	if !c.Private {
+		if existing, err := im.findChildChannel(parent.id, name); err != nil {
+			return err
+		} else if existing != nil && existing.IsPublic {
+			ch := &importedChannel{id: existing.ID, path: joinPath(parent.path, existing.Name)}
+			im.channels[c.ID] = ch
+			im.report.MergedChannels = append(im.report.MergedChannels, ch.path)
+			return nil
+		}
+	}
+	var (
+		creator = uuid.Nil
+		members []uuid.UUID
+	)
+	if c.Private {
+		creator, members = im.privateChannelMembers(c)
+		if creator == uuid.Nil {
+			im.report.Skipped = append(im.report.Skipped, fmt.Sprintf("private channel %s: no members", c.Name))
+			return nil
+		}
+	} else if u, ok := im.users[c.CreatorID]; ok {
+		creator = u.id
+	}
+	ch, err := im.createChannel(parent, name, c.Private, creator, members)
+	if err != nil {
+		return err
+	}
+	if len(c.Topic) > 0 && !im.opts.DryRun {
+		if err := im.repo.UpdateChannel(ch.id, repository.UpdateChannelArgs{Topic: null.StringFrom(truncate(c.Topic, 200))}); err != nil {
+			return err
+		}
+	}
+	im.channels[c.ID] = ch
+	im.report.CreatedChannels = append(im.report.CreatedChannels, ch.path)
+	return nil
+}
+
+// groupChannel グループ名の親チャンネルを取得または作成します
+func (im *importer) groupChannel(group string) (*importedChannel, error) {
+	if g, ok := im.groups[group]; ok {
+		return g, nil
+	}
+	parentPath := im.parentPath
+	name := sanitizeName(group, maxChannelNameLength, "team")
+	if existing, err := im.findChildChannel(im.opts.ParentID, name); err != nil {
+		return nil, err
+	} else if existing != nil && existing.IsPublic {
+		g := &importedChannel{id: existing.ID, path: joinPath(parentPath, existing.Name)}
+		im.groups[group] = g
+		im.report.MergedChannels = append(im.report.MergedChannels, g.path)
+		return g, nil
+	}
+	g, err := im.createChannel(importedChannel{id: im.opts.ParentID, path: parentPath}, name, false, uuid.Nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	im.groups[group] = g
+	im.report.CreatedChannels = append(im.report.CreatedChannels, g.path)
+	return g, nil
+}
+
+// createChannel 親チャンネルの直下で重複しない名前のチャンネルを作成します
+func (im *importer) createChannel(parent importedChannel, name string, private bool, creator uuid.UUID, members []uuid.UUID) (*importedChannel, error) {
+	for {
+		unique, err := im.uniqueChannelName(parent.id, name)
+		if err != nil {
+			return nil, err
+		}
+
+		ch := &importedChannel{id: uuid.Must(uuid.NewV4()), path: joinPath(parent.path, unique)}
+		if im.opts.DryRun {
+			return ch, nil
+		}
+		var created *model.Channel
+		if private {
+			created, err = im.repo.CreatePrivateChannel(unique, parent.id, creator, members)
+		} else {
+			created, err = im.repo.CreatePublicChannel(unique, parent.id, creator)
+		}
+		if err == repository.ErrAlreadyExists {
+			// アクセスできないプライベートチャンネルと重複していたので別の名前を試す
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		ch.id = created.ID
+		return ch, nil
+	}
+}
+
+// findChildChannel 親チャンネルの直下にある指定した名前のアクセス可能なチャンネルを返します
+func (im *importer) findChildChannel(parentID uuid.UUID, name string) (*model.Channel, error) {
+	var channels []*model.Channel
+	if parentID == uuid.Nil {
+		all, err := im.repo.GetChannelsByUserID(uuid.Nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, ch := range all {
+			if ch.ParentID == uuid.Nil {
+				channels = append(channels, ch)
+			}
+		}
+	} else {
+		ids, err := im.repo.GetChildrenChannelIDs(parentID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			ch, err := im.repo.GetChannel(id)
+			if err != nil {
+				return nil, err
+			}
+			channels = append(channels, ch)
+		}
+	}
+	for _, ch := range channels {
+		if strings.EqualFold(ch.Name, name) {
+			return ch, nil
+		}
+	}
+	return nil, nil
+}
+
+// uniqueChannelName 親チャンネルの直下で既存のチャンネル・作成予定のチャンネルと重複しないチャンネル名を返します
+//
+// アクセスできないプライベートチャンネルとの重複は確認できません。
+func (im *importer) uniqueChannelName(parentID uuid.UUID, name string) (string, error) {
+	names, ok := im.siblings[parentID]
+	if !ok {
+		names = map[string]bool{}
+		im.siblings[parentID] = names
+	}
+	for i := 1; ; i++ {
+		candidate := name
+		if i > 1 {
+			suffix := fmt.Sprintf("-%d", i)
+			candidate = truncate(name, maxChannelNameLength-len(suffix)) + suffix
+		}
+		if names[strings.ToLower(candidate)] {
+			continue
+		}
+		if existing, err := im.findChildChannel(parentID, candidate); err != nil {
+			return "", err
+		} else if existing != nil {
+			continue
+		}
+		names[strings.ToLower(candidate)] = true
+		return candidate, nil
+	}
+}
+
+// privateChannelMembers プライベートチャンネルの作成者とメンバーを返します
+func (im *importer) privateChannelMembers(c *Channel) (uuid.UUID, []uuid.UUID) {
+	members := make([]uuid.UUID, 0, len(c.MemberIDs))
+	for _, id := range c.MemberIDs {
+		if u, ok := im.users[id]; ok {
+			members = append(members, u.id)
+		}
+	}
+	if u, ok := im.users[c.CreatorID]; ok {
+		for _, id := range members {
+			if id == u.id {
+				return u.id, members
+			}
+		}
+	}
+	if len(members) == 0 {
+		return uuid.Nil, nil
+	}
+	return members[0], members
+}
+
+// archiveChannel チャンネルをアーカイブします
+func (im *importer) archiveChannel(ch *importedChannel, c *Channel) error {
+	userID := uuid.Nil
+	if u, ok := im.users[c.CreatorID]; ok {
+		userID = u.id
+	} else if len(c.Messages) > 0 {
+		if u, ok := im.users[c.Messages[0].UserID]; ok {
+			userID = u.id
+		}
+	}
+	if userID == uuid.Nil {
+		im.report.Skipped = append(im.report.Skipped, fmt.Sprintf("archiving channel %s: no user to archive", ch.path))
+		return nil
+	}
+	im.report.ArchivedChannels = append(im.report.ArchivedChannels, ch.path)
+	if im.opts.DryRun {
+		return nil
+	}
+	return im.repo.ArchiveChannel(ch.id, userID)
+}
+
+// importDirectMessage ダイレクトメッセージをインポートします
+func (im *importer) importDirectMessage(dm *DirectMessage) error {
+	u1, ok1 := im.users[dm.UserIDs[0]]
+	u2, ok2 := im.users[dm.UserIDs[1]]
+	if !ok1 || !ok2 {
+		im.report.Skipped = append(im.report.Skipped, fmt.Sprintf("direct messages between %s and %s: unknown user", dm.UserIDs[0], dm.UserIDs[1]))
+		return nil
+	}
+	if u1.id == u2.id {
+		im.report.Skipped = append(im.report.Skipped, fmt.Sprintf("direct messages between %s and %s: mapped to the same user", dm.UserIDs[0], dm.UserIDs[1]))
+		return nil
+	}
+
+	channelID := uuid.Must(uuid.NewV4())
+	if !im.opts.DryRun {
+		ch, err := im.repo.GetDirectMessageChannel(u1.id, u2.id)
+		if err != nil {
+			return err
+		}
+		channelID = ch.ID
+	}
+	im.report.DirectMessageChannels++
+	return im.importMessages(channelID, []uuid.UUID{u1.id, u2.id}, dm.Messages)
+}
+
+// fileACL 指定したチャンネルに添付されたファイルの閲覧を許可するユーザーを返します
+//
+// 公開チャンネルの場合は全員が閲覧できるため、nilを返します。
+func (im *importer) fileACL(channelID uuid.UUID) ([]uuid.UUID, error) {
+	if im.opts.DryRun {
+		return nil, nil
+	}
+	ch, err := im.repo.GetChannel(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if ch.IsPublic {
+		return nil, nil
+	}
+	members, err := im.repo.GetPrivateChannelMemberIDs(channelID)
+	if err != nil {
+		return nil, err
+	}
+	return append([]uuid.UUID{}, members...), nil
+}
+
+// importMessages メッセージを投稿日時の昇順にインポートします
+//
+// aclがnilでない場合、添付ファイルはacl内のユーザーのみ閲覧できます。
+func (im *importer) importMessages(channelID uuid.UUID, acl []uuid.UUID, messages []*Message) error {
+	imported := map[string]uuid.UUID{} // インポート元メッセージID -> メッセージUUID
+	for _, m := range messages {
+		u, ok := im.users[m.UserID]
+		if !ok {
+			im.report.Skipped = append(im.report.Skipped, fmt.Sprintf("message %s: unknown user %s", m.ID, m.UserID))
+			continue
+		}
+
+		text := m.Text
+		if im.ws.ConvertText != nil {
+			text = im.ws.ConvertText(text, im)
+		}
+		for _, f := range m.Files {
+			fileID, err := im.importFile(f, u.id, channelID, acl)
+			if err != nil {
+				im.report.Skipped = append(im.report.Skipped, fmt.Sprintf("file %s of message %s: %v", f.Name, m.ID, err))
+				continue
+			}
+			if len(text) > 0 {
+				text += "\n"
+			}
+			text += fileEmbed(fileID)
+		}
+		if len(strings.TrimSpace(text)) == 0 {
+			continue
+		}
+
+		args := repository.ImportMessageArgs{
+			UserID:    u.id,
+			ChannelID: channelID,
+			Text:      text,
+			CreatedAt: m.CreatedAt,
+			Stamps:    im.convertReactions(m),
+		}
+		if len(m.ParentID) > 0 {
+			if parentID, ok := imported[m.ParentID]; ok {
+				args.ThreadID = uuid.NullUUID{UUID: parentID, Valid: true}
+			}
+		}
+
+		id := uuid.Must(uuid.NewV4())
+		if !im.opts.DryRun {
+			created, err := im.repo.ImportMessage(args)
+			if err != nil {
+				return err
+			}
+			id = created.ID
+		}
+		imported[m.ID] = id
+		im.report.Messages++
+		im.report.Stamps += len(args.Stamps)
+	}
+	return nil
+}
+
+// convertReactions リアクションをメッセージスタンプに変換します
+//
+// 同名のスタンプが存在しないリアクションは無視します。
+func (im *importer) convertReactions(m *Message) []model.MessageStamp {
+	type key struct{ stamp, user uuid.UUID }
+	seen := map[key]bool{}
+	result := make([]model.MessageStamp, 0)
+	for _, r := range m.Reactions {
+		name := strings.ToLower(skinToneRegex.ReplaceAllString(r.Name, ""))
+		stampID, ok := im.stamps[name]
+		if !ok {
+			im.report.UnknownEmojis[name]++
+			continue
+		}
+		for _, uid := range r.UserIDs {
+			u, ok := im.users[uid]
+			if !ok || seen[key{stampID, u.id}] {
+				continue
+			}
+			seen[key{stampID, u.id}] = true
+			result = append(result, model.MessageStamp{
+				StampID:   stampID,
+				UserID:    u.id,
+				Count:     1,
+				CreatedAt: m.CreatedAt,
+			})
+		}
+	}
+	return result
+}
+
+// importFile 添付ファイルを保存し、そのUUIDを返します
+func (im *importer) importFile(f *File, userID, channelID uuid.UUID, acl []uuid.UUID) (uuid.UUID, error) {
+	im.report.Files++
+	if im.opts.DryRun {
+		return uuid.Must(uuid.NewV4()), nil
+	}
+
+	src, size, err := f.Open()
+	if err != nil {
+		im.report.Files--
+		return uuid.Nil, err
+	}
+	defer src.Close()
+
+	args := repository.SaveFileArgs{
+		FileName: f.Name,
+		FileSize: size,
+		MimeType: f.Mime,
+		FileType: model.FileTypeUserFile,
+		Src:      src,
+	}
+	args.SetCreator(userID)
+	args.SetChannel(channelID)
+	if acl != nil {
+		// メンバーがいない場合でも全員に公開しない
+		args.ACL = repository.ACL{}
+		for _, v := range acl {
+			args.ACLAllow(v)
+		}
+	}
+	meta, err := im.repo.SaveFile(args)
+	if err != nil {
+		im.report.Files--
+		return uuid.Nil, err
+	}
+	return meta.GetID(), nil
+}
+
+// User implements Resolver interface.
+func (im *importer) User(id string) (uuid.UUID, string, bool) {
+	u, ok := im.users[id]
+	if !ok {
+		return uuid.Nil, "", false
+	}
+	return u.id, u.name, true
+}
+
+// Channel implements Resolver interface.
+func (im *importer) Channel(id string) (uuid.UUID, string, bool) {
+	ch, ok := im.channels[id]
+	if !ok {
+		return uuid.Nil, "", false
+	}
+	return ch.id, ch.path, true
+}
+
+// sanitizeName 名前を使用可能な文字と長さに変換します
+func sanitizeName(name string, maxLength int, fallback string) string {
+	name = strings.Trim(invalidNameCharRegex.ReplaceAllString(name, "_"), "_")
+	if len(name) == 0 {
+		name = fallback
+	}
+	return truncate(name, maxLength)
+}
+
+func truncate(s string, maxLength int) string {
+	r := []rune(s)
+	if len(r) > maxLength {
+		return string(r[:maxLength])
+	}
+	return s
+}
+
+func joinPath(parent, name string) string {
+	if len(parent) == 0 {
+		return name
+	}
+	return parent + "/" + name
+}
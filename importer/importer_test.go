@@ -0,0 +1,135 @@
+package importer
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// fileRepository 添付ファイル付きメッセージのインポートに必要な操作のみを実装したリポジトリ
+type fileRepository struct {
+	repository.Repository
+	channels map[uuid.UUID]*model.Channel
+	members  map[uuid.UUID][]uuid.UUID
+	acls     []repository.ACL
+}
+
+func (repo *fileRepository) GetChannel(channelID uuid.UUID) (*model.Channel, error) {
+	ch, ok := repo.channels[channelID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return ch, nil
+}
+
+func (repo *fileRepository) GetPrivateChannelMemberIDs(channelID uuid.UUID) ([]uuid.UUID, error) {
+	return repo.members[channelID], nil
+}
+
+func (repo *fileRepository) SaveFile(args repository.SaveFileArgs) (model.FileMeta, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	repo.acls = append(repo.acls, args.ACL)
+	return &testFileMeta{id: uuid.Must(uuid.NewV4())}, nil
+}
+
+type testFileMeta struct {
+	model.FileMeta
+	id uuid.UUID
+}
+
+func (f *testFileMeta) GetID() uuid.UUID {
+	return f.id
+}
+
+func (repo *fileRepository) ImportMessage(args repository.ImportMessageArgs) (*model.Message, error) {
+	return &model.Message{ID: uuid.Must(uuid.NewV4()), UserID: args.UserID, ChannelID: args.ChannelID, Text: args.Text}, nil
+}
+
+func TestImporter_importMessages_FileACL(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.Must(uuid.NewV4())
+	other := uuid.Must(uuid.NewV4())
+	public := uuid.Must(uuid.NewV4())
+	private := uuid.Must(uuid.NewV4())
+	empty := uuid.Must(uuid.NewV4())
+	repo := &fileRepository{
+		channels: map[uuid.UUID]*model.Channel{
+			public:  {ID: public, IsPublic: true},
+			private: {ID: private, IsPublic: false},
+			empty:   {ID: empty, IsPublic: false},
+		},
+		members: map[uuid.UUID][]uuid.UUID{
+			private: {user, other},
+		},
+	}
+	im := &importer{
+		repo:   repo,
+		ws:     &Workspace{},
+		report: &Report{},
+		users:  map[string]*importedUser{"U1": {id: user, name: "user"}},
+	}
+	messages := []*Message{{
+		ID:        "M1",
+		UserID:    "U1",
+		CreatedAt: time.Now(),
+		Files: []*File{{
+			Name: "a.txt",
+			Open: func() (io.ReadCloser, int64, error) {
+				return ioutil.NopCloser(strings.NewReader("a")), 1, nil
+			},
+		}},
+	}}
+
+	t.Run("public channel", func(t *testing.T) {
+		repo.acls = nil
+		acl, err := im.fileACL(public)
+		require.NoError(t, err)
+		require.NoError(t, im.importMessages(public, acl, messages))
+		if assert.Len(t, repo.acls, 1) {
+			assert.True(t, repo.acls[0][uuid.Nil])
+		}
+	})
+
+	t.Run("private channel", func(t *testing.T) {
+		repo.acls = nil
+		acl, err := im.fileACL(private)
+		require.NoError(t, err)
+		require.NoError(t, im.importMessages(private, acl, messages))
+		if assert.Len(t, repo.acls, 1) {
+			assert.False(t, repo.acls[0][uuid.Nil])
+			assert.True(t, repo.acls[0][user])
+			assert.True(t, repo.acls[0][other])
+		}
+	})
+
+	t.Run("private channel without members", func(t *testing.T) {
+		repo.acls = nil
+		acl, err := im.fileACL(empty)
+		require.NoError(t, err)
+		require.NoError(t, im.importMessages(empty, acl, messages))
+		if assert.Len(t, repo.acls, 1) {
+			assert.False(t, repo.acls[0][uuid.Nil])
+			assert.True(t, repo.acls[0][user])
+		}
+	})
+
+	t.Run("direct message", func(t *testing.T) {
+		repo.acls = nil
+		require.NoError(t, im.importMessages(uuid.Must(uuid.NewV4()), []uuid.UUID{user, other}, messages))
+		if assert.Len(t, repo.acls, 1) {
+			assert.False(t, repo.acls[0][uuid.Nil])
+			assert.Len(t, repo.acls[0], 2)
+		}
+	})
+}
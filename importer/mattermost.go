@@ -0,0 +1,283 @@
+package importer
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MattermostSource Mattermostのインポート元サービス名
+const MattermostSource = "mattermost"
+
+type mattermostLine struct {
+	Type       string             `json:"type"`
+	User       *mattermostUser    `json:"user"`
+	Channel    *mattermostChannel `json:"channel"`
+	Post       *mattermostPost    `json:"post"`
+	DirectPost *mattermostPost    `json:"direct_post"`
+}
+
+type mattermostUser struct {
+	Username  string `json:"username"`
+	Nickname  string `json:"nickname"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Teams     []struct {
+		Name     string `json:"name"`
+		Channels []struct {
+			Name string `json:"name"`
+		} `json:"channels"`
+	} `json:"teams"`
+}
+
+type mattermostChannel struct {
+	Team      string `json:"team"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Header    string `json:"header"`
+	Purpose   string `json:"purpose"`
+	DeletedAt int64  `json:"deleted_at"`
+}
+
+type mattermostPost struct {
+	Team           string   `json:"team"`
+	Channel        string   `json:"channel"`
+	ChannelMembers []string `json:"channel_members"`
+	User           string   `json:"user"`
+	Message        string   `json:"message"`
+	CreateAt       int64    `json:"create_at"`
+	Reactions      []struct {
+		User      string `json:"user"`
+		EmojiName string `json:"emoji_name"`
+	} `json:"reactions"`
+	Attachments []struct {
+		Path string `json:"path"`
+	} `json:"attachments"`
+	Replies []*mattermostPost `json:"replies"`
+}
+
+// mattermostReader Mattermostのエクスポートデータの読み込み状態
+type mattermostReader struct {
+	ws       *Workspace
+	files    map[string]*zip.File
+	users    map[string]bool              // 既知のユーザー名
+	channels map[string]*Channel          // team/name -> チャンネル
+	dms      map[[2]string]*DirectMessage // ソート済みのメンバー -> DM
+	seq      int
+}
+
+// ReadMattermost Mattermostのバルクエクスポート(JSONL)を読み込みます
+//
+// 添付ファイルはattachmentsのアーカイブから読み込みます。attachmentsがnilの場合、添付ファイルはインポートしません。
+// 複数のチームが含まれる場合、チャンネルはチーム名の親チャンネルの下に作成されます。
+func ReadMattermost(jsonl io.Reader, attachments *zip.Reader) (*Workspace, error) {
+	mr := &mattermostReader{
+		ws:       &Workspace{Source: MattermostSource, ConvertText: convertMattermostText},
+		files:    map[string]*zip.File{},
+		users:    map[string]bool{},
+		channels: map[string]*Channel{},
+		dms:      map[[2]string]*DirectMessage{},
+	}
+	if attachments != nil {
+		for _, f := range attachments.File {
+			mr.files[f.Name] = f
+		}
+	}
+	ws := mr.ws
+
+	var (
+		users []*mattermostUser
+		teams = map[string]bool{}
+	)
+	dec := json.NewDecoder(jsonl)
+	for line := 1; ; line++ {
+		var l mattermostLine
+		if err := dec.Decode(&l); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		switch l.Type {
+		case "user":
+			if l.User == nil {
+				continue
+			}
+			users = append(users, l.User)
+			mr.addUser(l.User.Username, mattermostDisplayName(l.User))
+		case "channel":
+			c := l.Channel
+			if c == nil {
+				continue
+			}
+			if c.Type != "O" && c.Type != "P" {
+				ws.Skipped = append(ws.Skipped, fmt.Sprintf("channel %s/%s: unsupported channel type %s", c.Team, c.Name, c.Type))
+				continue
+			}
+			topic := c.Header
+			if len(topic) == 0 {
+				topic = c.Purpose
+			}
+			ch := &Channel{
+				ID:       c.Team + "/" + c.Name,
+				Name:     c.Name,
+				Group:    c.Team,
+				Topic:    topic,
+				Private:  c.Type == "P",
+				Archived: c.DeletedAt > 0,
+				Messages: make([]*Message, 0),
+			}
+			mr.channels[ch.ID] = ch
+			ws.Channels = append(ws.Channels, ch)
+			teams[c.Team] = true
+		case "post":
+			p := l.Post
+			if p == nil {
+				continue
+			}
+			ch, ok := mr.channels[p.Team+"/"+p.Channel]
+			if !ok {
+				ws.Skipped = append(ws.Skipped, fmt.Sprintf("post in %s/%s: unknown channel", p.Team, p.Channel))
+				continue
+			}
+			ch.Messages = append(ch.Messages, mr.convertPost(p)...)
+		case "direct_post":
+			p := l.DirectPost
+			if p == nil {
+				continue
+			}
+			if len(p.ChannelMembers) != 2 || p.ChannelMembers[0] == p.ChannelMembers[1] {
+				ws.Skipped = append(ws.Skipped, fmt.Sprintf("direct post by %s: group or self direct messages are not supported", p.User))
+				continue
+			}
+			key := [2]string{p.ChannelMembers[0], p.ChannelMembers[1]}
+			sort.Strings(key[:])
+			dm, ok := mr.dms[key]
+			if !ok {
+				dm = &DirectMessage{UserIDs: key, Messages: make([]*Message, 0)}
+				mr.dms[key] = dm
+				ws.DirectMessages = append(ws.DirectMessages, dm)
+			}
+			dm.Messages = append(dm.Messages, mr.convertPost(p)...)
+		}
+	}
+
+	// プライベートチャンネルのメンバー
+	for _, u := range users {
+		for _, t := range u.Teams {
+			for _, c := range t.Channels {
+				if ch, ok := mr.channels[t.Name+"/"+c.Name]; ok && ch.Private {
+					ch.MemberIDs = append(ch.MemberIDs, u.Username)
+				}
+			}
+		}
+	}
+	if len(teams) <= 1 {
+		for _, ch := range ws.Channels {
+			ch.Group = ""
+		}
+	}
+
+	for _, ch := range ws.Channels {
+		sortMessages(ch.Messages)
+	}
+	for _, dm := range ws.DirectMessages {
+		sortMessages(dm.Messages)
+	}
+	return ws, nil
+}
+
+func (mr *mattermostReader) addUser(name, displayName string) {
+	if mr.users[name] {
+		return
+	}
+	mr.users[name] = true
+	mr.ws.Users = append(mr.ws.Users, &User{ID: name, Name: name, DisplayName: displayName})
+}
+
+// convertPost 投稿とその返信を変換します
+func (mr *mattermostReader) convertPost(p *mattermostPost) []*Message {
+	root := mr.convertMessage(p, "")
+	result := []*Message{root}
+	for _, r := range p.Replies {
+		result = append(result, mr.convertMessage(r, root.ID))
+	}
+	return result
+}
+
+func (mr *mattermostReader) convertMessage(p *mattermostPost, parentID string) *Message {
+	// BOTなどユーザー行が存在しないユーザー
+	mr.addUser(p.User, p.User)
+
+	mr.seq++
+	m := &Message{
+		ID:        fmt.Sprintf("post-%d", mr.seq),
+		UserID:    p.User,
+		Text:      p.Message,
+		CreatedAt: time.Unix(0, p.CreateAt*int64(time.Millisecond)),
+		ParentID:  parentID,
+	}
+
+	reactions := map[string]*Reaction{}
+	for _, r := range p.Reactions {
+		re, ok := reactions[r.EmojiName]
+		if !ok {
+			re = &Reaction{Name: r.EmojiName}
+			reactions[r.EmojiName] = re
+			m.Reactions = append(m.Reactions, re)
+		}
+		re.UserIDs = append(re.UserIDs, r.User)
+	}
+
+	for _, a := range p.Attachments {
+		f, ok := mr.files[a.Path]
+		if !ok {
+			f, ok = mr.files[path.Join("data", a.Path)]
+		}
+		if !ok {
+			mr.ws.Skipped = append(mr.ws.Skipped, fmt.Sprintf("attachment %s: not found in archive", a.Path))
+			continue
+		}
+		m.Files = append(m.Files, &File{Name: path.Base(a.Path), Open: openZipFile(f)})
+	}
+	return m
+}
+
+func mattermostDisplayName(u *mattermostUser) string {
+	if len(u.Nickname) > 0 {
+		return u.Nickname
+	}
+	if name := strings.TrimSpace(u.FirstName + " " + u.LastName); len(name) > 0 {
+		return name
+	}
+	return u.Username
+}
+
+var mattermostMentionRegex = regexp.MustCompile(`(^|[^\w@])@([a-z0-9][a-z0-9._-]*)`)
+
+// convertMattermostText Mattermostのメッセージ本文をtraQの形式に変換します
+func convertMattermostText(text string, r Resolver) string {
+	return mattermostMentionRegex.ReplaceAllStringFunc(text, func(s string) string {
+		sub := mattermostMentionRegex.FindStringSubmatch(s)
+		prefix, name := sub[1], sub[2]
+		if id, n, ok := r.User(name); ok {
+			return prefix + userEmbed(id, n)
+		}
+		// 文末のピリオドなどはユーザー名に含めない
+		if trimmed := strings.TrimRight(name, "._-"); len(trimmed) > 0 && trimmed != name {
+			if id, n, ok := r.User(trimmed); ok {
+				return prefix + userEmbed(id, n) + name[len(trimmed):]
+			}
+		}
+		return s
+	})
+}
+
+func sortMessages(ms []*Message) {
+	sort.SliceStable(ms, func(i, j int) bool { return ms[i].CreatedAt.Before(ms[j].CreatedAt) })
+}
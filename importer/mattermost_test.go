@@ -0,0 +1,105 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMattermost(t *testing.T) {
+	t.Parallel()
+
+	jsonl := strings.Join([]string{
+		`{"type":"version","version":1}`,
+		`{"type":"team","team":{"name":"team1","display_name":"Team 1","type":"O"}}`,
+		`{"type":"channel","channel":{"team":"team1","name":"town-square","display_name":"Town Square","type":"O","header":"header"}}`,
+		`{"type":"channel","channel":{"team":"team1","name":"private","display_name":"Private","type":"P","purpose":"purpose"}}`,
+		`{"type":"user","user":{"username":"alice","nickname":"Ali","teams":[{"name":"team1","channels":[{"name":"town-square"},{"name":"private"}]}]}}`,
+		`{"type":"user","user":{"username":"bob","first_name":"Bob","last_name":"Smith","teams":[{"name":"team1","channels":[{"name":"town-square"}]}]}}`,
+		`{"type":"post","post":{"team":"team1","channel":"town-square","user":"alice","message":"hello","create_at":1577836800000,` +
+			`"reactions":[{"user":"bob","emoji_name":"smile"},{"user":"alice","emoji_name":"smile"}],` +
+			`"replies":[{"user":"bob","message":"reply","create_at":1577836900000}],` +
+			`"attachments":[{"path":"data/a.png"}]}}`,
+		`{"type":"post","post":{"team":"team1","channel":"unknown","user":"alice","message":"x","create_at":1577836800000}}`,
+		`{"type":"direct_post","direct_post":{"channel_members":["bob","alice"],"user":"bot","message":"dm","create_at":1577836800000}}`,
+		`{"type":"direct_post","direct_post":{"channel_members":["alice","bob","carol"],"user":"alice","message":"group","create_at":1577836800000}}`,
+	}, "\n")
+
+	ws, err := ReadMattermost(strings.NewReader(jsonl), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, MattermostSource, ws.Source)
+	if assert.Len(t, ws.Users, 3) {
+		assert.Equal(t, &User{ID: "alice", Name: "alice", DisplayName: "Ali"}, ws.Users[0])
+		assert.Equal(t, &User{ID: "bob", Name: "bob", DisplayName: "Bob Smith"}, ws.Users[1])
+		assert.Equal(t, "bot", ws.Users[2].Name)
+	}
+
+	require.Len(t, ws.Channels, 2)
+	ts := ws.Channels[0]
+	assert.Equal(t, "town-square", ts.Name)
+	assert.Empty(t, ts.Group) // チームが1つの場合は親チャンネルを作らない
+	assert.Equal(t, "header", ts.Topic)
+	if assert.Len(t, ts.Messages, 2) {
+		root := ts.Messages[0]
+		assert.Equal(t, "hello", root.Text)
+		assert.Equal(t, time.Unix(1577836800, 0), root.CreatedAt)
+		if assert.Len(t, root.Reactions, 1) {
+			assert.Equal(t, &Reaction{Name: "smile", UserIDs: []string{"bob", "alice"}}, root.Reactions[0])
+		}
+		assert.Empty(t, root.Files)
+		assert.Equal(t, root.ID, ts.Messages[1].ParentID)
+	}
+
+	private := ws.Channels[1]
+	assert.True(t, private.Private)
+	assert.Equal(t, "purpose", private.Topic)
+	assert.Equal(t, []string{"alice"}, private.MemberIDs)
+
+	if assert.Len(t, ws.DirectMessages, 1) {
+		assert.Equal(t, [2]string{"alice", "bob"}, ws.DirectMessages[0].UserIDs)
+	}
+	assert.Len(t, ws.Skipped, 3) // attachment, unknown channel, group dm
+}
+
+func TestReadMattermost_Teams(t *testing.T) {
+	t.Parallel()
+
+	jsonl := strings.Join([]string{
+		`{"type":"channel","channel":{"team":"team1","name":"general","type":"O"}}`,
+		`{"type":"channel","channel":{"team":"team2","name":"general","type":"O"}}`,
+	}, "\n")
+
+	ws, err := ReadMattermost(strings.NewReader(jsonl), nil)
+	require.NoError(t, err)
+	if assert.Len(t, ws.Channels, 2) {
+		assert.Equal(t, "team1", ws.Channels[0].Group)
+		assert.Equal(t, "team2", ws.Channels[1].Group)
+	}
+}
+
+func TestConvertMattermostText(t *testing.T) {
+	t.Parallel()
+
+	uid := uuid.Must(uuid.NewV4())
+	r := &testResolver{users: map[string]uuid.UUID{"alice": uid}}
+	embed := `!{"type":"user","raw":"@alice","id":"` + uid.String() + `"}`
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"hello", "hello"},
+		{"@alice hi", embed + " hi"},
+		{"hi @alice.", "hi " + embed + "."},
+		{"@bob hi", "@bob hi"},
+		{"mail@alice.com", "mail@alice.com"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, convertMattermostText(tt.text, r), tt.text)
+	}
+}
@@ -0,0 +1,100 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Report インポート結果
+type Report struct {
+	// Source インポート元サービス名
+	Source string
+	// DryRun 書き込みを行わなかったかどうか
+	DryRun bool
+	// MappedUsers 既存のユーザーに対応付けたユーザー インポート元のユーザー名 -> traQのユーザー名
+	MappedUsers map[string]string
+	// CreatedUsers 凍結済みユーザーとして作成したユーザー インポート元のユーザー名 -> traQのユーザー名
+	CreatedUsers map[string]string
+	// CreatedChannels 作成したチャンネルのパス
+	CreatedChannels []string
+	// MergedChannels 既存のチャンネルにまとめてインポートしたチャンネルのパス
+	MergedChannels []string
+	// ArchivedChannels アーカイブしたチャンネルのパス
+	ArchivedChannels []string
+	// DirectMessageChannels インポートしたDMチャンネル数
+	DirectMessageChannels int
+	// Messages インポートしたメッセージ数
+	Messages int
+	// Stamps インポートしたメッセージスタンプ数
+	Stamps int
+	// Files インポートした添付ファイル数
+	Files int
+	// UnknownEmojis 同名のスタンプが存在しなかったリアクションの絵文字名 -> 回数
+	UnknownEmojis map[string]int
+	// Skipped インポートしなかったものの説明
+	Skipped []string
+}
+
+// Print レポートを人が読める形式でwに書き出します
+func (r *Report) Print(w io.Writer) error {
+	var b strings.Builder
+	if r.DryRun {
+		fmt.Fprintf(&b, "Import report for %s (dry-run, nothing was written)\n", r.Source)
+	} else {
+		fmt.Fprintf(&b, "Import report for %s\n", r.Source)
+	}
+
+	fmt.Fprintf(&b, "\nUsers mapped to existing accounts: %d\n", len(r.MappedUsers))
+	writeMap(&b, r.MappedUsers)
+	fmt.Fprintf(&b, "\nDeactivated placeholder users: %d\n", len(r.CreatedUsers))
+	writeMap(&b, r.CreatedUsers)
+
+	fmt.Fprintf(&b, "\nCreated channels: %d\n", len(r.CreatedChannels))
+	writeList(&b, r.CreatedChannels)
+	fmt.Fprintf(&b, "\nMerged into existing channels: %d\n", len(r.MergedChannels))
+	writeList(&b, r.MergedChannels)
+	fmt.Fprintf(&b, "\nArchived channels: %d\n", len(r.ArchivedChannels))
+	writeList(&b, r.ArchivedChannels)
+
+	fmt.Fprintf(&b, "\nDirect message channels: %d\n", r.DirectMessageChannels)
+	fmt.Fprintf(&b, "Messages: %d\n", r.Messages)
+	fmt.Fprintf(&b, "Stamps: %d\n", r.Stamps)
+	fmt.Fprintf(&b, "Files: %d\n", r.Files)
+
+	fmt.Fprintf(&b, "\nReactions without a matching stamp: %d\n", len(r.UnknownEmojis))
+	names := make([]string, 0, len(r.UnknownEmojis))
+	for name := range r.UnknownEmojis {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "  :%s: x%d\n", name, r.UnknownEmojis[name])
+	}
+
+	fmt.Fprintf(&b, "\nSkipped: %d\n", len(r.Skipped))
+	for _, s := range r.Skipped {
+		fmt.Fprintf(&b, "  %s\n", s)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMap(b *strings.Builder, m map[string]string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(b, "  %s -> %s\n", k, m[k])
+	}
+}
+
+func writeList(b *strings.Builder, l []string) {
+	for _, v := range l {
+		fmt.Fprintf(b, "  %s\n", v)
+	}
+}
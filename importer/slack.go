@@ -0,0 +1,393 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// SlackSource Slackのインポート元サービス名
+const SlackSource = "slack"
+
+type slackUser struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	IsBot   bool   `json:"is_bot"`
+	Profile struct {
+		RealName    string `json:"real_name"`
+		DisplayName string `json:"display_name"`
+		BotID       string `json:"bot_id"`
+	} `json:"profile"`
+}
+
+type slackChannel struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Creator    string   `json:"creator"`
+	IsArchived bool     `json:"is_archived"`
+	Members    []string `json:"members"`
+	Topic      struct {
+		Value string `json:"value"`
+	} `json:"topic"`
+	Purpose struct {
+		Value string `json:"value"`
+	} `json:"purpose"`
+}
+
+type slackMessage struct {
+	Type      string `json:"type"`
+	Subtype   string `json:"subtype"`
+	User      string `json:"user"`
+	BotID     string `json:"bot_id"`
+	Username  string `json:"username"`
+	Text      string `json:"text"`
+	Ts        string `json:"ts"`
+	ThreadTs  string `json:"thread_ts"`
+	Reactions []struct {
+		Name  string   `json:"name"`
+		Users []string `json:"users"`
+	} `json:"reactions"`
+	Files []struct {
+		ID                 string `json:"id"`
+		Name               string `json:"name"`
+		Mimetype           string `json:"mimetype"`
+		Mode               string `json:"mode"`
+		URLPrivateDownload string `json:"url_private_download"`
+	} `json:"files"`
+}
+
+// slackMessageSubtypes インポートするメッセージのsubtype
+//
+// 参加・退出やトピック変更などのシステムメッセージはインポートしません。
+var slackMessageSubtypes = map[string]bool{
+	"":                 true,
+	"bot_message":      true,
+	"file_share":       true,
+	"me_message":       true,
+	"thread_broadcast": true,
+}
+
+// slackReader Slackのエクスポートアーカイブの読み込み状態
+type slackReader struct {
+	ws      *Workspace
+	files   map[string]*zip.File
+	token   string
+	users   map[string]bool   // 既知のユーザーID
+	botUser map[string]string // bot_id -> ユーザーID
+}
+
+// ReadSlack Slackのエクスポートアーカイブを読み込みます
+//
+// 添付ファイルはアーカイブ内の__uploads/<fileId>/<name>から読み込みます。
+// アーカイブ内に存在せず、tokenが指定されている場合はSlackからダウンロードします。
+func ReadSlack(r *zip.Reader, token string) (*Workspace, error) {
+	sr := &slackReader{
+		ws:      &Workspace{Source: SlackSource, ConvertText: convertSlackText},
+		files:   map[string]*zip.File{},
+		token:   token,
+		users:   map[string]bool{},
+		botUser: map[string]string{},
+	}
+	for _, f := range r.File {
+		sr.files[f.Name] = f
+	}
+	ws := sr.ws
+
+	// ユーザー
+	var users []*slackUser
+	if err := readZipJSON(sr.files, "users.json", &users); err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		name := u.Profile.DisplayName
+		if len(name) == 0 {
+			name = u.Profile.RealName
+		}
+		ws.Users = append(ws.Users, &User{ID: u.ID, Name: u.Name, DisplayName: name, Bot: u.IsBot})
+		sr.users[u.ID] = true
+		if len(u.Profile.BotID) > 0 {
+			sr.botUser[u.Profile.BotID] = u.ID
+		}
+	}
+
+	// チャンネル
+	for _, v := range []struct {
+		file    string
+		private bool
+	}{{"channels.json", false}, {"groups.json", true}} {
+		if _, ok := sr.files[v.file]; !ok {
+			continue
+		}
+		var channels []*slackChannel
+		if err := readZipJSON(sr.files, v.file, &channels); err != nil {
+			return nil, err
+		}
+		for _, c := range channels {
+			topic := c.Topic.Value
+			if len(topic) == 0 {
+				topic = c.Purpose.Value
+			}
+			ms, err := sr.readMessages(c.Name)
+			if err != nil {
+				return nil, err
+			}
+			ws.Channels = append(ws.Channels, &Channel{
+				ID:        c.ID,
+				Name:      c.Name,
+				Topic:     topic,
+				Private:   v.private,
+				Archived:  c.IsArchived,
+				CreatorID: c.Creator,
+				MemberIDs: c.Members,
+				Messages:  ms,
+			})
+		}
+	}
+
+	// ダイレクトメッセージ
+	if _, ok := sr.files["dms.json"]; ok {
+		var dms []*slackChannel
+		if err := readZipJSON(sr.files, "dms.json", &dms); err != nil {
+			return nil, err
+		}
+		for _, c := range dms {
+			if len(c.Members) != 2 {
+				ws.Skipped = append(ws.Skipped, fmt.Sprintf("direct message %s: unexpected number of members", c.ID))
+				continue
+			}
+			ms, err := sr.readMessages(c.ID)
+			if err != nil {
+				return nil, err
+			}
+			ws.DirectMessages = append(ws.DirectMessages, &DirectMessage{UserIDs: [2]string{c.Members[0], c.Members[1]}, Messages: ms})
+		}
+	}
+	if _, ok := sr.files["mpims.json"]; ok {
+		ws.Skipped = append(ws.Skipped, "group direct messages (mpims.json) are not supported")
+	}
+	return ws, nil
+}
+
+// resolveUser メッセージの投稿者のユーザーIDを返します
+func (sr *slackReader) resolveUser(m *slackMessage) (string, bool) {
+	if len(m.User) > 0 {
+		return m.User, true
+	}
+	if len(m.BotID) == 0 {
+		return "", false
+	}
+	if id, ok := sr.botUser[m.BotID]; ok {
+		return id, true
+	}
+	// インテグレーションなどユーザーが存在しないBOT
+	if !sr.users[m.BotID] {
+		name := m.Username
+		if len(name) == 0 {
+			name = m.BotID
+		}
+		sr.ws.Users = append(sr.ws.Users, &User{ID: m.BotID, Name: name, DisplayName: name, Bot: true})
+		sr.users[m.BotID] = true
+	}
+	return m.BotID, true
+}
+
+// readMessages アーカイブ内のdir/YYYY-MM-DD.jsonからメッセージを投稿日時の昇順で読み込みます
+func (sr *slackReader) readMessages(dir string) ([]*Message, error) {
+	var names []string
+	for name := range sr.files {
+		if path.Dir(name) == dir && path.Ext(name) == ".json" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	result := make([]*Message, 0)
+	for _, name := range names {
+		var messages []*slackMessage
+		if err := readZipJSON(sr.files, name, &messages); err != nil {
+			return nil, err
+		}
+		for _, m := range messages {
+			if m.Type != "message" || !slackMessageSubtypes[m.Subtype] {
+				continue
+			}
+			createdAt, err := parseSlackTs(m.Ts)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			userID, ok := sr.resolveUser(m)
+			if !ok {
+				sr.ws.Skipped = append(sr.ws.Skipped, fmt.Sprintf("message %s in %s: unknown user", m.Ts, dir))
+				continue
+			}
+
+			msg := &Message{
+				ID:        m.Ts,
+				UserID:    userID,
+				Text:      m.Text,
+				CreatedAt: createdAt,
+			}
+			if len(m.ThreadTs) > 0 && m.ThreadTs != m.Ts {
+				msg.ParentID = m.ThreadTs
+			}
+			for _, re := range m.Reactions {
+				msg.Reactions = append(msg.Reactions, &Reaction{Name: re.Name, UserIDs: re.Users})
+			}
+			for _, f := range m.Files {
+				if f.Mode == "tombstone" || f.Mode == "hidden_by_limit" || len(f.Name) == 0 {
+					continue
+				}
+				file := &File{Name: f.Name, Mime: f.Mimetype}
+				if zf, ok := sr.files[path.Join("__uploads", f.ID, f.Name)]; ok {
+					file.Open = openZipFile(zf)
+				} else if len(sr.token) > 0 && len(f.URLPrivateDownload) > 0 {
+					file.Open = downloadSlackFile(f.URLPrivateDownload, sr.token)
+				} else {
+					sr.ws.Skipped = append(sr.ws.Skipped, fmt.Sprintf("file %s (%s) in %s: not found in archive", f.ID, f.Name, dir))
+					continue
+				}
+				msg.Files = append(msg.Files, file)
+			}
+			result = append(result, msg)
+		}
+	}
+	sortMessages(result)
+	return result, nil
+}
+
+// parseSlackTs Slackのts("1614232123.000200")をtime.Timeに変換します
+func parseSlackTs(ts string) (time.Time, error) {
+	parts := strings.SplitN(ts, ".", 2)
+	sec, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid ts: %s", ts)
+	}
+	var usec int64
+	if len(parts) == 2 {
+		usec, err = strconv.ParseInt((parts[1] + "000000")[:6], 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid ts: %s", ts)
+		}
+	}
+	return time.Unix(sec, usec*1000), nil
+}
+
+func downloadSlackFile(url, token string) func() (io.ReadCloser, int64, error) {
+	return func() (io.ReadCloser, int64, error) {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, 0, err
+		}
+		if res.StatusCode != http.StatusOK {
+			res.Body.Close()
+			return nil, 0, fmt.Errorf("failed to download %s: %s", url, res.Status)
+		}
+		if res.ContentLength >= 0 {
+			return res.Body, res.ContentLength, nil
+		}
+		defer res.Body.Close()
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return nil, 0, err
+		}
+		return ioutil.NopCloser(bytes.NewReader(b)), int64(len(b)), nil
+	}
+}
+
+var slackLinkRegex = regexp.MustCompile(`<([^<>\n]+)>`)
+
+// convertSlackText Slackのメッセージ本文をtraQの形式に変換します
+func convertSlackText(text string, r Resolver) string {
+	text = slackLinkRegex.ReplaceAllStringFunc(text, func(s string) string {
+		body := s[1 : len(s)-1]
+		target, label := body, ""
+		if i := strings.Index(body, "|"); i >= 0 {
+			target, label = body[:i], body[i+1:]
+		}
+
+		switch {
+		case strings.HasPrefix(target, "@"):
+			if id, name, ok := r.User(target[1:]); ok {
+				return userEmbed(id, name)
+			}
+			if len(label) > 0 {
+				return "@" + strings.TrimPrefix(label, "@")
+			}
+			return target
+		case strings.HasPrefix(target, "#"):
+			if id, p, ok := r.Channel(target[1:]); ok {
+				return channelEmbed(id, p)
+			}
+			if len(label) > 0 {
+				return "#" + label
+			}
+			return target
+		case strings.HasPrefix(target, "!"):
+			// <!here>, <!channel>, <!subteam^ID|@name> など
+			if len(label) > 0 {
+				return label
+			}
+			return "@" + strings.SplitN(target[1:], "^", 2)[0]
+		default:
+			if len(label) > 0 && label != target {
+				return fmt.Sprintf("[%s](%s)", label, target)
+			}
+			return target
+		}
+	})
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(text)
+}
+
+func userEmbed(id uuid.UUID, name string) string {
+	return fmt.Sprintf(`!{"type":"user","raw":"@%s","id":"%s"}`, name, id)
+}
+
+func channelEmbed(id uuid.UUID, path string) string {
+	return fmt.Sprintf(`!{"type":"channel","raw":"#%s","id":"%s"}`, path, id)
+}
+
+func fileEmbed(id uuid.UUID) string {
+	return fmt.Sprintf(`!{"type":"file","raw":"","id":"%s"}`, id)
+}
+
+func readZipJSON(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%s is not found in archive", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := json.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func openZipFile(f *zip.File) func() (io.ReadCloser, int64, error) {
+	return func() (io.ReadCloser, int64, error) {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, 0, err
+		}
+		return rc, int64(f.UncompressedSize64), nil
+	}
+}
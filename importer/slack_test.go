@@ -0,0 +1,170 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testResolver struct {
+	users    map[string]uuid.UUID
+	channels map[string]uuid.UUID
+}
+
+func (r *testResolver) User(id string) (uuid.UUID, string, bool) {
+	u, ok := r.users[id]
+	return u, id, ok
+}
+
+func (r *testResolver) Channel(id string) (uuid.UUID, string, bool) {
+	c, ok := r.channels[id]
+	return c, "imported/" + id, ok
+}
+
+func makeZip(t *testing.T, files map[string]string) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	return r
+}
+
+func TestReadSlack(t *testing.T) {
+	t.Parallel()
+
+	r := makeZip(t, map[string]string{
+		"users.json": `[
+			{"id":"U1","name":"alice","profile":{"display_name":"Alice"}},
+			{"id":"U2","name":"bob.smith","profile":{"real_name":"Bob Smith"}}
+		]`,
+		"channels.json": `[{"id":"C1","name":"general","creator":"U1","is_archived":true,"topic":{"value":"topic"}}]`,
+		"groups.json":   `[{"id":"G1","name":"secret","creator":"U2","members":["U1","U2"]}]`,
+		"dms.json":      `[{"id":"D1","members":["U1","U2"]}]`,
+		"mpims.json":    `[]`,
+		"general/2020-01-02.json": `[
+			{"type":"message","user":"U2","text":"reply","ts":"1577923200.000200","thread_ts":"1577836800.000100"},
+			{"type":"message","subtype":"channel_join","user":"U2","text":"joined","ts":"1577923200.000300"}
+		]`,
+		"general/2020-01-01.json": `[
+			{"type":"message","user":"U1","text":"hello","ts":"1577836800.000100","thread_ts":"1577836800.000100",
+			 "reactions":[{"name":"+1","users":["U2"],"count":1}],
+			 "files":[{"id":"F1","name":"a.txt","mimetype":"text/plain"},{"id":"F2","name":"b.txt"}]},
+			{"type":"message","subtype":"bot_message","bot_id":"B1","username":"ci","text":"build passed","ts":"1577836900.000000"}
+		]`,
+		"__uploads/F1/a.txt":     "file content",
+		"secret/2020-01-01.json": `[{"type":"message","user":"U2","text":"secret","ts":"1577836800.000000"}]`,
+		"D1/2020-01-01.json":     `[{"type":"message","user":"U1","text":"dm","ts":"1577836800.000000"}]`,
+	})
+
+	ws, err := ReadSlack(r, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, SlackSource, ws.Source)
+	if assert.Len(t, ws.Users, 3) {
+		assert.Equal(t, &User{ID: "U1", Name: "alice", DisplayName: "Alice"}, ws.Users[0])
+		assert.Equal(t, &User{ID: "U2", Name: "bob.smith", DisplayName: "Bob Smith"}, ws.Users[1])
+		assert.Equal(t, &User{ID: "B1", Name: "ci", DisplayName: "ci", Bot: true}, ws.Users[2])
+	}
+
+	require.Len(t, ws.Channels, 2)
+	general := ws.Channels[0]
+	assert.Equal(t, "general", general.Name)
+	assert.Equal(t, "topic", general.Topic)
+	assert.True(t, general.Archived)
+	assert.False(t, general.Private)
+	if assert.Len(t, general.Messages, 3) {
+		m := general.Messages[0]
+		assert.Equal(t, "hello", m.Text)
+		assert.Equal(t, time.Unix(1577836800, 100000), m.CreatedAt)
+		assert.Empty(t, m.ParentID)
+		if assert.Len(t, m.Reactions, 1) {
+			assert.Equal(t, &Reaction{Name: "+1", UserIDs: []string{"U2"}}, m.Reactions[0])
+		}
+		if assert.Len(t, m.Files, 1) {
+			assert.Equal(t, "a.txt", m.Files[0].Name)
+			rc, size, err := m.Files[0].Open()
+			if assert.NoError(t, err) {
+				b, _ := ioutil.ReadAll(rc)
+				rc.Close()
+				assert.Equal(t, "file content", string(b))
+				assert.EqualValues(t, len("file content"), size)
+			}
+		}
+
+		assert.Equal(t, "B1", general.Messages[1].UserID)
+		assert.Equal(t, "1577836800.000100", general.Messages[2].ParentID)
+	}
+
+	secret := ws.Channels[1]
+	assert.True(t, secret.Private)
+	assert.Equal(t, []string{"U1", "U2"}, secret.MemberIDs)
+	assert.Len(t, secret.Messages, 1)
+
+	if assert.Len(t, ws.DirectMessages, 1) {
+		assert.Equal(t, [2]string{"U1", "U2"}, ws.DirectMessages[0].UserIDs)
+		assert.Len(t, ws.DirectMessages[0].Messages, 1)
+	}
+	assert.Len(t, ws.Skipped, 2) // F2, mpims
+}
+
+func TestReadSlack_NoUsers(t *testing.T) {
+	t.Parallel()
+
+	_, err := ReadSlack(makeZip(t, map[string]string{}), "")
+	assert.Error(t, err)
+}
+
+func TestConvertSlackText(t *testing.T) {
+	t.Parallel()
+
+	uid := uuid.Must(uuid.NewV4())
+	cid := uuid.Must(uuid.NewV4())
+	r := &testResolver{users: map[string]uuid.UUID{"U1": uid}, channels: map[string]uuid.UUID{"C1": cid}}
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"hello", "hello"},
+		{"<@U1> hi", `!{"type":"user","raw":"@U1","id":"` + uid.String() + `"} hi`},
+		{"<@U9|someone> hi", "@someone hi"},
+		{"<#C1|general>", `!{"type":"channel","raw":"#imported/C1","id":"` + cid.String() + `"}`},
+		{"<#C9|random>", "#random"},
+		{"<!here> <!subteam^S1|@team>", "@here @team"},
+		{"<https://example.com>", "https://example.com"},
+		{"<https://example.com|example>", "[example](https://example.com)"},
+		{"a &lt;b&gt; &amp; c", "a <b> & c"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, convertSlackText(tt.text, r), tt.text)
+	}
+}
+
+func TestParseSlackTs(t *testing.T) {
+	t.Parallel()
+
+	ts, err := parseSlackTs("1577836800.000100")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Unix(1577836800, 100000), ts)
+	}
+	ts, err = parseSlackTs("1577836800")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Unix(1577836800, 0), ts)
+	}
+	_, err = parseSlackTs("invalid")
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"io"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Workspace 他のサービスからインポートするワークスペース
+//
+// 各サービスのエクスポートデータはこの形式に変換されてからインポートされます。
+// 各IDはインポート元サービスにおけるIDです。
+type Workspace struct {
+	// Source インポート元サービス名
+	Source   string
+	Users    []*User
+	Channels []*Channel
+	// DirectMessages 1対1のダイレクトメッセージ
+	DirectMessages []*DirectMessage
+	// Skipped インポート対象外として読み飛ばしたものの説明
+	Skipped []string
+	// ConvertText メッセージ本文をtraQの形式に変換する関数 nilの場合は変換しません
+	ConvertText func(text string, r Resolver) string
+}
+
+// Resolver インポート元のIDからインポート先のユーザー・チャンネルを解決します
+type Resolver interface {
+	// User インポート元のユーザーIDに対応するユーザーのUUIDと名前を返します
+	User(id string) (uuid.UUID, string, bool)
+	// Channel インポート元のチャンネルIDに対応するチャンネルのUUIDとパスを返します
+	Channel(id string) (uuid.UUID, string, bool)
+}
+
+// User インポート元のユーザー
+type User struct {
+	ID          string
+	Name        string
+	DisplayName string
+	Bot         bool
+}
+
+// Channel インポート元のチャンネル
+type Channel struct {
+	ID   string
+	Name string
+	// Group チャンネルをまとめる親チャンネルの名前 (Mattermostのチームなど) 空の場合はインポート先の直下に作成します
+	Group     string
+	Topic     string
+	Private   bool
+	Archived  bool
+	CreatorID string
+	// MemberIDs プライベートチャンネルのメンバー
+	MemberIDs []string
+	// Messages 投稿日時の昇順のメッセージ
+	Messages []*Message
+}
+
+// DirectMessage インポート元の1対1のダイレクトメッセージ
+type DirectMessage struct {
+	UserIDs [2]string
+	// Messages 投稿日時の昇順のメッセージ
+	Messages []*Message
+}
+
+// Message インポート元のメッセージ
+type Message struct {
+	ID     string
+	UserID string
+	// Text 本文
+	//
+	// メンションは!{"type":"user",...}形式に変換する前の、インポート元サービスにおける表現です。
+	Text      string
+	CreatedAt time.Time
+	// ParentID 返信先メッセージのID 返信でない場合は空
+	ParentID  string
+	Reactions []*Reaction
+	Files     []*File
+}
+
+// Reaction インポート元のメッセージのリアクション
+type Reaction struct {
+	// Name 絵文字名
+	Name    string
+	UserIDs []string
+}
+
+// File インポート元のメッセージの添付ファイル
+type File struct {
+	Name string
+	Mime string
+	// Open ファイルの中身とそのサイズを返します
+	Open func() (io.ReadCloser, int64, error)
+}
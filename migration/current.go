@@ -28,6 +28,24 @@ func Migrations() []*gormigrate.Migration {
 		v15(), // 外部ログイン機能追加
 		v16(), // パーミッション修正
 		v17(), // ユーザーホームチャンネル
+		v18(), // メッセージスレッド
+		v19(), // 予約投稿メッセージ・リマインダー
+		v20(), // メッセージ下書き
+		v21(), // チャンネルアーカイブ
+		v22(), // チャンネルエクスポート
+		v23(), // メッセージ編集回数
+		v24(), // メッセージ削除者
+		v25(), // BOTイベント配送方式
+		v26(), // BOTイベント再送
+		v27(), // BOTスラッシュコマンド
+		v28(), // メッセージコンポーネント
+		v29(), // BOTイベント署名
+		v30(), // BOTチャンネル毎権限
+		v31(), // BOTマニフェスト
+		v32(), // BOTテストイベント
+		v33(), // BOTチャンネル毎権限フラグ
+		v34(), // プライベートチャンネルメンバー管理権限
+		v35(), // チャンネルBOT権限管理権限
 	}
 }
 
@@ -43,6 +61,7 @@ func AllTables() []interface{} {
 		&model.DMChannelMapping{},
 		&model.ChannelLatestMessage{},
 		&model.BotEventLog{},
+		&model.BotCommand{},
 		&model.BotJoinChannel{},
 		&model.Bot{},
 		&model.OAuth2Client{},
@@ -63,6 +82,12 @@ func AllTables() []interface{} {
 		&model.UserSubscribeChannel{},
 		&model.Tag{},
 		&model.ArchivedMessage{},
+		&model.ChannelExport{},
+		&model.Draft{},
+		&model.ScheduledMessage{},
+		&model.MessageThreadParticipant{},
+		&model.MessageThread{},
+		&model.MessageComponents{},
 		&model.ClipFolderMessage{},
 		&model.Message{},
 		&model.Channel{},
@@ -114,6 +139,7 @@ func AllForeignKeys() [][5]string {
 		{"webhook_bots", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
 		{"bots", "creator_id", "users(id)", "CASCADE", "CASCADE"},
 		{"bots", "bot_user_id", "users(id)", "CASCADE", "CASCADE"},
+		{"bot_commands", "bot_id", "bots(id)", "CASCADE", "CASCADE"},
 		{"channel_events", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
 		{"files", "channel_id", "channels(id)", "SET NULL", "CASCADE"},
 		{"files", "creator_id", "users(id)", "RESTRICT", "CASCADE"},
@@ -125,6 +151,19 @@ func AllForeignKeys() [][5]string {
 		{"stamp_palettes", "creator_id", "users(id)", "CASCADE", "CASCADE"},
 		{"external_provider_users", "user_id", "users(id)", "CASCADE", "CASCADE"},
 		{"user_profiles", "home_channel", "channels(id)", "CASCADE", "CASCADE"},
+		{"messages", "thread_id", "messages(id)", "CASCADE", "CASCADE"},
+		{"message_threads", "message_id", "messages(id)", "CASCADE", "CASCADE"},
+		{"message_components", "message_id", "messages(id)", "CASCADE", "CASCADE"},
+		{"message_thread_participants", "thread_id", "messages(id)", "CASCADE", "CASCADE"},
+		{"message_thread_participants", "user_id", "users(id)", "CASCADE", "CASCADE"},
+		{"scheduled_messages", "user_id", "users(id)", "CASCADE", "CASCADE"},
+		{"scheduled_messages", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+		{"scheduled_messages", "reminder_message_id", "messages(id)", "CASCADE", "CASCADE"},
+		{"drafts", "user_id", "users(id)", "CASCADE", "CASCADE"},
+		{"drafts", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+		{"channel_exports", "user_id", "users(id)", "CASCADE", "CASCADE"},
+		{"channel_exports", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+		{"channel_exports", "file_id", "files(id)", "SET NULL", "CASCADE"},
 	}
 }
 
@@ -0,0 +1,68 @@
+package migration
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/gormigrate.v1"
+	"time"
+)
+
+// v18 メッセージスレッド
+func v18() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "18",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v18Message{}, &v18MessageThread{}, &v18MessageThreadParticipant{}).Error; err != nil {
+				return err
+			}
+
+			foreignKeys := [][5]string{
+				{"messages", "thread_id", "messages(id)", "CASCADE", "CASCADE"},
+				{"message_threads", "message_id", "messages(id)", "CASCADE", "CASCADE"},
+				{"message_thread_participants", "thread_id", "messages(id)", "CASCADE", "CASCADE"},
+				{"message_thread_participants", "user_id", "users(id)", "CASCADE", "CASCADE"},
+			}
+			for _, c := range foreignKeys {
+				if err := db.Table(c[0]).AddForeignKey(c[1], c[2], c[3], c[4]).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v18Message struct {
+	ID        uuid.UUID     `gorm:"type:char(36);not null;primary_key"`
+	UserID    uuid.UUID     `gorm:"type:char(36);not null;"`
+	ChannelID uuid.UUID     `gorm:"type:char(36);not null;index"`
+	Text      string        `sql:"type:TEXT COLLATE utf8mb4_bin NOT NULL"`
+	ThreadID  uuid.NullUUID `gorm:"type:char(36);index"` // 追加
+	CreatedAt time.Time     `gorm:"precision:6;index"`
+	UpdatedAt time.Time     `gorm:"precision:6"`
+	DeletedAt *time.Time    `gorm:"precision:6"`
+}
+
+func (v18Message) TableName() string {
+	return "messages"
+}
+
+type v18MessageThread struct {
+	MessageID   uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	ReplyCount  int       `gorm:"type:int;not null;default:0"`
+	LastReplyAt time.Time `gorm:"precision:6"`
+}
+
+func (v18MessageThread) TableName() string {
+	return "message_threads"
+}
+
+type v18MessageThreadParticipant struct {
+	ThreadID  uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	CreatedAt time.Time `gorm:"precision:6"`
+}
+
+func (v18MessageThreadParticipant) TableName() string {
+	return "message_thread_participants"
+}
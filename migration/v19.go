@@ -0,0 +1,47 @@
+package migration
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/gormigrate.v1"
+	"time"
+)
+
+// v19 予約投稿メッセージ・リマインダー
+func v19() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "19",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v19ScheduledMessage{}).Error; err != nil {
+				return err
+			}
+
+			foreignKeys := [][5]string{
+				{"scheduled_messages", "user_id", "users(id)", "CASCADE", "CASCADE"},
+				{"scheduled_messages", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+				{"scheduled_messages", "reminder_message_id", "messages(id)", "CASCADE", "CASCADE"},
+			}
+			for _, c := range foreignKeys {
+				if err := db.Table(c[0]).AddForeignKey(c[1], c[2], c[3], c[4]).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v19ScheduledMessage struct {
+	ID                uuid.UUID     `gorm:"type:char(36);not null;primary_key"`
+	UserID            uuid.UUID     `gorm:"type:char(36);not null;index"`
+	ChannelID         uuid.UUID     `gorm:"type:char(36);not null"`
+	Text              string        `sql:"type:TEXT COLLATE utf8mb4_bin NOT NULL"`
+	ReminderMessageID uuid.NullUUID `gorm:"type:char(36)"`
+	ScheduledAt       time.Time     `gorm:"precision:6;index"`
+	CreatedAt         time.Time     `gorm:"precision:6"`
+	UpdatedAt         time.Time     `gorm:"precision:6"`
+}
+
+func (v19ScheduledMessage) TableName() string {
+	return "scheduled_messages"
+}
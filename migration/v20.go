@@ -0,0 +1,42 @@
+package migration
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/gormigrate.v1"
+	"time"
+)
+
+// v20 メッセージ下書き
+func v20() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v20Draft{}).Error; err != nil {
+				return err
+			}
+
+			foreignKeys := [][5]string{
+				{"drafts", "user_id", "users(id)", "CASCADE", "CASCADE"},
+				{"drafts", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+			}
+			for _, c := range foreignKeys {
+				if err := db.Table(c[0]).AddForeignKey(c[1], c[2], c[3], c[4]).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v20Draft struct {
+	UserID    uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	ChannelID uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	Text      string    `sql:"type:TEXT COLLATE utf8mb4_bin NOT NULL"`
+	UpdatedAt time.Time `gorm:"precision:6"`
+}
+
+func (v20Draft) TableName() string {
+	return "drafts"
+}
@@ -0,0 +1,38 @@
+package migration
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/gormigrate.v1"
+	"time"
+)
+
+// v21 チャンネルアーカイブ
+func v21() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "21",
+		Migrate: func(db *gorm.DB) error {
+			return db.AutoMigrate(&v21Channel{}).Error
+		},
+	}
+}
+
+type v21Channel struct {
+	ID         uuid.UUID  `gorm:"type:char(36);not null;primary_key"`
+	Name       string     `gorm:"type:varchar(20);not null;unique_index:name_parent"`
+	ParentID   uuid.UUID  `gorm:"type:char(36);not null;unique_index:name_parent"`
+	Topic      string     `sql:"type:TEXT COLLATE utf8mb4_bin NOT NULL"`
+	IsForced   bool       `gorm:"type:boolean;not null;default:false"`
+	IsPublic   bool       `gorm:"type:boolean;not null;default:false"`
+	IsVisible  bool       `gorm:"type:boolean;not null;default:false"`
+	IsArchived bool       `gorm:"type:boolean;not null;default:false"` // 追加
+	CreatorID  uuid.UUID  `gorm:"type:char(36);not null"`
+	UpdaterID  uuid.UUID  `gorm:"type:char(36);not null"`
+	CreatedAt  time.Time  `gorm:"precision:6"`
+	UpdatedAt  time.Time  `gorm:"precision:6"`
+	DeletedAt  *time.Time `gorm:"precision:6"`
+}
+
+func (v21Channel) TableName() string {
+	return "channels"
+}
@@ -0,0 +1,47 @@
+package migration
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/gormigrate.v1"
+	"time"
+)
+
+// v22 チャンネルエクスポート
+func v22() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "22",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v22ChannelExport{}).Error; err != nil {
+				return err
+			}
+
+			foreignKeys := [][5]string{
+				{"channel_exports", "user_id", "users(id)", "CASCADE", "CASCADE"},
+				{"channel_exports", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+				{"channel_exports", "file_id", "files(id)", "SET NULL", "CASCADE"},
+			}
+			for _, c := range foreignKeys {
+				if err := db.Table(c[0]).AddForeignKey(c[1], c[2], c[3], c[4]).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v22ChannelExport struct {
+	ID        uuid.UUID     `gorm:"type:char(36);not null;primary_key"`
+	UserID    uuid.UUID     `gorm:"type:char(36);not null;index"`
+	ChannelID uuid.UUID     `gorm:"type:char(36);not null"`
+	Status    string        `gorm:"type:varchar(20);not null"`
+	FileID    uuid.NullUUID `gorm:"type:char(36)"`
+	Error     string        `gorm:"type:text;not null"`
+	CreatedAt time.Time     `gorm:"precision:6;index"`
+	UpdatedAt time.Time     `gorm:"precision:6"`
+}
+
+func (v22ChannelExport) TableName() string {
+	return "channel_exports"
+}
@@ -0,0 +1,39 @@
+package migration
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/gormigrate.v1"
+	"time"
+)
+
+// v23 メッセージ編集回数
+func v23() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "23",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v23Message{}).Error; err != nil {
+				return err
+			}
+
+			// 既存のメッセージの編集回数は編集履歴の数
+			return db.Exec("UPDATE `messages` SET `edit_count` = (SELECT COUNT(*) FROM `archived_messages` WHERE `archived_messages`.`message_id` = `messages`.`id`)").Error
+		},
+	}
+}
+
+type v23Message struct {
+	ID        uuid.UUID     `gorm:"type:char(36);not null;primary_key"`
+	UserID    uuid.UUID     `gorm:"type:char(36);not null;"`
+	ChannelID uuid.UUID     `gorm:"type:char(36);not null;index"`
+	Text      string        `sql:"type:TEXT COLLATE utf8mb4_bin NOT NULL"`
+	ThreadID  uuid.NullUUID `gorm:"type:char(36);index"`
+	EditCount int           `gorm:"type:int;not null;default:0"` // 追加
+	CreatedAt time.Time     `gorm:"precision:6;index"`
+	UpdatedAt time.Time     `gorm:"precision:6"`
+	DeletedAt *time.Time    `gorm:"precision:6"`
+}
+
+func (v23Message) TableName() string {
+	return "messages"
+}
@@ -0,0 +1,35 @@
+package migration
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/gormigrate.v1"
+	"time"
+)
+
+// v24 メッセージ削除者
+func v24() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "24",
+		Migrate: func(db *gorm.DB) error {
+			return db.AutoMigrate(&v24Message{}).Error
+		},
+	}
+}
+
+type v24Message struct {
+	ID        uuid.UUID     `gorm:"type:char(36);not null;primary_key"`
+	UserID    uuid.UUID     `gorm:"type:char(36);not null;"`
+	ChannelID uuid.UUID     `gorm:"type:char(36);not null;index"`
+	Text      string        `sql:"type:TEXT COLLATE utf8mb4_bin NOT NULL"`
+	ThreadID  uuid.NullUUID `gorm:"type:char(36);index"`
+	EditCount int           `gorm:"type:int;not null;default:0"`
+	CreatedAt time.Time     `gorm:"precision:6;index"`
+	UpdatedAt time.Time     `gorm:"precision:6"`
+	DeletedAt *time.Time    `gorm:"precision:6;index"` // index追加
+	DeletedBy uuid.NullUUID `gorm:"type:char(36)"`     // 追加
+}
+
+func (v24Message) TableName() string {
+	return "messages"
+}
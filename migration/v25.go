@@ -0,0 +1,65 @@
+package migration
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/gormigrate.v1"
+	"time"
+)
+
+// v25 BOTイベント配送方式
+func v25() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "25",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v25Bot{}).Error; err != nil {
+				return err
+			}
+
+			addedRolePermissions := map[string][]string{
+				"bot": {
+					"bot_connect_ws",
+				},
+			}
+			for role, perms := range addedRolePermissions {
+				for _, perm := range perms {
+					if err := db.Create(&v25RolePermission{Role: role, Permission: perm}).Error; err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v25Bot struct {
+	ID                uuid.UUID  `gorm:"type:char(36);not null;primary_key"`
+	BotUserID         uuid.UUID  `gorm:"type:char(36);not null;unique"`
+	Description       string     `gorm:"type:text;not null"`
+	VerificationToken string     `gorm:"type:varchar(30);not null"`
+	AccessTokenID     uuid.UUID  `gorm:"type:char(36);not null"`
+	PostURL           string     `gorm:"type:text;not null"`
+	Mode              string     `gorm:"type:varchar(30);not null;default:'HTTP'"` // 追加
+	SubscribeEvents   string     `gorm:"type:text;not null"`
+	Privileged        bool       `gorm:"type:boolean;not null;default:false"`
+	State             int        `gorm:"type:tinyint;not null;default:0"`
+	BotCode           string     `gorm:"type:varchar(30);not null;unique"`
+	CreatorID         uuid.UUID  `gorm:"type:char(36);not null"`
+	CreatedAt         time.Time  `gorm:"precision:6"`
+	UpdatedAt         time.Time  `gorm:"precision:6"`
+	DeletedAt         *time.Time `gorm:"precision:6"`
+}
+
+func (v25Bot) TableName() string {
+	return "bots"
+}
+
+type v25RolePermission struct {
+	Role       string `gorm:"type:varchar(30);not null;primary_key"`
+	Permission string `gorm:"type:varchar(30);not null;primary_key"`
+}
+
+func (v25RolePermission) TableName() string {
+	return "user_role_permissions"
+}
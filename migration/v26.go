@@ -0,0 +1,36 @@
+package migration
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/gormigrate.v1"
+	"time"
+)
+
+// v26 BOTイベント再送
+func v26() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "26",
+		Migrate: func(db *gorm.DB) error {
+			return db.AutoMigrate(&v26BotEventLog{}).Error
+		},
+	}
+}
+
+type v26BotEventLog struct {
+	RequestID   uuid.UUID  `gorm:"type:char(36);not null;primary_key"`
+	BotID       uuid.UUID  `gorm:"type:char(36);not null;index:bot_id_date_time_idx"`
+	Event       string     `gorm:"type:varchar(30);not null"`
+	Body        string     `gorm:"type:text"`
+	Error       string     `gorm:"type:text"`
+	Code        int        `gorm:"not null;default:0"`
+	Latency     int64      `gorm:"not null;default:0"`
+	Attempts    int        `gorm:"not null;default:1"`     // 追加
+	NextRetryAt *time.Time `gorm:"precision:6;index"`      // 追加
+	DeadLetter  bool       `gorm:"not null;default:false"` // 追加
+	DateTime    time.Time  `gorm:"precision:6;index:bot_id_date_time_idx"`
+}
+
+func (v26BotEventLog) TableName() string {
+	return "bot_event_logs"
+}
@@ -0,0 +1,35 @@
+package migration
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/gormigrate.v1"
+	"time"
+)
+
+// v27 BOTスラッシュコマンド
+func v27() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "27",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v27BotCommand{}).Error; err != nil {
+				return err
+			}
+			return db.Table(v27BotCommand{}.TableName()).AddForeignKey("bot_id", "bots(id)", "CASCADE", "CASCADE").Error
+		},
+	}
+}
+
+type v27BotCommand struct {
+	BotID       uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	Name        string    `gorm:"type:varchar(32);not null;primary_key"`
+	Description string    `gorm:"type:text;not null"`
+	Arguments   string    `gorm:"type:text;not null"`
+	HideMessage bool      `gorm:"type:boolean;not null;default:false"`
+	CreatedAt   time.Time `gorm:"precision:6"`
+	UpdatedAt   time.Time `gorm:"precision:6"`
+}
+
+func (v27BotCommand) TableName() string {
+	return "bot_commands"
+}
@@ -0,0 +1,32 @@
+package migration
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/gormigrate.v1"
+	"time"
+)
+
+// v28 メッセージコンポーネント
+func v28() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "28",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v28MessageComponents{}).Error; err != nil {
+				return err
+			}
+			return db.Table(v28MessageComponents{}.TableName()).AddForeignKey("message_id", "messages(id)", "CASCADE", "CASCADE").Error
+		},
+	}
+}
+
+type v28MessageComponents struct {
+	MessageID  uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	Components string    `gorm:"type:text;not null"`
+	CreatedAt  time.Time `gorm:"precision:6"`
+	UpdatedAt  time.Time `gorm:"precision:6"`
+}
+
+func (v28MessageComponents) TableName() string {
+	return "message_components"
+}
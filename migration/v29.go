@@ -0,0 +1,56 @@
+package migration
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/traPtitech/traQ/utils"
+	"gopkg.in/gormigrate.v1"
+	"time"
+)
+
+// v29 BOTイベント署名
+func v29() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "29",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v29Bot{}).Error; err != nil {
+				return err
+			}
+
+			// 既存のBotに署名シークレットを発行
+			var ids []uuid.UUID
+			if err := db.Unscoped().Model(&v29Bot{}).Where("signing_secret = ''").Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if err := db.Unscoped().Model(&v29Bot{ID: id}).UpdateColumn("signing_secret", utils.RandAlphabetAndNumberString(64)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v29Bot struct {
+	ID                uuid.UUID  `gorm:"type:char(36);not null;primary_key"`
+	BotUserID         uuid.UUID  `gorm:"type:char(36);not null;unique"`
+	Description       string     `gorm:"type:text;not null"`
+	VerificationToken string     `gorm:"type:varchar(30);not null"`
+	SigningSecret     string     `gorm:"type:varchar(64);not null;default:''"` // 追加
+	AccessTokenID     uuid.UUID  `gorm:"type:char(36);not null"`
+	PostURL           string     `gorm:"type:text;not null"`
+	Mode              string     `gorm:"type:varchar(30);not null;default:'HTTP'"`
+	SubscribeEvents   string     `gorm:"type:text;not null"`
+	Privileged        bool       `gorm:"type:boolean;not null;default:false"`
+	State             int        `gorm:"type:tinyint;not null;default:0"`
+	BotCode           string     `gorm:"type:varchar(30);not null;unique"`
+	CreatorID         uuid.UUID  `gorm:"type:char(36);not null"`
+	CreatedAt         time.Time  `gorm:"precision:6"`
+	UpdatedAt         time.Time  `gorm:"precision:6"`
+	DeletedAt         *time.Time `gorm:"precision:6"`
+}
+
+func (v29Bot) TableName() string {
+	return "bots"
+}
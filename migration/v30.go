@@ -0,0 +1,52 @@
+package migration
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/gormigrate.v1"
+	"time"
+)
+
+// v30 BOTチャンネル毎権限
+func v30() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "30",
+		Migrate: func(db *gorm.DB) error {
+			return db.AutoMigrate(&v30Bot{}, &v30BotJoinChannel{}).Error
+		},
+	}
+}
+
+type v30Bot struct {
+	ID                uuid.UUID  `gorm:"type:char(36);not null;primary_key"`
+	BotUserID         uuid.UUID  `gorm:"type:char(36);not null;unique"`
+	Description       string     `gorm:"type:text;not null"`
+	VerificationToken string     `gorm:"type:varchar(30);not null"`
+	SigningSecret     string     `gorm:"type:varchar(64);not null;default:''"`
+	AccessTokenID     uuid.UUID  `gorm:"type:char(36);not null"`
+	PostURL           string     `gorm:"type:text;not null"`
+	Mode              string     `gorm:"type:varchar(30);not null;default:'HTTP'"`
+	SubscribeEvents   string     `gorm:"type:text;not null"`
+	Permissions       string     `gorm:"type:text;not null"` // 追加
+	Privileged        bool       `gorm:"type:boolean;not null;default:false"`
+	State             int        `gorm:"type:tinyint;not null;default:0"`
+	BotCode           string     `gorm:"type:varchar(30);not null;unique"`
+	CreatorID         uuid.UUID  `gorm:"type:char(36);not null"`
+	CreatedAt         time.Time  `gorm:"precision:6"`
+	UpdatedAt         time.Time  `gorm:"precision:6"`
+	DeletedAt         *time.Time `gorm:"precision:6"`
+}
+
+func (v30Bot) TableName() string {
+	return "bots"
+}
+
+type v30BotJoinChannel struct {
+	ChannelID   uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	BotID       uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	Permissions string    `gorm:"type:text;not null"` // 追加
+}
+
+func (v30BotJoinChannel) TableName() string {
+	return "bot_join_channels"
+}
@@ -0,0 +1,45 @@
+package migration
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/gormigrate.v1"
+	"time"
+)
+
+// v31 BOTマニフェスト
+func v31() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "31",
+		Migrate: func(db *gorm.DB) error {
+			return db.AutoMigrate(&v31Bot{}).Error
+		},
+	}
+}
+
+type v31Bot struct {
+	ID                uuid.UUID  `gorm:"type:char(36);not null;primary_key"`
+	BotUserID         uuid.UUID  `gorm:"type:char(36);not null;unique"`
+	Description       string     `gorm:"type:text;not null"`
+	HelpText          string     `gorm:"type:text;not null"` // 追加
+	Homepage          string     `gorm:"type:text;not null"` // 追加
+	PrivacyNote       string     `gorm:"type:text;not null"` // 追加
+	VerificationToken string     `gorm:"type:varchar(30);not null"`
+	SigningSecret     string     `gorm:"type:varchar(64);not null;default:''"`
+	AccessTokenID     uuid.UUID  `gorm:"type:char(36);not null"`
+	PostURL           string     `gorm:"type:text;not null"`
+	Mode              string     `gorm:"type:varchar(30);not null;default:'HTTP'"`
+	SubscribeEvents   string     `gorm:"type:text;not null"`
+	Permissions       string     `gorm:"type:text;not null"`
+	Privileged        bool       `gorm:"type:boolean;not null;default:false"`
+	State             int        `gorm:"type:tinyint;not null;default:0"`
+	BotCode           string     `gorm:"type:varchar(30);not null;unique"`
+	CreatorID         uuid.UUID  `gorm:"type:char(36);not null"`
+	CreatedAt         time.Time  `gorm:"precision:6"`
+	UpdatedAt         time.Time  `gorm:"precision:6"`
+	DeletedAt         *time.Time `gorm:"precision:6"`
+}
+
+func (v31Bot) TableName() string {
+	return "bots"
+}
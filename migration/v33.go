@@ -0,0 +1,50 @@
+package migration
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/gormigrate.v1"
+	"time"
+)
+
+// v33 BOTチャンネル毎権限フラグ
+func v33() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "33",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v33Bot{}).Error; err != nil {
+				return err
+			}
+			// 既に権限を要求しているBotはチャンネル毎の権限の制限を受ける
+			return db.Exec("UPDATE `bots` SET `channel_scoped` = TRUE WHERE `permissions` <> ''").Error
+		},
+	}
+}
+
+type v33Bot struct {
+	ID                uuid.UUID  `gorm:"type:char(36);not null;primary_key"`
+	BotUserID         uuid.UUID  `gorm:"type:char(36);not null;unique"`
+	Description       string     `gorm:"type:text;not null"`
+	HelpText          string     `gorm:"type:text;not null"`
+	Homepage          string     `gorm:"type:text;not null"`
+	PrivacyNote       string     `gorm:"type:text;not null"`
+	VerificationToken string     `gorm:"type:varchar(30);not null"`
+	SigningSecret     string     `gorm:"type:varchar(64);not null;default:''"`
+	AccessTokenID     uuid.UUID  `gorm:"type:char(36);not null"`
+	PostURL           string     `gorm:"type:text;not null"`
+	Mode              string     `gorm:"type:varchar(30);not null;default:'HTTP'"`
+	SubscribeEvents   string     `gorm:"type:text;not null"`
+	Permissions       string     `gorm:"type:text;not null"`
+	ChannelScoped     bool       `gorm:"type:boolean;not null;default:false"` // 追加
+	Privileged        bool       `gorm:"type:boolean;not null;default:false"`
+	State             int        `gorm:"type:tinyint;not null;default:0"`
+	BotCode           string     `gorm:"type:varchar(30);not null;unique"`
+	CreatorID         uuid.UUID  `gorm:"type:char(36);not null"`
+	CreatedAt         time.Time  `gorm:"precision:6"`
+	UpdatedAt         time.Time  `gorm:"precision:6"`
+	DeletedAt         *time.Time `gorm:"precision:6"`
+}
+
+func (v33Bot) TableName() string {
+	return "bots"
+}
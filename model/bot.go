@@ -5,6 +5,8 @@ import (
 	"errors"
 	vd "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/rbac"
+	"github.com/traPtitech/traQ/rbac/permission"
 	"strings"
 	"time"
 )
@@ -38,20 +40,68 @@ const (
 	BotEventUserCreated BotEvent = "USER_CREATED"
 	// BotEventStampCreated スタンプ作成イベント
 	BotEventStampCreated BotEvent = "STAMP_CREATED"
+	// BotEventMessageUpdated メッセージ編集イベント
+	BotEventMessageUpdated BotEvent = "MESSAGE_UPDATED"
+	// BotEventMessageDeleted メッセージ削除イベント
+	BotEventMessageDeleted BotEvent = "MESSAGE_DELETED"
+	// BotEventMessageStamped メッセージスタンプ追加イベント
+	BotEventMessageStamped BotEvent = "MESSAGE_STAMPED"
+	// BotEventMessageUnstamped メッセージスタンプ削除イベント
+	BotEventMessageUnstamped BotEvent = "MESSAGE_UNSTAMPED"
+	// BotEventMessagePinned メッセージピン留めイベント
+	BotEventMessagePinned BotEvent = "MESSAGE_PINNED"
+	// BotEventMessageUnpinned メッセージピン留め解除イベント
+	BotEventMessageUnpinned BotEvent = "MESSAGE_UNPINNED"
+	// BotEventChannelRenamed チャンネル名変更イベント
+	BotEventChannelRenamed BotEvent = "CHANNEL_RENAMED"
+	// BotEventChannelMoved チャンネル親変更イベント
+	BotEventChannelMoved BotEvent = "CHANNEL_MOVED"
+	// BotEventChannelVisibilityChanged チャンネル可視状態変更イベント
+	BotEventChannelVisibilityChanged BotEvent = "CHANNEL_VISIBILITY_CHANGED"
+	// BotEventUserGroupMemberAdded ユーザーグループメンバー追加イベント
+	BotEventUserGroupMemberAdded BotEvent = "USER_GROUP_MEMBER_ADDED"
+	// BotEventUserGroupMemberRemoved ユーザーグループメンバー削除イベント
+	BotEventUserGroupMemberRemoved BotEvent = "USER_GROUP_MEMBER_REMOVED"
+	// BotEventUserTagAdded ユーザータグ追加イベント
+	BotEventUserTagAdded BotEvent = "USER_TAG_ADDED"
+	// BotEventUserTagUpdated ユーザータグ更新イベント
+	BotEventUserTagUpdated BotEvent = "USER_TAG_UPDATED"
+	// BotEventUserTagRemoved ユーザータグ削除イベント
+	BotEventUserTagRemoved BotEvent = "USER_TAG_REMOVED"
+	// BotEventCommandInvoked コマンド実行イベント
+	BotEventCommandInvoked BotEvent = "COMMAND_INVOKED"
+	// BotEventInteraction メッセージコンポーネント操作イベント
+	BotEventInteraction BotEvent = "INTERACTION"
 )
 
 // BotEventSet ボットイベント一覧
 var BotEventSet = map[BotEvent]bool{
-	BotEventPing:                  true,
-	BotEventJoined:                true,
-	BotEventLeft:                  true,
-	BotEventMessageCreated:        true,
-	BotEventMentionMessageCreated: true,
-	BotEventDirectMessageCreated:  true,
-	BotEventChannelCreated:        true,
-	BotEventChannelTopicChanged:   true,
-	BotEventUserCreated:           true,
-	BotEventStampCreated:          true,
+	BotEventPing:                     true,
+	BotEventJoined:                   true,
+	BotEventLeft:                     true,
+	BotEventMessageCreated:           true,
+	BotEventMentionMessageCreated:    true,
+	BotEventDirectMessageCreated:     true,
+	BotEventChannelCreated:           true,
+	BotEventChannelTopicChanged:      true,
+	BotEventUserCreated:              true,
+	BotEventStampCreated:             true,
+	BotEventMessageUpdated:           true,
+	BotEventMessageDeleted:           true,
+	BotEventMessageStamped:           true,
+	BotEventMessageUnstamped:         true,
+	BotEventMessagePinned:            true,
+	BotEventMessageUnpinned:          true,
+	BotEventChannelRenamed:           true,
+	BotEventChannelMoved:             true,
+	BotEventChannelVisibilityChanged: true,
+	BotEventUserGroupMemberAdded:     true,
+	BotEventUserGroupMemberRemoved:   true,
+	BotEventUserTagAdded:             true,
+	BotEventUserTagUpdated:           true,
+	BotEventUserTagRemoved:           true,
+	BotEventCommandInvoked:           true,
+	BotEventInteraction:              true,
 }
 
 // BotEvents ボットイベントのセット
@@ -158,6 +208,112 @@ func (set BotEvents) Validate() error {
 	})))
 }
 
+// BotPermissions Botがチャンネルで使用できる権限のセット
+type BotPermissions map[rbac.Permission]bool
+
+// Value database/sql/driver.Valuer 実装
+func (set BotPermissions) Value() (driver.Value, error) {
+	return set.String(), nil
+}
+
+// Scan database/sql.Scanner 実装
+func (set *BotPermissions) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case nil:
+		*set = BotPermissions{}
+	case string:
+		*set = botPermissionsFromString(s)
+	case []byte:
+		*set = botPermissionsFromString(string(s))
+	default:
+		return errors.New("failed to scan BotPermissions")
+	}
+	return nil
+}
+
+func botPermissionsFromString(s string) BotPermissions {
+	set := BotPermissions{}
+	for _, v := range strings.Fields(s) {
+		set[rbac.Permission(v)] = true
+	}
+	return set
+}
+
+// String BotPermissionsをスペース区切りで文字列に出力します
+func (set BotPermissions) String() string {
+	return strings.Join(set.StringArray(), " ")
+}
+
+// StringArray BotPermissionsをstringの配列に変換します
+func (set BotPermissions) StringArray() []string {
+	r := make([]string, 0, len(set))
+	for p := range set {
+		r = append(r, p.Name())
+	}
+	return r
+}
+
+// Contains 指定した権限が含まれているかどうか
+func (set BotPermissions) Contains(p rbac.Permission) bool {
+	return set[p]
+}
+
+// IsSubsetOf setの権限が全てotherに含まれているかどうか
+func (set BotPermissions) IsSubsetOf(other BotPermissions) bool {
+	for p := range set {
+		if !other[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersect setとotherの両方に含まれる権限のセットを返します
+func (set BotPermissions) Intersect(other BotPermissions) BotPermissions {
+	r := BotPermissions{}
+	for p := range set {
+		if other[p] {
+			r[p] = true
+		}
+	}
+	return r
+}
+
+// MarshalJSON encoding/json.Marshaler 実装
+func (set BotPermissions) MarshalJSON() ([]byte, error) {
+	return json.Marshal(set.StringArray())
+}
+
+// UnmarshalJSON encoding/json.Unmarshaler 実装
+func (set *BotPermissions) UnmarshalJSON(data []byte) error {
+	var str []string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	s := BotPermissions{}
+	for _, v := range str {
+		s[rbac.Permission(v)] = true
+	}
+	*set = s
+	return nil
+}
+
+// Validate github.com/go-ozzo/ozzo-validation.Validatable 実装
+//
+// permission.BotChannelScopedに含まれる権限のみを許可します。
+func (set BotPermissions) Validate() error {
+	if set == nil {
+		return nil
+	}
+	return vd.Validate(set.StringArray(), vd.Each(vd.Required, vd.By(func(value interface{}) error {
+		s, _ := value.(string)
+		if !permission.BotChannelScoped.Has(rbac.Permission(s)) {
+			return errors.New("must be channel-scoped permission")
+		}
+		return nil
+	})))
+}
+
 // BotState Bot状態
 type BotState int
 
@@ -170,22 +326,53 @@ const (
 	BotPaused BotState = 2
 )
 
+// BotMode Botのイベント配送方式
+type BotMode string
+
+const (
+	// BotModeHTTP PostURLへのHTTP POSTでイベントを配送する
+	BotModeHTTP BotMode = "HTTP"
+	// BotModeWebSocket Botが接続したWebSocketでイベントを配送する
+	BotModeWebSocket BotMode = "WebSocket"
+)
+
+// String stringにキャスト
+func (m BotMode) String() string {
+	return string(m)
+}
+
+// Valid 有効な配送方式かどうか
+func (m BotMode) Valid() bool {
+	return m == BotModeHTTP || m == BotModeWebSocket
+}
+
 // Bot Bot構造体
+//
+// ChannelScopedがfalseの場合、Botはロールの権限の範囲で全てのチャンネルを操作できます。
+// ChannelScopedがtrueの場合、チャンネル毎に許可された権限(BotJoinChannel.Permissions)の範囲でのみチャンネルを操作できます。
+// ChannelScopedは一度Permissionsを設定するとtrueになり、falseに戻ることはありません。
 type Bot struct {
-	ID                uuid.UUID  `gorm:"type:char(36);not null;primary_key"`
-	BotUserID         uuid.UUID  `gorm:"type:char(36);not null;unique"`
-	Description       string     `gorm:"type:text;not null"`
-	VerificationToken string     `gorm:"type:varchar(30);not null"`
-	AccessTokenID     uuid.UUID  `gorm:"type:char(36);not null"`
-	PostURL           string     `gorm:"type:text;not null"`
-	SubscribeEvents   BotEvents  `gorm:"type:text;not null"`
-	Privileged        bool       `gorm:"type:boolean;not null;default:false"`
-	State             BotState   `gorm:"type:tinyint;not null;default:0"`
-	BotCode           string     `gorm:"type:varchar(30);not null;unique"`
-	CreatorID         uuid.UUID  `gorm:"type:char(36);not null"`
-	CreatedAt         time.Time  `gorm:"precision:6"`
-	UpdatedAt         time.Time  `gorm:"precision:6"`
-	DeletedAt         *time.Time `gorm:"precision:6"`
+	ID                uuid.UUID      `gorm:"type:char(36);not null;primary_key"`
+	BotUserID         uuid.UUID      `gorm:"type:char(36);not null;unique"`
+	Description       string         `gorm:"type:text;not null"`
+	HelpText          string         `gorm:"type:text;not null"`
+	Homepage          string         `gorm:"type:text;not null"`
+	PrivacyNote       string         `gorm:"type:text;not null"`
+	VerificationToken string         `gorm:"type:varchar(30);not null"`
+	SigningSecret     string         `gorm:"type:varchar(64);not null;default:''"`
+	AccessTokenID     uuid.UUID      `gorm:"type:char(36);not null"`
+	PostURL           string         `gorm:"type:text;not null"`
+	Mode              BotMode        `gorm:"type:varchar(30);not null;default:'HTTP'"`
+	SubscribeEvents   BotEvents      `gorm:"type:text;not null"`
+	Permissions       BotPermissions `gorm:"type:text;not null"`
+	ChannelScoped     bool           `gorm:"type:boolean;not null;default:false"`
+	Privileged        bool           `gorm:"type:boolean;not null;default:false"`
+	State             BotState       `gorm:"type:tinyint;not null;default:0"`
+	BotCode           string         `gorm:"type:varchar(30);not null;unique"`
+	CreatorID         uuid.UUID      `gorm:"type:char(36);not null"`
+	CreatedAt         time.Time      `gorm:"precision:6"`
+	UpdatedAt         time.Time      `gorm:"precision:6"`
+	DeletedAt         *time.Time     `gorm:"precision:6"`
 }
 
 // TableName Botのテーブル名
@@ -194,9 +381,12 @@ func (*Bot) TableName() string {
 }
 
 // BotJoinChannel Bot参加チャンネル構造体
+//
+// PermissionsはBotがこのチャンネルで許可されている権限で、Bot.Permissionsの部分集合です。
 type BotJoinChannel struct {
-	ChannelID uuid.UUID `gorm:"type:char(36);not null;primary_key"`
-	BotID     uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	ChannelID   uuid.UUID      `gorm:"type:char(36);not null;primary_key"`
+	BotID       uuid.UUID      `gorm:"type:char(36);not null;primary_key"`
+	Permissions BotPermissions `gorm:"type:text;not null"`
 }
 
 // TableName BotJoinChannelのテーブル名
@@ -205,15 +395,22 @@ func (*BotJoinChannel) TableName() string {
 }
 
 // BotEventLog Botイベントログ
+//
+// 配送に失敗したイベントは再送されるまでNextRetryAtが設定され、再送を諦めたイベントはDeadLetterがtrueになります。
+// 開発用に手動で送信したテストイベントはTestがtrueになり、失敗しても再送されません。
 type BotEventLog struct {
-	RequestID uuid.UUID `gorm:"type:char(36);not null;primary_key"                json:"requestId"`
-	BotID     uuid.UUID `gorm:"type:char(36);not null;index:bot_id_date_time_idx" json:"botId"`
-	Event     BotEvent  `gorm:"type:varchar(30);not null"                         json:"event"`
-	Body      string    `gorm:"type:text"                                         json:"-"`
-	Error     string    `gorm:"type:text"                                         json:"-"`
-	Code      int       `gorm:"not null;default:0"                                json:"code"`
-	Latency   int64     `gorm:"not null;default:0"                                json:"-"`
-	DateTime  time.Time `gorm:"precision:6;index:bot_id_date_time_idx"            json:"dateTime"`
+	RequestID   uuid.UUID  `gorm:"type:char(36);not null;primary_key"                json:"requestId"`
+	BotID       uuid.UUID  `gorm:"type:char(36);not null;index:bot_id_date_time_idx" json:"botId"`
+	Event       BotEvent   `gorm:"type:varchar(30);not null"                         json:"event"`
+	Body        string     `gorm:"type:text"                                         json:"-"`
+	Error       string     `gorm:"type:text"                                         json:"-"`
+	Code        int        `gorm:"not null;default:0"                                json:"code"`
+	Latency     int64      `gorm:"not null;default:0"                                json:"-"`
+	Attempts    int        `gorm:"not null;default:1"                                json:"attempts"`
+	NextRetryAt *time.Time `gorm:"precision:6;index"                                 json:"nextRetryAt"`
+	DeadLetter  bool       `gorm:"not null;default:false"                            json:"deadLetter"`
+	Test        bool       `gorm:"not null;default:false"                            json:"test"`
+	DateTime    time.Time  `gorm:"precision:6;index:bot_id_date_time_idx"            json:"dateTime"`
 }
 
 // TableName BotEventLogのテーブル名
@@ -0,0 +1,187 @@
+package model
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+)
+
+// BotCommandNameRegex Botコマンド名・引数名の正規表現
+var BotCommandNameRegex = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// BotCommandArgumentType Botコマンド引数の型
+type BotCommandArgumentType string
+
+const (
+	// BotCommandArgumentString 文字列
+	BotCommandArgumentString BotCommandArgumentType = "string"
+	// BotCommandArgumentInteger 整数
+	BotCommandArgumentInteger BotCommandArgumentType = "integer"
+	// BotCommandArgumentBoolean 真偽値
+	BotCommandArgumentBoolean BotCommandArgumentType = "boolean"
+)
+
+// BotCommandArgument Botコマンドの引数
+type BotCommandArgument struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Type        BotCommandArgumentType `json:"type"`
+	Required    bool                   `json:"required"`
+}
+
+// Validate github.com/go-ozzo/ozzo-validation.Validatable 実装
+func (a BotCommandArgument) Validate() error {
+	return vd.ValidateStruct(&a,
+		vd.Field(&a.Name, vd.Required, vd.Match(BotCommandNameRegex)),
+		vd.Field(&a.Description, vd.RuneLength(0, 100)),
+		vd.Field(&a.Type, vd.Required, vd.In(BotCommandArgumentString, BotCommandArgumentInteger, BotCommandArgumentBoolean)),
+	)
+}
+
+// BotCommandArguments Botコマンドの引数の配列
+type BotCommandArguments []*BotCommandArgument
+
+// Value database/sql/driver.Valuer 実装
+func (args BotCommandArguments) Value() (driver.Value, error) {
+	if args == nil {
+		args = BotCommandArguments{}
+	}
+	return json.MarshalToString(args)
+}
+
+// Scan database/sql.Scanner 実装
+func (args *BotCommandArguments) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case nil:
+		*args = BotCommandArguments{}
+		return nil
+	case string:
+		return json.Unmarshal([]byte(s), args)
+	case []byte:
+		return json.Unmarshal(s, args)
+	default:
+		return errors.New("failed to scan BotCommandArguments")
+	}
+}
+
+// Validate github.com/go-ozzo/ozzo-validation.Validatable 実装
+//
+// 引数名の重複と、省略可能な引数の後の必須引数を禁止します。
+func (args BotCommandArguments) Validate() error {
+	if err := vd.Validate([]*BotCommandArgument(args), vd.Length(0, 10), vd.Each(vd.NotNil)); err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(args))
+	optional := false
+	for _, a := range args {
+		if names[a.Name] {
+			return fmt.Errorf("argument %s is duplicated", a.Name)
+		}
+		names[a.Name] = true
+		if a.Required && optional {
+			return fmt.Errorf("required argument %s must not follow optional arguments", a.Name)
+		}
+		optional = optional || !a.Required
+	}
+	return nil
+}
+
+// BotCommand Botのスラッシュコマンド
+type BotCommand struct {
+	BotID       uuid.UUID           `gorm:"type:char(36);not null;primary_key"    json:"botId"`
+	Name        string              `gorm:"type:varchar(32);not null;primary_key" json:"name"`
+	Description string              `gorm:"type:text;not null"                    json:"description"`
+	Arguments   BotCommandArguments `gorm:"type:text;not null"                    json:"arguments"`
+	HideMessage bool                `gorm:"type:boolean;not null;default:false"   json:"hideMessage"`
+	CreatedAt   time.Time           `gorm:"precision:6"                           json:"createdAt"`
+	UpdatedAt   time.Time           `gorm:"precision:6"                           json:"updatedAt"`
+}
+
+// TableName BotCommandのテーブル名
+func (*BotCommand) TableName() string {
+	return "bot_commands"
+}
+
+// Validate github.com/go-ozzo/ozzo-validation.Validatable 実装
+func (c BotCommand) Validate() error {
+	return vd.ValidateStruct(&c,
+		vd.Field(&c.Name, vd.Required, vd.Match(BotCommandNameRegex)),
+		vd.Field(&c.Description, vd.RuneLength(0, 200)),
+		vd.Field(&c.Arguments),
+	)
+}
+
+// ParseArguments コマンド名に続く文字列を引数の定義に従って解析します
+//
+// 引数は空白で区切られます。最後の引数が文字列型の場合、残りの文字列全てがその引数になります。
+// 省略された任意引数は結果に含まれません。
+func (c *BotCommand) ParseArguments(text string) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(c.Arguments))
+	rest := strings.TrimSpace(text)
+	for i, a := range c.Arguments {
+		if len(rest) == 0 {
+			if a.Required {
+				return nil, fmt.Errorf("argument %s is required", a.Name)
+			}
+			continue
+		}
+
+		var token string
+		if i == len(c.Arguments)-1 && a.Type == BotCommandArgumentString {
+			token, rest = rest, ""
+		} else if j := strings.IndexFunc(rest, unicode.IsSpace); j >= 0 {
+			token, rest = rest[:j], strings.TrimLeftFunc(rest[j:], unicode.IsSpace)
+		} else {
+			token, rest = rest, ""
+		}
+
+		switch a.Type {
+		case BotCommandArgumentInteger:
+			v, err := strconv.ParseInt(token, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("argument %s must be an integer", a.Name)
+			}
+			result[a.Name] = v
+		case BotCommandArgumentBoolean:
+			v, err := strconv.ParseBool(token)
+			if err != nil {
+				return nil, fmt.Errorf("argument %s must be a boolean", a.Name)
+			}
+			result[a.Name] = v
+		default:
+			result[a.Name] = token
+		}
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("too many arguments")
+	}
+	return result, nil
+}
+
+// ParseBotCommandText `/コマンド名 引数...`形式の文字列をコマンド名と引数部分に分割します
+//
+// 形式が正しくない場合、okがfalseになります。
+func ParseBotCommandText(text string) (name string, args string, ok bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return "", "", false
+	}
+	text = text[1:]
+	if i := strings.IndexFunc(text, unicode.IsSpace); i >= 0 {
+		name, args = text[:i], strings.TrimSpace(text[i:])
+	} else {
+		name = text
+	}
+	if !BotCommandNameRegex.MatchString(name) {
+		return "", "", false
+	}
+	return name, args, true
+}
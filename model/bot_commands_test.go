@@ -0,0 +1,161 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBotCommand_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "bot_commands", (&BotCommand{}).TableName())
+}
+
+func TestBotCommandArguments_Value(t *testing.T) {
+	t.Parallel()
+
+	v, err := BotCommandArguments(nil).Value()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "[]", v)
+	}
+
+	v, err = BotCommandArguments{{Name: "a", Type: BotCommandArgumentString, Required: true}}.Value()
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `[{"name":"a","description":"","type":"string","required":true}]`, v.(string))
+	}
+}
+
+func TestBotCommandArguments_Scan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+		var args BotCommandArguments
+		assert.NoError(t, args.Scan(nil))
+		assert.Len(t, args, 0)
+	})
+	t.Run("string", func(t *testing.T) {
+		t.Parallel()
+		var args BotCommandArguments
+		assert.NoError(t, args.Scan(`[{"name":"a","type":"integer"}]`))
+		if assert.Len(t, args, 1) {
+			assert.Equal(t, "a", args[0].Name)
+			assert.Equal(t, BotCommandArgumentInteger, args[0].Type)
+		}
+	})
+	t.Run("[]byte", func(t *testing.T) {
+		t.Parallel()
+		var args BotCommandArguments
+		assert.NoError(t, args.Scan([]byte(`[{"name":"a","type":"boolean"}]`)))
+		assert.Len(t, args, 1)
+	})
+	t.Run("other", func(t *testing.T) {
+		t.Parallel()
+		var args BotCommandArguments
+		assert.Error(t, args.Scan(1))
+	})
+}
+
+func TestBotCommand_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cmd     BotCommand
+		wantErr bool
+	}{
+		{"ok", BotCommand{Name: "deploy", Arguments: BotCommandArguments{
+			{Name: "env", Type: BotCommandArgumentString, Required: true},
+			{Name: "force", Type: BotCommandArgumentBoolean},
+		}}, false},
+		{"no arguments", BotCommand{Name: "ping"}, false},
+		{"empty name", BotCommand{Name: ""}, true},
+		{"invalid name", BotCommand{Name: "Deploy"}, true},
+		{"invalid argument type", BotCommand{Name: "a", Arguments: BotCommandArguments{{Name: "x", Type: "float"}}}, true},
+		{"duplicated argument", BotCommand{Name: "a", Arguments: BotCommandArguments{
+			{Name: "x", Type: BotCommandArgumentString},
+			{Name: "x", Type: BotCommandArgumentString},
+		}}, true},
+		{"required after optional", BotCommand{Name: "a", Arguments: BotCommandArguments{
+			{Name: "x", Type: BotCommandArgumentString},
+			{Name: "y", Type: BotCommandArgumentString, Required: true},
+		}}, true},
+		{"nil argument", BotCommand{Name: "a", Arguments: BotCommandArguments{nil}}, true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if tt.wantErr {
+				assert.Error(t, tt.cmd.Validate())
+			} else {
+				assert.NoError(t, tt.cmd.Validate())
+			}
+		})
+	}
+}
+
+func TestBotCommand_ParseArguments(t *testing.T) {
+	t.Parallel()
+
+	cmd := &BotCommand{Name: "deploy", Arguments: BotCommandArguments{
+		{Name: "replicas", Type: BotCommandArgumentInteger, Required: true},
+		{Name: "force", Type: BotCommandArgumentBoolean},
+		{Name: "comment", Type: BotCommandArgumentString},
+	}}
+
+	tests := []struct {
+		name    string
+		text    string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{"all", "3 true hello  world", map[string]interface{}{"replicas": int64(3), "force": true, "comment": "hello  world"}, false},
+		{"optional omitted", " 3 ", map[string]interface{}{"replicas": int64(3)}, false},
+		{"required missing", "", nil, true},
+		{"invalid integer", "three", nil, true},
+		{"invalid boolean", "3 yes", nil, true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := cmd.ParseArguments(tt.text)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	t.Run("too many arguments", func(t *testing.T) {
+		t.Parallel()
+		_, err := (&BotCommand{Name: "a", Arguments: BotCommandArguments{{Name: "n", Type: BotCommandArgumentInteger}}}).ParseArguments("1 2")
+		assert.Error(t, err)
+	})
+}
+
+func TestParseBotCommandText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text string
+		name string
+		args string
+		ok   bool
+	}{
+		{"/deploy prod  now", "deploy", "prod  now", true},
+		{"  /ping", "ping", "", true},
+		{"/ping\nline", "ping", "line", true},
+		{"deploy prod", "", "", false},
+		{"/", "", "", false},
+		{"/Deploy", "", "", false},
+	}
+	for _, tt := range tests {
+		name, args, ok := ParseBotCommandText(tt.text)
+		assert.Equal(t, tt.ok, ok, tt.text)
+		assert.Equal(t, tt.name, name, tt.text)
+		assert.Equal(t, tt.args, args, tt.text)
+	}
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/traPtitech/traQ/rbac/permission"
 	"strings"
 	"testing"
 )
@@ -93,3 +94,53 @@ func TestBotEvents_MarshalJSON(t *testing.T) {
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, []string{`"PING"`, `"PONG"`}, strings.Split(strings.Trim(string(b), "[]"), ","))
 }
+
+func TestBotMode_String(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "WebSocket", BotModeWebSocket.String())
+}
+
+func TestBotMode_Valid(t *testing.T) {
+	t.Parallel()
+	assert.True(t, BotModeHTTP.Valid())
+	assert.True(t, BotModeWebSocket.Valid())
+	assert.False(t, BotMode("").Valid())
+	assert.False(t, BotMode("websocket").Valid())
+}
+
+func TestBotPermissions_Scan(t *testing.T) {
+	t.Parallel()
+
+	s := BotPermissions{}
+	assert.NoError(t, s.Scan("get_message  post_message "))
+	assert.True(t, s.Contains(permission.GetMessage))
+	assert.True(t, s.Contains(permission.PostMessage))
+	assert.Len(t, s, 2)
+
+	assert.NoError(t, s.Scan(nil))
+	assert.Len(t, s, 0)
+	assert.Error(t, s.Scan(123))
+}
+
+func TestBotPermissions_IsSubsetOf(t *testing.T) {
+	t.Parallel()
+	requested := BotPermissions{permission.GetMessage: true, permission.PostMessage: true}
+	assert.True(t, BotPermissions{}.IsSubsetOf(requested))
+	assert.True(t, BotPermissions{permission.GetMessage: true}.IsSubsetOf(requested))
+	assert.False(t, BotPermissions{permission.DeleteMessage: true}.IsSubsetOf(requested))
+}
+
+func TestBotPermissions_Intersect(t *testing.T) {
+	t.Parallel()
+	a := BotPermissions{permission.GetMessage: true, permission.PostMessage: true}
+	b := BotPermissions{permission.PostMessage: true, permission.DeleteMessage: true}
+	assert.EqualValues(t, BotPermissions{permission.PostMessage: true}, a.Intersect(b))
+}
+
+func TestBotPermissions_Validate(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, BotPermissions(nil).Validate())
+	assert.NoError(t, BotPermissions{permission.GetMessage: true, permission.EditChannelTopic: true}.Validate())
+	assert.Error(t, BotPermissions{permission.CreateChannel: true}.Validate())
+	assert.Error(t, BotPermissions{"unknown": true}.Validate())
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// ChannelExportStatus チャンネルエクスポートの状態
+type ChannelExportStatus string
+
+const (
+	// ChannelExportPending 生成待ち
+	ChannelExportPending ChannelExportStatus = "pending"
+	// ChannelExportRunning 生成中
+	ChannelExportRunning ChannelExportStatus = "running"
+	// ChannelExportCompleted 生成完了
+	ChannelExportCompleted ChannelExportStatus = "completed"
+	// ChannelExportFailed 生成失敗
+	ChannelExportFailed ChannelExportStatus = "failed"
+)
+
+// ChannelExport チャンネルエクスポート構造体
+//
+// ChannelIDのチャンネルとその子孫チャンネルのうち、UserIDのユーザーがアクセス可能なチャンネルの履歴をエクスポートします。
+type ChannelExport struct {
+	ID        uuid.UUID           `gorm:"type:char(36);not null;primary_key"`
+	UserID    uuid.UUID           `gorm:"type:char(36);not null;index"`
+	ChannelID uuid.UUID           `gorm:"type:char(36);not null"`
+	Status    ChannelExportStatus `gorm:"type:varchar(20);not null"`
+	// FileID 生成されたアーカイブファイルのUUID (生成完了時のみ)
+	FileID uuid.NullUUID `gorm:"type:char(36)"`
+	// Error 生成失敗時のエラーメッセージ
+	Error     string    `gorm:"type:text;not null"`
+	CreatedAt time.Time `gorm:"precision:6;index"`
+	UpdatedAt time.Time `gorm:"precision:6"`
+}
+
+// TableName ChannelExport構造体のテーブル名
+func (*ChannelExport) TableName() string {
+	return "channel_exports"
+}
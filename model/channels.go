@@ -18,18 +18,20 @@ var dmChannelRootUUID = uuid.Must(uuid.FromString(DirectMessageChannelRootID))
 
 // Channel チャンネルの構造体
 type Channel struct {
-	ID        uuid.UUID  `gorm:"type:char(36);not null;primary_key"`
-	Name      string     `gorm:"type:varchar(20);not null;unique_index:name_parent"`
-	ParentID  uuid.UUID  `gorm:"type:char(36);not null;unique_index:name_parent"`
-	Topic     string     `sql:"type:TEXT COLLATE utf8mb4_bin NOT NULL"`
-	IsForced  bool       `gorm:"type:boolean;not null;default:false"`
-	IsPublic  bool       `gorm:"type:boolean;not null;default:false"`
-	IsVisible bool       `gorm:"type:boolean;not null;default:false"`
-	CreatorID uuid.UUID  `gorm:"type:char(36);not null"`
-	UpdaterID uuid.UUID  `gorm:"type:char(36);not null"`
-	CreatedAt time.Time  `gorm:"precision:6"`
-	UpdatedAt time.Time  `gorm:"precision:6"`
-	DeletedAt *time.Time `gorm:"precision:6"`
+	ID        uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	Name      string    `gorm:"type:varchar(20);not null;unique_index:name_parent"`
+	ParentID  uuid.UUID `gorm:"type:char(36);not null;unique_index:name_parent"`
+	Topic     string    `sql:"type:TEXT COLLATE utf8mb4_bin NOT NULL"`
+	IsForced  bool      `gorm:"type:boolean;not null;default:false"`
+	IsPublic  bool      `gorm:"type:boolean;not null;default:false"`
+	IsVisible bool      `gorm:"type:boolean;not null;default:false"`
+	// IsArchived アーカイブされているかどうか。アーカイブされたチャンネルは読み取り専用です
+	IsArchived bool       `gorm:"type:boolean;not null;default:false"`
+	CreatorID  uuid.UUID  `gorm:"type:char(36);not null"`
+	UpdaterID  uuid.UUID  `gorm:"type:char(36);not null"`
+	CreatedAt  time.Time  `gorm:"precision:6"`
+	UpdatedAt  time.Time  `gorm:"precision:6"`
+	DeletedAt  *time.Time `gorm:"precision:6"`
 }
 
 // TableName テーブル名を指定するメソッド
@@ -164,6 +166,20 @@ const (
 	// 	userId    作成者UUID
 	// 	channelId チャンネルUUID
 	ChannelEventChildCreated = ChannelEventType("ChildCreated")
+	// ChannelEventArchived チャンネルイベント アーカイブ
+	//
+	// 	userId 変更者UUID
+	ChannelEventArchived = ChannelEventType("Archived")
+	// ChannelEventUnarchived チャンネルイベント アーカイブ解除
+	//
+	// 	userId 変更者UUID
+	ChannelEventUnarchived = ChannelEventType("Unarchived")
+	// ChannelEventMembersChanged チャンネルイベント プライベートチャンネルメンバー変更
+	//
+	// 	userId  変更者UUID
+	// 	added   追加されたユーザーのUUIDの配列
+	// 	removed 削除されたユーザーのUUIDの配列
+	ChannelEventMembersChanged = ChannelEventType("MembersChanged")
 )
 
 // ChannelEventDetail チャンネルイベント詳細
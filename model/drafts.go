@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Draft メッセージの下書き構造体
+type Draft struct {
+	UserID    uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	ChannelID uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	Text      string    `sql:"type:TEXT COLLATE utf8mb4_bin NOT NULL"`
+	UpdatedAt time.Time `gorm:"precision:6"`
+}
+
+// TableName Draft構造体のテーブル名
+func (*Draft) TableName() string {
+	return "drafts"
+}
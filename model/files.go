@@ -17,6 +17,8 @@ const (
 	FileTypeStamp = "stamp"
 	// FileTypeThumbnail サムネイルファイルタイプ
 	FileTypeThumbnail = "thumbnail"
+	// FileTypeExport チャンネルエクスポートファイルタイプ
+	FileTypeExport = "export"
 )
 
 type FileMeta interface {
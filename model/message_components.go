@@ -0,0 +1,164 @@
+package model
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+)
+
+// MessageComponentIDRegex メッセージコンポーネントIDの正規表現
+var MessageComponentIDRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// MessageComponentType メッセージコンポーネントの種類
+type MessageComponentType string
+
+const (
+	// MessageComponentButton ボタン
+	MessageComponentButton MessageComponentType = "button"
+	// MessageComponentSelect セレクトメニュー
+	MessageComponentSelect MessageComponentType = "select"
+)
+
+// MessageComponentButtonStyle ボタンの見た目
+type MessageComponentButtonStyle string
+
+const (
+	// MessageComponentButtonDefault 通常
+	MessageComponentButtonDefault MessageComponentButtonStyle = "default"
+	// MessageComponentButtonPrimary 強調
+	MessageComponentButtonPrimary MessageComponentButtonStyle = "primary"
+	// MessageComponentButtonDanger 危険な操作
+	MessageComponentButtonDanger MessageComponentButtonStyle = "danger"
+)
+
+// MessageComponentOption セレクトメニューの選択肢
+type MessageComponentOption struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// Validate github.com/go-ozzo/ozzo-validation.Validatable 実装
+func (o MessageComponentOption) Validate() error {
+	return vd.ValidateStruct(&o,
+		vd.Field(&o.Label, vd.Required, vd.RuneLength(1, 100)),
+		vd.Field(&o.Value, vd.Required, vd.RuneLength(1, 100)),
+	)
+}
+
+// MessageComponent メッセージに付けるボタン・セレクトメニュー
+//
+// ユーザーが操作すると、IDと値がメッセージを投稿したBotにINTERACTIONイベントとして送信されます。
+type MessageComponent struct {
+	Type     MessageComponentType        `json:"type"`
+	ID       string                      `json:"id"`
+	Label    string                      `json:"label"`
+	Style    MessageComponentButtonStyle `json:"style,omitempty"`
+	Value    string                      `json:"value,omitempty"`
+	Options  []*MessageComponentOption   `json:"options,omitempty"`
+	Disabled bool                        `json:"disabled"`
+}
+
+// Validate github.com/go-ozzo/ozzo-validation.Validatable 実装
+func (c MessageComponent) Validate() error {
+	isButton := c.Type == MessageComponentButton
+	isSelect := c.Type == MessageComponentSelect
+	return vd.ValidateStruct(&c,
+		vd.Field(&c.Type, vd.Required, vd.In(MessageComponentButton, MessageComponentSelect)),
+		vd.Field(&c.ID, vd.Required, vd.Match(MessageComponentIDRegex)),
+		vd.Field(&c.Label, vd.Required, vd.RuneLength(1, 100)),
+		// ボタン以外には見た目・値を、セレクトメニュー以外には選択肢を指定できない
+		vd.Field(&c.Style,
+			vd.When(isButton, vd.In(MessageComponentButtonDefault, MessageComponentButtonPrimary, MessageComponentButtonDanger)),
+			vd.When(!isButton, vd.In()),
+		),
+		vd.Field(&c.Value,
+			vd.When(isButton, vd.RuneLength(0, 100)),
+			vd.When(!isButton, vd.In()),
+		),
+		vd.Field(&c.Options,
+			vd.When(isSelect, vd.Required, vd.Length(1, 25), vd.Each(vd.NotNil)),
+			vd.When(!isSelect, vd.In()),
+		),
+	)
+}
+
+// FindOption 指定した値の選択肢を返します
+func (c *MessageComponent) FindOption(value string) *MessageComponentOption {
+	for _, o := range c.Options {
+		if o.Value == value {
+			return o
+		}
+	}
+	return nil
+}
+
+// MessageComponentList メッセージコンポーネントの配列
+type MessageComponentList []*MessageComponent
+
+// Value database/sql/driver.Valuer 実装
+func (l MessageComponentList) Value() (driver.Value, error) {
+	if l == nil {
+		l = MessageComponentList{}
+	}
+	return json.MarshalToString(l)
+}
+
+// Scan database/sql.Scanner 実装
+func (l *MessageComponentList) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case nil:
+		*l = MessageComponentList{}
+		return nil
+	case string:
+		return json.Unmarshal([]byte(s), l)
+	case []byte:
+		return json.Unmarshal(s, l)
+	default:
+		return errors.New("failed to scan MessageComponentList")
+	}
+}
+
+// Validate github.com/go-ozzo/ozzo-validation.Validatable 実装
+//
+// コンポーネントIDの重複を禁止します。
+func (l MessageComponentList) Validate() error {
+	if err := vd.Validate([]*MessageComponent(l), vd.Length(0, 25), vd.Each(vd.NotNil)); err != nil {
+		return err
+	}
+	ids := make(map[string]bool, len(l))
+	for _, c := range l {
+		if ids[c.ID] {
+			return fmt.Errorf("component %s is duplicated", c.ID)
+		}
+		ids[c.ID] = true
+	}
+	return nil
+}
+
+// Find 指定したIDのコンポーネントを返します
+func (l MessageComponentList) Find(id string) *MessageComponent {
+	for _, c := range l {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// MessageComponents メッセージに付けられたコンポーネント
+type MessageComponents struct {
+	MessageID  uuid.UUID            `gorm:"type:char(36);not null;primary_key"`
+	Components MessageComponentList `gorm:"type:text;not null"`
+	CreatedAt  time.Time            `gorm:"precision:6"`
+	UpdatedAt  time.Time            `gorm:"precision:6"`
+}
+
+// TableName MessageComponentsのテーブル名
+func (*MessageComponents) TableName() string {
+	return "message_components"
+}
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageComponents_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "message_components", (&MessageComponents{}).TableName())
+}
+
+func TestMessageComponentList_Value(t *testing.T) {
+	t.Parallel()
+
+	v, err := MessageComponentList(nil).Value()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "[]", v)
+	}
+
+	v, err = MessageComponentList{{Type: MessageComponentButton, ID: "ok", Label: "OK", Value: "1"}}.Value()
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `[{"type":"button","id":"ok","label":"OK","value":"1","disabled":false}]`, v.(string))
+	}
+}
+
+func TestMessageComponentList_Scan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+		var l MessageComponentList
+		assert.NoError(t, l.Scan(nil))
+		assert.Len(t, l, 0)
+	})
+	t.Run("string", func(t *testing.T) {
+		t.Parallel()
+		var l MessageComponentList
+		assert.NoError(t, l.Scan(`[{"type":"select","id":"s","label":"S","options":[{"label":"A","value":"a"}]}]`))
+		if assert.Len(t, l, 1) {
+			assert.Equal(t, MessageComponentSelect, l[0].Type)
+			assert.Len(t, l[0].Options, 1)
+		}
+	})
+	t.Run("[]byte", func(t *testing.T) {
+		t.Parallel()
+		var l MessageComponentList
+		assert.NoError(t, l.Scan([]byte(`[{"type":"button","id":"b","label":"B"}]`)))
+		assert.Len(t, l, 1)
+	})
+	t.Run("other", func(t *testing.T) {
+		t.Parallel()
+		var l MessageComponentList
+		assert.Error(t, l.Scan(1))
+	})
+}
+
+func TestMessageComponentList_Validate(t *testing.T) {
+	t.Parallel()
+
+	options := []*MessageComponentOption{{Label: "A", Value: "a"}, {Label: "B", Value: "b"}}
+	tests := []struct {
+		name    string
+		list    MessageComponentList
+		wantErr bool
+	}{
+		{"empty", MessageComponentList{}, false},
+		{"ok", MessageComponentList{
+			{Type: MessageComponentButton, ID: "ok", Label: "OK", Style: MessageComponentButtonPrimary, Value: "yes"},
+			{Type: MessageComponentSelect, ID: "choice", Label: "Choose", Options: options},
+		}, false},
+		{"nil component", MessageComponentList{nil}, true},
+		{"invalid type", MessageComponentList{{Type: "link", ID: "a", Label: "a"}}, true},
+		{"invalid id", MessageComponentList{{Type: MessageComponentButton, ID: "a b", Label: "a"}}, true},
+		{"empty label", MessageComponentList{{Type: MessageComponentButton, ID: "a"}}, true},
+		{"invalid style", MessageComponentList{{Type: MessageComponentButton, ID: "a", Label: "a", Style: "warning"}}, true},
+		{"button with options", MessageComponentList{{Type: MessageComponentButton, ID: "a", Label: "a", Options: options}}, true},
+		{"select without options", MessageComponentList{{Type: MessageComponentSelect, ID: "a", Label: "a"}}, true},
+		{"select with value", MessageComponentList{{Type: MessageComponentSelect, ID: "a", Label: "a", Value: "a", Options: options}}, true},
+		{"duplicated id", MessageComponentList{
+			{Type: MessageComponentButton, ID: "a", Label: "a"},
+			{Type: MessageComponentButton, ID: "a", Label: "b"},
+		}, true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if tt.wantErr {
+				assert.Error(t, tt.list.Validate())
+			} else {
+				assert.NoError(t, tt.list.Validate())
+			}
+		})
+	}
+}
+
+func TestMessageComponentList_Find(t *testing.T) {
+	t.Parallel()
+
+	l := MessageComponentList{
+		{Type: MessageComponentButton, ID: "a", Label: "a"},
+		{Type: MessageComponentSelect, ID: "b", Label: "b", Options: []*MessageComponentOption{{Label: "X", Value: "x"}}},
+	}
+	if c := l.Find("b"); assert.NotNil(t, c) {
+		assert.NotNil(t, c.FindOption("x"))
+		assert.Nil(t, c.FindOption("y"))
+	}
+	assert.Nil(t, l.Find("c"))
+}
@@ -7,16 +7,21 @@ import (
 
 // Message データベースに格納するmessageの構造体
 type Message struct {
-	ID        uuid.UUID  `gorm:"type:char(36);not null;primary_key"`
-	UserID    uuid.UUID  `gorm:"type:char(36);not null;"`
-	ChannelID uuid.UUID  `gorm:"type:char(36);not null;index"`
-	Text      string     `sql:"type:TEXT COLLATE utf8mb4_bin NOT NULL"`
-	CreatedAt time.Time  `gorm:"precision:6;index"`
-	UpdatedAt time.Time  `gorm:"precision:6"`
-	DeletedAt *time.Time `gorm:"precision:6"`
-
-	Stamps []MessageStamp `gorm:"association_autoupdate:false;association_autocreate:false;preload:false;foreignkey:MessageID"`
-	Pin    *Pin           `gorm:"association_autoupdate:false;association_autocreate:false;preload:false;foreignkey:MessageID"`
+	ID        uuid.UUID     `gorm:"type:char(36);not null;primary_key"`
+	UserID    uuid.UUID     `gorm:"type:char(36);not null;"`
+	ChannelID uuid.UUID     `gorm:"type:char(36);not null;index"`
+	Text      string        `sql:"type:TEXT COLLATE utf8mb4_bin NOT NULL"`
+	ThreadID  uuid.NullUUID `gorm:"type:char(36);index"`
+	EditCount int           `gorm:"type:int;not null;default:0"`
+	CreatedAt time.Time     `gorm:"precision:6;index"`
+	UpdatedAt time.Time     `gorm:"precision:6"`
+	DeletedAt *time.Time    `gorm:"precision:6;index"`
+	DeletedBy uuid.NullUUID `gorm:"type:char(36)"`
+
+	Stamps     []MessageStamp     `gorm:"association_autoupdate:false;association_autocreate:false;preload:false;foreignkey:MessageID"`
+	Pin        *Pin               `gorm:"association_autoupdate:false;association_autocreate:false;preload:false;foreignkey:MessageID"`
+	Thread     *MessageThread     `gorm:"association_autoupdate:false;association_autocreate:false;preload:false;foreignkey:MessageID"`
+	Components *MessageComponents `gorm:"association_autoupdate:false;association_autocreate:false;preload:false;foreignkey:MessageID"`
 }
 
 // TableName DBの名前を指定するメソッド
@@ -24,6 +29,49 @@ func (m *Message) TableName() string {
 	return "messages"
 }
 
+// IsEdited 編集されたことがあるかどうか
+func (m *Message) IsEdited() bool {
+	return m.EditCount > 0
+}
+
+// IsDeleted 削除済みかどうか
+func (m *Message) IsDeleted() bool {
+	return m.DeletedAt != nil
+}
+
+// IsReply スレッドへの返信メッセージかどうか
+func (m *Message) IsReply() bool {
+	return m.ThreadID.Valid
+}
+
+// MessageThread スレッドの集計情報
+//
+// MessageIDはスレッドの起点となったメッセージのIDで、スレッドIDとして扱われます。
+type MessageThread struct {
+	MessageID   uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	ReplyCount  int       `gorm:"type:int;not null;default:0"`
+	LastReplyAt time.Time `gorm:"precision:6"`
+}
+
+// TableName テーブル名
+func (t *MessageThread) TableName() string {
+	return "message_threads"
+}
+
+// MessageThreadParticipant スレッド参加者
+//
+// スレッドの起点メッセージの投稿者と、スレッドに返信したユーザーが参加者になります。
+type MessageThreadParticipant struct {
+	ThreadID  uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	CreatedAt time.Time `gorm:"precision:6"`
+}
+
+// TableName テーブル名
+func (p *MessageThreadParticipant) TableName() string {
+	return "message_thread_participants"
+}
+
 // ChannelLatestMessage チャンネル別最新メッセージ
 type ChannelLatestMessage struct {
 	ChannelID uuid.UUID `gorm:"type:char(36);not null;primary_key"`
@@ -2,7 +2,9 @@ package model
 
 import (
 	"testing"
+	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,3 +27,32 @@ func TestArchivedMessage_TableName(t *testing.T) {
 	t.Parallel()
 	assert.Equal(t, "archived_messages", (&ArchivedMessage{}).TableName())
 }
+
+func TestMessage_IsReply(t *testing.T) {
+	t.Parallel()
+	assert.False(t, (&Message{}).IsReply())
+	assert.True(t, (&Message{ThreadID: uuid.NullUUID{UUID: uuid.Must(uuid.NewV4()), Valid: true}}).IsReply())
+}
+
+func TestMessage_IsEdited(t *testing.T) {
+	t.Parallel()
+	assert.False(t, (&Message{}).IsEdited())
+	assert.True(t, (&Message{EditCount: 1}).IsEdited())
+}
+
+func TestMessage_IsDeleted(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	assert.False(t, (&Message{}).IsDeleted())
+	assert.True(t, (&Message{DeletedAt: &now}).IsDeleted())
+}
+
+func TestMessageThread_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "message_threads", (&MessageThread{}).TableName())
+}
+
+func TestMessageThreadParticipant_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "message_thread_participants", (&MessageThreadParticipant{}).TableName())
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// ScheduledMessage 予約投稿メッセージ構造体
+type ScheduledMessage struct {
+	ID        uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null;index"`
+	ChannelID uuid.UUID `gorm:"type:char(36);not null"`
+	Text      string    `sql:"type:TEXT COLLATE utf8mb4_bin NOT NULL"`
+	// ReminderMessageID リマインド対象のメッセージUUID
+	//
+	// 有効な場合、このメッセージはリマインダーであり、traQユーザーからUserIDへのDMとして投稿されます。
+	ReminderMessageID uuid.NullUUID `gorm:"type:char(36)"`
+	ScheduledAt       time.Time     `gorm:"precision:6;index"`
+	CreatedAt         time.Time     `gorm:"precision:6"`
+	UpdatedAt         time.Time     `gorm:"precision:6"`
+}
+
+// TableName ScheduledMessage構造体のテーブル名
+func (*ScheduledMessage) TableName() string {
+	return "scheduled_messages"
+}
+
+// IsReminder リマインダーかどうか
+func (m *ScheduledMessage) IsReminder() bool {
+	return m.ReminderMessageID.Valid
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduledMessage_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "scheduled_messages", (&ScheduledMessage{}).TableName())
+}
+
+func TestScheduledMessage_IsReminder(t *testing.T) {
+	t.Parallel()
+	assert.False(t, (&ScheduledMessage{}).IsReminder())
+	assert.True(t, (&ScheduledMessage{ReminderMessageID: uuid.NullUUID{UUID: uuid.Must(uuid.NewV4()), Valid: true}}).IsReminder())
+}
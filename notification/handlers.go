@@ -24,7 +24,9 @@ type eventHandler func(ns *Service, ev hub.Message)
 var handlerMap = map[string]eventHandler{
 	event.MessageCreated:           messageCreatedHandler,
 	event.MessageUpdated:           messageUpdatedHandler,
+	event.MessageComponentsUpdated: messageUpdatedHandler,
 	event.MessageDeleted:           messageDeletedHandler,
+	event.MessageRestored:          messageRestoredHandler,
 	event.MessagePinned:            messagePinnedHandler,
 	event.MessageUnpinned:          messageUnpinnedHandler,
 	event.MessageStamped:           messageStampedHandler,
@@ -35,7 +37,10 @@ var handlerMap = map[string]eventHandler{
 	event.ChannelStared:            channelStaredHandler,
 	event.ChannelUnstared:          channelUnstaredHandler,
 	event.ChannelRead:              channelReadHandler,
+	event.ThreadRead:               threadReadHandler,
 	event.ChannelViewersChanged:    channelViewersChangedHandler,
+	event.ChannelMemberAdded:       channelMemberAddedHandler,
+	event.ChannelMemberRemoved:     channelMemberRemovedHandler,
 	event.UserCreated:              userCreatedHandler,
 	event.UserUpdated:              userUpdatedHandler,
 	event.UserIconUpdated:          userIconUpdatedHandler,
@@ -55,11 +60,15 @@ var handlerMap = map[string]eventHandler{
 	event.StampPaletteUpdated:      stampPaletteUpdatedHandler,
 	event.StampPaletteDeleted:      stampPaletteDeletedHandler,
 	event.UserWebRTCStateChanged:   userWebRTCStateChangedHandler,
+	event.UserTyping:               userTypingHandler,
 	event.ClipFolderCreated:        clipFolderCreatedHandler,
 	event.ClipFolderUpdated:        clipFolderUpdatedHandler,
 	event.ClipFolderDeleted:        clipFolderDeletedHandler,
 	event.ClipFolderMessageDeleted: clipFolderMessageDeletedHandler,
 	event.ClipFolderMessageAdded:   clipFolderMessageAddedHandler,
+	event.DraftUpdated:             draftUpdatedHandler,
+	event.ChannelExportUpdated:     channelExportUpdatedHandler,
+	event.BotAutoPaused:            botAutoPausedHandler,
 }
 
 func messageCreatedHandler(ns *Service, ev hub.Message) {
@@ -86,11 +95,16 @@ func messageCreatedHandler(ns *Service, ev hub.Message) {
 		Icon: fmt.Sprintf("%s/api/v3/public/icon/%s", ns.origin, strings.ReplaceAll(mUser.GetName(), "#", "%23")),
 		Tag:  "c:" + m.ChannelID.String(),
 	}
+	payload := map[string]interface{}{
+		"id": m.ID,
+	}
+	if m.IsReply() {
+		fcmPayload.Tag = "t:" + m.ThreadID.UUID.String()
+		payload["thread_id"] = m.ThreadID.UUID
+	}
 	ssePayload := &sse.EventData{
 		EventType: "MESSAGE_CREATED",
-		Payload: map[string]interface{}{
-			"id": m.ID,
-		},
+		Payload:   payload,
 	}
 
 	viewers := set.UUIDSet{}       // バックグラウンドを含む対象チャンネル閲覧中のユーザー
@@ -125,7 +139,57 @@ func messageCreatedHandler(ns *Service, ev hub.Message) {
 
 	// 対象者計算
 	q := repository.UsersQuery{}.Active().NotBot()
+	addMentionedUsers := func() bool {
+		for _, v := range embedded {
+			switch v.Type {
+			case "user":
+				if uid, err := uuid.FromString(v.ID); err == nil {
+					// TODO 凍結ユーザーの除外
+					// MEMO 凍結ユーザーはクライアント側で置換されないのでこのままでも問題はない
+					notifiedUsers.Add(uid)
+					markedUsers.Add(uid)
+					noticeable.Add(uid)
+				}
+			case "group":
+				gs, err := ns.repo.GetUserIDs(q.GMemberOf(uuid.FromStringOrNil(v.ID)))
+				if err != nil {
+					logger.Error("failed to GetUserGroupMemberIDs", zap.Error(err), zap.String("groupId", v.ID)) // 失敗
+					return false
+				}
+				notifiedUsers.Add(gs...)
+				markedUsers.Add(gs...)
+				noticeable.Add(gs...)
+			}
+		}
+		return true
+	}
 	switch {
+	case m.IsReply(): // スレッドへの返信
+		// スレッド参加者のみに通知する
+		participants, err := ns.repo.GetThreadParticipantIDs(m.ThreadID.UUID)
+		if err != nil {
+			logger.Error("failed to GetThreadParticipantIDs", zap.Error(err), zap.Stringer("threadId", m.ThreadID.UUID)) // 失敗
+			return
+		}
+		for _, id := range participants {
+			u, err := ns.repo.GetUser(id, false)
+			if err != nil {
+				logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("userId", id)) // 失敗
+				return
+			}
+			if u.IsActive() && !u.IsBot() {
+				notifiedUsers.Add(id)
+				markedUsers.Add(id)
+			}
+		}
+
+		// プライベートチャンネル・DMではメンションによる通知はメンバーのみ
+		if ch.IsPublic {
+			if !addMentionedUsers() {
+				return
+			}
+		}
+
 	case ch.IsForced: // 強制通知チャンネル
 		users, err := ns.repo.GetUserIDs(q)
 		if err != nil {
@@ -163,39 +227,27 @@ func messageCreatedHandler(ns *Service, ev hub.Message) {
 		markedUsers.Add(mark...)
 
 		// ユーザーグループ・メンションユーザー取得
-		for _, v := range embedded {
-			switch v.Type {
-			case "user":
-				if uid, err := uuid.FromString(v.ID); err == nil {
-					// TODO 凍結ユーザーの除外
-					// MEMO 凍結ユーザーはクライアント側で置換されないのでこのままでも問題はない
-					notifiedUsers.Add(uid)
-					markedUsers.Add(uid)
-					noticeable.Add(uid)
-				}
-			case "group":
-				gs, err := ns.repo.GetUserIDs(q.GMemberOf(uuid.FromStringOrNil(v.ID)))
-				if err != nil {
-					logger.Error("failed to GetUserGroupMemberIDs", zap.Error(err), zap.String("groupId", v.ID)) // 失敗
-					return
-				}
-				notifiedUsers.Add(gs...)
-				markedUsers.Add(gs...)
-				noticeable.Add(gs...)
-			}
+		if !addMentionedUsers() {
+			return
 		}
 	}
 
 	// チャンネル閲覧者取得
 	for uid, swt := range ns.realtime.ViewerManager.GetChannelViewers(m.ChannelID) {
 		viewers.Add(uid)
-		if swt.State > viewer.StateNone {
-			markedUsers.Remove(uid) // 閲覧中ユーザーは未読管理から外す
+		if swt.State > viewer.StateNone && !m.IsReply() {
+			markedUsers.Remove(uid) // 閲覧中ユーザーは未読管理から外す (スレッドはチャンネルとは別に未読管理する)
 		}
 	}
 
+	// 他インスタンスで発生したイベントの場合、未読追加とFCM送信はそのインスタンスで行われる
+	remote := event.IsRemote(ev)
+
 	// 未読追加
 	markedUsers.Remove(m.UserID)
+	if remote {
+		markedUsers = set.UUIDSet{}
+	}
 	for id := range markedUsers {
 		err := ns.repo.SetMessageUnread(id, m.ID, noticeable.Contains(id))
 		if err != nil {
@@ -209,10 +261,10 @@ func messageCreatedHandler(ns *Service, ev hub.Message) {
 	}
 
 	// WS送信
-	go ns.ws.WriteMessage(ssePayload.EventType, ssePayload.Payload, ws.TargetUserSets(notifiedUsers, viewers))
+	go ns.ws.WriteMessage(ssePayload.EventType, ssePayload.Payload, ws.TargetAny(ws.TargetUserSets(notifiedUsers, viewers), ws.TargetChannelSubscribers(m.ChannelID)))
 
 	// FCM送信
-	if ns.fcm != nil {
+	if ns.fcm != nil && !remote {
 		notifiedUsers.Remove(m.UserID)
 		ns.fcm.Send(notifiedUsers, fcmPayload)
 	}
@@ -236,6 +288,15 @@ func messageDeletedHandler(ns *Service, ev hub.Message) {
 	})
 }
 
+func messageRestoredHandler(ns *Service, ev hub.Message) {
+	channelViewerMulticast(ns, ev.Fields["message"].(*model.Message).ChannelID, &sse.EventData{
+		EventType: "MESSAGE_RESTORED",
+		Payload: map[string]interface{}{
+			"id": ev.Fields["message_id"].(uuid.UUID),
+		},
+	})
+}
+
 func messagePinnedHandler(ns *Service, ev hub.Message) {
 	channelViewerMulticast(ns, ev.Fields["channel_id"].(uuid.UUID), &sse.EventData{
 		EventType: "MESSAGE_PINNED",
@@ -309,6 +370,26 @@ func channelDeletedHandler(ns *Service, ev hub.Message) {
 	})
 }
 
+func channelMemberAddedHandler(ns *Service, ev hub.Message) {
+	// 追加されたユーザーにとっては新しいチャンネル
+	userMulticast(ns, ev.Fields["user_id"].(uuid.UUID), &sse.EventData{
+		EventType: "CHANNEL_CREATED",
+		Payload: map[string]interface{}{
+			"id": ev.Fields["channel_id"].(uuid.UUID),
+		},
+	})
+}
+
+func channelMemberRemovedHandler(ns *Service, ev hub.Message) {
+	// 削除されたユーザーにとってはチャンネルが見えなくなる
+	userMulticast(ns, ev.Fields["user_id"].(uuid.UUID), &sse.EventData{
+		EventType: "CHANNEL_DELETED",
+		Payload: map[string]interface{}{
+			"id": ev.Fields["channel_id"].(uuid.UUID),
+		},
+	})
+}
+
 func channelStaredHandler(ns *Service, ev hub.Message) {
 	userMulticast(ns, ev.Fields["user_id"].(uuid.UUID), &sse.EventData{
 		EventType: "CHANNEL_STARED",
@@ -336,6 +417,15 @@ func channelReadHandler(ns *Service, ev hub.Message) {
 	})
 }
 
+func threadReadHandler(ns *Service, ev hub.Message) {
+	userMulticast(ns, ev.Fields["user_id"].(uuid.UUID), &sse.EventData{
+		EventType: "THREAD_READ",
+		Payload: map[string]interface{}{
+			"id": ev.Fields["thread_id"].(uuid.UUID),
+		},
+	})
+}
+
 func channelViewersChangedHandler(ns *Service, ev hub.Message) {
 	cid := ev.Fields["channel_id"].(uuid.UUID)
 	channelViewerMulticast(ns, cid, &sse.EventData{
@@ -493,6 +583,18 @@ func userWebRTCStateChangedHandler(ns *Service, ev hub.Message) {
 	})
 }
 
+func userTypingHandler(ns *Service, ev hub.Message) {
+	cid := ev.Fields["channel_id"].(uuid.UUID)
+	channelViewerMulticast(ns, cid, &sse.EventData{
+		EventType: "USER_TYPING",
+		Payload: map[string]interface{}{
+			"user_id":    ev.Fields["user_id"].(uuid.UUID),
+			"channel_id": cid,
+			"typing":     ev.Fields["typing"].(bool),
+		},
+	})
+}
+
 func clipFolderCreatedHandler(ns *Service, ev hub.Message) {
 	userMulticast(ns, ev.Fields["user_id"].(uuid.UUID), &sse.EventData{
 		EventType: "CLIP_FOLDER_CREATED",
@@ -563,7 +665,7 @@ func channelViewerMulticast(ns *Service, cid uuid.UUID, ssePayload *sse.EventDat
 	for uid := range ns.realtime.ViewerManager.GetChannelViewers(cid) {
 		go ns.sse.Multicast(uid, ssePayload)
 	}
-	go ns.ws.WriteMessage(ssePayload.EventType, ssePayload.Payload, ws.TargetChannelViewers(cid))
+	go ns.ws.WriteMessage(ssePayload.EventType, ssePayload.Payload, ws.TargetAny(ws.TargetChannelViewers(cid), ws.TargetChannelSubscribers(cid)))
 }
 
 func messageViewerMulticast(ns *Service, mid uuid.UUID, ssePayload *sse.EventData) {
@@ -580,6 +682,32 @@ func broadcast(ns *Service, ssePayload *sse.EventData) {
 	go ns.ws.WriteMessage(ssePayload.EventType, ssePayload.Payload, ws.TargetAll())
 }
 
+func draftUpdatedHandler(ns *Service, ev hub.Message) {
+	// 下書きを更新したクライアント以外のWSセッションにのみ送信
+	go ns.ws.WriteMessage("DRAFT_UPDATED", map[string]interface{}{
+		"id": ev.Fields["channel_id"].(uuid.UUID),
+	}, ws.TargetUserExceptClient(ev.Fields["user_id"].(uuid.UUID), ev.Fields["client_key"].(string)))
+}
+
+func channelExportUpdatedHandler(ns *Service, ev hub.Message) {
+	userMulticast(ns, ev.Fields["user_id"].(uuid.UUID), &sse.EventData{
+		EventType: "CHANNEL_EXPORT_UPDATED",
+		Payload: map[string]interface{}{
+			"id": ev.Fields["export_id"].(uuid.UUID),
+		},
+	})
+}
+
+func botAutoPausedHandler(ns *Service, ev hub.Message) {
+	bot := ev.Fields["bot"].(*model.Bot)
+	userMulticast(ns, bot.CreatorID, &sse.EventData{
+		EventType: "BOT_PAUSED",
+		Payload: map[string]interface{}{
+			"id": bot.ID,
+		},
+	})
+}
+
 func userMulticast(ns *Service, userID uuid.UUID, ssePayload *sse.EventData) {
 	go ns.sse.Multicast(userID, ssePayload)
 	go ns.ws.WriteMessage(ssePayload.EventType, ssePayload.Payload, ws.TargetUsers(userID))
@@ -31,4 +31,19 @@ const (
 	BotActionJoinChannel = rbac.Permission("bot_action_join_channel")
 	// BotActionLeaveChannel BOTアクション実行権限：チャンネル退出
 	BotActionLeaveChannel = rbac.Permission("bot_action_leave_channel")
+	// BotConnectWS BOTイベント受信用WebSocketへの接続権限
+	BotConnectWS = rbac.Permission("bot_connect_ws")
 )
+
+// BotChannelScoped Botにチャンネル毎に許可できる権限
+var BotChannelScoped = rbac.Permissions{
+	GetMessage:         true,
+	PostMessage:        true,
+	EditMessage:        true,
+	DeleteMessage:      true,
+	CreateMessagePin:   true,
+	DeleteMessagePin:   true,
+	AddMessageStamp:    true,
+	RemoveMessageStamp: true,
+	EditChannelTopic:   true,
+}
@@ -21,4 +21,8 @@ const (
 	GetChannelStar = rbac.Permission("get_channel_star")
 	// EditChannelStar チャンネルスター編集権限
 	EditChannelStar = rbac.Permission("edit_channel_star")
+	// ManagePrivateChannelMember プライベートチャンネルメンバー管理権限
+	ManagePrivateChannelMember = rbac.Permission("manage_private_channel_member")
+	// ManageChannelBotPermission チャンネルでのBOT権限管理権限
+	ManageChannelBotPermission = rbac.Permission("manage_channel_bot_permission")
 )
@@ -17,6 +17,8 @@ const (
 	ReportMessage = rbac.Permission("report_message")
 	// GetMessageReports メッセージ通報取得権限
 	GetMessageReports = rbac.Permission("get_message_reports")
+	// ManageMessageTrash 削除されたメッセージの閲覧・復元権限
+	ManageMessageTrash = rbac.Permission("manage_message_trash")
 	// CreateMessagePin ピン留め作成権限
 	CreateMessagePin = rbac.Permission("create_message_pin")
 	// DeleteMessagePin ピン留め削除権限
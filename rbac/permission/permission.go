@@ -20,6 +20,7 @@ func init() {
 
 		BotActionJoinChannel,
 		BotActionLeaveChannel,
+		BotConnectWS,
 
 		CreateChannel,
 		GetChannel,
@@ -27,6 +28,8 @@ func init() {
 		DeleteChannel,
 		ChangeParentChannel,
 		EditChannelTopic,
+		ManagePrivateChannelMember,
+		ManageChannelBotPermission,
 
 		GetMyTokens,
 		RevokeMyToken,
@@ -49,6 +52,7 @@ func init() {
 		DeleteMessage,
 		ReportMessage,
 		GetMessageReports,
+		ManageMessageTrash,
 
 		GetChannelSubscription,
 		EditChannelSubscription,
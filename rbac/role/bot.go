@@ -38,4 +38,5 @@ var botPerms = []rbac.Permission{
 	permission.DeleteFile,
 	permission.BotActionJoinChannel,
 	permission.BotActionLeaveChannel,
+	permission.BotConnectWS,
 }
@@ -11,6 +11,8 @@ const Write = "write"
 var writePerms = []rbac.Permission{
 	permission.CreateChannel,
 	permission.EditChannelTopic,
+	permission.ManagePrivateChannelMember,
+	permission.ManageChannelBotPermission,
 	permission.PostMessage,
 	permission.EditMessage,
 	permission.DeleteMessage,
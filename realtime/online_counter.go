@@ -6,27 +6,43 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/utils/set"
 	"sync"
 	"time"
 )
 
+const (
+	// syncInterval 他インスタンスに全状態を送信する間隔
+	syncInterval = 30 * time.Second
+	// remoteStateTTL 他インスタンスの状態の有効期間. この間同期されなかったインスタンスの状態は破棄されます
+	remoteStateTTL = 3 * syncInterval
+)
+
 var onlineUsersCounter = promauto.NewGauge(prometheus.GaugeOpts{
 	Namespace: "traq",
 	Name:      "online_users",
 })
 
 // OnlineCounter オンラインユーザーカウンター
+//
+// 自インスタンスの接続数を数え、他インスタンスのオンラインユーザーはevent.OnlineUsersSyncedで同期します
 type OnlineCounter struct {
 	hub          *hub.Hub
 	counters     map[uuid.UUID]*counter
 	countersLock sync.Mutex
+	remote       map[string]set.UUIDSet
+	remoteSeen   map[string]time.Time
+	remoteLock   sync.RWMutex
 }
 
 func newOnlineCounter(hub *hub.Hub) *OnlineCounter {
 	oc := &OnlineCounter{
-		hub:      hub,
-		counters: map[uuid.UUID]*counter{},
+		hub:        hub,
+		counters:   map[uuid.UUID]*counter{},
+		remote:     map[string]set.UUIDSet{},
+		remoteSeen: map[string]time.Time{},
 	}
+	go oc.syncLoop(hub.Subscribe(100, event.InstanceSyncRequested, event.InstanceStopped, event.OnlineUsersSynced))
 	return oc
 }
 
@@ -42,9 +58,13 @@ func (oc *OnlineCounter) Inc(userID uuid.UUID) (toOnline bool) {
 	}
 	oc.countersLock.Unlock()
 
-	toOnline = c.inc()
-	if toOnline {
+	if c.inc() {
 		onlineUsersCounter.Inc()
+		oc.publishSync(map[uuid.UUID]bool{userID: true}, false)
+		// 他インスタンスで既にオンラインの場合は通知しない
+		toOnline = !oc.isRemoteOnline(userID)
+	}
+	if toOnline {
 		oc.hub.Publish(hub.Message{
 			Name: event.UserOnline,
 			Fields: hub.Fields{
@@ -66,9 +86,13 @@ func (oc *OnlineCounter) Dec(userID uuid.UUID) (toOffline bool) {
 	}
 	oc.countersLock.Unlock()
 
-	toOffline = c.dec()
-	if toOffline {
+	if c.dec() {
 		onlineUsersCounter.Dec()
+		oc.publishSync(map[uuid.UUID]bool{userID: false}, false)
+		// 他インスタンスでまだオンラインの場合は通知しない
+		toOffline = !oc.isRemoteOnline(userID)
+	}
+	if toOffline {
 		oc.hub.Publish(hub.Message{
 			Name: event.UserOffline,
 			Fields: hub.Fields{
@@ -82,19 +106,29 @@ func (oc *OnlineCounter) Dec(userID uuid.UUID) (toOffline bool) {
 
 // IsOnline 指定したユーザーがオンラインかどうかを取得します
 func (oc *OnlineCounter) IsOnline(userID uuid.UUID) bool {
+	return oc.isLocalOnline(userID) || oc.isRemoteOnline(userID)
+}
+
+// GetOnlineUserIDs オンラインなユーザーのUUIDの配列を取得します
+func (oc *OnlineCounter) GetOnlineUserIDs() []uuid.UUID {
+	users := set.UUIDSet{}
+	users.Add(oc.getLocalOnlineUserIDs()...)
+	oc.remoteLock.RLock()
+	for _, s := range oc.remote {
+		users.Plus(s)
+	}
+	oc.remoteLock.RUnlock()
+	return users.Array()
+}
+
+func (oc *OnlineCounter) isLocalOnline(userID uuid.UUID) bool {
 	oc.countersLock.Lock()
 	c, ok := oc.counters[userID]
-	if !ok {
-		oc.countersLock.Unlock()
-		return false
-	}
 	oc.countersLock.Unlock()
-
-	return c.isOnline()
+	return ok && c.isOnline()
 }
 
-// GetOnlineUserIDs オンラインなユーザーのUUIDの配列を取得します
-func (oc *OnlineCounter) GetOnlineUserIDs() []uuid.UUID {
+func (oc *OnlineCounter) getLocalOnlineUserIDs() []uuid.UUID {
 	oc.countersLock.Lock()
 	users := make([]uuid.UUID, 0, len(oc.counters))
 	for u, c := range oc.counters {
@@ -106,6 +140,114 @@ func (oc *OnlineCounter) GetOnlineUserIDs() []uuid.UUID {
 	return users
 }
 
+func (oc *OnlineCounter) isRemoteOnline(userID uuid.UUID) bool {
+	oc.remoteLock.RLock()
+	defer oc.remoteLock.RUnlock()
+	for _, s := range oc.remote {
+		if s.Contains(userID) {
+			return true
+		}
+	}
+	return false
+}
+
+// publishSync 自インスタンスのオンライン状態を他インスタンスに同期します
+func (oc *OnlineCounter) publishSync(users map[uuid.UUID]bool, full bool) {
+	oc.hub.Publish(hub.Message{
+		Name: event.OnlineUsersSynced,
+		Fields: hub.Fields{
+			"users": users,
+			"full":  full,
+		},
+	})
+}
+
+func (oc *OnlineCounter) publishFullSync() {
+	users := map[uuid.UUID]bool{}
+	for _, id := range oc.getLocalOnlineUserIDs() {
+		users[id] = true
+	}
+	oc.publishSync(users, true)
+}
+
+func (oc *OnlineCounter) syncLoop(sub hub.Subscription) {
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case ev := <-sub.Receiver:
+			origin := event.Origin(ev)
+			if len(origin) == 0 {
+				continue
+			}
+			switch ev.Topic() {
+			case event.InstanceSyncRequested:
+				oc.publishFullSync()
+			case event.InstanceStopped:
+				oc.removeRemote(origin)
+			case event.OnlineUsersSynced:
+				oc.applyRemote(origin, ev.Fields["users"].(map[uuid.UUID]bool), ev.Fields["full"].(bool))
+			}
+		case <-ticker.C:
+			oc.publishFullSync()
+			oc.remoteLock.RLock()
+			expired := make([]string, 0)
+			for origin, seen := range oc.remoteSeen {
+				if time.Since(seen) > remoteStateTTL {
+					expired = append(expired, origin)
+				}
+			}
+			oc.remoteLock.RUnlock()
+			for _, origin := range expired {
+				oc.removeRemote(origin)
+			}
+		}
+	}
+}
+
+func (oc *OnlineCounter) applyRemote(origin string, users map[uuid.UUID]bool, full bool) {
+	oc.remoteLock.Lock()
+	defer oc.remoteLock.Unlock()
+	s, ok := oc.remote[origin]
+	if !ok || full {
+		s = set.UUIDSet{}
+		oc.remote[origin] = s
+	}
+	for id, online := range users {
+		if online {
+			s.Add(id)
+		} else {
+			s.Remove(id)
+		}
+	}
+	oc.remoteSeen[origin] = time.Now()
+}
+
+// removeRemote 指定したインスタンスの状態を破棄します
+func (oc *OnlineCounter) removeRemote(origin string) {
+	oc.remoteLock.Lock()
+	s := oc.remote[origin]
+	delete(oc.remote, origin)
+	delete(oc.remoteSeen, origin)
+	oc.remoteLock.Unlock()
+
+	now := time.Now()
+	for id := range s {
+		if oc.IsOnline(id) {
+			continue
+		}
+		// 停止したインスタンスに代わって通知する. 転送元を停止したインスタンスにすることで、他インスタンスには転送されない
+		oc.hub.Publish(hub.Message{
+			Name: event.UserOffline,
+			Fields: hub.Fields{
+				"user_id":         id,
+				"datetime":        now,
+				event.FieldOrigin: origin,
+			},
+		})
+	}
+}
+
 type counter struct {
 	sync.RWMutex
 	userID      uuid.UUID
@@ -14,6 +14,7 @@ type Service struct {
 	ViewerManager *viewer.Manager
 	HeartBeats    *HeartBeats
 	WebRTC        *webrtc.Manager
+	Typing        *TypingManager
 }
 
 // NewService realtime.Serviceを生成・起動します
@@ -22,6 +23,7 @@ func NewService(hub *hub.Hub) *Service {
 	vm := viewer.NewManager(hub)
 	hb := newHeartBeats(vm)
 	wr := webrtc.NewManager(hub)
+	tm := newTypingManager(hub)
 
 	go func() {
 		for e := range hub.Subscribe(8, event.SSEConnected, event.SSEDisconnected, event.WSConnected, event.WSDisconnected).Receiver {
@@ -39,5 +41,6 @@ func NewService(hub *hub.Hub) *Service {
 		ViewerManager: vm,
 		HeartBeats:    hb,
 		WebRTC:        wr,
+		Typing:        tm,
 	}
 }
@@ -0,0 +1,115 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/event/bridge"
+	"github.com/traPtitech/traQ/realtime/viewer"
+	"github.com/traPtitech/traQ/utils/set"
+	"go.uber.org/zap"
+)
+
+// setupCluster ループバックで接続した2インスタンス分のリアルタイムサービスを生成します
+func setupCluster() (a *Service, hubB *hub.Hub, b *Service, closer func()) {
+	network := bridge.NewLoopbackNetwork()
+	hubA := hub.New()
+	hubB = hub.New()
+	a = NewService(hubA)
+	b = NewService(hubB)
+	ba := bridge.New(hubA, network.NewTransport(), zap.NewNop())
+	bb := bridge.New(hubB, network.NewTransport(), zap.NewNop())
+	closer = func() {
+		_ = ba.Close()
+		_ = bb.Close()
+	}
+	return
+}
+
+func TestService_Cluster(t *testing.T) {
+	t.Parallel()
+
+	t.Run("online", func(t *testing.T) {
+		t.Parallel()
+		a, hubB, b, closer := setupCluster()
+		defer closer()
+		onlineB := hubB.Subscribe(10, event.UserOnline, event.UserOffline)
+		user := uuid.Must(uuid.NewV4())
+
+		assert.True(t, a.OnlineCounter.Inc(user))
+		require.Eventually(t, func() bool { return b.OnlineCounter.IsOnline(user) }, 3*time.Second, 10*time.Millisecond)
+		assert.Contains(t, b.OnlineCounter.GetOnlineUserIDs(), user)
+
+		// 他インスタンスで発生したオンライン通知が届く
+		ev := <-onlineB.Receiver
+		assert.Equal(t, event.UserOnline, ev.Topic())
+		assert.True(t, event.IsRemote(ev))
+
+		// 既に他インスタンスでオンラインなので通知しない
+		assert.False(t, b.OnlineCounter.Inc(user))
+		require.Eventually(t, func() bool { return a.OnlineCounter.isRemoteOnline(user) }, 3*time.Second, 10*time.Millisecond)
+		assert.False(t, a.OnlineCounter.Dec(user))
+		assert.True(t, a.OnlineCounter.IsOnline(user))
+
+		// 全インスタンスでオフラインになったら通知する
+		require.Eventually(t, func() bool { return !b.OnlineCounter.isRemoteOnline(user) }, 3*time.Second, 10*time.Millisecond)
+		assert.True(t, b.OnlineCounter.Dec(user))
+		require.Eventually(t, func() bool { return !a.OnlineCounter.IsOnline(user) }, 3*time.Second, 10*time.Millisecond)
+
+		// インスタンスが停止したらそのインスタンスの状態は破棄される
+		assert.True(t, a.OnlineCounter.Inc(user))
+		require.Eventually(t, func() bool { return b.OnlineCounter.IsOnline(user) }, 3*time.Second, 10*time.Millisecond)
+		hubB.Publish(hub.Message{
+			Name:   event.InstanceStopped,
+			Fields: hub.Fields{event.FieldOrigin: event.Origin(ev)},
+		})
+		require.Eventually(t, func() bool { return !b.OnlineCounter.IsOnline(user) }, 3*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("viewers", func(t *testing.T) {
+		t.Parallel()
+		a, hubB, b, closer := setupCluster()
+		defer closer()
+		changedB := hubB.Subscribe(10, event.ChannelViewersChanged)
+		user1 := uuid.Must(uuid.NewV4())
+		user2 := uuid.Must(uuid.NewV4())
+		channel := uuid.Must(uuid.NewV4())
+
+		a.ViewerManager.SetViewer("a", user1, channel, viewer.StateMonitoring)
+		require.Eventually(t, func() bool { return len(b.ViewerManager.GetChannelViewers(channel)) == 1 }, 3*time.Second, 10*time.Millisecond)
+		ev := <-changedB.Receiver
+		assert.True(t, event.IsRemote(ev))
+		assert.Contains(t, ev.Fields["viewers"], user1)
+
+		b.ViewerManager.SetViewer("b", user2, channel, viewer.StateEditing)
+		require.Eventually(t, func() bool { return len(a.ViewerManager.GetChannelViewers(channel)) == 2 }, 3*time.Second, 10*time.Millisecond)
+		viewers := b.ViewerManager.GetChannelViewers(channel)
+		assert.Equal(t, viewer.StateMonitoring, viewers[user1].State)
+		assert.Equal(t, viewer.StateEditing, viewers[user2].State)
+
+		a.ViewerManager.RemoveViewer("a")
+		require.Eventually(t, func() bool { return len(b.ViewerManager.GetChannelViewers(channel)) == 1 }, 3*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("webrtc", func(t *testing.T) {
+		t.Parallel()
+		a, _, b, closer := setupCluster()
+		defer closer()
+		user := uuid.Must(uuid.NewV4())
+		channel := uuid.Must(uuid.NewV4())
+
+		require.NoError(t, a.WebRTC.SetState(user, channel, set.StringSetFromArray([]string{"joined"})))
+		require.Eventually(t, func() bool { return b.WebRTC.GetUserState(user).ChannelID == channel }, 3*time.Second, 10*time.Millisecond)
+		assert.True(t, b.WebRTC.GetUserState(user).State.Contains("joined"))
+		assert.Contains(t, b.WebRTC.GetChannelState(channel).Users, user)
+
+		require.NoError(t, b.WebRTC.RemoveState(user))
+		require.Eventually(t, func() bool { return a.WebRTC.GetUserState(user).ChannelID == uuid.Nil }, 3*time.Second, 10*time.Millisecond)
+		assert.Empty(t, a.WebRTC.GetChannelState(channel).Users)
+	})
+}
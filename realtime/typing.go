@@ -0,0 +1,113 @@
+package realtime
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+)
+
+// typingTimeout 最後の入力通知からこの時間が経つと入力を止めたとみなす
+const typingTimeout = 5 * time.Second
+
+// TypingManager メッセージ入力状態マネージャー
+//
+// 入力中の通知を受け続けている間はイベントを発行せず、入力の開始時と終了時にのみevent.UserTypingを発行します
+type TypingManager struct {
+	hub     *hub.Hub
+	typings map[typingKey]time.Time
+	mu      sync.Mutex
+}
+
+type typingKey struct {
+	userID    uuid.UUID
+	channelID uuid.UUID
+}
+
+func newTypingManager(hub *hub.Hub) *TypingManager {
+	tm := &TypingManager{
+		hub:     hub,
+		typings: map[typingKey]time.Time{},
+	}
+	go func() {
+		t := time.NewTicker(tickTime)
+		for range t.C {
+			tm.onTick()
+		}
+	}()
+	// 他インスタンスで投稿された場合も入力を終了させる
+	sub := hub.Subscribe(100, event.MessageCreated)
+	go func() {
+		for e := range sub.Receiver {
+			m := e.Fields["message"].(*model.Message)
+			tm.Stop(m.UserID, m.ChannelID)
+		}
+	}()
+	return tm
+}
+
+func (tm *TypingManager) onTick() {
+	tm.mu.Lock()
+	timeout := time.Now().Add(-1 * typingTimeout)
+	expired := make([]typingKey, 0)
+	for k, t := range tm.typings {
+		// 最終通知から指定時間以上経ったものを削除する
+		if !timeout.Before(t) {
+			expired = append(expired, k)
+			delete(tm.typings, k)
+		}
+	}
+	tm.mu.Unlock()
+
+	for _, k := range expired {
+		tm.publish(k, false)
+	}
+}
+
+// Typing 指定したユーザーが指定したチャンネルでメッセージを入力中であることを通知します
+func (tm *TypingManager) Typing(userID, channelID uuid.UUID) {
+	k := typingKey{userID: userID, channelID: channelID}
+	tm.mu.Lock()
+	_, ok := tm.typings[k]
+	tm.typings[k] = time.Now()
+	tm.mu.Unlock()
+
+	if !ok {
+		tm.publish(k, true)
+	}
+}
+
+// Stop 指定したユーザーの指定したチャンネルでのメッセージ入力状態を終了させます
+func (tm *TypingManager) Stop(userID, channelID uuid.UUID) {
+	k := typingKey{userID: userID, channelID: channelID}
+	tm.mu.Lock()
+	_, ok := tm.typings[k]
+	delete(tm.typings, k)
+	tm.mu.Unlock()
+
+	if ok {
+		tm.publish(k, false)
+	}
+}
+
+// IsTyping 指定したユーザーが指定したチャンネルでメッセージを入力中かどうか
+func (tm *TypingManager) IsTyping(userID, channelID uuid.UUID) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	_, ok := tm.typings[typingKey{userID: userID, channelID: channelID}]
+	return ok
+}
+
+func (tm *TypingManager) publish(k typingKey, typing bool) {
+	tm.hub.Publish(hub.Message{
+		Name: event.UserTyping,
+		Fields: hub.Fields{
+			"user_id":    k.userID,
+			"channel_id": k.channelID,
+			"typing":     typing,
+		},
+	})
+}
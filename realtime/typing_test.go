@@ -0,0 +1,65 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+)
+
+func receiveTyping(t *testing.T, sub hub.Subscription) bool {
+	t.Helper()
+	select {
+	case ev := <-sub.Receiver:
+		return ev.Fields["typing"].(bool)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout")
+		return false
+	}
+}
+
+func TestTypingManager(t *testing.T) {
+	t.Parallel()
+
+	h := hub.New()
+	sub := h.Subscribe(10, event.UserTyping)
+	tm := newTypingManager(h)
+	user := uuid.Must(uuid.NewV4())
+	channel := uuid.Must(uuid.NewV4())
+
+	// 入力開始時のみ通知する
+	tm.Typing(user, channel)
+	assert.True(t, receiveTyping(t, sub))
+	tm.Typing(user, channel)
+	assert.True(t, tm.IsTyping(user, channel))
+	assert.Empty(t, sub.Receiver)
+
+	// 投稿したら入力終了
+	h.Publish(hub.Message{
+		Name: event.MessageCreated,
+		Fields: hub.Fields{
+			"message": &model.Message{UserID: user, ChannelID: channel},
+		},
+	})
+	assert.False(t, receiveTyping(t, sub))
+	assert.False(t, tm.IsTyping(user, channel))
+
+	// 一定時間通知がなければ入力終了
+	tm.Typing(user, channel)
+	assert.True(t, receiveTyping(t, sub))
+	tm.mu.Lock()
+	tm.typings[typingKey{userID: user, channelID: channel}] = time.Now().Add(-typingTimeout)
+	tm.mu.Unlock()
+	tm.onTick()
+	assert.False(t, receiveTyping(t, sub))
+	require.False(t, tm.IsTyping(user, channel))
+
+	// 入力していないユーザーの終了は通知しない
+	tm.Stop(user, channel)
+	assert.Empty(t, sub.Receiver)
+}
@@ -8,12 +8,23 @@ import (
 	"time"
 )
 
+const (
+	// syncInterval 他インスタンスに全状態を送信する間隔
+	syncInterval = 30 * time.Second
+	// remoteStateTTL 他インスタンスの状態の有効期間. この間同期されなかったインスタンスの状態は破棄されます
+	remoteStateTTL = 3 * syncInterval
+)
+
 // Manager チャンネル閲覧者マネージャ
+//
+// 他インスタンスのチャンネル閲覧者はevent.ChannelViewersSyncedで同期します
 type Manager struct {
-	hub      *hub.Hub
-	channels map[uuid.UUID]map[*viewer]struct{}
-	viewers  map[interface{}]*viewer
-	mu       sync.RWMutex
+	hub        *hub.Hub
+	channels   map[uuid.UUID]map[*viewer]struct{}
+	viewers    map[interface{}]*viewer
+	remote     map[string]map[uuid.UUID]map[uuid.UUID]StateWithTime
+	remoteSeen map[string]time.Time
+	mu         sync.RWMutex
 }
 
 type viewer struct {
@@ -26,9 +37,11 @@ type viewer struct {
 // NewManager チャンネル閲覧者マネージャーを生成します
 func NewManager(hub *hub.Hub) *Manager {
 	vm := &Manager{
-		hub:      hub,
-		channels: map[uuid.UUID]map[*viewer]struct{}{},
-		viewers:  map[interface{}]*viewer{},
+		hub:        hub,
+		channels:   map[uuid.UUID]map[*viewer]struct{}{},
+		viewers:    map[interface{}]*viewer{},
+		remote:     map[string]map[uuid.UUID]map[uuid.UUID]StateWithTime{},
+		remoteSeen: map[string]time.Time{},
 	}
 
 	go func() {
@@ -38,6 +51,8 @@ func NewManager(hub *hub.Hub) *Manager {
 			vm.mu.Unlock()
 		}
 	}()
+	// vm.muをロックしたままイベントを発行するため、ブロックしない購読にする
+	go vm.syncLoop(hub.NonBlockingSubscribe(1000, event.InstanceSyncRequested, event.InstanceStopped, event.ChannelViewersSynced))
 	return vm
 }
 
@@ -45,7 +60,7 @@ func NewManager(hub *hub.Hub) *Manager {
 func (vm *Manager) GetChannelViewers(channelID uuid.UUID) map[uuid.UUID]StateWithTime {
 	vm.mu.RLock()
 	defer vm.mu.RUnlock()
-	return calculateChannelViewers(vm.channels[channelID])
+	return vm.mergedChannelViewers(channelID)
 }
 
 // SetViewer 指定したキーのチャンネル閲覧者状態を設定します
@@ -80,13 +95,7 @@ func (vm *Manager) SetViewer(key interface{}, userID uuid.UUID, channelID uuid.U
 				Time:  time.Now(),
 			}
 
-			vm.hub.Publish(hub.Message{
-				Name: event.ChannelViewersChanged,
-				Fields: hub.Fields{
-					"channel_id": oldC,
-					"viewers":    calculateChannelViewers(old),
-				},
-			})
+			vm.publishChanged(oldC)
 		}
 	} else {
 		v = &viewer{
@@ -102,13 +111,7 @@ func (vm *Manager) SetViewer(key interface{}, userID uuid.UUID, channelID uuid.U
 	}
 
 	cv[v] = struct{}{}
-	vm.hub.Publish(hub.Message{
-		Name: event.ChannelViewersChanged,
-		Fields: hub.Fields{
-			"channel_id": channelID,
-			"viewers":    calculateChannelViewers(cv),
-		},
-	})
+	vm.publishChanged(channelID)
 }
 
 // RemoveViewer 指定したキーのチャンネル閲覧者状態を削除します
@@ -125,22 +128,143 @@ func (vm *Manager) RemoveViewer(key interface{}) {
 	delete(vm.viewers, key)
 	delete(cv, v)
 
+	vm.publishChanged(v.channelID)
+}
+
+// 5分に１回呼び出される。チャンネルマップのお掃除
+func (vm *Manager) gc() {
+	for cid, cv := range vm.channels {
+		if len(cv) == 0 {
+			delete(vm.channels, cid)
+		}
+	}
+}
+
+// publishChanged 指定したチャンネルの閲覧者の変化を通知し、他インスタンスに同期します. vm.muをロックした状態で呼び出してください
+func (vm *Manager) publishChanged(channelID uuid.UUID) {
+	vm.hub.Publish(hub.Message{
+		Name: event.ChannelViewersSynced,
+		Fields: hub.Fields{
+			"channels": map[uuid.UUID]map[uuid.UUID]StateWithTime{
+				channelID: calculateChannelViewers(vm.channels[channelID]),
+			},
+			"full": false,
+		},
+	})
 	vm.hub.Publish(hub.Message{
 		Name: event.ChannelViewersChanged,
 		Fields: hub.Fields{
-			"channel_id": v.channelID,
-			"viewers":    calculateChannelViewers(cv),
+			"channel_id": channelID,
+			"viewers":    vm.mergedChannelViewers(channelID),
 		},
 	})
 }
 
-// 5分に１回呼び出される。チャンネルマップのお掃除
-func (vm *Manager) gc() {
+// mergedChannelViewers 全インスタンスの指定したチャンネルの閲覧者状態を返します. vm.muをロックした状態で呼び出してください
+func (vm *Manager) mergedChannelViewers(channelID uuid.UUID) map[uuid.UUID]StateWithTime {
+	result := calculateChannelViewers(vm.channels[channelID])
+	for _, channels := range vm.remote {
+		for uid, s := range channels[channelID] {
+			if r, ok := result[uid]; ok && r.State > s.State {
+				continue
+			}
+			result[uid] = s
+		}
+	}
+	return result
+}
+
+func (vm *Manager) publishFullSync() {
+	vm.mu.RLock()
+	channels := make(map[uuid.UUID]map[uuid.UUID]StateWithTime, len(vm.channels))
 	for cid, cv := range vm.channels {
-		if len(cv) == 0 {
-			delete(vm.channels, cid)
+		if len(cv) > 0 {
+			channels[cid] = calculateChannelViewers(cv)
+		}
+	}
+	vm.mu.RUnlock()
+
+	vm.hub.Publish(hub.Message{
+		Name: event.ChannelViewersSynced,
+		Fields: hub.Fields{
+			"channels": channels,
+			"full":     true,
+		},
+	})
+}
+
+func (vm *Manager) syncLoop(sub hub.Subscription) {
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case ev := <-sub.Receiver:
+			origin := event.Origin(ev)
+			if len(origin) == 0 {
+				continue
+			}
+			switch ev.Topic() {
+			case event.InstanceSyncRequested:
+				vm.publishFullSync()
+			case event.InstanceStopped:
+				vm.removeRemote(origin)
+			case event.ChannelViewersSynced:
+				vm.applyRemote(origin, ev.Fields["channels"].(map[uuid.UUID]map[uuid.UUID]StateWithTime), ev.Fields["full"].(bool))
+			}
+		case <-ticker.C:
+			vm.publishFullSync()
+			vm.mu.RLock()
+			expired := make([]string, 0)
+			for origin, seen := range vm.remoteSeen {
+				if time.Since(seen) > remoteStateTTL {
+					expired = append(expired, origin)
+				}
+			}
+			vm.mu.RUnlock()
+			for _, origin := range expired {
+				vm.removeRemote(origin)
+			}
+		}
+	}
+}
+
+func (vm *Manager) applyRemote(origin string, channels map[uuid.UUID]map[uuid.UUID]StateWithTime, full bool) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	rc, ok := vm.remote[origin]
+	if !ok || full {
+		rc = map[uuid.UUID]map[uuid.UUID]StateWithTime{}
+		vm.remote[origin] = rc
+	}
+	for cid, viewers := range channels {
+		if len(viewers) == 0 {
+			delete(rc, cid)
+		} else {
+			rc[cid] = viewers
 		}
 	}
+	vm.remoteSeen[origin] = time.Now()
+}
+
+// removeRemote 指定したインスタンスの状態を破棄します
+func (vm *Manager) removeRemote(origin string) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	rc := vm.remote[origin]
+	delete(vm.remote, origin)
+	delete(vm.remoteSeen, origin)
+
+	for cid := range rc {
+		// 停止したインスタンスに代わって通知する. 転送元を停止したインスタンスにすることで、他インスタンスには転送されない
+		vm.hub.Publish(hub.Message{
+			Name: event.ChannelViewersChanged,
+			Fields: hub.Fields{
+				"channel_id":      cid,
+				"viewers":         vm.mergedChannelViewers(cid),
+				event.FieldOrigin: origin,
+			},
+		})
+	}
 }
 
 func calculateChannelViewers(vs map[*viewer]struct{}) map[uuid.UUID]StateWithTime {
@@ -11,6 +11,8 @@ import (
 )
 
 // Manager WebRTCマネージャー
+//
+// 状態は全インスタンスで複製され、他インスタンスでの変更はevent.UserWebRTCStateChangedで反映されます
 type Manager struct {
 	eventbus      *hub.Hub
 	userStates    map[uuid.UUID]*UserState
@@ -31,6 +33,8 @@ func NewManager(eventbus *hub.Hub) *Manager {
 			manager.sweep()
 		}
 	}()
+	// statesLockをロックしたままイベントを発行するため、ブロックしない購読にする
+	go manager.syncLoop(eventbus.NonBlockingSubscribe(1000, event.InstanceSyncRequested, event.WebRTCStatesSynced, event.UserWebRTCStateChanged))
 	return manager
 }
 
@@ -85,6 +89,13 @@ func (m *Manager) setState(user, channel uuid.UUID, state set.StringSet) error {
 	m.statesLock.Lock()
 	defer m.statesLock.Unlock()
 
+	us := m.applyState(user, channel, state)
+	m.publishChanged(us)
+	return nil
+}
+
+// applyState 指定した状態を反映します. statesLockをロックした状態で呼び出してください
+func (m *Manager) applyState(user, channel uuid.UUID, state set.StringSet) *UserState {
 	us, ok := m.userStates[user]
 	if !ok {
 		us = &UserState{
@@ -109,16 +120,7 @@ func (m *Manager) setState(user, channel uuid.UUID, state set.StringSet) error {
 	us.State = state
 	us.ChannelID = channel
 	cs.setUser(us)
-
-	m.eventbus.Publish(hub.Message{
-		Name: event.UserWebRTCStateChanged,
-		Fields: hub.Fields{
-			"user_id":    us.UserID,
-			"channel_id": us.ChannelID,
-			"state":      us.State,
-		},
-	})
-	return nil
+	return us
 }
 
 // RemoveState 指定したユーザーの状態を削除します
@@ -126,6 +128,16 @@ func (m *Manager) RemoveState(user uuid.UUID) error {
 	m.statesLock.Lock()
 	defer m.statesLock.Unlock()
 
+	us := m.removeState(user)
+	if us == nil {
+		return nil
+	}
+	m.publishChanged(us)
+	return nil
+}
+
+// removeState 指定したユーザーの状態を削除します. statesLockをロックした状態で呼び出してください
+func (m *Manager) removeState(user uuid.UUID) *UserState {
 	us, ok := m.userStates[user]
 	if !ok {
 		return nil
@@ -137,6 +149,10 @@ func (m *Manager) RemoveState(user uuid.UUID) error {
 
 	us.ChannelID = uuid.Nil
 	us.State = set.StringSet{}
+	return us
+}
+
+func (m *Manager) publishChanged(us *UserState) {
 	m.eventbus.Publish(hub.Message{
 		Name: event.UserWebRTCStateChanged,
 		Fields: hub.Fields{
@@ -145,7 +161,51 @@ func (m *Manager) RemoveState(user uuid.UUID) error {
 			"state":      us.State,
 		},
 	})
-	return nil
+}
+
+func (m *Manager) syncLoop(sub hub.Subscription) {
+	for ev := range sub.Receiver {
+		if !event.IsRemote(ev) {
+			continue
+		}
+		switch ev.Topic() {
+		case event.InstanceSyncRequested:
+			m.statesLock.RLock()
+			states := make([]*UserState, 0, len(m.userStates))
+			for _, us := range m.userStates {
+				if us.valid() {
+					states = append(states, us.clone())
+				}
+			}
+			m.statesLock.RUnlock()
+			m.eventbus.Publish(hub.Message{
+				Name: event.WebRTCStatesSynced,
+				Fields: hub.Fields{
+					"states": states,
+				},
+			})
+		case event.WebRTCStatesSynced:
+			m.statesLock.Lock()
+			for _, us := range ev.Fields["states"].([]*UserState) {
+				// 既に知っているユーザーの状態の方が新しい
+				if _, ok := m.userStates[us.UserID]; !ok && us.valid() {
+					m.applyState(us.UserID, us.ChannelID, us.State)
+				}
+			}
+			m.statesLock.Unlock()
+		case event.UserWebRTCStateChanged:
+			user := ev.Fields["user_id"].(uuid.UUID)
+			channel := ev.Fields["channel_id"].(uuid.UUID)
+			state := ev.Fields["state"].(set.StringSet)
+			m.statesLock.Lock()
+			if channel == uuid.Nil || len(state) == 0 {
+				m.removeState(user)
+			} else {
+				m.applyState(user, channel, state)
+			}
+			m.statesLock.Unlock()
+		}
+	}
 }
 
 func (m *Manager) sweep() {
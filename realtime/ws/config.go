@@ -13,13 +13,21 @@ const (
 	pingPeriod         = (pongWait * 9) / 10
 	maxReadMessageSize = 1 << 9 // 512B
 	messageBufferSize  = 256
+	// maxSubscribingChannels 1セッションが購読できるチャンネルの最大数
+	maxSubscribingChannels = 100
+	// minCompressionSize permessage-deflateで圧縮するメッセージの最小サイズ. これより小さいメッセージは圧縮しても効果が薄い
+	minCompressionSize = 128
+	// subprotocolMessagePack メッセージをMessagePackで受け取るサブプロトコル. 指定しない場合はJSON
+	subprotocolMessagePack = "traq.msgpack"
 )
 
 var (
 	json     = jsoniter.ConfigFastest
 	upgrader = &websocket.Upgrader{
-		ReadBufferSize:  1024,
-		WriteBufferSize: 1024,
-		CheckOrigin:     func(r *http.Request) bool { return true },
+		ReadBufferSize:    1024,
+		WriteBufferSize:   1024,
+		CheckOrigin:       func(r *http.Request) bool { return true },
+		Subprotocols:      []string{subprotocolMessagePack},
+		EnableCompression: true,
 	}
 )
@@ -3,8 +3,9 @@ package ws
 import (
 	"fmt"
 	"github.com/gofrs/uuid"
-	"github.com/gorilla/websocket"
 	"github.com/traPtitech/traQ/realtime/viewer"
+	"go.uber.org/zap"
+	"strconv"
 	"strings"
 )
 
@@ -20,14 +21,13 @@ func (s *session) commandHandler(cmd string) {
 		}
 
 		if strings.ToLower(args[1]) == "null" {
+			s.resetViewState()
 			s.streamer.realtime.ViewerManager.RemoveViewer(s)
 			break
 		}
 
-		cid, err := uuid.FromString(args[1])
-		if err != nil {
-			// チャンネルIDが不正
-			s.sendErrorMessage(fmt.Sprintf("invalid id: %s", args[1]))
+		cid, ok := s.parseAccessibleChannelID(args[1])
+		if !ok {
 			break
 		}
 
@@ -37,7 +37,6 @@ func (s *session) commandHandler(cmd string) {
 			break
 		}
 
-		// TODO channelのアクセスチェック
 		s.Lock()
 		s.viewState.channelID = cid
 		s.viewState.state = viewer.StateFromString(args[2])
@@ -45,15 +44,105 @@ func (s *session) commandHandler(cmd string) {
 
 		s.streamer.realtime.ViewerManager.SetViewer(s, s.userID, s.viewState.channelID, s.viewState.state)
 
+	case "subscribe":
+		if len(args) < 2 {
+			// 引数が不正
+			s.sendErrorMessage(fmt.Sprintf("invalid args: %s", cmd))
+			break
+		}
+
+		cid, ok := s.parseAccessibleChannelID(args[1])
+		if !ok {
+			break
+		}
+
+		if !s.subscribeChannel(cid) {
+			// 購読数の上限
+			s.sendErrorMessage(fmt.Sprintf("too many subscriptions: max %d channels", maxSubscribingChannels))
+		}
+
+	case "unsubscribe":
+		if len(args) < 2 {
+			// 引数が不正
+			s.sendErrorMessage(fmt.Sprintf("invalid args: %s", cmd))
+			break
+		}
+
+		cid, err := uuid.FromString(args[1])
+		if err != nil {
+			// チャンネルIDが不正
+			s.sendErrorMessage(fmt.Sprintf("invalid id: %s", args[1]))
+			break
+		}
+
+		s.unsubscribeChannel(cid)
+
+	case "typing":
+		if len(args) < 2 {
+			// 引数が不正
+			s.sendErrorMessage(fmt.Sprintf("invalid args: %s", cmd))
+			break
+		}
+
+		cid, ok := s.parseAccessibleChannelID(args[1])
+		if !ok {
+			break
+		}
+
+		s.streamer.realtime.Typing.Typing(s.userID, cid)
+
+	case "resume":
+		if len(args) < 2 {
+			// 引数が不正
+			s.sendErrorMessage(fmt.Sprintf("invalid args: %s", cmd))
+			break
+		}
+
+		lastSeq, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			// シーケンス番号が不正
+			s.sendErrorMessage(fmt.Sprintf("invalid seq: %s", args[1]))
+			break
+		}
+
+		if seq, ok := s.streamer.replay.resume(s, lastSeq); ok {
+			_ = s.writeMessage(makeMessage("RESUMED", map[string]interface{}{"seq": seq}).toRawMessage(s.encoding))
+		} else {
+			// 再送できないので、クライアントに全体の再取得を求める
+			_ = s.writeMessage(makeMessage("RESYNC_REQUIRED", map[string]interface{}{"seq": seq}).toRawMessage(s.encoding))
+		}
+
 	default:
 		// 不明なコマンド
 		s.sendErrorMessage(fmt.Sprintf("unknown command: %s", cmd))
 	}
 }
 
+// parseAccessibleChannelID チャンネルIDをパースし、このセッションのユーザーがアクセス可能か確認します
+//
+// 不正な場合はエラーメッセージを送信してfalseを返します
+func (s *session) parseAccessibleChannelID(str string) (uuid.UUID, bool) {
+	cid, err := uuid.FromString(str)
+	if err != nil {
+		// チャンネルIDが不正
+		s.sendErrorMessage(fmt.Sprintf("invalid id: %s", str))
+		return uuid.Nil, false
+	}
+
+	ok, err := s.streamer.repo.IsChannelAccessibleToUser(s.userID, cid)
+	if err != nil {
+		s.streamer.logger.Error("failed to IsChannelAccessibleToUser", zap.Error(err), zap.Stringer("userID", s.userID), zap.Stringer("channelID", cid))
+		s.sendErrorMessage("internal error")
+		return uuid.Nil, false
+	}
+	if !ok {
+		// アクセスできないチャンネル
+		s.sendErrorMessage(fmt.Sprintf("channel not found: %s", str))
+		return uuid.Nil, false
+	}
+	return cid, true
+}
+
 func (s *session) sendErrorMessage(error string) {
-	_ = s.writeMessage(&rawMessage{
-		t:    websocket.TextMessage,
-		data: makeMessage("ERROR", error).toJSON(),
-	})
+	_ = s.writeMessage(makeMessage("ERROR", error).toRawMessage(s.encoding))
 }
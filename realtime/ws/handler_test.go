@@ -0,0 +1,200 @@
+package ws
+
+import (
+	stdjson "encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/realtime"
+	"github.com/traPtitech/traQ/realtime/viewer"
+	"github.com/traPtitech/traQ/repository"
+	"go.uber.org/zap"
+)
+
+type accessibleRepository struct {
+	repository.Repository
+	channels map[uuid.UUID]bool
+}
+
+func (repo *accessibleRepository) IsChannelAccessibleToUser(_, channelID uuid.UUID) (bool, error) {
+	return repo.channels[channelID], nil
+}
+
+func setupStreamer(channels ...uuid.UUID) (*Streamer, *hub.Hub) {
+	h := hub.New()
+	repo := &accessibleRepository{channels: map[uuid.UUID]bool{}}
+	for _, c := range channels {
+		repo.channels[c] = true
+	}
+	return NewStreamer(h, realtime.NewService(h), repo, zap.NewNop()), h
+}
+
+func newStreamerSession(st *Streamer, userID uuid.UUID) *session {
+	s := newTestSession(userID)
+	s.streamer = st
+	s.channels = map[uuid.UUID]struct{}{}
+	st.mu.Lock()
+	st.sessions[s] = struct{}{}
+	st.mu.Unlock()
+	st.replay.attach(s)
+	return s
+}
+
+func lastErrorMessage(t *testing.T, s *session) string {
+	t.Helper()
+	msgs := readMessages(t, s)
+	if !assert.NotEmpty(t, msgs) {
+		return ""
+	}
+	last := msgs[len(msgs)-1]
+	assert.Equal(t, "ERROR", last.Type)
+	var body string
+	b, _ := stdjson.Marshal(last.Body)
+	_ = stdjson.Unmarshal(b, &body)
+	return body
+}
+
+func TestSession_commandHandler(t *testing.T) {
+	t.Parallel()
+
+	public := uuid.Must(uuid.NewV4())
+	private := uuid.Must(uuid.NewV4())
+	st, _ := setupStreamer(public)
+	user := uuid.Must(uuid.NewV4())
+
+	t.Run("viewstate", func(t *testing.T) {
+		s := newStreamerSession(st, user)
+
+		s.commandHandler("viewstate:" + private.String() + ":monitoring")
+		assert.Contains(t, lastErrorMessage(t, s), "channel not found")
+		cid, _ := s.ViewState()
+		assert.Equal(t, uuid.Nil, cid)
+
+		s.commandHandler("viewstate:" + public.String() + ":editing")
+		assert.Empty(t, readMessages(t, s))
+		cid, state := s.ViewState()
+		assert.Equal(t, public, cid)
+		assert.Equal(t, viewer.StateEditing, state)
+		assert.Contains(t, st.realtime.ViewerManager.GetChannelViewers(public), user)
+
+		s.commandHandler("viewstate:null")
+		cid, _ = s.ViewState()
+		assert.Equal(t, uuid.Nil, cid)
+		assert.NotContains(t, st.realtime.ViewerManager.GetChannelViewers(public), user)
+	})
+
+	t.Run("subscribe", func(t *testing.T) {
+		s := newStreamerSession(st, user)
+
+		s.commandHandler("subscribe:" + private.String())
+		assert.Contains(t, lastErrorMessage(t, s), "channel not found")
+		assert.False(t, s.IsSubscribingChannel(private))
+
+		s.commandHandler("subscribe:invalid")
+		assert.Contains(t, lastErrorMessage(t, s), "invalid id")
+
+		s.commandHandler("subscribe")
+		assert.Contains(t, lastErrorMessage(t, s), "invalid args")
+
+		s.commandHandler("subscribe:" + public.String())
+		assert.Empty(t, readMessages(t, s))
+		assert.True(t, s.IsSubscribingChannel(public))
+
+		st.WriteMessage("MESSAGE_UPDATED", struct{ ID string }{"a"}, TargetChannelSubscribers(public))
+		if msgs := readMessages(t, s); assert.Len(t, msgs, 1) {
+			assert.Equal(t, "MESSAGE_UPDATED", msgs[0].Type)
+		}
+
+		s.commandHandler("unsubscribe:" + public.String())
+		assert.False(t, s.IsSubscribingChannel(public))
+		st.WriteMessage("MESSAGE_UPDATED", struct{ ID string }{"a"}, TargetChannelSubscribers(public))
+		assert.Empty(t, readMessages(t, s))
+	})
+
+	t.Run("typing", func(t *testing.T) {
+		s := newStreamerSession(st, user)
+
+		s.commandHandler("typing:" + private.String())
+		assert.Contains(t, lastErrorMessage(t, s), "channel not found")
+		assert.False(t, st.realtime.Typing.IsTyping(user, private))
+
+		s.commandHandler("typing")
+		assert.Contains(t, lastErrorMessage(t, s), "invalid args")
+
+		s.commandHandler("typing:" + public.String())
+		assert.Empty(t, readMessages(t, s))
+		assert.True(t, st.realtime.Typing.IsTyping(user, public))
+	})
+
+	t.Run("subscription limit", func(t *testing.T) {
+		s := newStreamerSession(st, user)
+		for i := 0; i < maxSubscribingChannels; i++ {
+			assert.True(t, s.subscribeChannel(uuid.Must(uuid.NewV4())))
+		}
+		s.commandHandler("subscribe:" + public.String())
+		assert.Contains(t, lastErrorMessage(t, s), "too many subscriptions")
+	})
+}
+
+func TestStreamer_channelEventLoop(t *testing.T) {
+	t.Parallel()
+
+	channel := uuid.Must(uuid.NewV4())
+	st, h := setupStreamer(channel)
+	user1 := uuid.Must(uuid.NewV4())
+	user2 := uuid.Must(uuid.NewV4())
+	s1 := newStreamerSession(st, user1)
+	s2 := newStreamerSession(st, user2)
+	s1.commandHandler("subscribe:" + channel.String())
+	s1.commandHandler("viewstate:" + channel.String() + ":monitoring")
+	s2.commandHandler("subscribe:" + channel.String())
+	require.True(t, s1.IsSubscribingChannel(channel))
+	require.True(t, s2.IsSubscribingChannel(channel))
+
+	// メンバーから外されたユーザーの購読は解除される
+	h.Publish(hub.Message{
+		Name: event.ChannelMemberRemoved,
+		Fields: hub.Fields{
+			"channel_id": channel,
+			"user_id":    user1,
+			"updater_id": user2,
+		},
+	})
+	require.Eventually(t, func() bool { return !s1.IsSubscribingChannel(channel) }, time.Second, 10*time.Millisecond)
+	cid, _ := s1.ViewState()
+	assert.Equal(t, uuid.Nil, cid)
+	assert.True(t, s2.IsSubscribingChannel(channel))
+
+	// 削除されたチャンネルの購読は解除される
+	h.Publish(hub.Message{
+		Name: event.ChannelDeleted,
+		Fields: hub.Fields{
+			"channel_id": channel,
+			"private":    false,
+		},
+	})
+	require.Eventually(t, func() bool { return !s2.IsSubscribingChannel(channel) }, time.Second, 10*time.Millisecond)
+}
+
+func TestTargetAny(t *testing.T) {
+	t.Parallel()
+
+	user1 := uuid.Must(uuid.NewV4())
+	user2 := uuid.Must(uuid.NewV4())
+	channel := uuid.Must(uuid.NewV4())
+	s1 := newTestSession(user1)
+	s1.channels = map[uuid.UUID]struct{}{}
+	s2 := newTestSession(user2)
+	s2.channels = map[uuid.UUID]struct{}{channel: {}}
+
+	f := TargetAny(TargetUsers(user1), TargetChannelSubscribers(channel))
+	assert.True(t, f(s1))
+	assert.True(t, f(s2))
+	assert.False(t, TargetAny()(s1))
+	assert.False(t, TargetChannelSubscribers(channel)(s1))
+}
@@ -1,5 +1,32 @@
 package ws
 
+import (
+	"bytes"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// encoding セッションに送信するメッセージのエンコーディング
+type encoding int
+
+const (
+	// encodingJSON JSON (TextMessage)
+	encodingJSON encoding = iota
+	// encodingMessagePack MessagePack (BinaryMessage)
+	encodingMessagePack
+)
+
+// encodingFromSubprotocol ネゴシエーションされたサブプロトコルからエンコーディングを決定します
+func encodingFromSubprotocol(protocol string) encoding {
+	if protocol == subprotocolMessagePack {
+		return encodingMessagePack
+	}
+	return encodingJSON
+}
+
 type rawMessage struct {
 	t    int
 	data []byte
@@ -7,6 +34,7 @@ type rawMessage struct {
 
 type message struct {
 	Type string      `json:"type"`
+	Seq  uint64      `json:"seq,omitempty"`
 	Body interface{} `json:"body"`
 }
 
@@ -21,3 +49,77 @@ func (m *message) toJSON() (b []byte) {
 	b, _ = json.Marshal(m)
 	return
 }
+
+func (m *message) toRawMessage(enc encoding) *rawMessage {
+	if enc == encodingMessagePack {
+		if b, err := jsonToMessagePack(m.toJSON()); err == nil {
+			return &rawMessage{
+				t:    websocket.BinaryMessage,
+				data: b,
+			}
+		}
+	}
+	return &rawMessage{
+		t:    websocket.TextMessage,
+		data: m.toJSON(),
+	}
+}
+
+// eventBody 複数のセッションに送信するイベントのボディ
+//
+// エンコーディング毎に一度だけエンコードし、全ての送信先・再送で共有します
+type eventBody struct {
+	json jsoniter.RawMessage
+
+	msgpackOnce sync.Once
+	msgpack     []byte
+	msgpackErr  error
+}
+
+func newEventBody(b jsoniter.RawMessage) *eventBody {
+	return &eventBody{json: b}
+}
+
+func (b *eventBody) messagePack() ([]byte, error) {
+	b.msgpackOnce.Do(func() {
+		b.msgpack, b.msgpackErr = jsonToMessagePack(b.json)
+	})
+	return b.msgpack, b.msgpackErr
+}
+
+// makeSeqRawMessage エンコード済みのボディにシーケンス番号付きのエンベロープを付けたメッセージを作成します
+//
+// エンベロープはmessageと同じ形式です
+func makeSeqRawMessage(enc encoding, t string, seq uint64, body *eventBody) *rawMessage {
+	if enc == encodingMessagePack {
+		if b, err := body.messagePack(); err == nil {
+			var buf bytes.Buffer
+			buf.Grow(len(t) + len(b) + 32)
+			writeMessagePackHeader(&buf, 3, 0x80, 16, 0, 0xde, 0xdf)
+			_ = writeMessagePack(&buf, "type")
+			_ = writeMessagePack(&buf, t)
+			_ = writeMessagePack(&buf, "seq")
+			writeMessagePackUint(&buf, seq)
+			_ = writeMessagePack(&buf, "body")
+			buf.Write(b)
+			return &rawMessage{
+				t:    websocket.BinaryMessage,
+				data: buf.Bytes(),
+			}
+		}
+	}
+
+	typ, _ := json.Marshal(t)
+	data := make([]byte, 0, len(typ)+len(body.json)+48)
+	data = append(data, `{"type":`...)
+	data = append(data, typ...)
+	data = append(data, `,"seq":`...)
+	data = strconv.AppendUint(data, seq, 10)
+	data = append(data, `,"body":`...)
+	data = append(data, body.json...)
+	data = append(data, '}')
+	return &rawMessage{
+		t:    websocket.TextMessage,
+		data: data,
+	}
+}
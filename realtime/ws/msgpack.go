@@ -0,0 +1,145 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/binary"
+	stdjson "encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// jsonToMessagePack JSONをMessagePackに変換します
+//
+// メッセージボディのエンコードはJSONと共通にするため、JSONにエンコードしたものを変換します。
+// イベントのボディはeventBodyでイベント毎に一度だけ変換されます
+func jsonToMessagePack(b []byte) ([]byte, error) {
+	dec := stdjson.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := writeMessagePack(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeMessagePack(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case stdjson.Number:
+		return writeMessagePackNumber(buf, v)
+	case string:
+		writeMessagePackHeader(buf, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		buf.WriteString(v)
+	case []interface{}:
+		writeMessagePackHeader(buf, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, e := range v {
+			if err := writeMessagePack(buf, e); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		writeMessagePackHeader(buf, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		// 出力を一定にするためキーをソートする
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			_ = writeMessagePack(buf, k)
+			if err := writeMessagePack(buf, v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+	return nil
+}
+
+// writeMessagePackHeader str, array, mapの長さを書き込みます. code8が0の場合は8bit長の形式を持たない型です
+func writeMessagePackHeader(buf *bytes.Buffer, n int, fix byte, fixMax int, code8, code16, code32 byte) {
+	switch {
+	case n < fixMax:
+		buf.WriteByte(fix | byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		buf.WriteByte(code8)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(code16)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(code32)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+func writeMessagePackNumber(buf *bytes.Buffer, n stdjson.Number) error {
+	if i, err := n.Int64(); err == nil {
+		switch {
+		case i >= 0:
+			writeMessagePackUint(buf, uint64(i))
+		case i >= -32:
+			buf.WriteByte(byte(int8(i)))
+		case i >= math.MinInt8:
+			buf.WriteByte(0xd0)
+			buf.WriteByte(byte(int8(i)))
+		case i >= math.MinInt16:
+			buf.WriteByte(0xd1)
+			_ = binary.Write(buf, binary.BigEndian, int16(i))
+		case i >= math.MinInt32:
+			buf.WriteByte(0xd2)
+			_ = binary.Write(buf, binary.BigEndian, int32(i))
+		default:
+			buf.WriteByte(0xd3)
+			_ = binary.Write(buf, binary.BigEndian, i)
+		}
+		return nil
+	}
+
+	if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+		writeMessagePackUint(buf, u)
+		return nil
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return err
+	}
+	buf.WriteByte(0xcb)
+	_ = binary.Write(buf, binary.BigEndian, f)
+	return nil
+}
+
+func writeMessagePackUint(buf *bytes.Buffer, u uint64) {
+	switch {
+	case u <= math.MaxInt8:
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		_ = binary.Write(buf, binary.BigEndian, uint16(u))
+	case u <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		_ = binary.Write(buf, binary.BigEndian, uint32(u))
+	default:
+		buf.WriteByte(0xcf)
+		_ = binary.Write(buf, binary.BigEndian, u)
+	}
+}
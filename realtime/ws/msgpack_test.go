@@ -0,0 +1,145 @@
+package ws
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v4"
+)
+
+func TestJSONToMessagePack(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		json     string
+		expected []byte
+	}{
+		{`null`, []byte{0xc0}},
+		{`true`, []byte{0xc3}},
+		{`false`, []byte{0xc2}},
+		{`0`, []byte{0x00}},
+		{`127`, []byte{0x7f}},
+		{`128`, []byte{0xcc, 0x80}},
+		{`65535`, []byte{0xcd, 0xff, 0xff}},
+		{`65536`, []byte{0xce, 0x00, 0x01, 0x00, 0x00}},
+		{`1718000000000001`, []byte{0xcf, 0x00, 0x06, 0x1a, 0x83, 0x0b, 0xb9, 0x60, 0x01}},
+		{`18446744073709551615`, []byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{`-1`, []byte{0xff}},
+		{`-32`, []byte{0xe0}},
+		{`-33`, []byte{0xd0, 0xdf}},
+		{`-129`, []byte{0xd1, 0xff, 0x7f}},
+		{`-32769`, []byte{0xd2, 0xff, 0xff, 0x7f, 0xff}},
+		{`1.5`, []byte{0xcb, 0x3f, 0xf8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{`""`, []byte{0xa0}},
+		{`"abc"`, []byte{0xa3, 'a', 'b', 'c'}},
+		{`[]`, []byte{0x90}},
+		{`[1,"a",null]`, []byte{0x93, 0x01, 0xa1, 'a', 0xc0}},
+		{`{}`, []byte{0x80}},
+		{`{"b":1,"a":[true]}`, []byte{0x82, 0xa1, 'a', 0x91, 0xc3, 0xa1, 'b', 0x01}},
+	}
+	for _, c := range cases {
+		b, err := jsonToMessagePack([]byte(c.json))
+		if assert.NoError(t, err, c.json) {
+			assert.Equal(t, c.expected, b, c.json)
+		}
+	}
+
+	t.Run("long values", func(t *testing.T) {
+		t.Parallel()
+
+		s := strings.Repeat("a", 32)
+		b, err := jsonToMessagePack([]byte(`"` + s + `"`))
+		require.NoError(t, err)
+		assert.Equal(t, append([]byte{0xd9, 32}, s...), b)
+
+		s = strings.Repeat("a", 256)
+		b, err = jsonToMessagePack([]byte(`"` + s + `"`))
+		require.NoError(t, err)
+		assert.Equal(t, append([]byte{0xda, 0x01, 0x00}, s...), b)
+
+		b, err = jsonToMessagePack([]byte(`[` + strings.Repeat("0,", 15) + `0]`))
+		require.NoError(t, err)
+		assert.Equal(t, append([]byte{0xdc, 0x00, 0x10}, make([]byte, 16)...), b)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := jsonToMessagePack([]byte(`{`))
+		assert.Error(t, err)
+	})
+}
+
+// decodeMessagePackAsJSON MessagePackを外部のデコーダーでデコードし、比較用にJSONにします
+func decodeMessagePackAsJSON(t *testing.T, b []byte) string {
+	t.Helper()
+	var v interface{}
+	require.NoError(t, msgpack.Unmarshal(b, &v))
+	j, err := stdjson.Marshal(v)
+	require.NoError(t, err)
+	return string(j)
+}
+
+// normalizeJSON 比較用にJSONのキーの順序と空白を揃えます
+func normalizeJSON(t *testing.T, b []byte) string {
+	t.Helper()
+	dec := stdjson.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	require.NoError(t, dec.Decode(&v))
+	j, err := stdjson.Marshal(v)
+	require.NoError(t, err)
+	return string(j)
+}
+
+func TestJSONToMessagePack_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	long := make([]string, 70000)
+	for i := range long {
+		long[i] = `"k` + strings.Repeat("x", i%40) + `":` + strings.Repeat("1", 1+i%19)
+	}
+	cases := []string{
+		`null`,
+		`{"id":"0b8d5b9e-8a9d-4c4a-9f3e-7b0c5f6a1d2e","n":-9223372036854775808,"u":18446744073709551615,"f":-0.25,"e":1e+300}`,
+		`{"message":{"text":"こんにちは\n\"traQ\"","stamps":[],"pinned":false,"thread":null}}`,
+		`[` + strings.Repeat(`{"a":[1,-1,128,-129,65536,-32769]},`, 20) + `{}]`,
+		`"` + strings.Repeat("あ", 30000) + `"`,
+		`{` + strings.Join(long, ",") + `}`,
+	}
+	for _, c := range cases {
+		b, err := jsonToMessagePack([]byte(c))
+		if assert.NoError(t, err) {
+			assert.Equal(t, normalizeJSON(t, []byte(c)), decodeMessagePackAsJSON(t, b))
+		}
+	}
+}
+
+func TestMakeSeqRawMessage(t *testing.T) {
+	t.Parallel()
+
+	body := newEventBody([]byte(`{"id":"a","n":[1,2.5,null],"nested":{"ok":true}}`))
+	expected := normalizeJSON(t, makeMessage("MESSAGE_CREATED", stdjson.RawMessage(body.json)).toJSON())
+	expected = strings.Replace(expected, `"body"`, `"seq":1718000000000001,"body"`, 1)
+	expected = normalizeJSON(t, []byte(expected))
+
+	m := makeSeqRawMessage(encodingJSON, "MESSAGE_CREATED", 1718000000000001, body)
+	assert.Equal(t, websocket.TextMessage, m.t)
+	assert.Equal(t, expected, normalizeJSON(t, m.data))
+
+	m = makeSeqRawMessage(encodingMessagePack, "MESSAGE_CREATED", 1718000000000001, body)
+	assert.Equal(t, websocket.BinaryMessage, m.t)
+	assert.Equal(t, expected, decodeMessagePackAsJSON(t, m.data))
+
+	// ボディのエンコードは共有される
+	m2 := makeSeqRawMessage(encodingMessagePack, "MESSAGE_CREATED", 1718000000000002, body)
+	assert.Equal(t, len(m.data), len(m2.data))
+	b1, _ := body.messagePack()
+	b2, _ := body.messagePack()
+	assert.Equal(t, &b1[0], &b2[0])
+}
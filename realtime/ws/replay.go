@@ -0,0 +1,296 @@
+package ws
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	// replayBufferSize ユーザー毎に保持するイベントの最大数
+	replayBufferSize = 256
+	// replayRetention 切断されたセッションの再開を受け付ける期間
+	replayRetention = 2 * time.Minute
+)
+
+// replayEvent 再送用に保持されるイベント
+type replayEvent struct {
+	seq       uint64
+	t         string
+	body      *eventBody
+	createdAt time.Time
+	// targets 送信対象となったセッション(切断中のセッションを含む)
+	targets map[*session]struct{}
+}
+
+func (e *replayEvent) toRawMessage(enc encoding) *rawMessage {
+	return makeSeqRawMessage(enc, e.t, e.seq, e.body)
+}
+
+// userReplay ユーザー毎のイベントシーケンス番号と再送バッファ
+//
+// 各フィールドとセッションのfirstSeqはmuのロック下で読み書きします
+type userReplay struct {
+	mu sync.Mutex
+	// seq 最後に割り当てたシーケンス番号
+	seq uint64
+	// events 送信したイベントのリングバッファ. 古い順に並ぶ
+	events []*replayEvent
+	// sessions 接続中のセッション
+	sessions map[*session]struct{}
+	// detached 切断されてから再開を受け付けている期間中のセッションと切断日時
+	detached map[*session]time.Time
+}
+
+// replayBuffers ユーザー毎の再送バッファ
+//
+// シーケンス番号はバッファ作成時刻(マイクロ秒)から始まるため、
+// サーバーの再起動後や別インスタンスに再接続した場合に以前の番号と重なることはありません
+//
+// muはusersマップのみを保護し、ユーザー毎の状態はuserReplay.muで保護します。
+// 両方を取得する場合はmu, userReplay.muの順に取得します
+type replayBuffers struct {
+	users map[uuid.UUID]*userReplay
+	mu    sync.RWMutex
+}
+
+func newReplayBuffers() *replayBuffers {
+	return &replayBuffers{
+		users: map[uuid.UUID]*userReplay{},
+	}
+}
+
+// attach セッションの接続を記録します
+func (b *replayBuffers) attach(s *session) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	u, ok := b.users[s.userID]
+	if !ok {
+		u = &userReplay{
+			seq:      uint64(time.Now().UnixNano() / int64(time.Microsecond)),
+			sessions: map[*session]struct{}{},
+			detached: map[*session]time.Time{},
+		}
+		b.users[s.userID] = u
+	}
+	u.mu.Lock()
+	u.sessions[s] = struct{}{}
+	u.mu.Unlock()
+}
+
+// detach セッションの切断を記録します
+func (b *replayBuffers) detach(s *session) {
+	u, ok := b.get(s.userID)
+	if !ok {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if _, ok := u.sessions[s]; ok {
+		delete(u.sessions, s)
+		u.detached[s] = time.Now()
+	}
+}
+
+// write イベントにシーケンス番号を割り当ててバッファに記録し、対象の接続中のセッションに送信します
+//
+// 切断中のセッションが対象に含まれるユーザーについても記録します。
+// 全体のロックはユーザーの一覧を取得する間のみ保持し、ユーザー毎の処理はユーザー毎のロック下で行います
+func (b *replayBuffers) write(t string, body jsoniter.RawMessage, targetFunc TargetFunc, onError func(s *session, err error)) {
+	b.mu.RLock()
+	users := make([]*userReplay, 0, len(b.users))
+	for _, u := range b.users {
+		users = append(users, u)
+	}
+	b.mu.RUnlock()
+
+	// ボディは全てのユーザーで共有し、エンコードを一度だけ行う
+	eb := newEventBody(body)
+	now := time.Now()
+	for _, u := range users {
+		u.write(t, eb, now, targetFunc, onError)
+	}
+}
+
+// write イベントにシーケンス番号を割り当ててバッファに記録し、対象の接続中のセッションに送信します
+//
+// セッション毎の送信順がシーケンス番号順になるように、送信までをロック下で行います
+func (u *userReplay) write(t string, body *eventBody, now time.Time, targetFunc TargetFunc, onError func(s *session, err error)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	matched := map[*session]struct{}{}
+	var targets []*session
+	for s := range u.sessions {
+		if targetFunc(s) {
+			targets = append(targets, s)
+			matched[s] = struct{}{}
+		}
+	}
+	for s := range u.detached {
+		if targetFunc(s) {
+			matched[s] = struct{}{}
+		}
+	}
+	if len(matched) == 0 {
+		return
+	}
+
+	u.seq++
+	e := &replayEvent{
+		seq:       u.seq,
+		t:         t,
+		body:      body,
+		createdAt: now,
+		targets:   matched,
+	}
+	if len(u.events) >= replayBufferSize {
+		u.events = u.events[1:]
+	}
+	u.events = append(u.events, e)
+
+	// 同じエンコーディングのセッションには同じメッセージを送る
+	msgs := map[encoding]*rawMessage{}
+	for _, s := range targets {
+		m, ok := msgs[s.encoding]
+		if !ok {
+			m = e.toRawMessage(s.encoding)
+			msgs[s.encoding] = m
+		}
+		if err := s.writeMessage(m); err != nil {
+			onError(s, err)
+		} else if s.firstSeq == 0 {
+			s.firstSeq = e.seq
+		}
+	}
+}
+
+// resume lastSeqより後のイベントのうち、セッションにまだ送信していないものを再送します
+//
+// lastSeqのイベントの送信対象だった同じクライアントのセッションを切断前のセッションとみなし、
+// そのセッションが送信対象だったイベントのみを再送します。
+// 再送できない場合はfalseを返します. 返り値のseqは現在のシーケンス番号です
+func (b *replayBuffers) resume(s *session, lastSeq uint64) (seq uint64, ok bool) {
+	u, found := b.get(s.userID)
+	if !found {
+		return 0, false
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	seq = u.seq
+	if lastSeq > u.seq {
+		// 他インスタンスや再起動前の番号
+		return seq, false
+	}
+	if lastSeq == u.seq {
+		return seq, true
+	}
+	if len(u.events) == 0 || u.events[0].seq > lastSeq {
+		// 必要なイベントが既にバッファから溢れている
+		return seq, false
+	}
+
+	prev, ok := u.previousSession(s, lastSeq)
+	if !ok {
+		// 切断前のセッションを特定できない
+		return seq, false
+	}
+	if prev == s {
+		return seq, true
+	}
+
+	for _, e := range u.events {
+		if e.seq <= lastSeq {
+			continue
+		}
+		if s.firstSeq != 0 && e.seq >= s.firstSeq {
+			// 接続後に送信済み
+			break
+		}
+		if _, ok := e.targets[prev]; !ok {
+			// 切断前のセッションが対象でないイベント
+			continue
+		}
+		if err := s.writeMessage(e.toRawMessage(s.encoding)); err != nil {
+			return seq, false
+		}
+	}
+	delete(u.detached, prev)
+	return seq, true
+}
+
+// sweep 再開を受け付ける期間が過ぎたセッションと、不要になったバッファを破棄します
+func (b *replayBuffers) sweep() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for id, u := range b.users {
+		if u.sweep() {
+			delete(b.users, id)
+		}
+	}
+}
+
+// get 指定したユーザーのバッファを返します
+func (b *replayBuffers) get(userID uuid.UUID) (*userReplay, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	u, ok := b.users[userID]
+	return u, ok
+}
+
+// sweep 再開を受け付ける期間が過ぎたセッションとイベントを破棄します. バッファ自体が不要になった場合はtrueを返します
+func (u *userReplay) sweep() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for s, t := range u.detached {
+		if time.Since(t) > replayRetention {
+			delete(u.detached, s)
+		}
+	}
+	if len(u.sessions) == 0 && len(u.detached) == 0 {
+		return true
+	}
+	i := 0
+	for i < len(u.events) && time.Since(u.events[i].createdAt) > replayRetention {
+		i++
+	}
+	u.events = u.events[i:]
+	return false
+}
+
+// previousSession lastSeqのイベントの送信対象だったセッションのうち、sと同じクライアントのものを返します
+//
+// 該当するセッションが1つに定まらない場合はfalseを返します
+func (u *userReplay) previousSession(s *session, lastSeq uint64) (*session, bool) {
+	for _, e := range u.events {
+		if e.seq != lastSeq {
+			continue
+		}
+		if _, ok := e.targets[s]; ok {
+			return s, true
+		}
+		var prev *session
+		for t := range e.targets {
+			if t.clientKey != s.clientKey || !u.has(t) {
+				continue
+			}
+			if prev != nil {
+				return nil, false
+			}
+			prev = t
+		}
+		return prev, prev != nil
+	}
+	return nil, false
+}
+
+// has セッションが接続中か、再開を受け付けている期間中かどうかを返します
+func (u *userReplay) has(s *session) bool {
+	if _, ok := u.sessions[s]; ok {
+		return true
+	}
+	_, ok := u.detached[s]
+	return ok
+}
@@ -0,0 +1,242 @@
+package ws
+
+import (
+	stdjson "encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSession(userID uuid.UUID) *session {
+	return &session{
+		open:   true,
+		send:   make(chan *rawMessage, messageBufferSize),
+		userID: userID,
+	}
+}
+
+func readMessages(t *testing.T, s *session) []message {
+	t.Helper()
+	var result []message
+	for {
+		select {
+		case m := <-s.send:
+			var msg message
+			require.NoError(t, stdjson.Unmarshal(m.data, &msg))
+			result = append(result, msg)
+		default:
+			return result
+		}
+	}
+}
+
+func TestReplayBuffers(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.Must(uuid.NewV4())
+	other := uuid.Must(uuid.NewV4())
+	b := newReplayBuffers()
+	nop := func(*session, error) {}
+
+	s1 := newTestSession(user)
+	s3 := newTestSession(other)
+	b.attach(s1)
+	b.attach(s3)
+
+	b.write("A", []byte(`{"n":1}`), TargetUsers(user), nop)
+	msgs := readMessages(t, s1)
+	require.Len(t, msgs, 1)
+	base := msgs[0].Seq
+	assert.Equal(t, "A", msgs[0].Type)
+	assert.NotZero(t, base)
+	assert.Empty(t, readMessages(t, s3))
+
+	// 切断中のイベントも記録される
+	b.detach(s1)
+	b.write("B", []byte(`{"n":2}`), TargetUsers(user), nop)
+	b.write("C", []byte(`{"n":3}`), TargetAll(), nop)
+	assert.Empty(t, readMessages(t, s1))
+	msgs = readMessages(t, s3)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "C", msgs[0].Type)
+	otherSeq := msgs[0].Seq
+
+	// 再接続後に届いたイベント
+	s2 := newTestSession(user)
+	b.attach(s2)
+	b.write("D", []byte(`{"n":4}`), TargetUsers(user), nop)
+	if msgs := readMessages(t, s2); assert.Len(t, msgs, 1) {
+		assert.Equal(t, base+3, msgs[0].Seq)
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		seq, ok := b.resume(s2, base)
+		assert.True(t, ok)
+		assert.Equal(t, base+3, seq)
+		msgs := readMessages(t, s2)
+		if assert.Len(t, msgs, 2) {
+			assert.Equal(t, "B", msgs[0].Type)
+			assert.Equal(t, base+1, msgs[0].Seq)
+			assert.Equal(t, "C", msgs[1].Type)
+			assert.Equal(t, base+2, msgs[1].Seq)
+		}
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		_, ok := b.resume(s2, base+3)
+		assert.True(t, ok)
+		assert.Empty(t, readMessages(t, s2))
+	})
+
+	t.Run("unknown seq", func(t *testing.T) {
+		_, ok := b.resume(s2, base+100)
+		assert.False(t, ok)
+		_, ok = b.resume(s2, base-100)
+		assert.False(t, ok)
+	})
+
+	t.Run("overflowed", func(t *testing.T) {
+		s4 := newTestSession(other)
+		b.attach(s4)
+		b.write("E", []byte(`{}`), TargetUsers(other), nop)
+		readMessages(t, s3)
+		readMessages(t, s4)
+		_, ok := b.resume(s4, otherSeq)
+		assert.True(t, ok)
+
+		for i := 0; i < replayBufferSize; i++ {
+			b.write("E", []byte(`{}`), TargetUsers(other), nop)
+			readMessages(t, s3)
+		}
+		_, ok = b.resume(s4, otherSeq)
+		assert.False(t, ok)
+	})
+}
+
+func TestReplayBuffers_resumeTargets(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.Must(uuid.NewV4())
+	channel := uuid.Must(uuid.NewV4())
+	b := newReplayBuffers()
+	nop := func(*session, error) {}
+
+	s1 := newTestSession(user)
+	s1.clientKey = "a"
+	s1.viewState.channelID = channel
+	s2 := newTestSession(user)
+	s2.clientKey = "b"
+	b.attach(s1)
+	b.attach(s2)
+
+	b.write("A", []byte(`{}`), TargetUsers(user), nop)
+	msgs := readMessages(t, s1)
+	require.Len(t, msgs, 1)
+	base := msgs[0].Seq
+	readMessages(t, s2)
+
+	b.detach(s1)
+	b.detach(s2)
+	b.write("VIEWER", []byte(`{}`), TargetChannelViewers(channel), nop)
+	b.write("DRAFT", []byte(`{}`), TargetUserExceptClient(user, "a"), nop)
+
+	// 切断前のセッションが対象だったイベントのみ再送される
+	n1 := newTestSession(user)
+	n1.clientKey = "a"
+	b.attach(n1)
+	_, ok := b.resume(n1, base)
+	assert.True(t, ok)
+	if msgs := readMessages(t, n1); assert.Len(t, msgs, 1) {
+		assert.Equal(t, "VIEWER", msgs[0].Type)
+	}
+
+	n2 := newTestSession(user)
+	n2.clientKey = "b"
+	b.attach(n2)
+	_, ok = b.resume(n2, base)
+	assert.True(t, ok)
+	if msgs := readMessages(t, n2); assert.Len(t, msgs, 1) {
+		assert.Equal(t, "DRAFT", msgs[0].Type)
+	}
+
+	// 再開済みのセッションからは再開できない
+	n3 := newTestSession(user)
+	n3.clientKey = "a"
+	b.attach(n3)
+	_, ok = b.resume(n3, base)
+	assert.False(t, ok)
+	assert.Empty(t, readMessages(t, n3))
+}
+
+func TestReplayBuffers_resumeAmbiguous(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.Must(uuid.NewV4())
+	b := newReplayBuffers()
+	nop := func(*session, error) {}
+
+	s1 := newTestSession(user)
+	s2 := newTestSession(user)
+	b.attach(s1)
+	b.attach(s2)
+
+	b.write("A", []byte(`{}`), TargetUsers(user), nop)
+	msgs := readMessages(t, s1)
+	require.Len(t, msgs, 1)
+	readMessages(t, s2)
+	b.detach(s1)
+	b.write("B", []byte(`{}`), TargetUsers(user), nop)
+
+	// 同じクライアントの候補が複数ある場合は再送しない
+	s3 := newTestSession(user)
+	b.attach(s3)
+	_, ok := b.resume(s3, msgs[0].Seq)
+	assert.False(t, ok)
+	assert.Empty(t, readMessages(t, s3))
+}
+
+func TestReplayBuffers_writeConcurrently(t *testing.T) {
+	t.Parallel()
+
+	const (
+		writers = 8
+		events  = 16
+	)
+	users := []uuid.UUID{uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())}
+	b := newReplayBuffers()
+	nop := func(*session, error) {}
+
+	var sessions []*session
+	for _, u := range users {
+		for i := 0; i < 2; i++ {
+			s := newTestSession(u)
+			s.send = make(chan *rawMessage, writers*events)
+			b.attach(s)
+			sessions = append(sessions, s)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < events; j++ {
+				b.write("A", []byte(`{}`), TargetAll(), nop)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// セッション毎にシーケンス番号が連続して昇順に届く
+	for _, s := range sessions {
+		msgs := readMessages(t, s)
+		require.Len(t, msgs, writers*events)
+		for i := 1; i < len(msgs); i++ {
+			assert.Equal(t, msgs[i-1].Seq+1, msgs[i].Seq)
+		}
+	}
+}
@@ -15,6 +15,10 @@ type Session interface {
 	UserID() uuid.UUID
 	// State このセッションのチャンネル閲覧状態
 	ViewState() (channelID uuid.UUID, state viewer.State)
+	// ClientKey このセッションのクライアント識別キー
+	ClientKey() string
+	// IsSubscribingChannel このセッションが指定したチャンネルを購読しているかどうか
+	IsSubscribingChannel(channelID uuid.UUID) bool
 }
 
 type session struct {
@@ -24,10 +28,17 @@ type session struct {
 	streamer  *Streamer
 	send      chan *rawMessage
 	userID    uuid.UUID
+	clientKey string
+	// encoding 送信するメッセージのエンコーディング
+	encoding  encoding
 	viewState struct {
 		channelID uuid.UUID
 		state     viewer.State
 	}
+	// channels 購読しているチャンネル
+	channels map[uuid.UUID]struct{}
+	// firstSeq 接続後に最初に送信したイベントのシーケンス番号. userReplayのロック下で読み書きします
+	firstSeq uint64
 	sync.RWMutex
 }
 
@@ -97,6 +108,8 @@ func (s *session) writeMessage(msg *rawMessage) error {
 
 func (s *session) write(messageType int, data []byte) error {
 	_ = s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	// permessage-deflateがネゴシエーションされていない場合は無視される
+	s.conn.EnableWriteCompression(len(data) >= minCompressionSize)
 	return s.conn.WriteMessage(messageType, data)
 }
 
@@ -124,9 +137,51 @@ func (s *session) UserID() uuid.UUID {
 	return s.userID
 }
 
+// ClientKey implements Session interface.
+func (s *session) ClientKey() string {
+	return s.clientKey
+}
+
 // ViewState implements Session interface.
 func (s *session) ViewState() (uuid.UUID, viewer.State) {
 	s.RLock()
 	defer s.RUnlock()
 	return s.viewState.channelID, s.viewState.state
 }
+
+// IsSubscribingChannel implements Session interface.
+func (s *session) IsSubscribingChannel(channelID uuid.UUID) bool {
+	s.RLock()
+	defer s.RUnlock()
+	_, ok := s.channels[channelID]
+	return ok
+}
+
+// resetViewState チャンネル閲覧状態を解除します
+func (s *session) resetViewState() {
+	s.Lock()
+	defer s.Unlock()
+	s.viewState.channelID = uuid.Nil
+	s.viewState.state = viewer.StateNone
+}
+
+// subscribeChannel 指定したチャンネルを購読します. 購読数が上限に達している場合はfalseを返します
+func (s *session) subscribeChannel(channelID uuid.UUID) bool {
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.channels[channelID]; ok {
+		return true
+	}
+	if len(s.channels) >= maxSubscribingChannels {
+		return false
+	}
+	s.channels[channelID] = struct{}{}
+	return true
+}
+
+// unsubscribeChannel 指定したチャンネルの購読を解除します
+func (s *session) unsubscribeChannel(channelID uuid.UUID) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.channels, channelID)
+}
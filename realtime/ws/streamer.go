@@ -9,10 +9,12 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/traPtitech/traQ/event"
 	"github.com/traPtitech/traQ/realtime"
+	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router/extension"
 	"go.uber.org/zap"
 	"net/http"
 	"sync"
+	"time"
 )
 
 var (
@@ -31,8 +33,10 @@ var (
 type Streamer struct {
 	hub        *hub.Hub
 	realtime   *realtime.Service
+	repo       repository.Repository
 	logger     *zap.Logger
 	sessions   map[*session]struct{}
+	replay     *replayBuffers
 	register   chan *session
 	unregister chan *session
 	stop       chan struct{}
@@ -41,12 +45,14 @@ type Streamer struct {
 }
 
 // NewStreamer WebSocketストリーマーを生成し起動します
-func NewStreamer(hub *hub.Hub, realtime *realtime.Service, logger *zap.Logger) *Streamer {
+func NewStreamer(hub *hub.Hub, realtime *realtime.Service, repo repository.Repository, logger *zap.Logger) *Streamer {
 	h := &Streamer{
 		hub:        hub,
 		realtime:   realtime,
+		repo:       repo,
 		logger:     logger,
 		sessions:   make(map[*session]struct{}),
+		replay:     newReplayBuffers(),
 		register:   make(chan *session),
 		unregister: make(chan *session),
 		stop:       make(chan struct{}),
@@ -54,10 +60,13 @@ func NewStreamer(hub *hub.Hub, realtime *realtime.Service, logger *zap.Logger) *
 	}
 
 	go h.run()
+	go h.channelEventLoop(hub.Subscribe(100, event.ChannelMemberRemoved, event.ChannelDeleted))
 	return h
 }
 
 func (s *Streamer) run() {
+	ticker := time.NewTicker(replayRetention / 2)
+	defer ticker.Stop()
 	for {
 		select {
 		case session := <-s.register:
@@ -72,6 +81,9 @@ func (s *Streamer) run() {
 				s.mu.Unlock()
 			}
 
+		case <-ticker.C:
+			s.replay.sweep()
+
 		case <-s.stop:
 			s.mu.Lock()
 			m := &rawMessage{
@@ -91,25 +103,21 @@ func (s *Streamer) run() {
 }
 
 // WriteMessage 指定したセッションにメッセージを書き込みます
+//
+// メッセージにはユーザー毎のシーケンス番号が付与され、切断後に再開したセッションに再送できるように記録されます
 func (s *Streamer) WriteMessage(t string, body interface{}, targetFunc TargetFunc) {
-	m := &rawMessage{
-		t:    websocket.TextMessage,
-		data: makeMessage(t, body).toJSON(),
+	b, err := json.Marshal(body)
+	if err != nil {
+		s.logger.Error("failed to encode message body", zap.Error(err), zap.String("type", t))
+		return
 	}
-	s.mu.RLock()
-	for session := range s.sessions {
-		if targetFunc(session) {
-			if err := session.writeMessage(m); err != nil {
-				if err == ErrBufferIsFull {
-					s.logger.Warn("Discard a message because the session's buffer is full.",
-						zap.String("type", t), zap.Any("body", body),
-						zap.Stringer("userID", session.userID))
-					continue
-				}
-			}
+	s.replay.write(t, b, targetFunc, func(session *session, err error) {
+		if err == ErrBufferIsFull {
+			s.logger.Warn("Discard a message because the session's buffer is full.",
+				zap.String("type", t), zap.Any("body", body),
+				zap.Stringer("userID", session.userID))
 		}
-	}
-	s.mu.RUnlock()
+	})
 }
 
 // ServeHTTP http.Handlerインターフェイスの実装
@@ -131,8 +139,12 @@ func (s *Streamer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		streamer: s,
 		send:     make(chan *rawMessage, messageBufferSize),
 		userID:   r.Context().Value(extension.CtxUserIDKey).(uuid.UUID),
+		channels: map[uuid.UUID]struct{}{},
+		encoding: encodingFromSubprotocol(conn.Subprotocol()),
 	}
+	session.clientKey, _ = r.Context().Value(extension.CtxClientKeyKey).(string)
 
+	s.replay.attach(session)
 	s.register <- session
 	wsConnectionCounter.Inc()
 	s.hub.Publish(hub.Message{
@@ -155,10 +167,34 @@ func (s *Streamer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		},
 	})
 	wsConnectionCounter.Dec()
+	s.replay.detach(session)
 	s.unregister <- session
 	session.close()
 }
 
+// channelEventLoop チャンネルにアクセスできなくなったセッションの購読を解除します
+func (s *Streamer) channelEventLoop(sub hub.Subscription) {
+	for ev := range sub.Receiver {
+		channelID := ev.Fields["channel_id"].(uuid.UUID)
+		var userID uuid.UUID
+		if ev.Topic() == event.ChannelMemberRemoved {
+			userID = ev.Fields["user_id"].(uuid.UUID)
+		}
+
+		s.mu.RLock()
+		for session := range s.sessions {
+			if userID == uuid.Nil || session.userID == userID {
+				session.unsubscribeChannel(channelID)
+				if cid, _ := session.ViewState(); cid == channelID {
+					session.resetViewState()
+					s.realtime.ViewerManager.RemoveViewer(session)
+				}
+			}
+		}
+		s.mu.RUnlock()
+	}
+}
+
 // IsClosed ストリーマーが停止しているかどうか
 func (s *Streamer) IsClosed() bool {
 	s.mu.RLock()
@@ -0,0 +1,67 @@
+package ws
+
+import (
+	"context"
+	stdjson "encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/router/extension"
+)
+
+func TestStreamer_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	st, _ := setupStreamer()
+	user := uuid.Must(uuid.NewV4())
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		st.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), extension.CtxUserIDKey, user)))
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	dial := func(t *testing.T, dialer *websocket.Dialer) *websocket.Conn {
+		t.Helper()
+		conn, _, err := dialer.Dial(url, nil)
+		require.NoError(t, err)
+		_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("unknown")))
+		return conn
+	}
+
+	t.Run("json", func(t *testing.T) {
+		conn := dial(t, &websocket.Dialer{})
+		defer conn.Close()
+		assert.Empty(t, conn.Subprotocol())
+
+		mt, b, err := conn.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, websocket.TextMessage, mt)
+		var msg message
+		require.NoError(t, stdjson.Unmarshal(b, &msg))
+		assert.Equal(t, "ERROR", msg.Type)
+	})
+
+	t.Run("msgpack", func(t *testing.T) {
+		conn := dial(t, &websocket.Dialer{
+			Subprotocols:      []string{subprotocolMessagePack},
+			EnableCompression: true,
+		})
+		defer conn.Close()
+		assert.Equal(t, subprotocolMessagePack, conn.Subprotocol())
+
+		mt, b, err := conn.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, websocket.BinaryMessage, mt)
+		expected, err := jsonToMessagePack(makeMessage("ERROR", "unknown command: unknown").toJSON())
+		require.NoError(t, err)
+		assert.Equal(t, expected, b)
+	})
+}
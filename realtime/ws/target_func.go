@@ -27,6 +27,13 @@ func TargetUsers(userID ...uuid.UUID) TargetFunc {
 	}
 }
 
+// TargetUserExceptClient 指定したユーザーの、指定したクライアント以外のセッションを対象に送信します
+func TargetUserExceptClient(userID uuid.UUID, clientKey string) TargetFunc {
+	return func(s Session) bool {
+		return s.UserID() == userID && (len(clientKey) == 0 || s.ClientKey() != clientKey)
+	}
+}
+
 // TargetUserSets 指定したユーザーを対象に送信します
 func TargetUserSets(sets ...set.UUIDSet) TargetFunc {
 	return func(s Session) bool {
@@ -46,3 +53,22 @@ func TargetChannelViewers(channelID uuid.UUID) TargetFunc {
 		return c == channelID
 	}
 }
+
+// TargetChannelSubscribers 指定したチャンネルを購読しているセッションを対象に送信します
+func TargetChannelSubscribers(channelID uuid.UUID) TargetFunc {
+	return func(s Session) bool {
+		return s.IsSubscribingChannel(channelID)
+	}
+}
+
+// TargetAny 指定したいずれかの送信対象関数の対象となるセッションを対象に送信します
+func TargetAny(targetFuncs ...TargetFunc) TargetFunc {
+	return func(s Session) bool {
+		for _, f := range targetFuncs {
+			if f(s) {
+				return true
+			}
+		}
+		return false
+	}
+}
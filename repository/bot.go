@@ -4,16 +4,22 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/traPtitech/traQ/model"
 	"gopkg.in/guregu/null.v3"
+	"time"
 )
 
 // UpdateBotArgs Bot情報更新引数
 type UpdateBotArgs struct {
 	DisplayName     null.String
 	Description     null.String
+	HelpText        null.String
+	Homepage        null.String
+	PrivacyNote     null.String
 	WebhookURL      null.String
+	Mode            null.String
 	Privileged      null.Bool
 	CreatorID       uuid.NullUUID
 	SubscribeEvents model.BotEvents
+	Permissions     model.BotPermissions
 }
 
 // BotsQuery Bot情報取得用クエリ
@@ -23,6 +29,7 @@ type BotsQuery struct {
 	IsCMemberOf     uuid.NullUUID
 	SubscribeEvents model.BotEvents
 	Creator         uuid.NullUUID
+	Word            null.String
 }
 
 // Privileged 特権Botである
@@ -55,6 +62,12 @@ func (q BotsQuery) CMemberOf(channelID uuid.UUID) BotsQuery {
 	return q
 }
 
+// Search BotユーザーID・表示名・説明にwordを含む
+func (q BotsQuery) Search(word string) BotsQuery {
+	q.Word = null.StringFrom(word)
+	return q
+}
+
 // Subscribe eventsを購読している
 func (q BotsQuery) Subscribe(events ...model.BotEvent) BotsQuery {
 	if q.SubscribeEvents == nil {
@@ -72,13 +85,17 @@ func (q BotsQuery) Subscribe(events ...model.BotEvent) BotsQuery {
 type BotRepository interface {
 	// CreateBot Botを作成します
 	//
+	// webhookURLはmodeがmodel.BotModeHTTPの場合のみ必須です。
 	// 成功した場合、Botとnilを返します。
 	// 引数に問題がある場合、ArgumentErrorを返します。
 	// nameが既に使われている場合、ErrAlreadyExistsを返します。
 	// DBによるエラーを返すことがあります。
-	CreateBot(name, displayName, description string, creatorID uuid.UUID, webhookURL string) (*model.Bot, error)
+	CreateBot(name, displayName, description string, creatorID uuid.UUID, mode model.BotMode, webhookURL string) (*model.Bot, error)
 	// UpdateBot 指定したBotの情報を更新します
 	//
+	// WebhookURL又はModeを変更した場合、Botは一時停止状態になります。
+	// Permissionsを変更した場合、各チャンネルで許可されている権限は新しいPermissionsに含まれるものに制限され、
+	// Botはチャンネル毎の権限の制限を受けるようになります(空のPermissionsを指定しても解除されません)。
 	// 成功した場合、nilを返します。
 	// 存在しないBotを指定した場合、ErrNotFoundを返します。
 	// 更新内容に問題がある場合、ArgumentErrorを返します。
@@ -131,7 +148,9 @@ type BotRepository interface {
 	DeleteBot(id uuid.UUID) error
 	// AddBotToChannel 指定したBotをチャンネルに参加させます
 	//
+	// 新たに参加した場合、チャンネルで許可されている権限は空になります。
 	// 成功した場合、nilを返します。
+	// 存在しないBotを指定した場合、ErrNotFoundを返します。
 	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
 	// DBによるエラーを返すことがあります。
 	AddBotToChannel(botID, channelID uuid.UUID) error
@@ -141,21 +160,66 @@ type BotRepository interface {
 	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
 	// DBによるエラーを返すことがあります。
 	RemoveBotFromChannel(botID, channelID uuid.UUID) error
+	// GetBotJoinChannel 指定したBotの指定したチャンネルへの参加情報を取得します
+	//
+	// 成功した場合、参加情報とnilを返します。
+	// Botがチャンネルに参加していない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetBotJoinChannel(botID, channelID uuid.UUID) (*model.BotJoinChannel, error)
+	// SetBotChannelPermissions 指定したBotが指定したチャンネルで許可されている権限を設定します
+	//
+	// 成功した場合、nilを返します。
+	// Botがチャンネルに参加していない場合、ErrNotFoundを返します。
+	// permissionsにBotが要求していない権限が含まれている場合、ArgumentErrorを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	SetBotChannelPermissions(botID, channelID uuid.UUID, permissions model.BotPermissions) error
 	// GetParticipatingChannelIDsByBot 指定したBotが参加しているチャンネルのIDを取得します
 	//
 	// 成功した場合、チャンネルUUIDの配列とnilを返します。
 	// 存在しないBotを指定した場合、空配列とnilを返します。
 	// DBによるエラーを返すことがあります。
 	GetParticipatingChannelIDsByBot(botID uuid.UUID) ([]uuid.UUID, error)
+	// GetBotInstallCounts 指定したBotが参加しているチャンネルの数を取得します
+	//
+	// 成功した場合、BotのUUIDをキーとしたチャンネル数のマップとnilを返します。どのチャンネルにも参加していないBotはマップに含まれません。
+	// DBによるエラーを返すことがあります。
+	GetBotInstallCounts(botIDs []uuid.UUID) (map[uuid.UUID]int, error)
 	// WriteBotEventLog Botイベントログを書き込みます
 	//
+	// 同じRequestIDのログが既に存在する場合は上書きします。
 	// 成功した場合、nilを返します。
 	// DBによるエラーを返すことがあります。
 	WriteBotEventLog(log *model.BotEventLog) error
+	// GetBotEventLog 指定したリクエストIDのBotイベントログを取得します
+	//
+	// 成功した場合、イベントログとnilを返します。
+	// 存在しなかった場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetBotEventLog(requestID uuid.UUID) (*model.BotEventLog, error)
 	// GetBotEventLogs 指定したBotのイベントログを取得します
 	//
+	// deadLetterOnlyがtrueの場合、再送を諦めたイベントのログのみを取得します。
 	// 成功した場合、イベントログの配列とnilを返します。負のoffset, limitは無視されます。
 	// 存在しないBotを指定した場合、空配列とnilを返します。
 	// DBによるエラーを返すことがあります。
-	GetBotEventLogs(botID uuid.UUID, limit, offset int) ([]*model.BotEventLog, error)
+	GetBotEventLogs(botID uuid.UUID, deadLetterOnly bool, limit, offset int) ([]*model.BotEventLog, error)
+	// GetRetryableBotEventLogs 再送予定日時がbefore以前のBotイベントログを、再送予定日時の昇順で取得します
+	//
+	// 成功した場合、イベントログの配列とnilを返します。負のlimitは無視されます。
+	// DBによるエラーを返すことがあります。
+	GetRetryableBotEventLogs(before time.Time, limit int) ([]*model.BotEventLog, error)
+	// ClaimBotEventRetry 再送予定日時がatのBotイベントの再送予定日時をleaseUntilに変更し、再送する権利を獲得します
+	//
+	// 他のインスタンスが既に獲得していた場合など、再送予定日時がatでない場合は変更しません。
+	// 獲得できた場合、trueとnilを返します。獲得できなかった場合、falseとnilを返します。
+	// DBによるエラーを返すことがあります。
+	ClaimBotEventRetry(requestID uuid.UUID, at, leaseUntil time.Time) (bool, error)
+	// ScheduleBotEventRetry 指定したリクエストIDのBotイベントの再送をatに予約します
+	//
+	// 再送を諦めたイベントも再送の対象に戻ります。
+	// 成功した場合、nilを返します。
+	// 存在しなかった場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	ScheduleBotEventRetry(requestID uuid.UUID, at time.Time) error
 }
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+)
+
+// BotCommandRepository Botコマンドリポジトリ
+type BotCommandRepository interface {
+	// SetBotCommands 指定したBotのコマンドを全てcommandsに置き換えます
+	//
+	// 成功した場合、nilを返します。
+	// コマンドの定義に問題がある場合や、コマンド名が重複している場合、ArgumentErrorを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	SetBotCommands(botID uuid.UUID, commands []*model.BotCommand) error
+	// GetBotCommands 指定したBotのコマンドを、BotID・コマンド名の昇順で全て取得します
+	//
+	// 成功した場合、コマンドの配列とnilを返します。
+	// 存在しないBotを指定した場合、空配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetBotCommands(botIDs []uuid.UUID) ([]*model.BotCommand, error)
+}
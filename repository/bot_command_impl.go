@@ -0,0 +1,51 @@
+package repository
+
+import (
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/traPtitech/traQ/model"
+)
+
+// SetBotCommands implements BotCommandRepository interface.
+func (repo *GormRepository) SetBotCommands(botID uuid.UUID, commands []*model.BotCommand) error {
+	if botID == uuid.Nil {
+		return ErrNilID
+	}
+	names := make(map[string]bool, len(commands))
+	for _, c := range commands {
+		if err := vd.Validate(c, vd.NotNil); err != nil {
+			return ArgError("commands", err.Error())
+		}
+		if names[c.Name] {
+			return ArgError("commands", "command "+c.Name+" is duplicated")
+		}
+		names[c.Name] = true
+	}
+
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.BotCommand{}, &model.BotCommand{BotID: botID}).Error; err != nil {
+			return err
+		}
+		for _, c := range commands {
+			c.BotID = botID
+			if err := tx.Create(c).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetBotCommands implements BotCommandRepository interface.
+func (repo *GormRepository) GetBotCommands(botIDs []uuid.UUID) ([]*model.BotCommand, error) {
+	commands := make([]*model.BotCommand, 0)
+	if len(botIDs) == 0 {
+		return commands, nil
+	}
+	return commands, repo.db.
+		Where("bot_id IN (?)", botIDs).
+		Order("bot_id, name").
+		Find(&commands).
+		Error
+}
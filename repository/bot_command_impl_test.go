@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils"
+)
+
+func TestRepositoryImpl_SetBotCommands(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user := setupWithUser(t, common)
+
+	b, err := repo.CreateBot(utils.RandAlphabetAndNumberString(16), "bot", "", user.GetID(), model.BotModeWebSocket, "")
+	require.NoError(err)
+
+	assert.EqualError(repo.SetBotCommands(uuid.Nil, nil), ErrNilID.Error())
+	assert.True(IsArgError(repo.SetBotCommands(b.ID, []*model.BotCommand{{Name: "Invalid"}})))
+	assert.True(IsArgError(repo.SetBotCommands(b.ID, []*model.BotCommand{{Name: "a"}, {Name: "a"}})))
+
+	if assert.NoError(repo.SetBotCommands(b.ID, []*model.BotCommand{
+		{Name: "deploy", Arguments: model.BotCommandArguments{{Name: "env", Type: model.BotCommandArgumentString, Required: true}}},
+		{Name: "ping", HideMessage: true},
+	})) {
+		assert.Equal(2, count(t, getDB(repo).Model(&model.BotCommand{}).Where(&model.BotCommand{BotID: b.ID})))
+	}
+
+	// 置き換え
+	if assert.NoError(repo.SetBotCommands(b.ID, []*model.BotCommand{{Name: "status"}})) {
+		cmds, err := repo.GetBotCommands([]uuid.UUID{b.ID})
+		require.NoError(err)
+		if assert.Len(cmds, 1) {
+			assert.Equal("status", cmds[0].Name)
+		}
+	}
+
+	if assert.NoError(repo.SetBotCommands(b.ID, nil)) {
+		assert.Equal(0, count(t, getDB(repo).Model(&model.BotCommand{}).Where(&model.BotCommand{BotID: b.ID})))
+	}
+}
+
+func TestRepositoryImpl_GetBotCommands(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user := setupWithUser(t, common)
+
+	b1, err := repo.CreateBot(utils.RandAlphabetAndNumberString(16), "bot", "", user.GetID(), model.BotModeWebSocket, "")
+	require.NoError(err)
+	b2, err := repo.CreateBot(utils.RandAlphabetAndNumberString(16), "bot", "", user.GetID(), model.BotModeWebSocket, "")
+	require.NoError(err)
+	require.NoError(repo.SetBotCommands(b1.ID, []*model.BotCommand{
+		{Name: "b", Arguments: model.BotCommandArguments{{Name: "n", Type: model.BotCommandArgumentInteger}}},
+		{Name: "a"},
+	}))
+	require.NoError(repo.SetBotCommands(b2.ID, []*model.BotCommand{{Name: "c"}}))
+
+	cmds, err := repo.GetBotCommands(nil)
+	if assert.NoError(err) {
+		assert.Len(cmds, 0)
+	}
+
+	cmds, err = repo.GetBotCommands([]uuid.UUID{b1.ID})
+	if assert.NoError(err) && assert.Len(cmds, 2) {
+		assert.Equal("a", cmds[0].Name)
+		assert.Equal("b", cmds[1].Name)
+		if assert.Len(cmds[1].Arguments, 1) {
+			assert.Equal(model.BotCommandArgumentInteger, cmds[1].Arguments[0].Type)
+		}
+	}
+
+	cmds, err = repo.GetBotCommands([]uuid.UUID{b1.ID, b2.ID})
+	if assert.NoError(err) {
+		assert.Len(cmds, 3)
+	}
+}
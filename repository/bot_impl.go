@@ -18,15 +18,20 @@ import (
 )
 
 // CreateBot implements BotRepository interface.
-func (repo *GormRepository) CreateBot(name, displayName, description string, creatorID uuid.UUID, webhookURL string) (*model.Bot, error) {
+func (repo *GormRepository) CreateBot(name, displayName, description string, creatorID uuid.UUID, mode model.BotMode, webhookURL string) (*model.Bot, error) {
 	if err := vd.Validate(name, validator.BotUserNameRuleRequired...); err != nil {
 		return nil, ArgError("name", "invalid name")
 	}
 	if len(displayName) == 0 || utf8.RuneCountInString(displayName) > 32 {
 		return nil, ArgError("displayName", "DisplayName must be non-empty and shorter than 33 characters")
 	}
-	if err := vd.Validate(webhookURL, vd.Required, is.URL, validator.NotInternalURL); err != nil || !strings.HasPrefix(webhookURL, "http") {
-		return nil, ArgError("webhookURL", "invalid webhookURL")
+	if !mode.Valid() {
+		return nil, ArgError("mode", "invalid mode")
+	}
+	if mode == model.BotModeHTTP || len(webhookURL) > 0 {
+		if err := vd.Validate(webhookURL, vd.Required, is.URL, validator.NotInternalURL); err != nil || !strings.HasPrefix(webhookURL, "http") {
+			return nil, ArgError("webhookURL", "invalid webhookURL")
+		}
 	}
 	if creatorID == uuid.Nil {
 		return nil, ArgError("creatorID", "CreatorID is required")
@@ -60,9 +65,12 @@ func (repo *GormRepository) CreateBot(name, displayName, description string, cre
 		BotUserID:         uid,
 		Description:       description,
 		VerificationToken: utils.RandAlphabetAndNumberString(30),
+		SigningSecret:     utils.RandAlphabetAndNumberString(64),
 		PostURL:           webhookURL,
+		Mode:              mode,
 		AccessTokenID:     tid,
 		SubscribeEvents:   model.BotEvents{},
+		Permissions:       model.BotPermissions{},
 		Privileged:        false,
 		State:             model.BotInactive,
 		BotCode:           utils.RandAlphabetAndNumberString(30),
@@ -127,6 +135,21 @@ func (repo *GormRepository) UpdateBot(id uuid.UUID, args UpdateBotArgs) error {
 		if args.Description.Valid {
 			changes["description"] = args.Description.String
 		}
+		if args.HelpText.Valid {
+			changes["help_text"] = args.HelpText.String
+		}
+		if args.Homepage.Valid {
+			h := args.Homepage.String
+			if len(h) > 0 {
+				if err := vd.Validate(h, is.URL); err != nil || !strings.HasPrefix(h, "http") {
+					return ArgError("args.Homepage", "invalid homepage")
+				}
+			}
+			changes["homepage"] = h
+		}
+		if args.PrivacyNote.Valid {
+			changes["privacy_note"] = args.PrivacyNote.String
+		}
 		if args.Privileged.Valid {
 			changes["privileged"] = args.Privileged.Bool
 		}
@@ -138,6 +161,23 @@ func (repo *GormRepository) UpdateBot(id uuid.UUID, args UpdateBotArgs) error {
 			changes["post_url"] = w
 			changes["state"] = model.BotPaused
 		}
+		if args.Mode.Valid {
+			mode := model.BotMode(args.Mode.String)
+			if !mode.Valid() {
+				return ArgError("args.Mode", "invalid mode")
+			}
+			postURL := b.PostURL
+			if args.WebhookURL.Valid {
+				postURL = args.WebhookURL.String
+			}
+			if mode == model.BotModeHTTP && len(postURL) == 0 {
+				return ArgError("args.Mode", "webhookURL is required for HTTP mode")
+			}
+			if mode != b.Mode {
+				changes["mode"] = mode
+				changes["state"] = model.BotPaused
+			}
+		}
 		if args.CreatorID.Valid {
 			// 作成者検証
 			user, err := getUser(tx, false, "id = ?", args.CreatorID.UUID)
@@ -156,6 +196,27 @@ func (repo *GormRepository) UpdateBot(id uuid.UUID, args UpdateBotArgs) error {
 		if args.SubscribeEvents != nil {
 			changes["subscribe_events"] = args.SubscribeEvents
 		}
+		if args.Permissions != nil {
+			if err := args.Permissions.Validate(); err != nil {
+				return ArgError("args.Permissions", "invalid permissions")
+			}
+			changes["permissions"] = args.Permissions
+			changes["channel_scoped"] = true
+
+			// 各チャンネルで許可されている権限を要求している権限に制限
+			var joins []*model.BotJoinChannel
+			if err := tx.Where(&model.BotJoinChannel{BotID: id}).Find(&joins).Error; err != nil {
+				return err
+			}
+			for _, j := range joins {
+				if j.Permissions.IsSubsetOf(args.Permissions) {
+					continue
+				}
+				if err := tx.Model(j).Update("permissions", j.Permissions.Intersect(args.Permissions)).Error; err != nil {
+					return err
+				}
+			}
+		}
 
 		if len(changes) > 0 {
 			if err := tx.Model(&b).Updates(changes).Error; err != nil {
@@ -202,7 +263,7 @@ func (repo *GormRepository) UpdateBot(id uuid.UUID, args UpdateBotArgs) error {
 // GetBots implements BotRepository interface.
 func (repo *GormRepository) GetBots(query BotsQuery) ([]*model.Bot, error) {
 	bots := make([]*model.Bot, 0)
-	tx := repo.db.Table("bots")
+	tx := repo.db.Table("bots").Select("bots.*")
 
 	if query.IsPrivileged.Valid {
 		tx = tx.Where("bots.privileged = ?", query.IsPrivileged.Bool)
@@ -220,6 +281,12 @@ func (repo *GormRepository) GetBots(query BotsQuery) ([]*model.Bot, error) {
 	if query.IsCMemberOf.Valid {
 		tx = tx.Joins("INNER JOIN bot_join_channels ON bot_join_channels.bot_id = bots.id AND bot_join_channels.channel_id = ?", query.IsCMemberOf.UUID)
 	}
+	if query.Word.Valid && len(query.Word.String) > 0 {
+		w := "%" + likeEscaper.Replace(query.Word.String) + "%"
+		tx = tx.
+			Joins("INNER JOIN users ON users.id = bots.bot_user_id").
+			Where("users.name LIKE ? OR users.display_name LIKE ? OR bots.description LIKE ?", w, w, w)
+	}
 	if len(query.SubscribeEvents) == 0 {
 		return bots, tx.Find(&bots).Error
 	}
@@ -320,6 +387,7 @@ func (repo *GormRepository) ReissueBotTokens(id uuid.UUID) (*model.Bot, error) {
 		bot.State = model.BotPaused
 		bot.BotCode = utils.RandAlphabetAndNumberString(30)
 		bot.VerificationToken = utils.RandAlphabetAndNumberString(30)
+		bot.SigningSecret = utils.RandAlphabetAndNumberString(64)
 
 		if err := tx.Delete(&model.OAuth2Token{ID: bot.AccessTokenID}).Error; err != nil {
 			return err
@@ -397,9 +465,26 @@ func (repo *GormRepository) AddBotToChannel(botID, channelID uuid.UUID) error {
 	if botID == uuid.Nil || channelID == uuid.Nil {
 		return ErrNilID
 	}
-	var b model.BotJoinChannel
-	result := repo.db.FirstOrCreate(&b, &model.BotJoinChannel{BotID: botID, ChannelID: channelID})
-	if result.RowsAffected > 0 {
+	var (
+		b      model.Bot
+		joined bool
+	)
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&b, &model.Bot{ID: botID}).Error; err != nil {
+			return convertError(err)
+		}
+		var j model.BotJoinChannel
+		result := tx.
+			Where(&model.BotJoinChannel{BotID: botID, ChannelID: channelID}).
+			Attrs(model.BotJoinChannel{Permissions: model.BotPermissions{}}).
+			FirstOrCreate(&j)
+		joined = result.RowsAffected > 0
+		return result.Error
+	})
+	if err != nil {
+		return err
+	}
+	if joined {
 		repo.hub.Publish(hub.Message{
 			Name: event.BotJoined,
 			Fields: hub.Fields{
@@ -408,7 +493,7 @@ func (repo *GormRepository) AddBotToChannel(botID, channelID uuid.UUID) error {
 			},
 		})
 	}
-	return result.Error
+	return nil
 }
 
 // RemoveBotFromChannel implements BotRepository interface.
@@ -429,6 +514,55 @@ func (repo *GormRepository) RemoveBotFromChannel(botID, channelID uuid.UUID) err
 	return result.Error
 }
 
+// GetBotJoinChannel implements BotRepository interface.
+func (repo *GormRepository) GetBotJoinChannel(botID, channelID uuid.UUID) (*model.BotJoinChannel, error) {
+	if botID == uuid.Nil || channelID == uuid.Nil {
+		return nil, ErrNotFound
+	}
+	var j model.BotJoinChannel
+	if err := repo.db.First(&j, &model.BotJoinChannel{BotID: botID, ChannelID: channelID}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &j, nil
+}
+
+// SetBotChannelPermissions implements BotRepository interface.
+func (repo *GormRepository) SetBotChannelPermissions(botID, channelID uuid.UUID, permissions model.BotPermissions) error {
+	if botID == uuid.Nil || channelID == uuid.Nil {
+		return ErrNilID
+	}
+	if permissions == nil {
+		permissions = model.BotPermissions{}
+	}
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		var b model.Bot
+		if err := tx.First(&b, &model.Bot{ID: botID}).Error; err != nil {
+			return convertError(err)
+		}
+		if !permissions.IsSubsetOf(b.Permissions) {
+			return ArgError("permissions", "permissions must be requested by the bot")
+		}
+		result := tx.
+			Model(&model.BotJoinChannel{}).
+			Where(&model.BotJoinChannel{BotID: botID, ChannelID: channelID}).
+			Update("permissions", permissions)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// 権限が変化していない場合も含まれるので存在確認
+			var count int
+			if err := tx.Model(&model.BotJoinChannel{}).Where(&model.BotJoinChannel{BotID: botID, ChannelID: channelID}).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return ErrNotFound
+			}
+		}
+		return nil
+	})
+}
+
 // GetParticipatingChannelIDsByBot implements BotRepository interface.
 func (repo *GormRepository) GetParticipatingChannelIDsByBot(botID uuid.UUID) ([]uuid.UUID, error) {
 	channels := make([]uuid.UUID, 0)
@@ -442,23 +576,112 @@ func (repo *GormRepository) GetParticipatingChannelIDsByBot(botID uuid.UUID) ([]
 		Error
 }
 
+// GetBotInstallCounts implements BotRepository interface.
+func (repo *GormRepository) GetBotInstallCounts(botIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int, len(botIDs))
+	if len(botIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		BotID uuid.UUID
+		Count int
+	}
+	err := repo.db.
+		Model(&model.BotJoinChannel{}).
+		Select("bot_id, COUNT(*) AS count").
+		Where("bot_id IN (?)", botIDs).
+		Group("bot_id").
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		counts[r.BotID] = r.Count
+	}
+	return counts, nil
+}
+
 // WriteBotEventLog implements BotRepository interface.
 func (repo *GormRepository) WriteBotEventLog(log *model.BotEventLog) error {
 	if log == nil || log.RequestID == uuid.Nil {
 		return nil
 	}
-	return repo.db.Create(log).Error
+	return repo.db.Save(log).Error
+}
+
+// GetBotEventLog implements BotRepository interface.
+func (repo *GormRepository) GetBotEventLog(requestID uuid.UUID) (*model.BotEventLog, error) {
+	if requestID == uuid.Nil {
+		return nil, ErrNotFound
+	}
+	var l model.BotEventLog
+	if err := repo.db.Take(&l, &model.BotEventLog{RequestID: requestID}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &l, nil
 }
 
 // GetBotEventLogs implements BotRepository interface.
-func (repo *GormRepository) GetBotEventLogs(botID uuid.UUID, limit, offset int) ([]*model.BotEventLog, error) {
+func (repo *GormRepository) GetBotEventLogs(botID uuid.UUID, deadLetterOnly bool, limit, offset int) ([]*model.BotEventLog, error) {
 	logs := make([]*model.BotEventLog, 0)
 	if botID == uuid.Nil {
 		return logs, nil
 	}
-	return logs, repo.db.Where(&model.BotEventLog{BotID: botID}).
+	tx := repo.db.Where(&model.BotEventLog{BotID: botID})
+	if deadLetterOnly {
+		tx = tx.Where("dead_letter = ?", true)
+	}
+	return logs, tx.
 		Order("date_time DESC").
 		Scopes(limitAndOffset(limit, offset)).
 		Find(&logs).
 		Error
 }
+
+// GetRetryableBotEventLogs implements BotRepository interface.
+func (repo *GormRepository) GetRetryableBotEventLogs(before time.Time, limit int) ([]*model.BotEventLog, error) {
+	logs := make([]*model.BotEventLog, 0)
+	return logs, repo.db.
+		Where("next_retry_at IS NOT NULL AND next_retry_at <= ?", before).
+		Order("next_retry_at").
+		Scopes(limitAndOffset(limit, 0)).
+		Find(&logs).
+		Error
+}
+
+// ClaimBotEventRetry implements BotRepository interface.
+func (repo *GormRepository) ClaimBotEventRetry(requestID uuid.UUID, at, leaseUntil time.Time) (bool, error) {
+	if requestID == uuid.Nil {
+		return false, nil
+	}
+	result := repo.db.
+		Model(&model.BotEventLog{}).
+		Where("request_id = ? AND next_retry_at = ?", requestID, at).
+		Update("next_retry_at", leaseUntil)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ScheduleBotEventRetry implements BotRepository interface.
+func (repo *GormRepository) ScheduleBotEventRetry(requestID uuid.UUID, at time.Time) error {
+	if requestID == uuid.Nil {
+		return ErrNotFound
+	}
+	result := repo.db.
+		Model(&model.BotEventLog{}).
+		Where(&model.BotEventLog{RequestID: requestID}).
+		Updates(map[string]interface{}{
+			"next_retry_at": at,
+			"dead_letter":   false,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/rbac/permission"
+	"github.com/traPtitech/traQ/utils"
+)
+
+func TestRepositoryImpl_SetBotChannelPermissions(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	b, err := repo.CreateBot(utils.RandAlphabetAndNumberString(16), "bot", "", user.GetID(), model.BotModeWebSocket, "")
+	require.NoError(err)
+	require.NoError(repo.UpdateBot(b.ID, UpdateBotArgs{Permissions: model.BotPermissions{permission.GetMessage: true, permission.PostMessage: true}}))
+
+	assert.EqualError(repo.SetBotChannelPermissions(uuid.Nil, channel.ID, nil), ErrNilID.Error())
+	assert.EqualError(repo.SetBotChannelPermissions(b.ID, channel.ID, nil), ErrNotFound.Error())
+
+	// 参加時は権限が許可されない
+	require.NoError(repo.AddBotToChannel(b.ID, channel.ID))
+	j, err := repo.GetBotJoinChannel(b.ID, channel.ID)
+	if assert.NoError(err) {
+		assert.Len(j.Permissions, 0)
+	}
+
+	assert.True(IsArgError(repo.SetBotChannelPermissions(b.ID, channel.ID, model.BotPermissions{permission.DeleteMessage: true})))
+
+	if assert.NoError(repo.SetBotChannelPermissions(b.ID, channel.ID, model.BotPermissions{permission.GetMessage: true, permission.PostMessage: true})) {
+		j, err := repo.GetBotJoinChannel(b.ID, channel.ID)
+		if assert.NoError(err) {
+			assert.True(j.Permissions.Contains(permission.GetMessage))
+			assert.True(j.Permissions.Contains(permission.PostMessage))
+		}
+	}
+	if assert.NoError(repo.SetBotChannelPermissions(b.ID, channel.ID, model.BotPermissions{permission.GetMessage: true})) {
+		j, err := repo.GetBotJoinChannel(b.ID, channel.ID)
+		if assert.NoError(err) {
+			assert.True(j.Permissions.Contains(permission.GetMessage))
+			assert.False(j.Permissions.Contains(permission.PostMessage))
+		}
+	}
+
+	// 再度参加しても許可されている権限は変わらない
+	require.NoError(repo.AddBotToChannel(b.ID, channel.ID))
+	j, err = repo.GetBotJoinChannel(b.ID, channel.ID)
+	if assert.NoError(err) {
+		assert.True(j.Permissions.Contains(permission.GetMessage))
+	}
+
+	// 要求する権限を減らすと許可されている権限も減る
+	require.NoError(repo.UpdateBot(b.ID, UpdateBotArgs{Permissions: model.BotPermissions{permission.PostMessage: true}}))
+	j, err = repo.GetBotJoinChannel(b.ID, channel.ID)
+	if assert.NoError(err) {
+		assert.Len(j.Permissions, 0)
+	}
+
+	assert.True(IsArgError(repo.UpdateBot(b.ID, UpdateBotArgs{Permissions: model.BotPermissions{permission.CreateChannel: true}})))
+
+	// 要求する権限を空にしてもチャンネル毎の権限の制限は解除されない
+	require.NoError(repo.UpdateBot(b.ID, UpdateBotArgs{Permissions: model.BotPermissions{}}))
+	b, err = repo.GetBotByID(b.ID)
+	if assert.NoError(err) {
+		assert.True(b.ChannelScoped)
+		assert.Len(b.Permissions, 0)
+	}
+}
+
+func TestRepositoryImpl_GetBotInstallCounts(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user := setupWithUser(t, common)
+
+	b1, err := repo.CreateBot(utils.RandAlphabetAndNumberString(16), "bot", "", user.GetID(), model.BotModeWebSocket, "")
+	require.NoError(err)
+	b2, err := repo.CreateBot(utils.RandAlphabetAndNumberString(16), "bot", "", user.GetID(), model.BotModeWebSocket, "")
+	require.NoError(err)
+	for i := 0; i < 2; i++ {
+		require.NoError(repo.AddBotToChannel(b1.ID, mustMakeChannel(t, repo, random).ID))
+	}
+
+	counts, err := repo.GetBotInstallCounts([]uuid.UUID{b1.ID, b2.ID})
+	if assert.NoError(err) {
+		assert.Equal(2, counts[b1.ID])
+		assert.Equal(0, counts[b2.ID])
+	}
+
+	counts, err = repo.GetBotInstallCounts(nil)
+	if assert.NoError(err) {
+		assert.Len(counts, 0)
+	}
+}
+
+func TestRepositoryImpl_GetBots_Search(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user := setupWithUser(t, common)
+
+	word := utils.RandAlphabetAndNumberString(20)
+	_, err := repo.CreateBot(utils.RandAlphabetAndNumberString(16), "bot", "searchable "+word, user.GetID(), model.BotModeWebSocket, "")
+	require.NoError(err)
+	_, err = repo.CreateBot(utils.RandAlphabetAndNumberString(16), "bot", "other", user.GetID(), model.BotModeWebSocket, "")
+	require.NoError(err)
+
+	bots, err := repo.GetBots(BotsQuery{}.Search(word))
+	if assert.NoError(err) {
+		assert.Len(bots, 1)
+	}
+
+	// ワイルドカードはエスケープされる
+	bots, err = repo.GetBots(BotsQuery{}.Search("%" + word))
+	if assert.NoError(err) {
+		assert.Len(bots, 0)
+	}
+}
+
+func TestRepositoryImpl_BotEventRetry(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user := setupWithUser(t, common)
+
+	b, err := repo.CreateBot(utils.RandAlphabetAndNumberString(16), "bot", "", user.GetID(), model.BotModeWebSocket, "")
+	require.NoError(err)
+
+	now := time.Now().Truncate(time.Microsecond)
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+	due := &model.BotEventLog{RequestID: uuid.Must(uuid.NewV4()), BotID: b.ID, Event: model.BotEventPing, Attempts: 1, NextRetryAt: &past, DateTime: now}
+	later := &model.BotEventLog{RequestID: uuid.Must(uuid.NewV4()), BotID: b.ID, Event: model.BotEventPing, Attempts: 1, NextRetryAt: &future, DateTime: now}
+	dead := &model.BotEventLog{RequestID: uuid.Must(uuid.NewV4()), BotID: b.ID, Event: model.BotEventPing, Attempts: 8, DeadLetter: true, DateTime: now}
+	for _, l := range []*model.BotEventLog{due, later, dead} {
+		require.NoError(repo.WriteBotEventLog(l))
+	}
+
+	containsLog := func(logs []*model.BotEventLog, id uuid.UUID) bool {
+		for _, l := range logs {
+			if l.RequestID == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	logs, err := repo.GetRetryableBotEventLogs(now, 1000)
+	if assert.NoError(err) {
+		assert.True(containsLog(logs, due.RequestID))
+		assert.False(containsLog(logs, later.RequestID))
+		assert.False(containsLog(logs, dead.RequestID))
+	}
+
+	// 再送は1度だけ獲得できる
+	claimed, err := repo.ClaimBotEventRetry(due.RequestID, past, now.Add(time.Minute))
+	if assert.NoError(err) {
+		assert.True(claimed)
+	}
+	claimed, err = repo.ClaimBotEventRetry(due.RequestID, past, now.Add(time.Minute))
+	if assert.NoError(err) {
+		assert.False(claimed)
+	}
+	logs, err = repo.GetRetryableBotEventLogs(now, 1000)
+	if assert.NoError(err) {
+		assert.False(containsLog(logs, due.RequestID))
+	}
+
+	// 再送を諦めたイベントも再送の対象に戻せる
+	assert.EqualError(repo.ScheduleBotEventRetry(uuid.Nil, now), ErrNotFound.Error())
+	assert.EqualError(repo.ScheduleBotEventRetry(uuid.Must(uuid.NewV4()), now), ErrNotFound.Error())
+	if assert.NoError(repo.ScheduleBotEventRetry(dead.RequestID, past)) {
+		l, err := repo.GetBotEventLog(dead.RequestID)
+		if assert.NoError(err) {
+			assert.False(l.DeadLetter)
+			assert.NotNil(l.NextRetryAt)
+		}
+		logs, err := repo.GetRetryableBotEventLogs(now, 1000)
+		if assert.NoError(err) {
+			assert.True(containsLog(logs, dead.RequestID))
+		}
+	}
+}
@@ -11,6 +11,8 @@ import (
 var (
 	// ErrChannelDepthLimitation チャンネルの深さ制限を超えている
 	ErrChannelDepthLimitation = errors.New("channel depth limit exceeded")
+	// ErrChannelArchived チャンネルがアーカイブされている
+	ErrChannelArchived = errors.New("channel is archived")
 )
 
 // ChangeChannelSubscriptionArgs チャンネル購読変更引数
@@ -77,11 +79,24 @@ type ChannelRepository interface {
 	// 引数に問題がある場合、ArgumentErrorを返します。
 	// 既にNameが使われている場合、ErrAlreadyExistsを返します。
 	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
-	// 作成不可能な親チャンネルを指定した場合、ErrForbiddenを返します。
+	// 作成不可能な親チャンネル(アーカイブされたチャンネルを含む)を指定した場合、ErrForbiddenを返します。
 	// 階層数制限に到達する場合、ErrChannelDepthLimitationを返します。
 	// 存在しない親チャンネルを指定した場合、ErrNotFoundを返します。
 	// DBによるエラーを返すことがあります。
 	CreatePublicChannel(name string, parent, creatorID uuid.UUID) (*model.Channel, error)
+	// CreatePrivateChannel プライベートチャンネルを作成します
+	//
+	// 作成者は自動的にメンバーに追加されます。
+	// 親チャンネルを指定する場合、作成者がメンバーであるプライベートチャンネルである必要があります。
+	// 成功した場合、チャンネルとnilを返します。
+	// 引数に問題がある場合、存在しないユーザーをメンバーに指定した場合、ArgumentErrorを返します。
+	// 既にNameが使われている場合、ErrAlreadyExistsを返します。
+	// creatorIDにuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 作成不可能な親チャンネルを指定した場合、ErrForbiddenを返します。
+	// 階層数制限に到達する場合、ErrChannelDepthLimitationを返します。
+	// 存在しない親チャンネルを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	CreatePrivateChannel(name string, parent, creatorID uuid.UUID, members []uuid.UUID) (*model.Channel, error)
 	// UpdateChannel 指定したチャンネルの情報を変更します
 	//
 	// 成功した場合、nilを返します。
@@ -100,6 +115,23 @@ type ChannelRepository interface {
 	// 存在しないチャンネルを指定した場合、ErrNotFoundを返します。
 	// DBによるエラーを返すことがあります。
 	DeleteChannel(channelID uuid.UUID) error
+	// ArchiveChannel 指定したチャンネルとその子孫チャンネルを全てアーカイブします
+	//
+	// アーカイブされたチャンネルは読み取り専用になり、メッセージの投稿・編集やスタンプ・ピン留めの追加ができなくなります。
+	// 成功した、或いは既にアーカイブされていた場合、nilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 存在しないチャンネルを指定した場合、ErrNotFoundを返します。
+	// DMチャンネルを指定した場合、ErrForbiddenを返します。
+	// DBによるエラーを返すことがあります。
+	ArchiveChannel(channelID, userID uuid.UUID) error
+	// UnarchiveChannel 指定したチャンネルとその子孫チャンネルのアーカイブを全て解除します
+	//
+	// 成功した、或いは既にアーカイブされていなかった場合、nilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 存在しないチャンネルを指定した場合、ErrNotFoundを返します。
+	// DMチャンネル、或いは親チャンネルがアーカイブされているチャンネルを指定した場合、ErrForbiddenを返します。
+	// DBによるエラーを返すことがあります。
+	UnarchiveChannel(channelID, userID uuid.UUID) error
 	// GetChannel 指定したチャンネルを取得します
 	//
 	// 成功した場合、チャンネルとnilを返します。
@@ -153,6 +185,25 @@ type ChannelRepository interface {
 	// 存在しないチャンネルを指定した場合は空配列とnilを返します。
 	// DBによるエラーを返すことがあります。
 	GetPrivateChannelMemberIDs(channelID uuid.UUID) ([]uuid.UUID, error)
+	// AddPrivateChannelMembers 指定したプライベートチャンネルにメンバーを追加します
+	//
+	// 成功した場合、nilを返します。既にメンバーのユーザーは無視されます。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 存在しないチャンネルを指定した場合、ErrNotFoundを返します。
+	// パブリックチャンネル・DMチャンネルを指定した場合、ErrForbiddenを返します。
+	// 存在しないユーザーを指定した場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	AddPrivateChannelMembers(channelID, updaterID uuid.UUID, userIDs []uuid.UUID) error
+	// RemovePrivateChannelMembers 指定したプライベートチャンネルからメンバーを削除します
+	//
+	// 削除されたメンバーのチャンネルの購読設定・未読は削除され、BOTの場合はチャンネルから退出します。
+	// 成功した場合、nilを返します。メンバーでないユーザーは無視されます。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 存在しないチャンネルを指定した場合、ErrNotFoundを返します。
+	// パブリックチャンネル・DMチャンネルを指定した場合、ErrForbiddenを返します。
+	// メンバーが居なくなる場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	RemovePrivateChannelMembers(channelID, updaterID uuid.UUID, userIDs []uuid.UUID) error
 	// ChangeChannelSubscription ユーザーのチャンネルの購読を変更します
 	//
 	// 成功した場合、nilを返します。
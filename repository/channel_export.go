@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
+	"time"
+)
+
+// UpdateChannelExportArgs チャンネルエクスポート更新引数
+type UpdateChannelExportArgs struct {
+	Status model.ChannelExportStatus
+	FileID uuid.NullUUID
+	Error  null.String
+}
+
+// ChannelExportRepository チャンネルエクスポートリポジトリ
+type ChannelExportRepository interface {
+	// CreateChannelExport チャンネルエクスポートを生成待ち状態で作成します
+	//
+	// 成功した場合、チャンネルエクスポートとnilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	CreateChannelExport(userID, channelID uuid.UUID) (*model.ChannelExport, error)
+	// GetChannelExport 指定したチャンネルエクスポートを取得します
+	//
+	// 成功した場合、チャンネルエクスポートとnilを返します。
+	// 存在しなかった場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetChannelExport(id uuid.UUID) (*model.ChannelExport, error)
+	// GetChannelExportsByUserID 指定したユーザーのチャンネルエクスポートを作成日時の降順で全て取得します
+	//
+	// 成功した場合、チャンネルエクスポートの配列とnilを返します。
+	// 存在しないユーザーを指定した場合は空配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetChannelExportsByUserID(userID uuid.UUID) ([]*model.ChannelExport, error)
+	// GetUnfinishedChannelExports 生成待ちのチャンネルエクスポートと、staleBefore以降更新されていない生成中のチャンネルエクスポートを
+	// 作成日時の昇順で最大limit件取得します
+	//
+	// 成功した場合、チャンネルエクスポートの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetUnfinishedChannelExports(staleBefore time.Time, limit int) ([]*model.ChannelExport, error)
+	// ClaimChannelExport 指定したチャンネルエクスポートを生成中状態にして、生成する権利を獲得します
+	//
+	// 生成待ちのチャンネルエクスポートか、staleBefore以降更新されていない生成中のチャンネルエクスポートのみ獲得できます。
+	// 獲得できた場合、trueとnilを返します。他のワーカーが獲得済みの場合、falseとnilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	ClaimChannelExport(id uuid.UUID, staleBefore time.Time) (bool, error)
+	// TouchChannelExport 生成中のチャンネルエクスポートの更新日時を現在時刻にします
+	//
+	// 生成中であることを他のワーカーに示すために、生成中に定期的に呼び出します。
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// 生成中でないチャンネルエクスポートを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	TouchChannelExport(id uuid.UUID) error
+	// UpdateChannelExport 指定したチャンネルエクスポートの状態を変更します
+	//
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// 存在しないチャンネルエクスポートを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateChannelExport(id uuid.UUID, args UpdateChannelExportArgs) error
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"time"
+)
+
+// CreateChannelExport implements ChannelExportRepository interface.
+func (repo *GormRepository) CreateChannelExport(userID, channelID uuid.UUID) (*model.ChannelExport, error) {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return nil, ErrNilID
+	}
+	e := &model.ChannelExport{
+		ID:        uuid.Must(uuid.NewV4()),
+		UserID:    userID,
+		ChannelID: channelID,
+		Status:    model.ChannelExportPending,
+	}
+	if err := repo.db.Create(e).Error; err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// GetChannelExport implements ChannelExportRepository interface.
+func (repo *GormRepository) GetChannelExport(id uuid.UUID) (*model.ChannelExport, error) {
+	if id == uuid.Nil {
+		return nil, ErrNotFound
+	}
+	var e model.ChannelExport
+	if err := repo.db.Where(&model.ChannelExport{ID: id}).Take(&e).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &e, nil
+}
+
+// GetChannelExportsByUserID implements ChannelExportRepository interface.
+func (repo *GormRepository) GetChannelExportsByUserID(userID uuid.UUID) ([]*model.ChannelExport, error) {
+	exports := make([]*model.ChannelExport, 0)
+	if userID == uuid.Nil {
+		return exports, nil
+	}
+	return exports, repo.db.
+		Where(&model.ChannelExport{UserID: userID}).
+		Order("created_at DESC").
+		Find(&exports).
+		Error
+}
+
+// GetUnfinishedChannelExports implements ChannelExportRepository interface.
+func (repo *GormRepository) GetUnfinishedChannelExports(staleBefore time.Time, limit int) ([]*model.ChannelExport, error) {
+	exports := make([]*model.ChannelExport, 0)
+	return exports, repo.db.
+		Scopes(claimableChannelExports(staleBefore)).
+		Order("created_at").
+		Scopes(limitAndOffset(limit, 0)).
+		Find(&exports).
+		Error
+}
+
+// ClaimChannelExport implements ChannelExportRepository interface.
+func (repo *GormRepository) ClaimChannelExport(id uuid.UUID, staleBefore time.Time) (bool, error) {
+	if id == uuid.Nil {
+		return false, ErrNilID
+	}
+	var (
+		e       model.ChannelExport
+		claimed bool
+	)
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Model(&model.ChannelExport{}).
+			Where("id = ?", id).
+			Scopes(claimableChannelExports(staleBefore)).
+			Updates(map[string]interface{}{"status": model.ChannelExportRunning, "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		claimed = true
+		return tx.First(&e, &model.ChannelExport{ID: id}).Error
+	})
+	if err != nil || !claimed {
+		return false, err
+	}
+	repo.hub.Publish(hub.Message{
+		Name: event.ChannelExportUpdated,
+		Fields: hub.Fields{
+			"export_id": e.ID,
+			"user_id":   e.UserID,
+			"status":    e.Status,
+		},
+	})
+	return true, nil
+}
+
+// TouchChannelExport implements ChannelExportRepository interface.
+func (repo *GormRepository) TouchChannelExport(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return ErrNilID
+	}
+	result := repo.db.
+		Model(&model.ChannelExport{}).
+		Where("id = ? AND status = ?", id, model.ChannelExportRunning).
+		Update("updated_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// claimableChannelExports 生成待ち、或いはstaleBefore以降更新されていない生成中のチャンネルエクスポート
+func claimableChannelExports(staleBefore time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ? OR (status = ? AND updated_at < ?)", model.ChannelExportPending, model.ChannelExportRunning, staleBefore)
+	}
+}
+
+// UpdateChannelExport implements ChannelExportRepository interface.
+func (repo *GormRepository) UpdateChannelExport(id uuid.UUID, args UpdateChannelExportArgs) error {
+	if id == uuid.Nil {
+		return ErrNilID
+	}
+
+	changes := map[string]interface{}{
+		"status": args.Status,
+	}
+	if args.FileID.Valid {
+		changes["file_id"] = args.FileID.UUID
+	}
+	if args.Error.Valid {
+		changes["error"] = args.Error.String
+	}
+
+	var e model.ChannelExport
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&e, &model.ChannelExport{ID: id}).Error; err != nil {
+			return convertError(err)
+		}
+		return tx.Model(&e).Updates(changes).Error
+	})
+	if err != nil {
+		return err
+	}
+	repo.hub.Publish(hub.Message{
+		Name: event.ChannelExportUpdated,
+		Fields: hub.Fields{
+			"export_id": e.ID,
+			"user_id":   e.UserID,
+			"status":    e.Status,
+		},
+	})
+	return nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestRepositoryImpl_CreateChannelExport(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	_, err := repo.CreateChannelExport(uuid.Nil, channel.ID)
+	assert.EqualError(err, ErrNilID.Error())
+	_, err = repo.CreateChannelExport(user.GetID(), uuid.Nil)
+	assert.EqualError(err, ErrNilID.Error())
+
+	e, err := repo.CreateChannelExport(user.GetID(), channel.ID)
+	if assert.NoError(err) {
+		assert.NotEmpty(e.ID)
+		assert.Equal(user.GetID(), e.UserID)
+		assert.Equal(channel.ID, e.ChannelID)
+		assert.Equal(model.ChannelExportPending, e.Status)
+		assert.False(e.FileID.Valid)
+	}
+}
+
+func TestRepositoryImpl_GetChannelExport(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	e, err := repo.CreateChannelExport(user.GetID(), channel.ID)
+	require.NoError(err)
+
+	r, err := repo.GetChannelExport(e.ID)
+	if assert.NoError(err) {
+		assert.Equal(e.ID, r.ID)
+		assert.Equal(e.Status, r.Status)
+	}
+
+	_, err = repo.GetChannelExport(uuid.Nil)
+	assert.EqualError(err, ErrNotFound.Error())
+	_, err = repo.GetChannelExport(uuid.Must(uuid.NewV4()))
+	assert.EqualError(err, ErrNotFound.Error())
+}
+
+func TestRepositoryImpl_GetChannelExportsByUserID(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	e1, err := repo.CreateChannelExport(user.GetID(), channel.ID)
+	require.NoError(err)
+	e2, err := repo.CreateChannelExport(user.GetID(), channel.ID)
+	require.NoError(err)
+
+	es, err := repo.GetChannelExportsByUserID(user.GetID())
+	if assert.NoError(err) && assert.Len(es, 2) {
+		ids := []uuid.UUID{es[0].ID, es[1].ID}
+		assert.ElementsMatch([]uuid.UUID{e1.ID, e2.ID}, ids)
+	}
+
+	es, err = repo.GetChannelExportsByUserID(uuid.Must(uuid.NewV4()))
+	if assert.NoError(err) {
+		assert.Empty(es)
+	}
+}
+
+func TestRepositoryImpl_UpdateChannelExport(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	e, err := repo.CreateChannelExport(user.GetID(), channel.ID)
+	require.NoError(err)
+
+	assert.EqualError(repo.UpdateChannelExport(uuid.Nil, UpdateChannelExportArgs{Status: model.ChannelExportRunning}), ErrNilID.Error())
+	assert.EqualError(repo.UpdateChannelExport(uuid.Must(uuid.NewV4()), UpdateChannelExportArgs{Status: model.ChannelExportRunning}), ErrNotFound.Error())
+
+	if assert.NoError(repo.UpdateChannelExport(e.ID, UpdateChannelExportArgs{Status: model.ChannelExportFailed, Error: null.StringFrom("error")})) {
+		r, err := repo.GetChannelExport(e.ID)
+		require.NoError(err)
+		assert.Equal(model.ChannelExportFailed, r.Status)
+		assert.Equal("error", r.Error)
+	}
+
+	unfinished, err := repo.GetUnfinishedChannelExports(time.Now(), 100)
+	if assert.NoError(err) {
+		for _, u := range unfinished {
+			assert.NotEqual(e.ID, u.ID)
+		}
+	}
+}
+
+func TestRepositoryImpl_ClaimChannelExport(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	e, err := repo.CreateChannelExport(user.GetID(), channel.ID)
+	require.NoError(err)
+
+	_, err = repo.ClaimChannelExport(uuid.Nil, time.Now())
+	assert.EqualError(err, ErrNilID.Error())
+	assert.EqualError(repo.TouchChannelExport(uuid.Nil), ErrNilID.Error())
+	assert.EqualError(repo.TouchChannelExport(e.ID), ErrNotFound.Error())
+
+	// 生成待ちのものは1度だけ獲得できる
+	staleBefore := time.Now().Add(-time.Minute)
+	claimed, err := repo.ClaimChannelExport(e.ID, staleBefore)
+	if assert.NoError(err) {
+		assert.True(claimed)
+	}
+	claimed, err = repo.ClaimChannelExport(e.ID, staleBefore)
+	if assert.NoError(err) {
+		assert.False(claimed)
+	}
+	r, err := repo.GetChannelExport(e.ID)
+	require.NoError(err)
+	assert.Equal(model.ChannelExportRunning, r.Status)
+
+	unfinished, err := repo.GetUnfinishedChannelExports(staleBefore, 100)
+	if assert.NoError(err) {
+		for _, u := range unfinished {
+			assert.NotEqual(e.ID, u.ID)
+		}
+	}
+
+	// 更新されなくなった生成中のものは再び獲得できる
+	assert.NoError(repo.TouchChannelExport(e.ID))
+	staleBefore = time.Now().Add(time.Minute)
+	unfinished, err = repo.GetUnfinishedChannelExports(staleBefore, 100)
+	if assert.NoError(err) {
+		ids := make([]uuid.UUID, len(unfinished))
+		for i, u := range unfinished {
+			ids[i] = u.ID
+		}
+		assert.Contains(ids, e.ID)
+	}
+	claimed, err = repo.ClaimChannelExport(e.ID, staleBefore)
+	if assert.NoError(err) {
+		assert.True(claimed)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/traPtitech/traQ/event"
 	"github.com/traPtitech/traQ/model"
 	"github.com/traPtitech/traQ/utils"
+	"github.com/traPtitech/traQ/utils/set"
 	"github.com/traPtitech/traQ/utils/validator"
 	"go.uber.org/zap"
 	"sync"
@@ -35,47 +36,8 @@ func (repo *GormRepository) CreatePublicChannel(name string, parent, creatorID u
 		return nil, ErrAlreadyExists
 	}
 
-	switch parent {
-	case pubChannelRootUUID: // ルート
-		break
-	case dmChannelRootUUID: // DMルート
-		return nil, ErrForbidden
-	default: // ルート以外
-		// 親チャンネル検証
-		pCh, err := repo.GetChannel(parent)
-		if err != nil {
-			return nil, err
-		}
-
-		// DMチャンネルの子チャンネルには出来ない
-		if pCh.IsDMChannel() {
-			return nil, ErrForbidden
-		}
-
-		// 親と公開状況が一致しているか
-		if !pCh.IsPublic {
-			return nil, ErrForbidden
-		}
-
-		// 深さを検証
-		for parent, depth := pCh, 2; ; { // 祖先
-			if parent.ParentID == uuid.Nil {
-				// ルート
-				break
-			}
-
-			parent, err = repo.GetChannel(parent.ParentID)
-			if err != nil {
-				if err == ErrNotFound {
-					break
-				}
-				return nil, err
-			}
-			depth++
-			if depth > model.MaxChannelDepth {
-				return nil, ErrChannelDepthLimitation
-			}
-		}
+	if err := repo.validateNewChannelParent(repo.db, parent, true); err != nil {
+		return nil, err
 	}
 
 	ch := &model.Channel{
@@ -111,6 +73,85 @@ func (repo *GormRepository) CreatePublicChannel(name string, parent, creatorID u
 	return ch, nil
 }
 
+// CreatePrivateChannel implements ChannelRepository interface.
+func (repo *GormRepository) CreatePrivateChannel(name string, parent, creatorID uuid.UUID, members []uuid.UUID) (*model.Channel, error) {
+	if creatorID == uuid.Nil {
+		return nil, ErrNilID
+	}
+	// チャンネル名検証
+	if !validator.ChannelRegex.MatchString(name) {
+		return nil, ArgError("name", "invalid name")
+	}
+
+	ch := &model.Channel{
+		ID:        uuid.Must(uuid.NewV4()),
+		Name:      name,
+		ParentID:  parent,
+		CreatorID: creatorID,
+		UpdaterID: creatorID,
+		IsPublic:  false,
+		IsForced:  false,
+		IsVisible: true,
+	}
+	memberSet := set.UUIDSet{}
+	memberSet.Add(creatorID)
+	memberSet.Add(members...)
+	memberSet.Remove(uuid.Nil)
+
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if has, err := repo.isChannelPresent(tx, name, parent); err != nil {
+			return err
+		} else if has {
+			return ErrAlreadyExists
+		}
+
+		if err := repo.validateNewChannelParent(tx, parent, false); err != nil {
+			return err
+		}
+		if parent != uuid.Nil {
+			// 親チャンネルのメンバーでないといけない
+			if ok, err := dbExists(tx, &model.UsersPrivateChannel{UserID: creatorID, ChannelID: parent}); err != nil {
+				return err
+			} else if !ok {
+				return ErrForbidden
+			}
+		}
+
+		if err := tx.Create(ch).Error; err != nil {
+			return err
+		}
+		for _, uid := range memberSet.Array() {
+			if err := tx.Create(&model.UsersPrivateChannel{UserID: uid, ChannelID: ch.ID}).Error; err != nil {
+				if isMySQLForeignKeyConstraintFailsError(err) {
+					return ArgError("members", "the user is not found")
+				}
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	repo.hub.Publish(hub.Message{
+		Name: event.ChannelCreated,
+		Fields: hub.Fields{
+			"channel_id": ch.ID,
+			"channel":    ch,
+			"private":    true,
+		},
+	})
+
+	if ch.ParentID != uuid.Nil {
+		// ロギング
+		go repo.recordChannelEvent(ch.ParentID, model.ChannelEventChildCreated, model.ChannelEventDetail{
+			"userId":    ch.CreatorID,
+			"channelId": ch.ID,
+		}, ch.UpdatedAt)
+	}
+	return ch, nil
+}
+
 // UpdateChannel implements ChannelRepository interface.
 func (repo *GormRepository) UpdateChannel(channelID uuid.UUID, args UpdateChannelArgs) error {
 	if channelID == uuid.Nil {
@@ -217,6 +258,11 @@ func (repo *GormRepository) UpdateChannel(channelID uuid.UUID, args UpdateChanne
 						return ArgError("args.Parent", "invalid parent channel")
 					}
 
+					// アーカイブされていないチャンネルをアーカイブされたチャンネルの子には出来ない
+					if pCh.IsArchived && !ch.IsArchived {
+						return ArgError("args.Parent", "invalid parent channel")
+					}
+
 					// 深さを検証
 					depth := 1 // ↑で見た親
 					for {      // 祖先
@@ -303,12 +349,31 @@ func (repo *GormRepository) UpdateChannel(channelID uuid.UUID, args UpdateChanne
 			}, ch.UpdatedAt)
 		}
 		if visibilityChanged {
+			repo.hub.Publish(hub.Message{
+				Name: event.ChannelVisibilityUpdated,
+				Fields: hub.Fields{
+					"channel_id": channelID,
+					"visibility": args.Visibility.Bool,
+					"updater_id": args.UpdaterID,
+				},
+			})
+
 			go repo.recordChannelEvent(channelID, model.ChannelEventVisibilityChanged, model.ChannelEventDetail{
 				"userId":     args.UpdaterID,
 				"visibility": args.Visibility.Bool,
 			}, ch.UpdatedAt)
 		}
 		if nameChanged {
+			repo.hub.Publish(hub.Message{
+				Name: event.ChannelNameUpdated,
+				Fields: hub.Fields{
+					"channel_id": channelID,
+					"name":       args.Name.String,
+					"old_name":   nameBefore,
+					"updater_id": args.UpdaterID,
+				},
+			})
+
 			go repo.recordChannelEvent(channelID, model.ChannelEventNameChanged, model.ChannelEventDetail{
 				"userId": args.UpdaterID,
 				"before": nameBefore,
@@ -316,6 +381,16 @@ func (repo *GormRepository) UpdateChannel(channelID uuid.UUID, args UpdateChanne
 			}, ch.UpdatedAt)
 		}
 		if parentChanged {
+			repo.hub.Publish(hub.Message{
+				Name: event.ChannelParentUpdated,
+				Fields: hub.Fields{
+					"channel_id":    channelID,
+					"parent_id":     args.Parent.UUID,
+					"old_parent_id": parentBefore,
+					"updater_id":    args.UpdaterID,
+				},
+			})
+
 			go repo.recordChannelEvent(channelID, model.ChannelEventParentChanged, model.ChannelEventDetail{
 				"userId": args.UpdaterID,
 				"before": parentBefore,
@@ -377,6 +452,86 @@ func (repo *GormRepository) DeleteChannel(channelID uuid.UUID) error {
 	return err
 }
 
+// ArchiveChannel implements ChannelRepository interface.
+func (repo *GormRepository) ArchiveChannel(channelID, userID uuid.UUID) error {
+	return repo.setChannelArchived(channelID, userID, true)
+}
+
+// UnarchiveChannel implements ChannelRepository interface.
+func (repo *GormRepository) UnarchiveChannel(channelID, userID uuid.UUID) error {
+	return repo.setChannelArchived(channelID, userID, false)
+}
+
+func (repo *GormRepository) setChannelArchived(channelID, userID uuid.UUID, archived bool) error {
+	if channelID == uuid.Nil || userID == uuid.Nil {
+		return ErrNilID
+	}
+
+	changed := make([]*model.Channel, 0)
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		ch, err := repo.getChannel(tx, channelID)
+		if err != nil {
+			return err
+		}
+		if ch.IsDMChannel() {
+			return ErrForbidden
+		}
+		if !archived && ch.ParentID != uuid.Nil {
+			// 親チャンネルがアーカイブされている場合は解除できない
+			if parentArchived, err := repo.isChannelArchived(tx, ch.ParentID); err != nil {
+				return err
+			} else if parentArchived {
+				return ErrForbidden
+			}
+		}
+
+		desc, err := repo.getDescendantChannelIDs(tx, channelID)
+		if err != nil {
+			return err
+		}
+		desc = append(desc, channelID)
+
+		for _, v := range desc {
+			ch := model.Channel{}
+			if err := tx.First(&ch, &model.Channel{ID: v}).Error; err != nil {
+				if gorm.IsRecordNotFoundError(err) {
+					continue
+				}
+				return err
+			}
+			if ch.IsArchived == archived {
+				continue
+			}
+			if err := tx.Model(&ch).Updates(map[string]interface{}{"is_archived": archived, "updater_id": userID}).Error; err != nil {
+				return err
+			}
+			changed = append(changed, &ch)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	eventType := model.ChannelEventUnarchived
+	if archived {
+		eventType = model.ChannelEventArchived
+	}
+	for _, v := range changed {
+		repo.hub.Publish(hub.Message{
+			Name: event.ChannelUpdated,
+			Fields: hub.Fields{
+				"channel_id": v.ID,
+				"private":    !v.IsPublic,
+			},
+		})
+		go repo.recordChannelEvent(v.ID, eventType, model.ChannelEventDetail{
+			"userId": userID,
+		}, v.UpdatedAt)
+	}
+	return nil
+}
+
 // GetChannel implements ChannelRepository interface.
 func (repo *GormRepository) GetChannel(channelID uuid.UUID) (*model.Channel, error) {
 	return repo.getChannel(repo.db, channelID)
@@ -563,7 +718,12 @@ func (repo *GormRepository) GetPrivateChannelMemberIDs(channelID uuid.UUID) (use
 	if channelID == uuid.Nil {
 		return users, nil
 	}
-	err = repo.db.
+	return repo.getPrivateChannelMemberIDs(repo.db, channelID)
+}
+
+func (repo *GormRepository) getPrivateChannelMemberIDs(tx *gorm.DB, channelID uuid.UUID) (users []uuid.UUID, err error) {
+	users = make([]uuid.UUID, 0)
+	err = tx.
 		Model(&model.UsersPrivateChannel{}).
 		Where(&model.UsersPrivateChannel{ChannelID: channelID}).
 		Pluck("user_id", &users).
@@ -571,6 +731,164 @@ func (repo *GormRepository) GetPrivateChannelMemberIDs(channelID uuid.UUID) (use
 	return users, err
 }
 
+// AddPrivateChannelMembers implements ChannelRepository interface.
+func (repo *GormRepository) AddPrivateChannelMembers(channelID, updaterID uuid.UUID, userIDs []uuid.UUID) error {
+	if channelID == uuid.Nil || updaterID == uuid.Nil {
+		return ErrNilID
+	}
+
+	added := make([]uuid.UUID, 0, len(userIDs))
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		ch, err := repo.getChannel(tx, channelID)
+		if err != nil {
+			return err
+		}
+		if ch.IsPublic || ch.IsDMChannel() {
+			return ErrForbidden
+		}
+
+		current, err := repo.getPrivateChannelMemberIDs(tx, channelID)
+		if err != nil {
+			return err
+		}
+		currentSet := set.UUIDSetFromArray(current)
+		for _, uid := range userIDs {
+			if uid == uuid.Nil || currentSet.Contains(uid) {
+				continue
+			}
+			if err := tx.Create(&model.UsersPrivateChannel{UserID: uid, ChannelID: channelID}).Error; err != nil {
+				if isMySQLForeignKeyConstraintFailsError(err) {
+					return ArgError("userIDs", "the user is not found")
+				}
+				return err
+			}
+			currentSet.Add(uid)
+			added = append(added, uid)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(added) > 0 {
+		repo.publishPrivateChannelMembersChanged(channelID, updaterID, added, make([]uuid.UUID, 0))
+	}
+	return nil
+}
+
+// RemovePrivateChannelMembers implements ChannelRepository interface.
+func (repo *GormRepository) RemovePrivateChannelMembers(channelID, updaterID uuid.UUID, userIDs []uuid.UUID) error {
+	if channelID == uuid.Nil || updaterID == uuid.Nil {
+		return ErrNilID
+	}
+
+	removed := make([]uuid.UUID, 0, len(userIDs))
+	leftBots := make([]uuid.UUID, 0)
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		ch, err := repo.getChannel(tx, channelID)
+		if err != nil {
+			return err
+		}
+		if ch.IsPublic || ch.IsDMChannel() {
+			return ErrForbidden
+		}
+
+		current, err := repo.getPrivateChannelMemberIDs(tx, channelID)
+		if err != nil {
+			return err
+		}
+		remain := set.UUIDSetFromArray(current)
+		for _, uid := range userIDs {
+			if remain.Contains(uid) {
+				remain.Remove(uid)
+				removed = append(removed, uid)
+			}
+		}
+		if len(removed) == 0 {
+			return nil
+		}
+		if len(remain) == 0 {
+			return ArgError("userIDs", "private channel must have at least one member")
+		}
+
+		if err := tx.Where("channel_id = ? AND user_id IN (?)", channelID, removed).Delete(&model.UsersPrivateChannel{}).Error; err != nil {
+			return err
+		}
+		// 削除されたメンバーの購読・未読を削除
+		if err := tx.Where("channel_id = ? AND user_id IN (?)", channelID, removed).Delete(&model.UserSubscribeChannel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE unreads FROM unreads INNER JOIN messages ON unreads.message_id = messages.id WHERE messages.channel_id = ? AND unreads.user_id IN (?)", channelID, removed).Error; err != nil {
+			return err
+		}
+		// 削除されたメンバーがBOTの場合はチャンネルから退出させる
+		if err := tx.
+			Model(&model.BotJoinChannel{}).
+			Where("channel_id = ? AND bot_id IN (?)", channelID, tx.Model(&model.Bot{}).Select("id").Where("bot_user_id IN (?)", removed).SubQuery()).
+			Pluck("bot_id", &leftBots).
+			Error; err != nil {
+			return err
+		}
+		if len(leftBots) > 0 {
+			if err := tx.Where("channel_id = ? AND bot_id IN (?)", channelID, leftBots).Delete(&model.BotJoinChannel{}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(removed) > 0 {
+		repo.publishPrivateChannelMembersChanged(channelID, updaterID, make([]uuid.UUID, 0), removed)
+	}
+	for _, botID := range leftBots {
+		repo.hub.Publish(hub.Message{
+			Name: event.BotLeft,
+			Fields: hub.Fields{
+				"bot_id":     botID,
+				"channel_id": channelID,
+			},
+		})
+	}
+	return nil
+}
+
+func (repo *GormRepository) publishPrivateChannelMembersChanged(channelID, updaterID uuid.UUID, added, removed []uuid.UUID) {
+	for _, uid := range added {
+		repo.hub.Publish(hub.Message{
+			Name: event.ChannelMemberAdded,
+			Fields: hub.Fields{
+				"channel_id": channelID,
+				"user_id":    uid,
+				"updater_id": updaterID,
+			},
+		})
+	}
+	for _, uid := range removed {
+		repo.hub.Publish(hub.Message{
+			Name: event.ChannelMemberRemoved,
+			Fields: hub.Fields{
+				"channel_id": channelID,
+				"user_id":    uid,
+				"updater_id": updaterID,
+			},
+		})
+	}
+	repo.hub.Publish(hub.Message{
+		Name: event.ChannelUpdated,
+		Fields: hub.Fields{
+			"channel_id": channelID,
+			"private":    true,
+		},
+	})
+	go repo.recordChannelEvent(channelID, model.ChannelEventMembersChanged, model.ChannelEventDetail{
+		"userId":  updaterID,
+		"added":   added,
+		"removed": removed,
+	}, time.Now())
+}
+
 // ChangeChannelSubscription implements ChannelRepository interface.
 func (repo *GormRepository) ChangeChannelSubscription(channelID uuid.UUID, args ChangeChannelSubscriptionArgs) error {
 	if channelID == uuid.Nil {
@@ -773,6 +1091,63 @@ func (repo *GormRepository) getChannel(tx *gorm.DB, channelID uuid.UUID) (*model
 	return ch, nil
 }
 
+// validateNewChannelParent 新しく作成するチャンネルの親チャンネルとしてparentが有効かどうかを検証します
+func (repo *GormRepository) validateNewChannelParent(tx *gorm.DB, parent uuid.UUID, public bool) error {
+	switch parent {
+	case pubChannelRootUUID: // ルート
+		break
+	case dmChannelRootUUID: // DMルート
+		return ErrForbidden
+	default: // ルート以外
+		// 親チャンネル検証
+		pCh, err := repo.getChannel(tx, parent)
+		if err != nil {
+			return err
+		}
+
+		// DMチャンネルの子チャンネルには出来ない
+		if pCh.IsDMChannel() {
+			return ErrForbidden
+		}
+
+		// 親と公開状況が一致しているか
+		if pCh.IsPublic != public {
+			return ErrForbidden
+		}
+
+		// アーカイブされたチャンネルの子チャンネルには出来ない
+		if pCh.IsArchived {
+			return ErrForbidden
+		}
+
+		// 深さを検証
+		for parent, depth := pCh, 2; ; { // 祖先
+			if parent.ParentID == uuid.Nil {
+				// ルート
+				break
+			}
+
+			parent, err = repo.getChannel(tx, parent.ParentID)
+			if err != nil {
+				if err == ErrNotFound {
+					break
+				}
+				return err
+			}
+			depth++
+			if depth > model.MaxChannelDepth {
+				return ErrChannelDepthLimitation
+			}
+		}
+	}
+	return nil
+}
+
+// isChannelArchived 指定したチャンネルがアーカイブされているかどうか
+func (repo *GormRepository) isChannelArchived(tx *gorm.DB, channelID uuid.UUID) (bool, error) {
+	return dbExists(tx, &model.Channel{ID: channelID, IsArchived: true})
+}
+
 // isChannelPresent チャンネル名が同階層に既に存在するか
 func (repo *GormRepository) isChannelPresent(tx *gorm.DB, name string, parent uuid.UUID) (bool, error) {
 	c := 0
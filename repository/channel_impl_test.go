@@ -267,6 +267,53 @@ func TestRepositoryImpl_DeleteChannel(t *testing.T) {
 	}
 }
 
+func TestGormRepository_ArchiveChannel(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, c1 := setupWithUserAndChannel(t, common)
+
+	c2 := mustMakeChannelDetail(t, repo, uuid.Nil, random, c1.ID)
+
+	assert.EqualValues(t, ErrNilID, repo.ArchiveChannel(uuid.Nil, user.GetID()))
+	assert.EqualValues(t, ErrNotFound, repo.ArchiveChannel(uuid.Must(uuid.NewV4()), user.GetID()))
+
+	if assert.NoError(t, repo.ArchiveChannel(c1.ID, user.GetID())) {
+		for _, id := range []uuid.UUID{c1.ID, c2.ID} {
+			ch, err := repo.GetChannel(id)
+			if assert.NoError(t, err) {
+				assert.True(t, ch.IsArchived)
+			}
+		}
+
+		_, err := repo.CreateMessage(user.GetID(), c2.ID, "a")
+		assert.EqualValues(t, ErrChannelArchived, err)
+		_, err = repo.CreatePublicChannel(utils.RandAlphabetAndNumberString(20), c1.ID, user.GetID())
+		assert.EqualValues(t, ErrForbidden, err)
+	}
+}
+
+func TestGormRepository_UnarchiveChannel(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, c1 := setupWithUserAndChannel(t, common)
+
+	c2 := mustMakeChannelDetail(t, repo, uuid.Nil, random, c1.ID)
+	require.NoError(t, repo.ArchiveChannel(c1.ID, user.GetID()))
+
+	// 親がアーカイブされている
+	assert.EqualValues(t, ErrForbidden, repo.UnarchiveChannel(c2.ID, user.GetID()))
+
+	if assert.NoError(t, repo.UnarchiveChannel(c1.ID, user.GetID())) {
+		for _, id := range []uuid.UUID{c1.ID, c2.ID} {
+			ch, err := repo.GetChannel(id)
+			if assert.NoError(t, err) {
+				assert.False(t, ch.IsArchived)
+			}
+		}
+
+		_, err := repo.CreateMessage(user.GetID(), c2.ID, "a")
+		assert.NoError(t, err)
+	}
+}
+
 func TestRepositoryImpl_GetChildrenChannelIDs(t *testing.T) {
 	t.Parallel()
 	repo, _, _, c1 := setupWithChannel(t, common)
@@ -299,6 +346,143 @@ func TestRepositoryImpl_GetChildrenChannelIDs(t *testing.T) {
 	}
 }
 
+func TestGormRepository_CreatePrivateChannel(t *testing.T) {
+	t.Parallel()
+	repo, _, _ := setup(t, common)
+
+	t.Run("Nil ID", func(t *testing.T) {
+		t.Parallel()
+		assert, _ := assertAndRequire(t)
+
+		_, err := repo.CreatePrivateChannel(utils.RandAlphabetAndNumberString(20), uuid.Nil, uuid.Nil, nil)
+		assert.EqualError(err, ErrNilID.Error())
+	})
+
+	t.Run("Public parent", func(t *testing.T) {
+		t.Parallel()
+		assert, _ := assertAndRequire(t)
+		user := mustMakeUser(t, repo, random)
+		parent := mustMakeChannel(t, repo, random)
+
+		_, err := repo.CreatePrivateChannel(utils.RandAlphabetAndNumberString(20), parent.ID, user.GetID(), nil)
+		assert.EqualError(err, ErrForbidden.Error())
+	})
+
+	t.Run("Unknown member", func(t *testing.T) {
+		t.Parallel()
+		assert, _ := assertAndRequire(t)
+		user := mustMakeUser(t, repo, random)
+
+		_, err := repo.CreatePrivateChannel(utils.RandAlphabetAndNumberString(20), uuid.Nil, user.GetID(), []uuid.UUID{uuid.Must(uuid.NewV4())})
+		assert.True(IsArgError(err))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+		assert, require := assertAndRequire(t)
+		user1 := mustMakeUser(t, repo, random)
+		user2 := mustMakeUser(t, repo, random)
+		user3 := mustMakeUser(t, repo, random)
+
+		ch, err := repo.CreatePrivateChannel(utils.RandAlphabetAndNumberString(20), uuid.Nil, user1.GetID(), []uuid.UUID{user2.GetID()})
+		require.NoError(err)
+		assert.False(ch.IsPublic)
+		members, err := repo.GetPrivateChannelMemberIDs(ch.ID)
+		if assert.NoError(err) {
+			assert.ElementsMatch([]uuid.UUID{user1.GetID(), user2.GetID()}, members)
+		}
+
+		// 子チャンネルは親チャンネルのメンバーのみ作成可能
+		_, err = repo.CreatePrivateChannel(utils.RandAlphabetAndNumberString(20), ch.ID, user3.GetID(), nil)
+		assert.EqualError(err, ErrForbidden.Error())
+		_, err = repo.CreatePrivateChannel(utils.RandAlphabetAndNumberString(20), ch.ID, user2.GetID(), nil)
+		assert.NoError(err)
+
+		ok, err := repo.IsChannelAccessibleToUser(user3.GetID(), ch.ID)
+		if assert.NoError(err) {
+			assert.False(ok)
+		}
+	})
+}
+
+func TestGormRepository_AddPrivateChannelMembers(t *testing.T) {
+	t.Parallel()
+	repo, _, _ := setup(t, common)
+
+	t.Run("Nil ID", func(t *testing.T) {
+		t.Parallel()
+		assert, _ := assertAndRequire(t)
+
+		assert.EqualError(repo.AddPrivateChannelMembers(uuid.Nil, uuid.Nil, nil), ErrNilID.Error())
+	})
+
+	t.Run("Public channel", func(t *testing.T) {
+		t.Parallel()
+		assert, _ := assertAndRequire(t)
+		user := mustMakeUser(t, repo, random)
+		ch := mustMakeChannel(t, repo, random)
+
+		assert.EqualError(repo.AddPrivateChannelMembers(ch.ID, user.GetID(), []uuid.UUID{user.GetID()}), ErrForbidden.Error())
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+		assert, require := assertAndRequire(t)
+		user1 := mustMakeUser(t, repo, random)
+		user2 := mustMakeUser(t, repo, random)
+
+		ch, err := repo.CreatePrivateChannel(utils.RandAlphabetAndNumberString(20), uuid.Nil, user1.GetID(), nil)
+		require.NoError(err)
+
+		if assert.NoError(repo.AddPrivateChannelMembers(ch.ID, user1.GetID(), []uuid.UUID{user1.GetID(), user2.GetID()})) {
+			members, err := repo.GetPrivateChannelMemberIDs(ch.ID)
+			if assert.NoError(err) {
+				assert.ElementsMatch([]uuid.UUID{user1.GetID(), user2.GetID()}, members)
+			}
+		}
+		assert.True(IsArgError(repo.AddPrivateChannelMembers(ch.ID, user1.GetID(), []uuid.UUID{uuid.Must(uuid.NewV4())})))
+	})
+}
+
+func TestGormRepository_RemovePrivateChannelMembers(t *testing.T) {
+	t.Parallel()
+	repo, _, _ := setup(t, common)
+
+	t.Run("Nil ID", func(t *testing.T) {
+		t.Parallel()
+		assert, _ := assertAndRequire(t)
+
+		assert.EqualError(repo.RemovePrivateChannelMembers(uuid.Nil, uuid.Nil, nil), ErrNilID.Error())
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+		assert, require := assertAndRequire(t)
+		user1 := mustMakeUser(t, repo, random)
+		user2 := mustMakeUser(t, repo, random)
+
+		ch, err := repo.CreatePrivateChannel(utils.RandAlphabetAndNumberString(20), uuid.Nil, user1.GetID(), []uuid.UUID{user2.GetID()})
+		require.NoError(err)
+		m := mustMakeMessage(t, repo, user1.GetID(), ch.ID)
+		mustMakeMessageUnread(t, repo, user2.GetID(), m.ID)
+		require.NoError(repo.ChangeChannelSubscription(ch.ID, ChangeChannelSubscriptionArgs{
+			Subscription: map[uuid.UUID]model.ChannelSubscribeLevel{user2.GetID(): model.ChannelSubscribeLevelMarkAndNotify},
+		}))
+
+		if assert.NoError(repo.RemovePrivateChannelMembers(ch.ID, user1.GetID(), []uuid.UUID{user2.GetID()})) {
+			members, err := repo.GetPrivateChannelMemberIDs(ch.ID)
+			if assert.NoError(err) {
+				assert.ElementsMatch([]uuid.UUID{user1.GetID()}, members)
+			}
+			assert.Equal(0, count(t, getDB(repo).Model(model.UserSubscribeChannel{}).Where(&model.UserSubscribeChannel{ChannelID: ch.ID})))
+			assert.Equal(0, count(t, getDB(repo).Model(model.Unread{}).Where(&model.Unread{UserID: user2.GetID()})))
+		}
+
+		// 最後のメンバーは削除できない
+		assert.True(IsArgError(repo.RemovePrivateChannelMembers(ch.ID, user1.GetID(), []uuid.UUID{user1.GetID()})))
+	})
+}
+
 func TestGormRepository_ChangeChannelSubscription(t *testing.T) {
 	t.Parallel()
 	repo, _, _ := setup(t, common)
@@ -408,7 +592,7 @@ func TestGormRepository_GetChannelStats(t *testing.T) {
 		for i := 0; i < 14; i++ {
 			mustMakeMessage(t, repo, user.GetID(), channel.ID)
 		}
-		require.NoError(t, repo.DeleteMessage(mustMakeMessage(t, repo, user.GetID(), channel.ID).ID))
+		require.NoError(t, repo.DeleteMessage(mustMakeMessage(t, repo, user.GetID(), channel.ID).ID, user.GetID()))
 
 		stats, err := repo.GetChannelStats(channel.ID)
 		if assert.NoError(t, err) {
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+)
+
+// DraftRepository メッセージ下書きリポジトリ
+type DraftRepository interface {
+	// GetDraft 指定したユーザーの指定したチャンネルの下書きを取得します
+	//
+	// 成功した場合、下書きとnilを返します。
+	// 存在しなかった場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetDraft(userID, channelID uuid.UUID) (*model.Draft, error)
+	// GetDraftsByUserID 指定したユーザーの下書きを全て取得します
+	//
+	// 成功した場合、下書きの配列とnilを返します。
+	// 存在しないユーザーを指定した場合は空配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetDraftsByUserID(userID uuid.UUID) ([]*model.Draft, error)
+	// SaveDraft 指定したユーザーの指定したチャンネルの下書きを保存します
+	//
+	// clientKeyには下書きを保存したクライアントの識別キーを指定します。このクライアントには更新が通知されません。
+	// 成功した場合、保存された下書きとnilを返します。既に下書きが存在する場合は上書きします。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	SaveDraft(userID, channelID uuid.UUID, text string, clientKey string) (*model.Draft, error)
+	// DeleteDraft 指定したユーザーの指定したチャンネルの下書きを削除します
+	//
+	// clientKeyには下書きを削除したクライアントの識別キーを指定します。このクライアントには削除が通知されません。
+	// 成功した、或いは既に存在しなかった場合にnilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteDraft(userID, channelID uuid.UUID, clientKey string) error
+}
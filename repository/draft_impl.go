@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+)
+
+// GetDraft implements DraftRepository interface.
+func (repo *GormRepository) GetDraft(userID, channelID uuid.UUID) (*model.Draft, error) {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return nil, ErrNotFound
+	}
+	var d model.Draft
+	if err := repo.db.Where(&model.Draft{UserID: userID, ChannelID: channelID}).Take(&d).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &d, nil
+}
+
+// GetDraftsByUserID implements DraftRepository interface.
+func (repo *GormRepository) GetDraftsByUserID(userID uuid.UUID) ([]*model.Draft, error) {
+	drafts := make([]*model.Draft, 0)
+	if userID == uuid.Nil {
+		return drafts, nil
+	}
+	return drafts, repo.db.Where(&model.Draft{UserID: userID}).Order("updated_at DESC").Find(&drafts).Error
+}
+
+// SaveDraft implements DraftRepository interface.
+func (repo *GormRepository) SaveDraft(userID, channelID uuid.UUID, text string, clientKey string) (*model.Draft, error) {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return nil, ErrNilID
+	}
+	d := &model.Draft{
+		UserID:    userID,
+		ChannelID: channelID,
+		Text:      text,
+		UpdatedAt: time.Now(),
+	}
+	err := repo.db.
+		Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE text = VALUES(text), updated_at = VALUES(updated_at)").
+		Create(d).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	repo.publishDraftUpdated(userID, channelID, clientKey)
+	return d, nil
+}
+
+// DeleteDraft implements DraftRepository interface.
+func (repo *GormRepository) DeleteDraft(userID, channelID uuid.UUID, clientKey string) error {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return ErrNilID
+	}
+	result := repo.db.Delete(&model.Draft{UserID: userID, ChannelID: channelID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		repo.publishDraftUpdated(userID, channelID, clientKey)
+	}
+	return nil
+}
+
+func (repo *GormRepository) publishDraftUpdated(userID, channelID uuid.UUID, clientKey string) {
+	repo.hub.Publish(hub.Message{
+		Name: event.DraftUpdated,
+		Fields: hub.Fields{
+			"user_id":    userID,
+			"channel_id": channelID,
+			"client_key": clientKey,
+		},
+	})
+}
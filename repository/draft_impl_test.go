@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryImpl_SaveDraft(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	_, err := repo.SaveDraft(uuid.Nil, channel.ID, "a", "")
+	assert.EqualError(err, ErrNilID.Error())
+	_, err = repo.SaveDraft(user.GetID(), uuid.Nil, "a", "")
+	assert.EqualError(err, ErrNilID.Error())
+
+	if d, err := repo.SaveDraft(user.GetID(), channel.ID, "first", ""); assert.NoError(err) {
+		assert.Equal("first", d.Text)
+	}
+	if _, err := repo.SaveDraft(user.GetID(), channel.ID, "second", ""); assert.NoError(err) {
+		d, err := repo.GetDraft(user.GetID(), channel.ID)
+		if assert.NoError(err) {
+			assert.Equal("second", d.Text)
+		}
+	}
+}
+
+func TestRepositoryImpl_GetDraft(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	_, err := repo.GetDraft(user.GetID(), channel.ID)
+	assert.EqualError(err, ErrNotFound.Error())
+	_, err = repo.GetDraft(uuid.Nil, channel.ID)
+	assert.EqualError(err, ErrNotFound.Error())
+
+	_, err = repo.SaveDraft(user.GetID(), channel.ID, "draft", "")
+	if assert.NoError(err) {
+		d, err := repo.GetDraft(user.GetID(), channel.ID)
+		if assert.NoError(err) {
+			assert.Equal(user.GetID(), d.UserID)
+			assert.Equal(channel.ID, d.ChannelID)
+			assert.Equal("draft", d.Text)
+			assert.NotZero(d.UpdatedAt)
+		}
+	}
+}
+
+func TestRepositoryImpl_GetDraftsByUserID(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
+	channel2 := mustMakeChannel(t, repo, random)
+
+	_, err := repo.SaveDraft(user.GetID(), channel.ID, "a", "")
+	assert.NoError(t, err)
+	_, err = repo.SaveDraft(user.GetID(), channel2.ID, "b", "")
+	assert.NoError(t, err)
+
+	t.Run("nil id", func(t *testing.T) {
+		t.Parallel()
+
+		ds, err := repo.GetDraftsByUserID(uuid.Nil)
+		if assert.NoError(t, err) {
+			assert.Len(t, ds, 0)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ds, err := repo.GetDraftsByUserID(user.GetID())
+		if assert.NoError(t, err) {
+			assert.Len(t, ds, 2)
+		}
+	})
+}
+
+func TestRepositoryImpl_DeleteDraft(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	assert.EqualError(repo.DeleteDraft(uuid.Nil, channel.ID, ""), ErrNilID.Error())
+	assert.EqualError(repo.DeleteDraft(user.GetID(), uuid.Nil, ""), ErrNilID.Error())
+	assert.NoError(repo.DeleteDraft(user.GetID(), channel.ID, ""))
+
+	_, err := repo.SaveDraft(user.GetID(), channel.ID, "draft", "")
+	if assert.NoError(err) && assert.NoError(repo.DeleteDraft(user.GetID(), channel.ID, "")) {
+		_, err := repo.GetDraft(user.GetID(), channel.ID)
+		assert.EqualError(err, ErrNotFound.Error())
+	}
+}
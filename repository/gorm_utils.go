@@ -3,6 +3,7 @@ package repository
 import (
 	"github.com/go-sql-driver/mysql"
 	"github.com/jinzhu/gorm"
+	"strings"
 )
 
 const (
@@ -57,3 +58,6 @@ func convertError(err error) error {
 		return err
 	}
 }
+
+// likeEscaper LIKE句のワイルドカード文字をエスケープします
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
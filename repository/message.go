@@ -12,7 +12,13 @@ import (
 type MessagesQuery struct {
 	User           uuid.UUID
 	Channel        uuid.UUID
+	Thread         uuid.UUID
+	ExcludeReplies bool
 	Since          null.Time
+	// SinceID Sinceと同時刻のメッセージのうち、IDがSinceIDより大きいもののみを取得します
+	//
+	// (created_at, id)の順でページングする際に指定します。Sinceが必要です。Inclusiveに関わらずSinceIDのメッセージは含みません。
+	SinceID        uuid.UUID
 	Until          null.Time
 	Inclusive      bool
 	Limit          int
@@ -22,28 +28,120 @@ type MessagesQuery struct {
 	DisablePreload bool
 }
 
+// ImportMessageArgs メッセージインポート引数
+type ImportMessageArgs struct {
+	UserID    uuid.UUID
+	ChannelID uuid.UUID
+	// ThreadID 返信先スレッドの起点メッセージ
+	ThreadID  uuid.NullUUID
+	Text      string
+	CreatedAt time.Time
+	Stamps    []model.MessageStamp
+}
+
 // MessageRepository メッセージリポジトリ
 type MessageRepository interface {
 	// CreateMessage メッセージを作成します
 	//
 	// 成功した場合、メッセージとnilを返します。
 	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルを指定した場合、ErrChannelArchivedを返します。
 	// DBによるエラーを返すことがあります。
 	CreateMessage(userID, channelID uuid.UUID, text string) (*model.Message, error)
+	// CreateMessageWithComponents コンポーネント付きのメッセージを作成します
+	//
+	// メッセージとコンポーネントは同時に作成され、コンポーネントを含むメッセージの作成イベントが発行されます。
+	// componentsがnilまたは空の場合、CreateMessageと同じです。
+	// 成功した場合、メッセージとnilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルを指定した場合、ErrChannelArchivedを返します。
+	// 引数に問題がある場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	CreateMessageWithComponents(userID, channelID uuid.UUID, text string, components model.MessageComponentList) (*model.Message, error)
+	// ImportMessage 投稿日時・スタンプを指定してメッセージを作成します
+	//
+	// 他のサービスからの移行用で、イベントは発行されません。
+	// 成功した場合、メッセージとnilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルを指定した場合、ErrChannelArchivedを返します。
+	// 存在しない返信先スレッドを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	ImportMessage(args ImportMessageArgs) (*model.Message, error)
+	// CreateReplyMessage 指定したメッセージのスレッドに返信メッセージを作成します
+	//
+	// 成功した場合、メッセージとnilを返します。
+	// parentIDに返信メッセージを指定した場合、そのメッセージが属するスレッドへの返信になります。
+	// 存在しないメッセージを指定した場合、ErrNotFoundを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルのメッセージを指定した場合、ErrChannelArchivedを返します。
+	// DBによるエラーを返すことがあります。
+	CreateReplyMessage(userID, parentID uuid.UUID, text string) (*model.Message, error)
+	// CreateReplyMessageWithComponents 指定したメッセージのスレッドにコンポーネント付きの返信メッセージを作成します
+	//
+	// メッセージとコンポーネントは同時に作成され、コンポーネントを含むメッセージの作成イベントが発行されます。
+	// componentsがnilまたは空の場合、CreateReplyMessageと同じです。
+	// 成功した場合、メッセージとnilを返します。
+	// 存在しないメッセージを指定した場合、ErrNotFoundを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルのメッセージを指定した場合、ErrChannelArchivedを返します。
+	// 引数に問題がある場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	CreateReplyMessageWithComponents(userID, parentID uuid.UUID, text string, components model.MessageComponentList) (*model.Message, error)
 	// UpdateMessage 指定したメッセージを更新します
 	//
+	// 更新前の本文は編集履歴として保存され、メッセージの編集回数が1増えます。
 	// 成功した場合、nilを返します。
 	// 存在しないメッセージを指定した場合、ErrNotFoundを返します。
 	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルのメッセージを指定した場合、ErrChannelArchivedを返します。
 	// DBによるエラーを返すことがあります。
 	UpdateMessage(messageID uuid.UUID, text string) error
+	// UpdateMessageWithComponents 指定したメッセージの本文とコンポーネントを更新します
+	//
+	// 本文とコンポーネントは同時に更新され、メッセージの更新イベントが1回だけ発行されます。
+	// componentsがnilの場合はコンポーネントを変更せず、空の場合はコンポーネントを削除します。
+	// 成功した場合、nilを返します。
+	// 存在しないメッセージを指定した場合、ErrNotFoundを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルのメッセージを指定した場合、ErrChannelArchivedを返します。
+	// 引数に問題がある場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateMessageWithComponents(messageID uuid.UUID, text string, components model.MessageComponentList) error
 	// DeleteMessage 指定したメッセージを削除します
 	//
+	// メッセージは論理削除され、削除したユーザーdeletedByが記録されます。
 	// 成功した場合、nilを返します。
 	// 存在しないメッセージを指定した場合、ErrNotFoundを返します。
+	// messageIDにuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteMessage(messageID, deletedBy uuid.UUID) error
+	// GetDeletedMessages 指定したチャンネルの削除されたメッセージを削除日時の降順で取得します
+	//
+	// 成功した場合、メッセージの配列と追加で取得可能なメッセージが存在するかどうかとnilを返します。
+	// 存在しないチャンネルを指定した場合、空配列とfalseとnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetDeletedMessages(channelID uuid.UUID, limit, offset int) (messages []*model.Message, more bool, err error)
+	// RestoreMessage 削除されたメッセージを復元します
+	//
+	// 成功した場合、復元したメッセージとnilを返します。
+	// 削除されたメッセージ以外を指定した場合、ErrNotFoundを返します。
 	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルのメッセージを指定した場合、ErrChannelArchivedを返します。
 	// DBによるエラーを返すことがあります。
-	DeleteMessage(messageID uuid.UUID) error
+	RestoreMessage(messageID uuid.UUID) (*model.Message, error)
+	// PurgeDeletedMessages 指定した日時より前に削除されたメッセージを、未読・スタンプ等と共に完全に削除します
+	//
+	// 削除されていない返信メッセージが存在するスレッドの起点メッセージは削除しません。
+	// 成功した場合、完全に削除したメッセージの数とnilを返します。一度に最大limit件削除します。
+	// DBによるエラーを返すことがあります。
+	PurgeDeletedMessages(before time.Time, limit int) (int, error)
+	// SetMessageComponents 指定したメッセージのコンポーネントを設定します
+	//
+	// 成功した場合、nilを返します。componentsが空の場合、コンポーネントを削除します。
+	// 存在しないメッセージを指定した場合、ErrNotFoundを返します。
+	// 引数に問題がある場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	SetMessageComponents(messageID uuid.UUID, components model.MessageComponentList) error
 	// GetMessageByID 指定したメッセージを取得します
 	//
 	// 成功した場合、メッセージとnilを返します。
@@ -62,6 +160,12 @@ type MessageRepository interface {
 	// 引数にuuid.Nilを指定するとErrNilIDを返します。
 	// DBによるエラーを返すことがあります。
 	SetMessageUnread(userID, messageID uuid.UUID, noticeable bool) error
+	// GetThreadParticipantIDs 指定したスレッドの参加者のIDを取得します
+	//
+	// 成功した場合、ユーザーUUIDの配列とnilを返します。
+	// 存在しないスレッドを指定した場合、空配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetThreadParticipantIDs(threadID uuid.UUID) ([]uuid.UUID, error)
 	// GetUnreadMessagesByUserID 指定したユーザーの未読メッセージをすべて取得します
 	//
 	// 成功した場合、メッセージの配列とnilを返します。
@@ -70,16 +174,31 @@ type MessageRepository interface {
 	GetUnreadMessagesByUserID(userID uuid.UUID) ([]*model.Message, error)
 	// DeleteUnreadsByChannelID 指定したチャンネルに存在する、指定したユーザーの未読レコードをすべて削除します
 	//
+	// スレッドへの返信メッセージの未読は削除されません。
+	//
 	// 成功した場合、nilを返します。
 	// 引数にuuid.Nilを指定するとErrNilIDを返します。
 	// DBによるエラーを返すことがあります。
 	DeleteUnreadsByChannelID(channelID, userID uuid.UUID) error
 	// GetUserUnreadChannels 指定したユーザーの未読チャンネル一覧を取得します
 	//
+	// スレッドへの返信メッセージの未読は含まれません。
 	// 成功した場合、UserUnreadChannelの配列とnilを返します。
 	// 存在しないユーザーを指定した場合、空配列とnilを返します。
 	// DBによるエラーを返すことがあります。
 	GetUserUnreadChannels(userID uuid.UUID) ([]*UserUnreadChannel, error)
+	// DeleteUnreadsByThreadID 指定したスレッドに存在する、指定したユーザーの未読レコードをすべて削除します
+	//
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteUnreadsByThreadID(threadID, userID uuid.UUID) error
+	// GetUserUnreadThreads 指定したユーザーの未読スレッド一覧を取得します
+	//
+	// 成功した場合、UserUnreadThreadの配列とnilを返します。
+	// 存在しないユーザーを指定した場合、空配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetUserUnreadThreads(userID uuid.UUID) ([]*UserUnreadThread, error)
 	// GetChannelLatestMessagesByUserID 指定したユーザーが閲覧可能な全てのパブリックチャンネルの最新のメッセージの一覧を取得します
 	//
 	// 成功した場合、メッセージの配列とnilを返します。負のlimitは無視されます。
@@ -87,7 +206,7 @@ type MessageRepository interface {
 	GetChannelLatestMessagesByUserID(userID uuid.UUID, limit int, subscribeOnly bool) ([]*model.Message, error)
 	// GetArchivedMessagesByID 指定したメッセージのアーカイブメッセージを取得します
 	//
-	// 成功した場合、アーカイブメッセージの配列とnilを返します。
+	// 成功した場合、編集日時の昇順のアーカイブメッセージの配列とnilを返します。
 	// 存在しないメッセージを指定した場合、空配列とnilを返します。
 	// DBによるエラーを返すことがあります。
 	GetArchivedMessagesByID(messageID uuid.UUID) ([]*model.ArchivedMessage, error)
@@ -95,6 +214,7 @@ type MessageRepository interface {
 	//
 	// 成功した場合、そのメッセージスタンプとnilを返します。
 	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルのメッセージを指定した場合、ErrChannelArchivedを返します。
 	// DBによるエラーを返すことがあります。
 	AddStampToMessage(messageID, stampID, userID uuid.UUID, count int) (ms *model.MessageStamp, err error)
 	// RemoveStampFromMessage 指定したメッセージから指定したユーザーの指定したスタンプを全て削除します
@@ -113,3 +233,13 @@ type UserUnreadChannel struct {
 	Since      time.Time `json:"since"`
 	UpdatedAt  time.Time `json:"updatedAt"`
 }
+
+// UserUnreadThread ユーザーの未読スレッド構造体
+type UserUnreadThread struct {
+	ThreadID   uuid.UUID `json:"threadId"`
+	ChannelID  uuid.UUID `json:"channelId"`
+	Count      int       `json:"count"`
+	Noticeable bool      `json:"noticeable"`
+	Since      time.Time `json:"since"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
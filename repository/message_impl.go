@@ -3,6 +3,7 @@ package repository
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/traPtitech/traQ/utils/message"
 
@@ -11,13 +12,22 @@ import (
 	"github.com/leandro-lugaresi/hub"
 	"github.com/traPtitech/traQ/event"
 	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
 )
 
 // CreateMessage implements MessageRepository interface.
 func (repo *GormRepository) CreateMessage(userID, channelID uuid.UUID, text string) (*model.Message, error) {
+	return repo.CreateMessageWithComponents(userID, channelID, text, nil)
+}
+
+// CreateMessageWithComponents implements MessageRepository interface.
+func (repo *GormRepository) CreateMessageWithComponents(userID, channelID uuid.UUID, text string, components model.MessageComponentList) (*model.Message, error) {
 	if userID == uuid.Nil || channelID == uuid.Nil {
 		return nil, ErrNilID
 	}
+	if err := components.Validate(); err != nil {
+		return nil, ArgError("components", err.Error())
+	}
 
 	m := &model.Message{
 		ID:        uuid.Must(uuid.NewV4()),
@@ -27,9 +37,18 @@ func (repo *GormRepository) CreateMessage(userID, channelID uuid.UUID, text stri
 		Stamps:    []model.MessageStamp{},
 	}
 	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if archived, err := repo.isChannelArchived(tx, channelID); err != nil {
+			return err
+		} else if archived {
+			return ErrChannelArchived
+		}
+
 		if err := tx.Create(m).Error; err != nil {
 			return err
 		}
+		if err := repo.setMessageComponents(tx, m, components); err != nil {
+			return err
+		}
 
 		clm := &model.ChannelLatestMessage{
 			ChannelID: m.ChannelID,
@@ -49,7 +68,181 @@ func (repo *GormRepository) CreateMessage(userID, channelID uuid.UUID, text stri
 		return nil, err
 	}
 
-	embedded, plain := message.Parse(text)
+	repo.publishMessageCreated(m)
+	return m, nil
+}
+
+// ImportMessage implements MessageRepository interface.
+func (repo *GormRepository) ImportMessage(args ImportMessageArgs) (*model.Message, error) {
+	if args.UserID == uuid.Nil || args.ChannelID == uuid.Nil {
+		return nil, ErrNilID
+	}
+
+	m := &model.Message{
+		ID:        uuid.Must(uuid.NewV4()),
+		UserID:    args.UserID,
+		ChannelID: args.ChannelID,
+		Text:      args.Text,
+		ThreadID:  args.ThreadID,
+		CreatedAt: args.CreatedAt,
+		UpdatedAt: args.CreatedAt,
+		Stamps:    []model.MessageStamp{},
+	}
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if archived, err := repo.isChannelArchived(tx, args.ChannelID); err != nil {
+			return err
+		} else if archived {
+			return ErrChannelArchived
+		}
+		participants := []uuid.UUID{m.UserID}
+		if args.ThreadID.Valid {
+			var parent model.Message
+			if err := tx.Where(&model.Message{ID: args.ThreadID.UUID}).First(&parent).Error; err != nil {
+				return convertError(err)
+			}
+			// 返信への返信は起点メッセージのスレッドへの返信として扱う
+			if parent.IsReply() {
+				m.ThreadID = parent.ThreadID
+			} else {
+				participants = append(participants, parent.UserID)
+			}
+		}
+
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+		for _, s := range args.Stamps {
+			s.MessageID = m.ID
+			if s.UpdatedAt.IsZero() {
+				s.UpdatedAt = s.CreatedAt
+			}
+			if err := tx.Create(&s).Error; err != nil {
+				return err
+			}
+			m.Stamps = append(m.Stamps, s)
+		}
+
+		if m.ThreadID.Valid {
+			// スレッド集計情報を更新
+			err := tx.
+				Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE reply_count = reply_count + 1, last_reply_at = GREATEST(last_reply_at, VALUES(last_reply_at))").
+				Create(&model.MessageThread{MessageID: m.ThreadID.UUID, ReplyCount: 1, LastReplyAt: m.CreatedAt}).
+				Error
+			if err != nil {
+				return err
+			}
+
+			// スレッド参加者を追加
+			for _, id := range participants {
+				err := tx.
+					Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE thread_id = thread_id").
+					Create(&model.MessageThreadParticipant{ThreadID: m.ThreadID.UUID, UserID: id}).
+					Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// 最新メッセージより新しい場合のみ更新
+		var clm model.ChannelLatestMessage
+		if err := tx.First(&clm, &model.ChannelLatestMessage{ChannelID: m.ChannelID}).Error; err != nil {
+			if !gorm.IsRecordNotFoundError(err) {
+				return err
+			}
+			return tx.Create(&model.ChannelLatestMessage{ChannelID: m.ChannelID, MessageID: m.ID, DateTime: m.CreatedAt}).Error
+		}
+		if clm.DateTime.Before(m.CreatedAt) {
+			return tx.Model(&clm).Updates(&model.ChannelLatestMessage{MessageID: m.ID, DateTime: m.CreatedAt}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CreateReplyMessage implements MessageRepository interface.
+func (repo *GormRepository) CreateReplyMessage(userID, parentID uuid.UUID, text string) (*model.Message, error) {
+	return repo.CreateReplyMessageWithComponents(userID, parentID, text, nil)
+}
+
+// CreateReplyMessageWithComponents implements MessageRepository interface.
+func (repo *GormRepository) CreateReplyMessageWithComponents(userID, parentID uuid.UUID, text string, components model.MessageComponentList) (*model.Message, error) {
+	if userID == uuid.Nil || parentID == uuid.Nil {
+		return nil, ErrNilID
+	}
+	if err := components.Validate(); err != nil {
+		return nil, ArgError("components", err.Error())
+	}
+
+	var m *model.Message
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var parent model.Message
+		if err := tx.Where(&model.Message{ID: parentID}).First(&parent).Error; err != nil {
+			return convertError(err)
+		}
+		if archived, err := repo.isChannelArchived(tx, parent.ChannelID); err != nil {
+			return err
+		} else if archived {
+			return ErrChannelArchived
+		}
+
+		// 返信への返信は起点メッセージのスレッドへの返信として扱う
+		threadID := parent.ID
+		participants := []uuid.UUID{userID, parent.UserID}
+		if parent.IsReply() {
+			threadID = parent.ThreadID.UUID
+			participants = participants[:1]
+		}
+
+		m = &model.Message{
+			ID:        uuid.Must(uuid.NewV4()),
+			UserID:    userID,
+			ChannelID: parent.ChannelID,
+			Text:      text,
+			ThreadID:  uuid.NullUUID{UUID: threadID, Valid: true},
+			Stamps:    []model.MessageStamp{},
+		}
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+		if err := repo.setMessageComponents(tx, m, components); err != nil {
+			return err
+		}
+
+		// スレッド集計情報を更新
+		err := tx.
+			Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE reply_count = reply_count + 1, last_reply_at = VALUES(last_reply_at)").
+			Create(&model.MessageThread{MessageID: threadID, ReplyCount: 1, LastReplyAt: m.CreatedAt}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		// スレッド参加者を追加
+		for _, id := range participants {
+			err := tx.
+				Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE thread_id = thread_id").
+				Create(&model.MessageThreadParticipant{ThreadID: threadID, UserID: id}).
+				Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	repo.publishMessageCreated(m)
+	return m, nil
+}
+
+func (repo *GormRepository) publishMessageCreated(m *model.Message) {
+	embedded, plain := message.Parse(m.Text)
 	repo.hub.Publish(hub.Message{
 		Name: event.MessageCreated,
 		Fields: hub.Fields{
@@ -60,14 +253,21 @@ func (repo *GormRepository) CreateMessage(userID, channelID uuid.UUID, text stri
 		},
 	})
 	messagesCounter.Inc()
-	return m, nil
 }
 
 // UpdateMessage implements MessageRepository interface.
 func (repo *GormRepository) UpdateMessage(messageID uuid.UUID, text string) error {
+	return repo.UpdateMessageWithComponents(messageID, text, nil)
+}
+
+// UpdateMessageWithComponents implements MessageRepository interface.
+func (repo *GormRepository) UpdateMessageWithComponents(messageID uuid.UUID, text string, components model.MessageComponentList) error {
 	if messageID == uuid.Nil {
 		return ErrNilID
 	}
+	if err := components.Validate(); err != nil {
+		return ArgError("components", err.Error())
+	}
 
 	var (
 		old model.Message
@@ -78,6 +278,11 @@ func (repo *GormRepository) UpdateMessage(messageID uuid.UUID, text string) erro
 		if err := tx.First(&old, &model.Message{ID: messageID}).Error; err != nil {
 			return convertError(err)
 		}
+		if archived, err := repo.isChannelArchived(tx, old.ChannelID); err != nil {
+			return err
+		} else if archived {
+			return ErrChannelArchived
+		}
 
 		// archiving
 		if err := tx.Create(&model.ArchivedMessage{
@@ -91,12 +296,21 @@ func (repo *GormRepository) UpdateMessage(messageID uuid.UUID, text string) erro
 		}
 
 		// update
-		if err := tx.Model(&old).Update("text", text).Error; err != nil {
+		if err := tx.Model(&old).Updates(map[string]interface{}{
+			"text":       text,
+			"edit_count": gorm.Expr("edit_count + 1"),
+		}).Error; err != nil {
 			return err
 		}
 
 		ok = true
-		return tx.Where(&model.Message{ID: messageID}).First(&new).Error
+		if err := tx.Where(&model.Message{ID: messageID}).First(&new).Error; err != nil {
+			return err
+		}
+		if components != nil {
+			return repo.setMessageComponents(tx, &new, components)
+		}
+		return nil
 	})
 	if err != nil {
 		return err
@@ -115,7 +329,7 @@ func (repo *GormRepository) UpdateMessage(messageID uuid.UUID, text string) erro
 }
 
 // DeleteMessage implements MessageRepository interface.
-func (repo *GormRepository) DeleteMessage(messageID uuid.UUID) error {
+func (repo *GormRepository) DeleteMessage(messageID, deletedBy uuid.UUID) error {
 	if messageID == uuid.Nil {
 		return ErrNilID
 	}
@@ -128,6 +342,10 @@ func (repo *GormRepository) DeleteMessage(messageID uuid.UUID) error {
 		if err := tx.Where(&model.Message{ID: messageID}).First(&m).Error; err != nil {
 			return convertError(err)
 		}
+		m.DeletedBy = uuid.NullUUID{UUID: deletedBy, Valid: deletedBy != uuid.Nil}
+		if err := tx.Model(&m).UpdateColumn("deleted_by", m.DeletedBy).Error; err != nil {
+			return err
+		}
 		errs := tx.
 			Delete(&m).
 			Delete(model.Unread{}, &model.Unread{MessageID: messageID}).
@@ -137,6 +355,11 @@ func (repo *GormRepository) DeleteMessage(messageID uuid.UUID) error {
 		if len(errs) > 0 {
 			return errs[0]
 		}
+		if m.IsReply() {
+			if err := updateMessageThread(tx, m.ThreadID.UUID); err != nil {
+				return err
+			}
+		}
 		ok = true
 		return nil
 	})
@@ -155,6 +378,176 @@ func (repo *GormRepository) DeleteMessage(messageID uuid.UUID) error {
 	return nil
 }
 
+// GetDeletedMessages implements MessageRepository interface.
+func (repo *GormRepository) GetDeletedMessages(channelID uuid.UUID, limit, offset int) (messages []*model.Message, more bool, err error) {
+	messages = make([]*model.Message, 0)
+	if channelID == uuid.Nil {
+		return messages, false, nil
+	}
+
+	tx := repo.db.
+		Unscoped().
+		Scopes(messagePreloads).
+		Where("channel_id = ? AND deleted_at IS NOT NULL", channelID).
+		Order("deleted_at DESC")
+	if offset > 0 {
+		tx = tx.Offset(offset)
+	}
+
+	if limit > 0 {
+		err = tx.Limit(limit + 1).Find(&messages).Error
+		if len(messages) > limit {
+			return messages[:len(messages)-1], true, err
+		}
+	} else {
+		err = tx.Find(&messages).Error
+	}
+	return messages, false, err
+}
+
+// RestoreMessage implements MessageRepository interface.
+func (repo *GormRepository) RestoreMessage(messageID uuid.UUID) (*model.Message, error) {
+	if messageID == uuid.Nil {
+		return nil, ErrNilID
+	}
+
+	var m model.Message
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", messageID).First(&m).Error; err != nil {
+			return convertError(err)
+		}
+		if archived, err := repo.isChannelArchived(tx, m.ChannelID); err != nil {
+			return err
+		} else if archived {
+			return ErrChannelArchived
+		}
+
+		err := tx.
+			Unscoped().
+			Model(&model.Message{}).
+			Where("id = ?", messageID).
+			UpdateColumns(map[string]interface{}{"deleted_at": nil, "deleted_by": nil}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		if m.IsReply() {
+			// 返信が全て削除されていた場合はスレッドの集計情報も削除されている
+			err := tx.
+				Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE message_id = message_id").
+				Create(&model.MessageThread{MessageID: m.ThreadID.UUID, LastReplyAt: m.CreatedAt}).
+				Error
+			if err != nil {
+				return err
+			}
+			if err := updateMessageThread(tx, m.ThreadID.UUID); err != nil {
+				return err
+			}
+		}
+		return tx.Scopes(messagePreloads).Where(&model.Message{ID: messageID}).Take(&m).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	repo.hub.Publish(hub.Message{
+		Name: event.MessageRestored,
+		Fields: hub.Fields{
+			"message_id": messageID,
+			"message":    &m,
+		},
+	})
+	return &m, nil
+}
+
+// PurgeDeletedMessages implements MessageRepository interface.
+func (repo *GormRepository) PurgeDeletedMessages(before time.Time, limit int) (int, error) {
+	var ids []uuid.UUID
+	err := repo.db.
+		Unscoped().
+		Model(&model.Message{}).
+		Where("deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM messages AS replies WHERE replies.thread_id = messages.id AND replies.deleted_at IS NULL)").
+		Order("deleted_at").
+		Limit(limit).
+		Pluck("id", &ids).
+		Error
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	err = repo.db.Transaction(func(tx *gorm.DB) error {
+		for _, v := range []interface{}{&model.Unread{}, &model.MessageStamp{}, &model.ArchivedMessage{}} {
+			if err := tx.Where("message_id IN (?)", ids).Delete(v).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Where("id IN (?)", ids).Delete(&model.Message{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// SetMessageComponents implements MessageRepository interface.
+func (repo *GormRepository) SetMessageComponents(messageID uuid.UUID, components model.MessageComponentList) error {
+	if messageID == uuid.Nil {
+		return ErrNilID
+	}
+	if err := components.Validate(); err != nil {
+		return ArgError("components", err.Error())
+	}
+
+	var m model.Message
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(&model.Message{ID: messageID}).First(&m).Error; err != nil {
+			return convertError(err)
+		}
+		if components == nil {
+			components = model.MessageComponentList{}
+		}
+		return repo.setMessageComponents(tx, &m, components)
+	})
+	if err != nil {
+		return err
+	}
+	repo.hub.Publish(hub.Message{
+		Name: event.MessageComponentsUpdated,
+		Fields: hub.Fields{
+			"message_id": messageID,
+			"message":    &m,
+		},
+	})
+	return nil
+}
+
+// setMessageComponents メッセージのコンポーネントを設定し、m.Componentsに反映します
+//
+// componentsがnilの場合は何もしません。空の場合はコンポーネントを削除します。
+func (repo *GormRepository) setMessageComponents(tx *gorm.DB, m *model.Message, components model.MessageComponentList) error {
+	if components == nil {
+		return nil
+	}
+	if len(components) == 0 {
+		m.Components = nil
+		return tx.Delete(&model.MessageComponents{MessageID: m.ID}).Error
+	}
+	m.Components = &model.MessageComponents{MessageID: m.ID, Components: components}
+	r := tx.Model(&model.MessageComponents{MessageID: m.ID}).Updates(map[string]interface{}{"components": components})
+	if r.Error != nil {
+		return r.Error
+	}
+	if r.RowsAffected == 0 {
+		return tx.Create(m.Components).Error
+	}
+	return nil
+}
+
 // GetMessageByID implements MessageRepository interface.
 func (repo *GormRepository) GetMessageByID(messageID uuid.UUID) (*model.Message, error) {
 	if messageID == uuid.Nil {
@@ -177,9 +570,9 @@ func (repo *GormRepository) GetMessages(query MessagesQuery) (messages []*model.
 	}
 
 	if query.Asc {
-		tx = tx.Order("messages.created_at")
+		tx = tx.Order("messages.created_at").Order("messages.id")
 	} else {
-		tx = tx.Order("messages.created_at DESC")
+		tx = tx.Order("messages.created_at DESC").Order("messages.id DESC")
 	}
 
 	if query.Channel != uuid.Nil {
@@ -188,19 +581,25 @@ func (repo *GormRepository) GetMessages(query MessagesQuery) (messages []*model.
 	if query.User != uuid.Nil {
 		tx = tx.Where("messages.user_id = ?", query.User)
 	}
+	if query.Thread != uuid.Nil {
+		tx = tx.Where("messages.thread_id = ?", query.Thread)
+	}
+	if query.ExcludeReplies {
+		tx = tx.Where("messages.thread_id IS NULL")
+	}
 
-	if query.Inclusive {
-		if query.Since.Valid {
-			tx = tx.Where("messages.created_at >= ?", query.Since.Time)
-		}
-		if query.Until.Valid {
+	switch {
+	case query.Since.Valid && query.SinceID != uuid.Nil:
+		tx = tx.Where("messages.created_at > ? OR (messages.created_at = ? AND messages.id > ?)", query.Since.Time, query.Since.Time, query.SinceID)
+	case query.Since.Valid && query.Inclusive:
+		tx = tx.Where("messages.created_at >= ?", query.Since.Time)
+	case query.Since.Valid:
+		tx = tx.Where("messages.created_at > ?", query.Since.Time)
+	}
+	if query.Until.Valid {
+		if query.Inclusive {
 			tx = tx.Where("messages.created_at <= ?", query.Until.Time)
-		}
-	} else {
-		if query.Since.Valid {
-			tx = tx.Where("messages.created_at > ?", query.Since.Time)
-		}
-		if query.Until.Valid {
+		} else {
 			tx = tx.Where("messages.created_at < ?", query.Until.Time)
 		}
 	}
@@ -253,7 +652,50 @@ func (repo *GormRepository) GetUserUnreadChannels(userID uuid.UUID) ([]*UserUnre
 	if userID == uuid.Nil {
 		return res, nil
 	}
-	return res, repo.db.Raw(`SELECT m.channel_id AS channel_id, COUNT(m.id) AS count, MAX(u.noticeable) AS noticeable, MIN(m.created_at) AS since, MAX(m.created_at) AS updated_at FROM unreads u JOIN messages m on u.message_id = m.id WHERE u.user_id = ? GROUP BY m.channel_id`, userID).Scan(&res).Error
+	return res, repo.db.Raw(`SELECT m.channel_id AS channel_id, COUNT(m.id) AS count, MAX(u.noticeable) AS noticeable, MIN(m.created_at) AS since, MAX(m.created_at) AS updated_at FROM unreads u JOIN messages m on u.message_id = m.id WHERE u.user_id = ? AND m.thread_id IS NULL GROUP BY m.channel_id`, userID).Scan(&res).Error
+}
+
+// GetUserUnreadThreads implements MessageRepository interface.
+func (repo *GormRepository) GetUserUnreadThreads(userID uuid.UUID) ([]*UserUnreadThread, error) {
+	res := make([]*UserUnreadThread, 0)
+	if userID == uuid.Nil {
+		return res, nil
+	}
+	return res, repo.db.Raw(`SELECT m.thread_id AS thread_id, m.channel_id AS channel_id, COUNT(m.id) AS count, MAX(u.noticeable) AS noticeable, MIN(m.created_at) AS since, MAX(m.created_at) AS updated_at FROM unreads u JOIN messages m on u.message_id = m.id WHERE u.user_id = ? AND m.thread_id IS NOT NULL GROUP BY m.thread_id, m.channel_id`, userID).Scan(&res).Error
+}
+
+// DeleteUnreadsByThreadID implements MessageRepository interface.
+func (repo *GormRepository) DeleteUnreadsByThreadID(threadID, userID uuid.UUID) error {
+	if threadID == uuid.Nil || userID == uuid.Nil {
+		return ErrNilID
+	}
+	result := repo.db.Exec("DELETE unreads FROM unreads INNER JOIN messages ON unreads.user_id = ? AND unreads.message_id = messages.id WHERE messages.thread_id = ?", userID, threadID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		repo.hub.Publish(hub.Message{
+			Name: event.ThreadRead,
+			Fields: hub.Fields{
+				"thread_id": threadID,
+				"user_id":   userID,
+			},
+		})
+	}
+	return nil
+}
+
+// GetThreadParticipantIDs implements MessageRepository interface.
+func (repo *GormRepository) GetThreadParticipantIDs(threadID uuid.UUID) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0)
+	if threadID == uuid.Nil {
+		return ids, nil
+	}
+	return ids, repo.db.
+		Model(&model.MessageThreadParticipant{}).
+		Where(&model.MessageThreadParticipant{ThreadID: threadID}).
+		Pluck("user_id", &ids).
+		Error
 }
 
 // DeleteUnreadsByChannelID implements MessageRepository interface.
@@ -261,7 +703,7 @@ func (repo *GormRepository) DeleteUnreadsByChannelID(channelID, userID uuid.UUID
 	if channelID == uuid.Nil || userID == uuid.Nil {
 		return ErrNilID
 	}
-	result := repo.db.Exec("DELETE unreads FROM unreads INNER JOIN messages ON unreads.user_id = ? AND unreads.message_id = messages.id WHERE messages.channel_id = ?", userID, channelID)
+	result := repo.db.Exec("DELETE unreads FROM unreads INNER JOIN messages ON unreads.user_id = ? AND unreads.message_id = messages.id WHERE messages.channel_id = ? AND messages.thread_id IS NULL", userID, channelID)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -316,6 +758,22 @@ func (repo *GormRepository) AddStampToMessage(messageID, stampID, userID uuid.UU
 		return nil, ErrNilID
 	}
 
+	// アーカイブされたチャンネルのメッセージにはスタンプを押せない
+	archived := 0
+	err = repo.db.
+		Model(&model.Channel{}).
+		Where("id = ?", repo.db.Model(&model.Message{}).Select("channel_id").Where(&model.Message{ID: messageID}).SubQuery()).
+		Where(&model.Channel{IsArchived: true}).
+		Limit(1).
+		Count(&archived).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	if archived > 0 {
+		return nil, ErrChannelArchived
+	}
+
 	err = repo.db.
 		Set("gorm:insert_option", fmt.Sprintf("ON DUPLICATE KEY UPDATE count = count + %d, updated_at = now()", count)).
 		Create(&model.MessageStamp{MessageID: messageID, StampID: stampID, UserID: userID, Count: count}).
@@ -369,5 +827,31 @@ func messagePreloads(db *gorm.DB) *gorm.DB {
 		Preload("Stamps", func(db *gorm.DB) *gorm.DB {
 			return db.Order("updated_at")
 		}).
-		Preload("Pin")
+		Preload("Pin").
+		Preload("Thread").
+		Preload("Components")
+}
+
+// updateMessageThread スレッドの集計情報を再計算します
+func updateMessageThread(tx *gorm.DB, threadID uuid.UUID) error {
+	var r struct {
+		Count       int
+		LastReplyAt null.Time
+	}
+	err := tx.
+		Model(&model.Message{}).
+		Select("COUNT(id) AS count, MAX(created_at) AS last_reply_at").
+		Where("thread_id = ?", threadID).
+		Scan(&r).
+		Error
+	if err != nil {
+		return err
+	}
+	if r.Count == 0 {
+		return tx.Delete(&model.MessageThread{MessageID: threadID}).Error
+	}
+	return tx.Model(&model.MessageThread{MessageID: threadID}).Updates(map[string]interface{}{
+		"reply_count":   r.Count,
+		"last_reply_at": r.LastReplyAt.Time,
+	}).Error
 }
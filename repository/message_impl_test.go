@@ -3,8 +3,11 @@ package repository
 import (
 	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
 	"testing"
+	"time"
 )
 
 func TestRepositoryImpl_CreateMessage(t *testing.T) {
@@ -69,6 +72,164 @@ func TestRepositoryImpl_CreateMessage(t *testing.T) {
 	})
 }
 
+func TestRepositoryImpl_GetMessages_SinceID(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	at := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		_, err := repo.ImportMessage(ImportMessageArgs{UserID: user.GetID(), ChannelID: channel.ID, Text: "a", CreatedAt: at})
+		require.NoError(err)
+	}
+	_, err := repo.ImportMessage(ImportMessageArgs{UserID: user.GetID(), ChannelID: channel.ID, Text: "b", CreatedAt: at.Add(time.Second)})
+	require.NoError(err)
+
+	// 同一時刻のメッセージがページサイズより多くても全て取得できる
+	var (
+		all     []*model.Message
+		since   null.Time
+		sinceID uuid.UUID
+	)
+	for {
+		messages, more, err := repo.GetMessages(MessagesQuery{Channel: channel.ID, Since: since, SinceID: sinceID, Inclusive: true, Limit: 2, Asc: true})
+		require.NoError(err)
+		all = append(all, messages...)
+		if !more {
+			break
+		}
+		since = null.TimeFrom(messages[len(messages)-1].CreatedAt)
+		sinceID = messages[len(messages)-1].ID
+	}
+	if assert.Len(all, 6) {
+		ids := map[uuid.UUID]bool{}
+		for _, m := range all {
+			ids[m.ID] = true
+		}
+		assert.Len(ids, 6)
+		assert.Equal("b", all[5].Text)
+	}
+}
+
+func TestRepositoryImpl_ImportMessage(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	_, err := repo.ImportMessage(ImportMessageArgs{ChannelID: channel.ID, Text: "a"})
+	assert.EqualError(err, ErrNilID.Error())
+	_, err = repo.ImportMessage(ImportMessageArgs{UserID: user.GetID(), Text: "a"})
+	assert.EqualError(err, ErrNilID.Error())
+	_, err = repo.ImportMessage(ImportMessageArgs{UserID: user.GetID(), ChannelID: channel.ID, Text: "a", ThreadID: uuid.NullUUID{UUID: uuid.Must(uuid.NewV4()), Valid: true}})
+	assert.EqualError(err, ErrNotFound.Error())
+
+	stamp := mustMakeStamp(t, repo, random, uuid.Nil)
+	at := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	parent, err := repo.ImportMessage(ImportMessageArgs{
+		UserID:    user.GetID(),
+		ChannelID: channel.ID,
+		Text:      "parent",
+		CreatedAt: at,
+		Stamps:    []model.MessageStamp{{StampID: stamp.ID, UserID: user.GetID(), Count: 1, CreatedAt: at}},
+	})
+	require.NoError(err)
+	assert.True(parent.CreatedAt.Equal(at))
+	assert.True(parent.UpdatedAt.Equal(at))
+
+	m, err := repo.GetMessageByID(parent.ID)
+	if assert.NoError(err) {
+		assert.True(m.CreatedAt.Equal(at))
+		if assert.Len(m.Stamps, 1) {
+			assert.Equal(stamp.ID, m.Stamps[0].StampID)
+			assert.True(m.Stamps[0].CreatedAt.Equal(at))
+		}
+	}
+
+	reply, err := repo.ImportMessage(ImportMessageArgs{
+		UserID:    user.GetID(),
+		ChannelID: channel.ID,
+		Text:      "reply",
+		ThreadID:  uuid.NullUUID{UUID: parent.ID, Valid: true},
+		CreatedAt: at.Add(time.Minute),
+	})
+	if assert.NoError(err) {
+		assert.Equal(parent.ID, reply.ThreadID.UUID)
+		ids, err := repo.GetThreadParticipantIDs(parent.ID)
+		if assert.NoError(err) {
+			assert.ElementsMatch([]uuid.UUID{user.GetID()}, ids)
+		}
+	}
+}
+
+func TestRepositoryImpl_CreateReplyMessage(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
+
+	creator := mustMakeUser(t, repo, random)
+	parent := mustMakeMessage(t, repo, creator.GetID(), channel.ID)
+
+	t.Run("nil id", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := repo.CreateReplyMessage(user.GetID(), uuid.Nil, "a")
+		assert.EqualError(t, err, ErrNilID.Error())
+		_, err = repo.CreateReplyMessage(uuid.Nil, parent.ID, "a")
+		assert.EqualError(t, err, ErrNilID.Error())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := repo.CreateReplyMessage(user.GetID(), uuid.Must(uuid.NewV4()), "a")
+		assert.EqualError(t, err, ErrNotFound.Error())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		r1, err := repo.CreateReplyMessage(user.GetID(), parent.ID, "reply1")
+		if assert.NoError(err) {
+			assert.Equal(channel.ID, r1.ChannelID)
+			assert.True(r1.IsReply())
+			assert.Equal(parent.ID, r1.ThreadID.UUID)
+		}
+
+		// 返信への返信は起点メッセージのスレッドに入る
+		r2, err := repo.CreateReplyMessage(user.GetID(), r1.ID, "reply2")
+		if assert.NoError(err) {
+			assert.Equal(parent.ID, r2.ThreadID.UUID)
+		}
+
+		m, err := repo.GetMessageByID(parent.ID)
+		if assert.NoError(err) && assert.NotNil(m.Thread) {
+			assert.Equal(2, m.Thread.ReplyCount)
+			assert.Equal(r2.CreatedAt.Unix(), m.Thread.LastReplyAt.Unix())
+		}
+
+		ids, err := repo.GetThreadParticipantIDs(parent.ID)
+		if assert.NoError(err) {
+			assert.ElementsMatch([]uuid.UUID{creator.GetID(), user.GetID()}, ids)
+		}
+
+		replies, _, err := repo.GetMessages(MessagesQuery{Thread: parent.ID})
+		if assert.NoError(err) {
+			assert.Len(replies, 2)
+		}
+		messages, _, err := repo.GetMessages(MessagesQuery{Channel: channel.ID, ExcludeReplies: true})
+		if assert.NoError(err) {
+			for _, v := range messages {
+				assert.False(v.IsReply())
+			}
+		}
+
+		if assert.NoError(repo.DeleteMessage(r2.ID, user.GetID())) {
+			m, err := repo.GetMessageByID(parent.ID)
+			if assert.NoError(err) && assert.NotNil(m.Thread) {
+				assert.Equal(1, m.Thread.ReplyCount)
+			}
+		}
+	})
+}
+
 func TestRepositoryImpl_UpdateMessage(t *testing.T) {
 	t.Parallel()
 	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
@@ -83,6 +244,7 @@ func TestRepositoryImpl_UpdateMessage(t *testing.T) {
 	m, err := repo.GetMessageByID(m.ID)
 	if assert.NoError(err) {
 		assert.Equal("new message", m.Text)
+		assert.Equal(1, m.EditCount)
 		assert.Equal(1, count(t, getDB(repo).Model(&model.ArchivedMessage{}).Where(&model.ArchivedMessage{MessageID: m.ID, Text: originalText})))
 	}
 }
@@ -93,13 +255,100 @@ func TestRepositoryImpl_DeleteMessage(t *testing.T) {
 
 	m := mustMakeMessage(t, repo, user.GetID(), channel.ID)
 
-	assert.EqualError(repo.DeleteMessage(uuid.Nil), ErrNilID.Error())
+	assert.EqualError(repo.DeleteMessage(uuid.Nil, user.GetID()), ErrNilID.Error())
 
-	if assert.NoError(repo.DeleteMessage(m.ID)) {
+	if assert.NoError(repo.DeleteMessage(m.ID, user.GetID())) {
 		_, err := repo.GetMessageByID(m.ID)
 		assert.EqualError(err, ErrNotFound.Error())
 	}
-	assert.EqualError(repo.DeleteMessage(m.ID), ErrNotFound.Error())
+	assert.EqualError(repo.DeleteMessage(m.ID, user.GetID()), ErrNotFound.Error())
+}
+
+func TestRepositoryImpl_GetDeletedMessages(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	m1 := mustMakeMessage(t, repo, user.GetID(), channel.ID)
+	m2 := mustMakeMessage(t, repo, user.GetID(), channel.ID)
+	mustMakeMessage(t, repo, user.GetID(), channel.ID)
+	require.NoError(repo.DeleteMessage(m1.ID, user.GetID()))
+	require.NoError(repo.DeleteMessage(m2.ID, uuid.Nil))
+
+	messages, more, err := repo.GetDeletedMessages(channel.ID, 0, 0)
+	if assert.NoError(err) && assert.Len(messages, 2) {
+		assert.False(more)
+		assert.Equal(m2.ID, messages[0].ID)
+		assert.False(messages[0].DeletedBy.Valid)
+		assert.Equal(m1.ID, messages[1].ID)
+		assert.Equal(user.GetID(), messages[1].DeletedBy.UUID)
+		assert.True(messages[1].IsDeleted())
+	}
+
+	messages, more, err = repo.GetDeletedMessages(channel.ID, 1, 0)
+	if assert.NoError(err) && assert.Len(messages, 1) {
+		assert.True(more)
+		assert.Equal(m2.ID, messages[0].ID)
+	}
+
+	messages, _, err = repo.GetDeletedMessages(uuid.Nil, 0, 0)
+	if assert.NoError(err) {
+		assert.Empty(messages)
+	}
+}
+
+func TestRepositoryImpl_RestoreMessage(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	parent := mustMakeMessage(t, repo, user.GetID(), channel.ID)
+	reply, err := repo.CreateReplyMessage(user.GetID(), parent.ID, "reply")
+	require.NoError(err)
+	require.NoError(repo.DeleteMessage(reply.ID, user.GetID()))
+
+	_, err = repo.RestoreMessage(uuid.Nil)
+	assert.EqualError(err, ErrNilID.Error())
+	_, err = repo.RestoreMessage(parent.ID)
+	assert.EqualError(err, ErrNotFound.Error())
+
+	m, err := repo.RestoreMessage(reply.ID)
+	if assert.NoError(err) {
+		assert.Equal(reply.ID, m.ID)
+		assert.False(m.IsDeleted())
+		assert.False(m.DeletedBy.Valid)
+	}
+	m, err = repo.GetMessageByID(parent.ID)
+	if assert.NoError(err) && assert.NotNil(m.Thread) {
+		assert.Equal(1, m.Thread.ReplyCount)
+	}
+	_, err = repo.RestoreMessage(reply.ID)
+	assert.EqualError(err, ErrNotFound.Error())
+}
+
+func TestRepositoryImpl_PurgeDeletedMessages(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, ex3)
+
+	m := mustMakeMessage(t, repo, user.GetID(), channel.ID)
+	mustAddMessageStamp(t, repo, m.ID, mustMakeStamp(t, repo, random, uuid.Nil).ID, user.GetID())
+	require.NoError(repo.DeleteMessage(m.ID, user.GetID()))
+
+	parent := mustMakeMessage(t, repo, user.GetID(), channel.ID)
+	_, err := repo.CreateReplyMessage(user.GetID(), parent.ID, "reply")
+	require.NoError(err)
+	require.NoError(repo.DeleteMessage(parent.ID, user.GetID()))
+
+	n, err := repo.PurgeDeletedMessages(time.Now().Add(-time.Hour), 100)
+	if assert.NoError(err) {
+		assert.Equal(0, n)
+	}
+
+	_, err = repo.PurgeDeletedMessages(time.Now().Add(time.Second), 100)
+	if assert.NoError(err) {
+		assert.Equal(0, count(t, getDB(repo).Unscoped().Model(&model.Message{}).Where(&model.Message{ID: m.ID})))
+		assert.Equal(0, count(t, getDB(repo).Model(&model.MessageStamp{}).Where(&model.MessageStamp{MessageID: m.ID})))
+		// 削除されていない返信があるスレッドの起点メッセージは残る
+		assert.Equal(1, count(t, getDB(repo).Unscoped().Model(&model.Message{}).Where(&model.Message{ID: parent.ID})))
+	}
 }
 
 func TestRepositoryImpl_GetMessageByID(t *testing.T) {
@@ -120,6 +369,95 @@ func TestRepositoryImpl_GetMessageByID(t *testing.T) {
 	assert.Error(err)
 }
 
+func TestRepositoryImpl_SetMessageComponents(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	m := mustMakeMessage(t, repo, user.GetID(), channel.ID)
+	components := model.MessageComponentList{
+		{Type: model.MessageComponentButton, ID: "ok", Label: "OK", Style: model.MessageComponentButtonPrimary},
+	}
+
+	assert.EqualError(repo.SetMessageComponents(uuid.Nil, components), ErrNilID.Error())
+	assert.EqualError(repo.SetMessageComponents(uuid.Must(uuid.NewV4()), components), ErrNotFound.Error())
+	assert.True(IsArgError(repo.SetMessageComponents(m.ID, model.MessageComponentList{{Type: "link", ID: "a", Label: "a"}})))
+
+	if assert.NoError(repo.SetMessageComponents(m.ID, components)) {
+		r, err := repo.GetMessageByID(m.ID)
+		if assert.NoError(err) && assert.NotNil(r.Components) {
+			assert.Len(r.Components.Components, 1)
+		}
+	}
+	if assert.NoError(repo.SetMessageComponents(m.ID, nil)) {
+		r, err := repo.GetMessageByID(m.ID)
+		if assert.NoError(err) {
+			assert.Nil(r.Components)
+		}
+	}
+}
+
+func TestRepositoryImpl_CreateMessageWithComponents(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	components := model.MessageComponentList{
+		{Type: model.MessageComponentButton, ID: "ok", Label: "OK", Style: model.MessageComponentButtonPrimary},
+	}
+
+	_, err := repo.CreateMessageWithComponents(user.GetID(), channel.ID, "a", model.MessageComponentList{{Type: "link", ID: "a", Label: "a"}})
+	assert.True(IsArgError(err))
+
+	m, err := repo.CreateMessageWithComponents(user.GetID(), channel.ID, "a", components)
+	if assert.NoError(err) && assert.NotNil(m.Components) {
+		assert.Len(m.Components.Components, 1)
+		r, err := repo.GetMessageByID(m.ID)
+		if assert.NoError(err) && assert.NotNil(r.Components) {
+			assert.Equal("ok", r.Components.Components[0].ID)
+		}
+	}
+
+	reply, err := repo.CreateReplyMessageWithComponents(user.GetID(), m.ID, "b", components)
+	if assert.NoError(err) && assert.NotNil(reply.Components) {
+		r, err := repo.GetMessageByID(reply.ID)
+		if assert.NoError(err) {
+			assert.NotNil(r.Components)
+		}
+	}
+}
+
+func TestRepositoryImpl_UpdateMessageWithComponents(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	m := mustMakeMessage(t, repo, user.GetID(), channel.ID)
+	components := model.MessageComponentList{
+		{Type: model.MessageComponentButton, ID: "ok", Label: "OK", Style: model.MessageComponentButtonPrimary},
+	}
+
+	assert.True(IsArgError(repo.UpdateMessageWithComponents(m.ID, "a", model.MessageComponentList{{Type: "link", ID: "a", Label: "a"}})))
+
+	if assert.NoError(repo.UpdateMessageWithComponents(m.ID, "a", components)) {
+		r, err := repo.GetMessageByID(m.ID)
+		if assert.NoError(err) && assert.NotNil(r.Components) {
+			assert.Equal("a", r.Text)
+			assert.Len(r.Components.Components, 1)
+		}
+	}
+	// nilの場合はコンポーネントを変更しない
+	if assert.NoError(repo.UpdateMessageWithComponents(m.ID, "b", nil)) {
+		r, err := repo.GetMessageByID(m.ID)
+		if assert.NoError(err) {
+			assert.NotNil(r.Components)
+		}
+	}
+	if assert.NoError(repo.UpdateMessageWithComponents(m.ID, "c", model.MessageComponentList{})) {
+		r, err := repo.GetMessageByID(m.ID)
+		if assert.NoError(err) {
+			assert.Nil(r.Components)
+		}
+	}
+}
+
 func TestRepositoryImpl_SetMessageUnread(t *testing.T) {
 	t.Parallel()
 	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
@@ -177,6 +515,45 @@ func TestRepositoryImpl_DeleteUnreadsByChannelID(t *testing.T) {
 	})
 }
 
+func TestRepositoryImpl_DeleteUnreadsByThreadID(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
+
+	creator := mustMakeUser(t, repo, random)
+	parent := mustMakeMessage(t, repo, creator.GetID(), channel.ID)
+	reply, err := repo.CreateReplyMessage(creator.GetID(), parent.ID, "reply")
+	require.NoError(t, err)
+	mustMakeMessageUnread(t, repo, user.GetID(), parent.ID)
+	mustMakeMessageUnread(t, repo, user.GetID(), reply.ID)
+
+	t.Run("nil id", func(t *testing.T) {
+		t.Parallel()
+
+		assert.EqualError(t, repo.DeleteUnreadsByThreadID(parent.ID, uuid.Nil), ErrNilID.Error())
+		assert.EqualError(t, repo.DeleteUnreadsByThreadID(uuid.Nil, user.GetID()), ErrNilID.Error())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		if threads, err := repo.GetUserUnreadThreads(user.GetID()); assert.NoError(err) && assert.Len(threads, 1) {
+			assert.Equal(parent.ID, threads[0].ThreadID)
+			assert.Equal(1, threads[0].Count)
+		}
+		if channels, err := repo.GetUserUnreadChannels(user.GetID()); assert.NoError(err) && assert.Len(channels, 1) {
+			assert.Equal(1, channels[0].Count)
+		}
+
+		if assert.NoError(repo.DeleteUnreadsByThreadID(parent.ID, user.GetID())) {
+			assert.Equal(1, count(t, getDB(repo).Model(model.Unread{}).Where(&model.Unread{UserID: user.GetID()})))
+			if threads, err := repo.GetUserUnreadThreads(user.GetID()); assert.NoError(err) {
+				assert.Len(threads, 0)
+			}
+		}
+	})
+}
+
 func TestRepositoryImpl_GetChannelLatestMessagesByUserID(t *testing.T) {
 	t.Parallel()
 	repo, _, _, user := setupWithUser(t, ex1)
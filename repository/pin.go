@@ -12,6 +12,7 @@ type PinRepository interface {
 	// 成功した、或いは既にピン留めされていた場合、ピン留めのUUIDとnilを返します。既にピン留めされていた場合にユーザーIDは上書きされません。
 	// 引数にuuid.Nilを指定するとErrNilIDを返します。
 	// 存在しないメッセージを指定した場合はErrNotFoundを返します。
+	// アーカイブされたチャンネルのメッセージを指定した場合、ErrChannelArchivedを返します。
 	// DBによるエラーを返すことがあります。
 	CreatePin(messageID, userID uuid.UUID) (*model.Pin, error)
 	// GetPin 指定したピン留めを取得します
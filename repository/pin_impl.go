@@ -23,6 +23,11 @@ func (repo *GormRepository) CreatePin(messageID, userID uuid.UUID) (*model.Pin,
 		if err := tx.First(&m, &model.Message{ID: messageID}).Error; err != nil {
 			return convertError(err)
 		}
+		if archived, err := repo.isChannelArchived(tx, m.ChannelID); err != nil {
+			return err
+		} else if archived {
+			return ErrChannelArchived
+		}
 
 		if err := tx.First(&p, &model.Pin{MessageID: messageID}).Error; err != nil {
 			if gorm.IsRecordNotFoundError(err) {
@@ -45,6 +50,7 @@ func (repo *GormRepository) CreatePin(messageID, userID uuid.UUID) (*model.Pin,
 				"message_id": messageID,
 				"channel_id": m.ChannelID,
 				"pin_id":     p.ID,
+				"user_id":    userID,
 			},
 		})
 
@@ -100,6 +106,7 @@ func (repo *GormRepository) DeletePin(pinID, userID uuid.UUID) error {
 				"pin_id":     pinID,
 				"channel_id": pin.Message.ChannelID,
 				"message_id": pin.MessageID,
+				"user_id":    userID,
 			},
 		})
 
@@ -29,7 +29,11 @@ type Repository interface {
 	WebhookRepository
 	OAuth2Repository
 	BotRepository
+	BotCommandRepository
 	UserRoleRepository
 	message.ReplaceMapper
 	ClipRepository
+	ScheduledMessageRepository
+	DraftRepository
+	ChannelExportRepository
 }
@@ -136,6 +136,10 @@ func NewGormRepository(db *gorm.DB, fs storage.FileStorage, hub *hub.Hub, logger
 	go func() {
 		sub := hub.Subscribe(10, event.UserOffline)
 		for ev := range sub.Receiver {
+			if event.IsRemote(ev) {
+				// 他インスタンスで発生したイベントはそのインスタンスで記録される
+				continue
+			}
 			userID := ev.Fields["user_id"].(uuid.UUID)
 			datetime := ev.Fields["datetime"].(time.Time)
 			_ = repo.UpdateUser(userID, UpdateUserArgs{LastOnline: null.TimeFrom(datetime)})
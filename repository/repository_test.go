@@ -16,6 +16,7 @@ import (
 	"go.uber.org/zap"
 	"os"
 	"testing"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 )
@@ -281,6 +282,18 @@ func mustMakeClipFolderMessage(t *testing.T, repo Repository, folderID, messageI
 	return cfm
 }
 
+func mustMakeScheduledMessage(t *testing.T, repo Repository, userID, channelID uuid.UUID, scheduledAt time.Time) *model.ScheduledMessage {
+	t.Helper()
+	m, err := repo.CreateScheduledMessage(CreateScheduledMessageArgs{
+		UserID:      userID,
+		ChannelID:   channelID,
+		Text:        "scheduled message test",
+		ScheduledAt: scheduledAt,
+	})
+	require.NoError(t, err)
+	return m
+}
+
 func count(t *testing.T, where *gorm.DB) int {
 	t.Helper()
 	c := 0
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
+)
+
+// CreateScheduledMessageArgs 予約投稿メッセージ作成引数
+type CreateScheduledMessageArgs struct {
+	UserID    uuid.UUID
+	ChannelID uuid.UUID
+	Text      string
+	// ReminderMessageID リマインド対象のメッセージUUID (リマインダーの場合のみ)
+	ReminderMessageID uuid.NullUUID
+	ScheduledAt       time.Time
+}
+
+// ScheduledMessageRepository 予約投稿メッセージリポジトリ
+type ScheduledMessageRepository interface {
+	// CreateScheduledMessage 予約投稿メッセージを作成します
+	//
+	// 成功した場合、予約投稿メッセージとnilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	CreateScheduledMessage(args CreateScheduledMessageArgs) (*model.ScheduledMessage, error)
+	// GetScheduledMessage 指定した予約投稿メッセージを取得します
+	//
+	// 成功した場合、予約投稿メッセージとnilを返します。
+	// 存在しなかった場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetScheduledMessage(id uuid.UUID) (*model.ScheduledMessage, error)
+	// GetScheduledMessagesByUserID 指定したユーザーの予約投稿メッセージを予定日時の昇順で全て取得します
+	//
+	// 成功した場合、予約投稿メッセージの配列とnilを返します。
+	// 存在しないユーザーを指定した場合は空配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetScheduledMessagesByUserID(userID uuid.UUID) ([]*model.ScheduledMessage, error)
+	// GetDueScheduledMessages 指定した日時までに投稿予定の予約投稿メッセージを予定日時の昇順で最大limit件取得します
+	//
+	// 成功した場合、予約投稿メッセージの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetDueScheduledMessages(until time.Time, limit int) ([]*model.ScheduledMessage, error)
+	// UpdateScheduledMessage 指定した予約投稿メッセージの本文・予定日時を変更します
+	//
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// 存在しない予約投稿メッセージを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateScheduledMessage(id uuid.UUID, text null.String, scheduledAt null.Time) error
+	// DeleteScheduledMessage 指定した予約投稿メッセージを削除します
+	//
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// 存在しない予約投稿メッセージを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteScheduledMessage(id uuid.UUID) error
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
+)
+
+// CreateScheduledMessage implements ScheduledMessageRepository interface.
+func (repo *GormRepository) CreateScheduledMessage(args CreateScheduledMessageArgs) (*model.ScheduledMessage, error) {
+	if args.UserID == uuid.Nil || args.ChannelID == uuid.Nil {
+		return nil, ErrNilID
+	}
+	m := &model.ScheduledMessage{
+		ID:                uuid.Must(uuid.NewV4()),
+		UserID:            args.UserID,
+		ChannelID:         args.ChannelID,
+		Text:              args.Text,
+		ReminderMessageID: args.ReminderMessageID,
+		ScheduledAt:       args.ScheduledAt,
+	}
+	if err := repo.db.Create(m).Error; err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GetScheduledMessage implements ScheduledMessageRepository interface.
+func (repo *GormRepository) GetScheduledMessage(id uuid.UUID) (*model.ScheduledMessage, error) {
+	if id == uuid.Nil {
+		return nil, ErrNotFound
+	}
+	var m model.ScheduledMessage
+	if err := repo.db.Where(&model.ScheduledMessage{ID: id}).Take(&m).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &m, nil
+}
+
+// GetScheduledMessagesByUserID implements ScheduledMessageRepository interface.
+func (repo *GormRepository) GetScheduledMessagesByUserID(userID uuid.UUID) ([]*model.ScheduledMessage, error) {
+	messages := make([]*model.ScheduledMessage, 0)
+	if userID == uuid.Nil {
+		return messages, nil
+	}
+	return messages, repo.db.
+		Where(&model.ScheduledMessage{UserID: userID}).
+		Order("scheduled_at").
+		Find(&messages).
+		Error
+}
+
+// GetDueScheduledMessages implements ScheduledMessageRepository interface.
+func (repo *GormRepository) GetDueScheduledMessages(until time.Time, limit int) ([]*model.ScheduledMessage, error) {
+	messages := make([]*model.ScheduledMessage, 0)
+	return messages, repo.db.
+		Where("scheduled_at <= ?", until).
+		Order("scheduled_at").
+		Scopes(limitAndOffset(limit, 0)).
+		Find(&messages).
+		Error
+}
+
+// UpdateScheduledMessage implements ScheduledMessageRepository interface.
+func (repo *GormRepository) UpdateScheduledMessage(id uuid.UUID, text null.String, scheduledAt null.Time) error {
+	if id == uuid.Nil {
+		return ErrNilID
+	}
+
+	changes := map[string]interface{}{}
+	if text.Valid {
+		changes["text"] = text.String
+	}
+	if scheduledAt.Valid {
+		changes["scheduled_at"] = scheduledAt.Time
+	}
+
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		var m model.ScheduledMessage
+		if err := tx.First(&m, &model.ScheduledMessage{ID: id}).Error; err != nil {
+			return convertError(err)
+		}
+		if len(changes) > 0 {
+			return tx.Model(&m).Updates(changes).Error
+		}
+		return nil
+	})
+}
+
+// DeleteScheduledMessage implements ScheduledMessageRepository interface.
+func (repo *GormRepository) DeleteScheduledMessage(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return ErrNilID
+	}
+	result := repo.db.Delete(&model.ScheduledMessage{ID: id})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestRepositoryImpl_CreateScheduledMessage(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	_, err := repo.CreateScheduledMessage(CreateScheduledMessageArgs{ChannelID: channel.ID, Text: "a", ScheduledAt: time.Now()})
+	assert.EqualError(err, ErrNilID.Error())
+	_, err = repo.CreateScheduledMessage(CreateScheduledMessageArgs{UserID: user.GetID(), Text: "a", ScheduledAt: time.Now()})
+	assert.EqualError(err, ErrNilID.Error())
+
+	at := time.Now().Add(time.Hour)
+	m, err := repo.CreateScheduledMessage(CreateScheduledMessageArgs{
+		UserID:      user.GetID(),
+		ChannelID:   channel.ID,
+		Text:        "test",
+		ScheduledAt: at,
+	})
+	if assert.NoError(err) {
+		assert.NotEmpty(m.ID)
+		assert.Equal(user.GetID(), m.UserID)
+		assert.Equal(channel.ID, m.ChannelID)
+		assert.Equal("test", m.Text)
+		assert.False(m.IsReminder())
+	}
+}
+
+func TestRepositoryImpl_GetScheduledMessage(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	m := mustMakeScheduledMessage(t, repo, user.GetID(), channel.ID, time.Now().Add(time.Hour))
+
+	r, err := repo.GetScheduledMessage(m.ID)
+	if assert.NoError(err) {
+		assert.Equal(m.ID, r.ID)
+		assert.Equal(m.Text, r.Text)
+	}
+
+	_, err = repo.GetScheduledMessage(uuid.Nil)
+	assert.EqualError(err, ErrNotFound.Error())
+	_, err = repo.GetScheduledMessage(uuid.Must(uuid.NewV4()))
+	assert.EqualError(err, ErrNotFound.Error())
+}
+
+func TestRepositoryImpl_GetScheduledMessagesByUserID(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
+
+	m2 := mustMakeScheduledMessage(t, repo, user.GetID(), channel.ID, time.Now().Add(2*time.Hour))
+	m1 := mustMakeScheduledMessage(t, repo, user.GetID(), channel.ID, time.Now().Add(time.Hour))
+
+	t.Run("nil id", func(t *testing.T) {
+		t.Parallel()
+
+		ms, err := repo.GetScheduledMessagesByUserID(uuid.Nil)
+		if assert.NoError(t, err) {
+			assert.Len(t, ms, 0)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ms, err := repo.GetScheduledMessagesByUserID(user.GetID())
+		if assert.NoError(t, err) && assert.Len(t, ms, 2) {
+			assert.Equal(t, m1.ID, ms[0].ID)
+			assert.Equal(t, m2.ID, ms[1].ID)
+		}
+	})
+}
+
+func TestRepositoryImpl_GetDueScheduledMessages(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	due := mustMakeScheduledMessage(t, repo, user.GetID(), channel.ID, time.Now().Add(-time.Minute))
+	notDue := mustMakeScheduledMessage(t, repo, user.GetID(), channel.ID, time.Now().Add(time.Hour))
+
+	ms, err := repo.GetDueScheduledMessages(time.Now(), 100)
+	if assert.NoError(err) {
+		ids := make([]uuid.UUID, len(ms))
+		for i, m := range ms {
+			ids[i] = m.ID
+		}
+		assert.Contains(ids, due.ID)
+		assert.NotContains(ids, notDue.ID)
+	}
+}
+
+func TestRepositoryImpl_UpdateScheduledMessage(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	m := mustMakeScheduledMessage(t, repo, user.GetID(), channel.ID, time.Now().Add(time.Hour))
+
+	assert.EqualError(repo.UpdateScheduledMessage(uuid.Nil, null.StringFrom("a"), null.Time{}), ErrNilID.Error())
+	assert.EqualError(repo.UpdateScheduledMessage(uuid.Must(uuid.NewV4()), null.StringFrom("a"), null.Time{}), ErrNotFound.Error())
+
+	at := time.Now().Add(3 * time.Hour)
+	if assert.NoError(repo.UpdateScheduledMessage(m.ID, null.StringFrom("updated"), null.TimeFrom(at))) {
+		r, err := repo.GetScheduledMessage(m.ID)
+		if assert.NoError(err) {
+			assert.Equal("updated", r.Text)
+			assert.Equal(at.Unix(), r.ScheduledAt.Unix())
+		}
+	}
+}
+
+func TestRepositoryImpl_DeleteScheduledMessage(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	m := mustMakeScheduledMessage(t, repo, user.GetID(), channel.ID, time.Now().Add(time.Hour))
+
+	assert.EqualError(repo.DeleteScheduledMessage(uuid.Nil), ErrNilID.Error())
+	assert.EqualError(repo.DeleteScheduledMessage(uuid.Must(uuid.NewV4())), ErrNotFound.Error())
+	if assert.NoError(repo.DeleteScheduledMessage(m.ID)) {
+		_, err := repo.GetScheduledMessage(m.ID)
+		assert.EqualError(err, ErrNotFound.Error())
+	}
+}
@@ -25,6 +25,7 @@ type WebhookRepository interface {
 	CreateWebhook(name, description string, channelID, creatorID uuid.UUID, secret string) (model.Webhook, error)
 	// UpdateWebhook Webhookを更新します
 	//
+	// プライベートチャンネルは、WebhookのBOTユーザーがメンバーである場合のみ指定できます。
 	// 成功した場合、nilを返します。
 	// 存在しないWebhookの場合、ErrNotFoundを返します。
 	// 更新内容に問題がある場合、ArgumentErrorを返します。
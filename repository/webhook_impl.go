@@ -114,7 +114,15 @@ func (repo *GormRepository) UpdateWebhook(id uuid.UUID, args UpdateWebhookArgs)
 				return err
 			}
 			if !ch.IsPublic {
-				return ArgError("args.ChannelID", "private channels are not allowed")
+				// プライベートチャンネルはWebhookのBOTユーザーがメンバーの場合のみ許可
+				if ch.IsDMChannel() {
+					return ArgError("args.ChannelID", "dm channels are not allowed")
+				}
+				if ok, err := dbExists(tx, &model.UsersPrivateChannel{UserID: w.BotUserID, ChannelID: ch.ID}); err != nil {
+					return err
+				} else if !ok {
+					return ArgError("args.ChannelID", "the Webhook is not a member of the private channel")
+				}
 			}
 
 			changes["channel_id"] = args.ChannelID.UUID
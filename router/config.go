@@ -2,13 +2,17 @@ package router
 
 import (
 	"github.com/leandro-lugaresi/hub"
+	botws "github.com/traPtitech/traQ/bot/ws"
 	"github.com/traPtitech/traQ/rbac"
 	"github.com/traPtitech/traQ/realtime"
 	"github.com/traPtitech/traQ/realtime/sse"
 	"github.com/traPtitech/traQ/realtime/ws"
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router/auth"
+	"github.com/traPtitech/traQ/router/middlewares"
+	"github.com/traPtitech/traQ/search"
 	"go.uber.org/zap"
+	"time"
 )
 
 // Config APIサーバー設定
@@ -31,8 +35,12 @@ type Config struct {
 	IsRefreshEnabled bool
 	// SkyWaySecretKey SkyWayクレデンシャル用シークレットキー
 	SkyWaySecretKey string
+	// MessageEditLimit メッセージを投稿後に編集可能な期間 0の場合は無制限
+	MessageEditLimit time.Duration
 	// ExternalAuth 外部認証設定
 	ExternalAuth ExternalAuthConfig
+	// RateLimit Bot・Webhookのレート制限設定
+	RateLimit RateLimitConfig
 	// Hub イベントハブ
 	Hub *hub.Hub
 	// Repository リポジトリ
@@ -41,14 +49,30 @@ type Config struct {
 	RBAC rbac.RBAC
 	// WS WebSocketストリーマー
 	WS *ws.Streamer
+	// BotWS BOT用WebSocketストリーマー
+	BotWS *botws.Streamer
 	// SSE SSEストリーマー
 	SSE *sse.Streamer
 	// Realtime リアルタイムサービス
 	Realtime *realtime.Service
+	// SearchEngine メッセージ検索エンジン
+	SearchEngine search.Engine
 	// RootLogger ルートロガー
 	RootLogger *zap.Logger
 }
 
+// RateLimitConfig Bot・Webhookのレート制限設定
+type RateLimitConfig struct {
+	// Enabled 有効かどうか
+	Enabled bool
+	// Bot Botのレート制限
+	Bot middlewares.RateLimitConfig
+	// PrivilegedBot 特権Botのレート制限
+	PrivilegedBot middlewares.RateLimitConfig
+	// Webhook Webhookのレート制限
+	Webhook middlewares.RateLimitConfig
+}
+
 // ExternalAuth 外部認証設定
 type ExternalAuthConfig struct {
 	// GitHub GitHub OAuth2
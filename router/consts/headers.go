@@ -13,4 +13,5 @@ const (
 	HeaderChannelID         = "X-TRAQ-Channel-Id"
 	HeaderMore              = "X-TRAQ-More"
 	HeaderVersion           = "X-TRAQ-VERSION"
+	HeaderRetryAfter        = "Retry-After"
 )
@@ -1,20 +1,23 @@
 package consts
 
 const (
-	KeyTraceID            = "traceId"
-	KeyLogger             = "logger"
-	KeyUserID             = "userID"
-	KeyUser               = "user"
-	KeyOAuth2AccessScopes = "scopes"
-	KeyParamStamp         = "paramStamp"
-	KeyParamStampPalette  = "paramStampPalette"
-	KeyParamGroup         = "paramGroup"
-	KeyParamUser          = "paramUser"
-	KeyParamClient        = "paramClient"
-	KeyParamBot           = "paramBot"
-	KeyParamWebhook       = "paramWebhook"
-	KeyParamMessage       = "paramMessage"
-	KeyParamChannel       = "paramChannel"
-	KeyParamFile          = "paramFile"
-	KeyParamClipFolder    = "paramClipFolder"
+	KeyTraceID               = "traceId"
+	KeyLogger                = "logger"
+	KeyUserID                = "userID"
+	KeyUser                  = "user"
+	KeyOAuth2AccessScopes    = "scopes"
+	KeyClientKey             = "clientKey"
+	KeyParamStamp            = "paramStamp"
+	KeyParamStampPalette     = "paramStampPalette"
+	KeyParamGroup            = "paramGroup"
+	KeyParamUser             = "paramUser"
+	KeyParamClient           = "paramClient"
+	KeyParamBot              = "paramBot"
+	KeyParamWebhook          = "paramWebhook"
+	KeyParamMessage          = "paramMessage"
+	KeyParamChannel          = "paramChannel"
+	KeyParamFile             = "paramFile"
+	KeyParamClipFolder       = "paramClipFolder"
+	KeyParamScheduledMessage = "paramScheduledMessage"
+	KeyParamChannelExport    = "paramChannelExport"
 )
@@ -1,19 +1,21 @@
 package consts
 
 const (
-	ParamChannelID      = "channelID"
-	ParamPinID          = "pinID"
-	ParamUserID         = "userID"
-	ParamGroupID        = "groupID"
-	ParamTagID          = "tagID"
-	ParamStampID        = "stampID"
-	ParamStampPaletteID = "paletteID"
-	ParamMessageID      = "messageID"
-	ParamReferenceID    = "referenceID"
-	ParamFileID         = "fileID"
-	ParamWebhookID      = "webhookID"
-	ParamTokenID        = "tokenID"
-	ParamBotID          = "botID"
-	ParamClientID       = "clientID"
-	ParamClipFolderID   = "folderID"
+	ParamChannelID          = "channelID"
+	ParamPinID              = "pinID"
+	ParamUserID             = "userID"
+	ParamGroupID            = "groupID"
+	ParamTagID              = "tagID"
+	ParamStampID            = "stampID"
+	ParamStampPaletteID     = "paletteID"
+	ParamMessageID          = "messageID"
+	ParamReferenceID        = "referenceID"
+	ParamFileID             = "fileID"
+	ParamWebhookID          = "webhookID"
+	ParamTokenID            = "tokenID"
+	ParamBotID              = "botID"
+	ParamClientID           = "clientID"
+	ParamClipFolderID       = "folderID"
+	ParamScheduledMessageID = "scheduledMessageID"
+	ParamChannelExportID    = "exportID"
 )
@@ -14,6 +14,10 @@ type CtxKey int
 const (
 	// CtxUserIDKey ユーザーUUIDキー
 	CtxUserIDKey CtxKey = iota
+	// CtxClientKeyKey クライアント識別キー
+	//
+	// リクエストの認証に用いられたOAuth2トークン又はセッションを識別する文字列です。
+	CtxClientKeyKey
 )
 
 // Context echo.Contextのカスタム
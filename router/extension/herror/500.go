@@ -3,6 +3,7 @@ package herror
 import (
 	"fmt"
 	"github.com/blendle/zapdriver"
+	"github.com/traPtitech/traQ/repository"
 	"go.uber.org/zap"
 	"runtime"
 	"runtime/debug"
@@ -29,3 +30,17 @@ func InternalServerError(err error) error {
 		Fields: []zap.Field{zapdriver.ErrorReport(runtime.Caller(1)), zap.Error(err)},
 	}
 }
+
+// ChannelWriteError チャンネルへの書き込み操作で発生したエラーをHTTPエラーに変換します
+//
+// アーカイブされたチャンネルへの書き込みの場合は400、それ以外の場合は500を返します
+func ChannelWriteError(err error) error {
+	if err == repository.ErrChannelArchived {
+		return BadRequest("channel is archived")
+	}
+	return &InternalError{
+		Err:    err,
+		Stack:  debug.Stack(),
+		Fields: []zap.Field{zapdriver.ErrorReport(runtime.Caller(1)), zap.Error(err)},
+	}
+}
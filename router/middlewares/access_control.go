@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"fmt"
+	"github.com/gofrs/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/traPtitech/traQ/model"
 	"github.com/traPtitech/traQ/rbac"
@@ -15,7 +16,9 @@ import (
 )
 
 // AccessControlMiddlewareGenerator アクセスコントロールミドルウェアのジェネレーターを返します
-func AccessControlMiddlewareGenerator(r rbac.RBAC) func(p ...rbac.Permission) echo.MiddlewareFunc {
+//
+// チャンネル毎の権限を要求しているBotの場合、permission.BotChannelScopedの権限はBotがチャンネルで許可されている権限の範囲でのみ通します。
+func AccessControlMiddlewareGenerator(r rbac.RBAC, repo repository.Repository) func(p ...rbac.Permission) echo.MiddlewareFunc {
 	return func(p ...rbac.Permission) echo.MiddlewareFunc {
 		return func(next echo.HandlerFunc) echo.HandlerFunc {
 			return func(c echo.Context) error {
@@ -38,12 +41,82 @@ func AccessControlMiddlewareGenerator(r rbac.RBAC) func(p ...rbac.Permission) ec
 					}
 				}
 
+				// Botのチャンネル毎権限検証
+				if user.IsBot() {
+					if ok, err := isBotPermitted(c, repo, user.GetID(), p); err != nil {
+						return herror.InternalServerError(err)
+					} else if !ok {
+						return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("you are not permitted to request to '%s' in this channel", c.Request().URL.Path))
+					}
+				}
+
 				return next(c) // OK
 			}
 		}
 	}
 }
 
+// isBotPermitted Botがリクエスト先のチャンネルでpの権限を全て許可されているかどうかを返します
+//
+// リクエスト先のチャンネルが定まらない場合は、Botが要求している権限に含まれているかどうかを返します。
+func isBotPermitted(c echo.Context, repo repository.Repository, botUserID uuid.UUID, p []rbac.Permission) (bool, error) {
+	scoped := false
+	for _, v := range p {
+		if permission.BotChannelScoped.Has(v) {
+			scoped = true
+			break
+		}
+	}
+	if !scoped {
+		return true, nil
+	}
+
+	b, err := repo.GetBotByBotUserID(botUserID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return true, nil // Webhookなど
+		}
+		return false, err
+	}
+	if !b.ChannelScoped {
+		return true, nil // チャンネル毎の権限を要求していないBot
+	}
+
+	granted := b.Permissions
+	if channelID, ok := requestChannelID(c); ok {
+		j, err := repo.GetBotJoinChannel(b.ID, channelID)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return false, nil // 参加していないチャンネル
+			}
+			return false, err
+		}
+		granted = j.Permissions
+	}
+	for _, v := range p {
+		if permission.BotChannelScoped.Has(v) && !granted.Contains(v) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// requestChannelID リクエスト先のチャンネルのIDを返します
+//
+// 取得済みのメッセージ・チャンネル、或いはパスパラメータから判断します。
+func requestChannelID(c echo.Context) (uuid.UUID, bool) {
+	if m, ok := c.Get(consts.KeyParamMessage).(*model.Message); ok {
+		return m.ChannelID, true
+	}
+	if ch, ok := c.Get(consts.KeyParamChannel).(*model.Channel); ok {
+		return ch.ID, true
+	}
+	if id, err := uuid.FromString(c.Param(consts.ParamChannelID)); err == nil {
+		return id, true
+	}
+	return uuid.Nil, false
+}
+
 // AdminOnly 管理者ユーザーのみを通すミドルウェア
 func AdminOnly(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -188,6 +261,31 @@ func CheckChannelAccessPerm(rbac rbac.RBAC, repo repository.Repository) echo.Mid
 	}
 }
 
+// CheckPrivateChannelManagePerm プライベートチャンネルのメンバー管理権限を確認するミドルウェア
+//
+// チャンネルのメンバー或いは管理者ユーザーのみを通します。
+func CheckPrivateChannelManagePerm(rbac rbac.RBAC, repo repository.Repository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := c.Get(consts.KeyUser).(model.UserInfo)
+			ch := c.Get(consts.KeyParamChannel).(*model.Channel)
+
+			if user.GetRole() == role.Admin {
+				return next(c)
+			}
+
+			// アクセス権確認
+			if ok, err := repo.IsChannelAccessibleToUser(user.GetID(), ch.ID); err != nil {
+				return herror.InternalServerError(err)
+			} else if !ok {
+				return herror.NotFound()
+			}
+
+			return next(c)
+		}
+	}
+}
+
 // CheckUserGroupAdminPerm UserGroup管理者権限を確認するミドルウェア
 func CheckUserGroupAdminPerm(rbac rbac.RBAC, repo repository.Repository) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -218,3 +316,33 @@ func CheckClipFolderAccessPerm(rbac rbac.RBAC, repo repository.Repository) echo.
 		}
 	}
 }
+
+// CheckScheduledMessageAccessPerm ScheduledMessageアクセス権限を確認するミドルウェア
+func CheckScheduledMessageAccessPerm(rbac rbac.RBAC, repo repository.Repository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := c.Get(consts.KeyUser).(model.UserInfo)
+			m := c.Get(consts.KeyParamScheduledMessage).(*model.ScheduledMessage)
+			if user.GetID() == m.UserID {
+				return next(c) // 作成者のアクセス
+			}
+
+			return herror.Forbidden()
+		}
+	}
+}
+
+// CheckChannelExportAccessPerm ChannelExportアクセス権限を確認するミドルウェア
+func CheckChannelExportAccessPerm(rbac rbac.RBAC, repo repository.Repository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := c.Get(consts.KeyUser).(model.UserInfo)
+			e := c.Get(consts.KeyParamChannelExport).(*model.ChannelExport)
+			if user.GetID() == e.UserID {
+				return next(c) // 要求者のアクセス
+			}
+
+			return herror.Forbidden()
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/rbac"
+	"github.com/traPtitech/traQ/rbac/permission"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/consts"
+)
+
+type botRepository struct {
+	repository.Repository
+	bot   *model.Bot
+	joins map[uuid.UUID]*model.BotJoinChannel
+}
+
+func (repo *botRepository) GetBotByBotUserID(id uuid.UUID) (*model.Bot, error) {
+	if repo.bot.BotUserID != id {
+		return nil, repository.ErrNotFound
+	}
+	return repo.bot, nil
+}
+
+func (repo *botRepository) GetBotJoinChannel(botID, channelID uuid.UUID) (*model.BotJoinChannel, error) {
+	j, ok := repo.joins[channelID]
+	if !ok || repo.bot.ID != botID {
+		return nil, repository.ErrNotFound
+	}
+	return j, nil
+}
+
+func TestRequestChannelID(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	channelID := uuid.Must(uuid.NewV4())
+	messageChannelID := uuid.Must(uuid.NewV4())
+
+	newContext := func() echo.Context {
+		return e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	}
+
+	c := newContext()
+	_, ok := requestChannelID(c)
+	assert.False(t, ok)
+
+	c = newContext()
+	c.Set(consts.KeyParamChannel, &model.Channel{ID: channelID})
+	id, ok := requestChannelID(c)
+	assert.True(t, ok)
+	assert.Equal(t, channelID, id)
+
+	// メッセージが優先
+	c.Set(consts.KeyParamMessage, &model.Message{ChannelID: messageChannelID})
+	id, ok = requestChannelID(c)
+	assert.True(t, ok)
+	assert.Equal(t, messageChannelID, id)
+}
+
+func TestIsBotPermitted_NotScoped(t *testing.T) {
+	t.Parallel()
+
+	// チャンネル毎の権限でない場合はBotを取得せずに通す
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	ok, err := isBotPermitted(c, nil, uuid.Must(uuid.NewV4()), []rbac.Permission{permission.GetUser, permission.GetChannel})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestIsBotPermitted(t *testing.T) {
+	t.Parallel()
+
+	channelID := uuid.Must(uuid.NewV4())
+	newRepo := func(scoped bool, requested, granted model.BotPermissions) *botRepository {
+		b := &model.Bot{
+			ID:            uuid.Must(uuid.NewV4()),
+			BotUserID:     uuid.Must(uuid.NewV4()),
+			Permissions:   requested,
+			ChannelScoped: scoped,
+		}
+		return &botRepository{
+			bot:   b,
+			joins: map[uuid.UUID]*model.BotJoinChannel{channelID: {BotID: b.ID, ChannelID: channelID, Permissions: granted}},
+		}
+	}
+	check := func(repo *botRepository, channel bool) bool {
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		if channel {
+			c.Set(consts.KeyParamChannel, &model.Channel{ID: channelID})
+		}
+		ok, err := isBotPermitted(c, repo, repo.bot.BotUserID, []rbac.Permission{permission.PostMessage})
+		assert.NoError(t, err)
+		return ok
+	}
+
+	// チャンネル毎の権限を要求していないBot
+	assert.True(t, check(newRepo(false, model.BotPermissions{}, model.BotPermissions{}), true))
+
+	// 要求している権限が空でもチャンネル毎の権限の制限を受ける
+	assert.False(t, check(newRepo(true, model.BotPermissions{}, model.BotPermissions{}), true))
+	assert.False(t, check(newRepo(true, model.BotPermissions{}, model.BotPermissions{}), false))
+
+	// 参加直後は許可されている権限が空なので、要求していても拒否される
+	repo := newRepo(true, model.BotPermissions{permission.PostMessage: true}, model.BotPermissions{})
+	assert.False(t, check(repo, true))
+
+	// チャンネルで許可されると通す
+	repo.joins[channelID].Permissions = model.BotPermissions{permission.PostMessage: true}
+	assert.True(t, check(repo, true))
+	repo.joins[channelID].Permissions = model.BotPermissions{}
+	assert.False(t, check(repo, true))
+}
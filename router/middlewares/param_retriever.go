@@ -166,3 +166,17 @@ func (pr *ParamRetriever) ClipFolderID() echo.MiddlewareFunc {
 		return pr.repo.GetClipFolder(v)
 	})
 }
+
+// ScheduledMessageID リクエストURLの`scheduledMessageID`パラメータからScheduledMessageを取り出す
+func (pr *ParamRetriever) ScheduledMessageID() echo.MiddlewareFunc {
+	return pr.byUUID(consts.ParamScheduledMessageID, consts.KeyParamScheduledMessage, func(c echo.Context, v uuid.UUID) (interface{}, error) {
+		return pr.repo.GetScheduledMessage(v)
+	})
+}
+
+// ChannelExportID リクエストURLの`exportID`パラメータからChannelExportを取り出す
+func (pr *ParamRetriever) ChannelExportID() echo.MiddlewareFunc {
+	return pr.byUUID(consts.ParamChannelExportID, consts.KeyParamChannelExport, func(c echo.Context, v uuid.UUID) (interface{}, error) {
+		return pr.repo.GetChannelExport(v)
+	})
+}
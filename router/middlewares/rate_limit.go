@@ -0,0 +1,230 @@
+package middlewares
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension/herror"
+)
+
+const (
+	rateLimitWindow        = time.Minute
+	rateLimitPrivilegedTTL = time.Minute
+
+	rateLimitKindAPI     = "api"
+	rateLimitKindMessage = "message"
+	rateLimitKindStamp   = "stamp"
+	rateLimitKindWebhook = "webhook"
+)
+
+var rateLimitedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "traq",
+	Name:      "rate_limited_requests_total",
+}, []string{"kind"})
+
+// RateLimitConfig レート制限設定
+//
+// 全て1分あたりの回数です。0の場合は無制限です。
+type RateLimitConfig struct {
+	// MessagesPerChannel チャンネル毎のメッセージ投稿数
+	MessagesPerChannel int
+	// Stamps スタンプ付与数
+	Stamps int
+	// APICalls API呼び出し数
+	APICalls int
+}
+
+// RateLimiter Bot・Webhookのレート制限器
+//
+// カウントはプロセス毎に保持されます。
+type RateLimiter struct {
+	repo repository.Repository
+	// Bot Botのレート制限
+	Bot RateLimitConfig
+	// PrivilegedBot 特権Botのレート制限
+	//
+	// 特権Botの場合はBotの代わりにこちらが使用されます。
+	PrivilegedBot RateLimitConfig
+	// Webhook Webhookのレート制限
+	//
+	// MessagesPerChannelのみが使用されます。
+	Webhook RateLimitConfig
+
+	mu         sync.Mutex
+	windows    map[string]*rateWindow
+	lastSweep  time.Time
+	privileged map[uuid.UUID]privilegedCache
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+type privilegedCache struct {
+	privileged bool
+	expiresAt  time.Time
+}
+
+// NewRateLimiter RateLimiterを生成します
+func NewRateLimiter(repo repository.Repository, bot, privilegedBot, webhook RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		repo:          repo,
+		Bot:           bot,
+		PrivilegedBot: privilegedBot,
+		Webhook:       webhook,
+		windows:       map[string]*rateWindow{},
+		privileged:    map[uuid.UUID]privilegedCache{},
+	}
+}
+
+// APICall BotのAPI呼び出しを制限するミドルウェア
+//
+// UserAuthenticateの後に使用してください。Bot以外のユーザーは制限されません。
+func (l *RateLimiter) APICall() echo.MiddlewareFunc {
+	return l.botLimit(rateLimitKindAPI, func(c RateLimitConfig) int { return c.APICalls }, func(c echo.Context) string { return "" })
+}
+
+// Message Botのメッセージ投稿を制限するミドルウェア
+//
+// チャンネル毎に制限します。投稿先はパスパラメータまたは取得済みのメッセージから判断します。
+func (l *RateLimiter) Message() echo.MiddlewareFunc {
+	return l.botLimit(rateLimitKindMessage, func(c RateLimitConfig) int { return c.MessagesPerChannel }, messageTargetKey)
+}
+
+// Stamp Botのスタンプ付与を制限するミドルウェア
+func (l *RateLimiter) Stamp() echo.MiddlewareFunc {
+	return l.botLimit(rateLimitKindStamp, func(c RateLimitConfig) int { return c.Stamps }, func(c echo.Context) string { return "" })
+}
+
+// TakeWebhookMessage Webhookのメッセージ投稿を1回分数えます
+//
+// Webhook・投稿先チャンネル毎に制限します。制限を超えた場合は429エラーを返します。
+// 未認証のリクエストで枠を消費しないよう、署名と投稿先チャンネルを検証した後に呼び出してください。
+func (l *RateLimiter) TakeWebhookMessage(c echo.Context, webhookID, channelID uuid.UUID) error {
+	if l == nil {
+		return nil
+	}
+	key := fmt.Sprintf("%s:%s:%s", rateLimitKindWebhook, webhookID, channelID)
+	if ok, retryAfter := l.take(key, l.Webhook.MessagesPerChannel, time.Now()); !ok {
+		return tooManyRequests(c, rateLimitKindWebhook, retryAfter)
+	}
+	return nil
+}
+
+func (l *RateLimiter) botLimit(kind string, limitOf func(c RateLimitConfig) int, targetKey func(c echo.Context) string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if l == nil {
+				return next(c)
+			}
+			user := c.Get(consts.KeyUser).(model.UserInfo)
+			if !user.IsBot() {
+				return next(c)
+			}
+
+			config := l.Bot
+			if privileged, err := l.isPrivileged(user.GetID()); err != nil {
+				return herror.InternalServerError(err)
+			} else if privileged {
+				config = l.PrivilegedBot
+			}
+
+			key := fmt.Sprintf("%s:%s:%s", kind, user.GetID(), targetKey(c))
+			if ok, retryAfter := l.take(key, limitOf(config), time.Now()); !ok {
+				return tooManyRequests(c, kind, retryAfter)
+			}
+			return next(c)
+		}
+	}
+}
+
+// take keyのカウントを1増やします。制限を超える場合はfalseと再試行可能になるまでの時間を返します
+func (l *RateLimiter) take(key string, limit int, now time.Time) (bool, time.Duration) {
+	if limit <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 期限切れのカウントを掃除
+	if now.Sub(l.lastSweep) >= rateLimitWindow {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= rateLimitWindow {
+				delete(l.windows, k)
+			}
+		}
+		for k, v := range l.privileged {
+			if now.After(v.expiresAt) {
+				delete(l.privileged, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= rateLimitWindow {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+	if w.count >= limit {
+		return false, w.start.Add(rateLimitWindow).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// isPrivileged 指定したBotユーザーが特権Botかどうかを返します
+//
+// Webhookなど、Botが見つからない場合はfalseを返します。
+func (l *RateLimiter) isPrivileged(botUserID uuid.UUID) (bool, error) {
+	now := time.Now()
+	l.mu.Lock()
+	v, ok := l.privileged[botUserID]
+	l.mu.Unlock()
+	if ok && now.Before(v.expiresAt) {
+		return v.privileged, nil
+	}
+
+	privileged := false
+	b, err := l.repo.GetBotByBotUserID(botUserID)
+	switch err {
+	case nil:
+		privileged = b.Privileged
+	case repository.ErrNotFound:
+	default:
+		return false, err
+	}
+
+	l.mu.Lock()
+	l.privileged[botUserID] = privilegedCache{privileged: privileged, expiresAt: now.Add(rateLimitPrivilegedTTL)}
+	l.mu.Unlock()
+	return privileged, nil
+}
+
+// messageTargetKey メッセージの投稿先を表すキーを返します
+func messageTargetKey(c echo.Context) string {
+	if channelID, ok := requestChannelID(c); ok {
+		return channelID.String()
+	}
+	// DMはユーザー毎
+	return "user:" + c.Param(consts.ParamUserID)
+}
+
+func tooManyRequests(c echo.Context, kind string, retryAfter time.Duration) error {
+	rateLimitedCounter.WithLabelValues(kind).Inc()
+	c.Response().Header().Set(consts.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	return echo.NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+}
@@ -0,0 +1,79 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/traPtitech/traQ/router/consts"
+)
+
+func TestRateLimiter_take(t *testing.T) {
+	t.Parallel()
+
+	l := NewRateLimiter(nil, RateLimitConfig{}, RateLimitConfig{}, RateLimitConfig{})
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.take("a", 3, now)
+		assert.True(t, ok)
+	}
+	ok, retryAfter := l.take("a", 3, now.Add(10*time.Second))
+	assert.False(t, ok)
+	assert.Equal(t, 50*time.Second, retryAfter)
+
+	// 別のキーは独立
+	ok, _ = l.take("b", 3, now)
+	assert.True(t, ok)
+
+	// 期間が過ぎるとリセット
+	ok, _ = l.take("a", 3, now.Add(rateLimitWindow))
+	assert.True(t, ok)
+
+	// 0は無制限
+	for i := 0; i < 100; i++ {
+		ok, _ := l.take("c", 0, now)
+		assert.True(t, ok)
+	}
+}
+
+func TestRateLimiter_TakeWebhookMessage(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	l := NewRateLimiter(nil, RateLimitConfig{}, RateLimitConfig{}, RateLimitConfig{MessagesPerChannel: 1})
+	w1, w2 := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	ch1, ch2 := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	take := func(l *RateLimiter, webhookID, channelID uuid.UUID) (int, *httptest.ResponseRecorder) {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+		if err := l.TakeWebhookMessage(c, webhookID, channelID); err != nil {
+			return err.(*echo.HTTPError).Code, rec
+		}
+		return http.StatusOK, rec
+	}
+
+	code, _ := take(l, w1, ch1)
+	assert.Equal(t, http.StatusOK, code)
+	code, rec := take(l, w1, ch1)
+	assert.Equal(t, http.StatusTooManyRequests, code)
+	assert.Equal(t, "60", rec.Header().Get(consts.HeaderRetryAfter))
+
+	// Webhook・チャンネル毎に独立
+	code, _ = take(l, w1, ch2)
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = take(l, w2, ch1)
+	assert.Equal(t, http.StatusOK, code)
+
+	// nilの場合は制限しない
+	var nl *RateLimiter
+	for i := 0; i < 3; i++ {
+		code, _ := take(nl, w1, ch1)
+		assert.Equal(t, http.StatusOK, code)
+	}
+}
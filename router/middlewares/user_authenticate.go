@@ -18,7 +18,10 @@ const authScheme = "Bearer"
 func UserAuthenticate(repo repository.Repository) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			var user model.UserInfo
+			var (
+				user      model.UserInfo
+				clientKey string
+			)
 			ah := c.Request().Header.Get(echo.HeaderAuthorization)
 			if len(ah) > 0 {
 				// AuthorizationヘッダーがあるためOAuth2で検証
@@ -52,6 +55,7 @@ func UserAuthenticate(repo repository.Repository) echo.MiddlewareFunc {
 				}
 
 				c.Set(consts.KeyOAuth2AccessScopes, token.Scopes)
+				clientKey = "token:" + token.ID.String()
 			} else {
 				// Authorizationヘッダーがないためセッションを確認する
 				sess, err := sessions.Get(c.Response(), c.Request(), false)
@@ -66,6 +70,9 @@ func UserAuthenticate(repo repository.Repository) echo.MiddlewareFunc {
 				if err != nil {
 					return herror.InternalServerError(err)
 				}
+
+				referenceID, _, _, _, _ := sess.GetSessionInfo()
+				clientKey = "session:" + referenceID.String()
 			}
 
 			// ユーザーアカウント状態を確認
@@ -75,7 +82,10 @@ func UserAuthenticate(repo repository.Repository) echo.MiddlewareFunc {
 
 			c.Set(consts.KeyUser, user)
 			c.Set(consts.KeyUserID, user.GetID())
-			c.SetRequest(c.Request().WithContext(context.WithValue(c.Request().Context(), extension.CtxUserIDKey, user.GetID()))) // SSEストリーマーで使う
+			c.Set(consts.KeyClientKey, clientKey)
+			ctx := context.WithValue(c.Request().Context(), extension.CtxUserIDKey, user.GetID()) // SSEストリーマーで使う
+			ctx = context.WithValue(ctx, extension.CtxClientKeyKey, clientKey)                    // WSストリーマーで使う
+			c.SetRequest(c.Request().WithContext(ctx))
 			return next(c)
 		}
 	}
@@ -42,16 +42,23 @@ func Setup(config *Config) *echo.Echo {
 	api.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
 	api.GET("/ping", func(c echo.Context) error { return c.String(http.StatusOK, http.StatusText(http.StatusOK)) })
 
+	var rateLimiter *middlewares.RateLimiter
+	if config.RateLimit.Enabled {
+		rateLimiter = middlewares.NewRateLimiter(config.Repository, config.RateLimit.Bot, config.RateLimit.PrivilegedBot, config.RateLimit.Webhook)
+	}
+
 	// v1 APIハンドラ
 	v1 := v1.Handlers{
-		RBAC:            config.RBAC,
-		Repo:            config.Repository,
-		SSE:             config.SSE,
-		WS:              config.WS,
-		Hub:             config.Hub,
-		Logger:          config.RootLogger.Named("api_handler"),
-		Realtime:        config.Realtime,
-		SkyWaySecretKey: config.SkyWaySecretKey,
+		RBAC:             config.RBAC,
+		Repo:             config.Repository,
+		SSE:              config.SSE,
+		WS:               config.WS,
+		Hub:              config.Hub,
+		Logger:           config.RootLogger.Named("api_handler"),
+		Realtime:         config.Realtime,
+		SkyWaySecretKey:  config.SkyWaySecretKey,
+		MessageEditLimit: config.MessageEditLimit,
+		RateLimiter:      rateLimiter,
 	}
 	v1.Setup(api)
 
@@ -60,12 +67,16 @@ func Setup(config *Config) *echo.Echo {
 		RBAC:                            config.RBAC,
 		Repo:                            config.Repository,
 		WS:                              config.WS,
+		BotWS:                           config.BotWS,
 		Hub:                             config.Hub,
 		Logger:                          config.RootLogger.Named("api_handler"),
 		Realtime:                        config.Realtime,
+		SearchEngine:                    config.SearchEngine,
 		Version:                         config.Version,
 		Revision:                        config.Revision,
 		SkyWaySecretKey:                 config.SkyWaySecretKey,
+		MessageEditLimit:                config.MessageEditLimit,
+		RateLimiter:                     rateLimiter,
 		EnabledExternalAccountProviders: config.ExternalAuth.ValidProviders(),
 	}
 	v3.Setup(api)
@@ -64,7 +64,7 @@ func (h *Handlers) PostBots(c echo.Context) error {
 		return err
 	}
 
-	b, err := h.Repo.CreateBot(req.Name, req.DisplayName, req.Description, getRequestUserID(c), req.WebhookURL)
+	b, err := h.Repo.CreateBot(req.Name, req.DisplayName, req.Description, getRequestUserID(c), model.BotModeHTTP, req.WebhookURL)
 	if err != nil {
 		switch {
 		case err == repository.ErrAlreadyExists:
@@ -290,7 +290,7 @@ func (h *Handlers) GetBotEventLogs(c echo.Context) error {
 		req.Limit = 50
 	}
 
-	logs, err := h.Repo.GetBotEventLogs(b.ID, req.Limit, req.Offset)
+	logs, err := h.Repo.GetBotEventLogs(b.ID, false, req.Limit, req.Offset)
 	if err != nil {
 		return herror.InternalServerError(err)
 	}
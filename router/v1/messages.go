@@ -49,8 +49,13 @@ func (h *Handlers) PutMessageByID(c echo.Context) error {
 		return herror.Forbidden("This is not your message")
 	}
 
+	// 編集可能期間を過ぎたメッセージは編集できない
+	if h.MessageEditLimit > 0 && time.Since(m.CreatedAt) > h.MessageEditLimit {
+		return herror.BadRequest("the message can no longer be edited")
+	}
+
 	if err := h.Repo.UpdateMessage(messageID, req.Text); err != nil {
-		return herror.InternalServerError(err)
+		return herror.ChannelWriteError(err)
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -88,7 +93,7 @@ func (h *Handlers) DeleteMessageByID(c echo.Context) error {
 		}
 	}
 
-	if err := h.Repo.DeleteMessage(messageID); err != nil {
+	if err := h.Repo.DeleteMessage(messageID, userID); err != nil {
 		return herror.InternalServerError(err)
 	}
 
@@ -135,7 +140,7 @@ func (h *Handlers) PostMessage(c echo.Context) error {
 
 	m, err := h.Repo.CreateMessage(userID, channelID, req.Text)
 	if err != nil {
-		return herror.InternalServerError(err)
+		return herror.ChannelWriteError(err)
 	}
 
 	return c.JSON(http.StatusCreated, formatMessage(m))
@@ -194,7 +199,7 @@ func (h *Handlers) PostDirectMessage(c echo.Context) error {
 
 	m, err := h.Repo.CreateMessage(myID, ch.ID, req.Text)
 	if err != nil {
-		return herror.InternalServerError(err)
+		return herror.ChannelWriteError(err)
 	}
 
 	return c.JSON(http.StatusCreated, formatMessage(m))
@@ -51,7 +51,7 @@ func (h *Handlers) PostPin(c echo.Context) error {
 
 	pin, err := h.Repo.CreatePin(m.ID, userID)
 	if err != nil {
-		return herror.InternalServerError(err)
+		return herror.ChannelWriteError(err)
 	}
 
 	return c.JSON(http.StatusCreated, echo.Map{"id": pin.ID})
@@ -76,7 +76,7 @@ func TestHandlers_GetPin(t *testing.T) {
 
 		message := mustMakeMessage(t, repo, testUser.GetID(), channel.ID)
 		pin := mustMakePin(t, repo, message.ID, testUser.GetID())
-		require.NoError(t, repo.DeleteMessage(message.ID))
+		require.NoError(t, repo.DeleteMessage(message.ID, testUser.GetID()))
 
 		e := makeExp(t, server)
 		e.GET("/api/1.0/pins/{pinID}", pin).
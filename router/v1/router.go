@@ -48,6 +48,10 @@ type Handlers struct {
 
 	// SkyWaySecretKey SkyWayクレデンシャル用シークレットキー
 	SkyWaySecretKey string
+	// MessageEditLimit メッセージを投稿後に編集可能な期間 0の場合は無制限
+	MessageEditLimit time.Duration
+	// RateLimiter Bot・Webhookのレート制限器 nilの場合は制限しない
+	RateLimiter *middlewares.RateLimiter
 
 	webhookDefTmpls *template.Template
 
@@ -66,12 +70,14 @@ type Handlers struct {
 // Setup APIルーティングを行います
 func (h *Handlers) Setup(e *echo.Group) {
 	// middleware preparation
-	requires := middlewares.AccessControlMiddlewareGenerator(h.RBAC)
+	requires := middlewares.AccessControlMiddlewareGenerator(h.RBAC, h.Repo)
 	bodyLimit := middlewares.RequestBodyLengthLimit
 	adminOnly := middlewares.AdminOnly
 	retrieve := middlewares.NewParamRetriever(h.Repo)
 	blockBot := middlewares.BlockBot(h.Repo)
 	nologin := middlewares.NoLogin()
+	limitMessage := h.RateLimiter.Message()
+	limitStamp := h.RateLimiter.Stamp()
 
 	requiresBotAccessPerm := middlewares.CheckBotAccessPerm(h.RBAC, h.Repo)
 	requiresWebhookAccessPerm := middlewares.CheckWebhookAccessPerm(h.RBAC, h.Repo)
@@ -80,7 +86,7 @@ func (h *Handlers) Setup(e *echo.Group) {
 	requiresMessageAccessPerm := middlewares.CheckMessageAccessPerm(h.RBAC, h.Repo)
 	requiresChannelAccessPerm := middlewares.CheckChannelAccessPerm(h.RBAC, h.Repo)
 
-	api := e.Group("/1.0", middlewares.UserAuthenticate(h.Repo))
+	api := e.Group("/1.0", middlewares.UserAuthenticate(h.Repo), h.RateLimiter.APICall())
 	{
 		apiUsers := api.Group("/users")
 		{
@@ -130,7 +136,7 @@ func (h *Handlers) Setup(e *echo.Group) {
 				apiUsersUID.PUT("/status", h.PutUserStatus, requires(permission.EditOtherUsers))
 				apiUsersUID.PUT("/password", h.PutUserPassword, requires(permission.EditOtherUsers))
 				apiUsersUID.GET("/messages", h.GetDirectMessages, requires(permission.GetMessage))
-				apiUsersUID.POST("/messages", h.PostDirectMessage, bodyLimit(100), requires(permission.PostMessage))
+				apiUsersUID.POST("/messages", h.PostDirectMessage, bodyLimit(100), requires(permission.PostMessage), limitMessage)
 				apiUsersUID.GET("/icon", h.GetUserIcon, requires(permission.DownloadFile))
 				apiUsersUID.PUT("/icon", h.PutUserIcon, requires(permission.EditOtherUsers))
 				apiUsersUID.GET("/notification", h.GetNotificationChannels, requires(permission.GetChannelSubscription))
@@ -175,7 +181,7 @@ func (h *Handlers) Setup(e *echo.Group) {
 				apiChannelsCidMessages := apiChannelsCid.Group("/messages")
 				{
 					apiChannelsCidMessages.GET("", h.GetMessagesByChannelID, requires(permission.GetMessage))
-					apiChannelsCidMessages.POST("", h.PostMessage, bodyLimit(100), requires(permission.PostMessage))
+					apiChannelsCidMessages.POST("", h.PostMessage, bodyLimit(100), requires(permission.PostMessage), limitMessage)
 				}
 				apiChannelsCidNotification := apiChannelsCid.Group("/notification")
 				{
@@ -211,7 +217,7 @@ func (h *Handlers) Setup(e *echo.Group) {
 				apiMessagesMid.GET("/stamps", h.GetMessageStamps, requires(permission.GetMessage))
 				apiMessagesMidStampsSid := apiMessagesMid.Group("/stamps/:stampID", retrieve.StampID(true))
 				{
-					apiMessagesMidStampsSid.POST("", h.PostMessageStamp, requires(permission.AddMessageStamp))
+					apiMessagesMidStampsSid.POST("", h.PostMessageStamp, requires(permission.AddMessageStamp), limitStamp)
 					apiMessagesMidStampsSid.DELETE("", h.DeleteMessageStamp, requires(permission.RemoveMessageStamp))
 				}
 			}
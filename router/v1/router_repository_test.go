@@ -175,7 +175,27 @@ func (repo *TestRepository) UpdateBot(id uuid.UUID, args repository.UpdateBotArg
 	panic("implement me")
 }
 
-func (repo *TestRepository) GetBotEventLogs(botID uuid.UUID, limit, offset int) ([]*model.BotEventLog, error) {
+func (repo *TestRepository) GetBotEventLog(requestID uuid.UUID) (*model.BotEventLog, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetBotEventLogs(botID uuid.UUID, deadLetterOnly bool, limit, offset int) ([]*model.BotEventLog, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetRetryableBotEventLogs(before time.Time, limit int) ([]*model.BotEventLog, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) ClaimBotEventRetry(requestID uuid.UUID, at, leaseUntil time.Time) (bool, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) ScheduleBotEventRetry(requestID uuid.UUID, at time.Time) error {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetBotInstallCounts(botIDs []uuid.UUID) (map[uuid.UUID]int, error) {
 	panic("implement me")
 }
 
@@ -183,6 +203,14 @@ func (repo *TestRepository) WriteBotEventLog(log *model.BotEventLog) error {
 	panic("implement me")
 }
 
+func (repo *TestRepository) SetBotCommands(botID uuid.UUID, commands []*model.BotCommand) error {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetBotCommands(botIDs []uuid.UUID) ([]*model.BotCommand, error) {
+	panic("implement me")
+}
+
 func (repo *TestRepository) ReissueBotTokens(id uuid.UUID) (*model.Bot, error) {
 	panic("implement me")
 }
@@ -1007,6 +1035,10 @@ func (repo *TestRepository) CreatePublicChannel(name string, parent, creatorID u
 	return &ch, nil
 }
 
+func (repo *TestRepository) CreatePrivateChannel(name string, parent, creatorID uuid.UUID, members []uuid.UUID) (*model.Channel, error) {
+	panic("implement me")
+}
+
 func (repo *TestRepository) UpdateChannel(channelID uuid.UUID, args repository.UpdateChannelArgs) error {
 	if channelID == uuid.Nil {
 		return repository.ErrNilID
@@ -1338,6 +1370,14 @@ func (repo *TestRepository) IsUserPrivateChannelMember(channelID, userID uuid.UU
 	return false, nil
 }
 
+func (repo *TestRepository) AddPrivateChannelMembers(channelID, updaterID uuid.UUID, userIDs []uuid.UUID) error {
+	panic("implement me")
+}
+
+func (repo *TestRepository) RemovePrivateChannelMembers(channelID, updaterID uuid.UUID, userIDs []uuid.UUID) error {
+	panic("implement me")
+}
+
 func (repo *TestRepository) ChangeChannelSubscription(channelID uuid.UUID, args repository.ChangeChannelSubscriptionArgs) error {
 	if channelID == uuid.Nil {
 		return repository.ErrNilID
@@ -1436,6 +1476,74 @@ func (repo *TestRepository) GetChannelSubscriptions(query repository.ChannelSubs
 	return result, nil
 }
 
+func (repo *TestRepository) ImportMessage(args repository.ImportMessageArgs) (*model.Message, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) CreateReplyMessage(userID, parentID uuid.UUID, text string) (*model.Message, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetThreadParticipantIDs(threadID uuid.UUID) ([]uuid.UUID, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetUserUnreadThreads(userID uuid.UUID) ([]*repository.UserUnreadThread, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) DeleteUnreadsByThreadID(threadID, userID uuid.UUID) error {
+	panic("implement me")
+}
+
+func (repo *TestRepository) CreateScheduledMessage(args repository.CreateScheduledMessageArgs) (*model.ScheduledMessage, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetScheduledMessage(id uuid.UUID) (*model.ScheduledMessage, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetScheduledMessagesByUserID(userID uuid.UUID) ([]*model.ScheduledMessage, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetDueScheduledMessages(until time.Time, limit int) ([]*model.ScheduledMessage, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) UpdateScheduledMessage(id uuid.UUID, text null.String, scheduledAt null.Time) error {
+	panic("implement me")
+}
+
+func (repo *TestRepository) DeleteScheduledMessage(id uuid.UUID) error {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetDraft(userID, channelID uuid.UUID) (*model.Draft, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetDraftsByUserID(userID uuid.UUID) ([]*model.Draft, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) SaveDraft(userID, channelID uuid.UUID, text string, clientKey string) (*model.Draft, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) DeleteDraft(userID, channelID uuid.UUID, clientKey string) error {
+	panic("implement me")
+}
+
+func (repo *TestRepository) ArchiveChannel(channelID, userID uuid.UUID) error {
+	panic("implement me")
+}
+
+func (repo *TestRepository) UnarchiveChannel(channelID, userID uuid.UUID) error {
+	panic("implement me")
+}
+
 func (repo *TestRepository) CreateMessage(userID, channelID uuid.UUID, text string) (*model.Message, error) {
 	if userID == uuid.Nil || channelID == uuid.Nil {
 		return nil, repository.ErrNilID
@@ -1474,7 +1582,7 @@ func (repo *TestRepository) UpdateMessage(messageID uuid.UUID, text string) erro
 	return nil
 }
 
-func (repo *TestRepository) DeleteMessage(messageID uuid.UUID) error {
+func (repo *TestRepository) DeleteMessage(messageID, deletedBy uuid.UUID) error {
 	if messageID == uuid.Nil {
 		return repository.ErrNilID
 	}
@@ -1488,6 +1596,34 @@ func (repo *TestRepository) DeleteMessage(messageID uuid.UUID) error {
 	return nil
 }
 
+func (repo *TestRepository) GetDeletedMessages(channelID uuid.UUID, limit, offset int) (messages []*model.Message, more bool, err error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) RestoreMessage(messageID uuid.UUID) (*model.Message, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) PurgeDeletedMessages(before time.Time, limit int) (int, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) SetMessageComponents(messageID uuid.UUID, components model.MessageComponentList) error {
+	panic("implement me")
+}
+
+func (repo *TestRepository) CreateMessageWithComponents(userID, channelID uuid.UUID, text string, components model.MessageComponentList) (*model.Message, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) CreateReplyMessageWithComponents(userID, parentID uuid.UUID, text string, components model.MessageComponentList) (*model.Message, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) UpdateMessageWithComponents(messageID uuid.UUID, text string, components model.MessageComponentList) error {
+	panic("implement me")
+}
+
 func (repo *TestRepository) GetMessageByID(messageID uuid.UUID) (*model.Message, error) {
 	repo.MessagesLock.RLock()
 	m, ok := repo.Messages[messageID]
@@ -2623,7 +2759,7 @@ func (repo *TestRepository) DeleteTokenByClient(clientID string) error {
 	return nil
 }
 
-func (repo *TestRepository) CreateBot(name, displayName, description string, creatorID uuid.UUID, webhookURL string) (*model.Bot, error) {
+func (repo *TestRepository) CreateBot(name, displayName, description string, creatorID uuid.UUID, mode model.BotMode, webhookURL string) (*model.Bot, error) {
 	panic("implement me")
 }
 
@@ -2655,6 +2791,14 @@ func (repo *TestRepository) RemoveBotFromChannel(botID, channelID uuid.UUID) err
 	panic("implement me")
 }
 
+func (repo *TestRepository) GetBotJoinChannel(botID, channelID uuid.UUID) (*model.BotJoinChannel, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) SetBotChannelPermissions(botID, channelID uuid.UUID, permissions model.BotPermissions) error {
+	panic("implement me")
+}
+
 func (repo *TestRepository) GetParticipatingChannelIDsByBot(botID uuid.UUID) ([]uuid.UUID, error) {
 	panic("implement me")
 }
@@ -2764,6 +2908,34 @@ func (f *fileMetaImpl) GetAlternativeURL() string {
 	return url
 }
 
+func (repo *TestRepository) CreateChannelExport(userID, channelID uuid.UUID) (*model.ChannelExport, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetChannelExport(id uuid.UUID) (*model.ChannelExport, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetChannelExportsByUserID(userID uuid.UUID) ([]*model.ChannelExport, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetUnfinishedChannelExports(staleBefore time.Time, limit int) ([]*model.ChannelExport, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) ClaimChannelExport(id uuid.UUID, staleBefore time.Time) (bool, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) TouchChannelExport(id uuid.UUID) error {
+	panic("implement me")
+}
+
+func (repo *TestRepository) UpdateChannelExport(id uuid.UUID, args repository.UpdateChannelExportArgs) error {
+	panic("implement me")
+}
+
 func (repo *TestRepository) GetFiles(q repository.FilesQuery) (result []model.FileMeta, more bool, err error) {
 	panic("implement me")
 }
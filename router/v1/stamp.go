@@ -149,7 +149,7 @@ func (h *Handlers) PostMessageStamp(c echo.Context) error {
 
 	// スタンプをメッセージに押す
 	if _, err := h.Repo.AddStampToMessage(messageID, stampID, userID, req.Count); err != nil {
-		return herror.InternalServerError(err)
+		return herror.ChannelWriteError(err)
 	}
 
 	return c.NoContent(http.StatusNoContent)
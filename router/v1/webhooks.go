@@ -181,7 +181,15 @@ func (h *Handlers) PostWebhook(c echo.Context) error {
 			}
 		}
 		if !ch.IsPublic {
-			return herror.BadRequest("invalid channel")
+			// プライベートチャンネルはWebhookのBOTユーザーがメンバーの場合のみ許可
+			if ch.IsDMChannel() {
+				return herror.BadRequest("invalid channel")
+			}
+			if ok, err := h.Repo.IsChannelAccessibleToUser(w.GetBotUserID(), ch.ID); err != nil {
+				return herror.InternalServerError(err)
+			} else if !ok {
+				return herror.BadRequest("invalid channel")
+			}
 		}
 		channelID = id
 	}
@@ -190,8 +198,12 @@ func (h *Handlers) PostWebhook(c echo.Context) error {
 		body = []byte(message.NewReplacer(h.Repo).Replace(string(body)))
 	}
 
+	if err := h.RateLimiter.TakeWebhookMessage(c, w.GetID(), channelID); err != nil {
+		return err
+	}
+
 	if _, err := h.Repo.CreateMessage(w.GetBotUserID(), channelID, string(body)); err != nil {
-		return herror.InternalServerError(err)
+		return herror.ChannelWriteError(err)
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -273,9 +285,12 @@ func (h *Handlers) PostWebhookByGithub(c echo.Context) error {
 		messageBuf.WriteString(err.Error())
 	}
 	if messageBuf.Len() > 0 {
+		if err := h.RateLimiter.TakeWebhookMessage(c, w.GetID(), w.GetChannelID()); err != nil {
+			return err
+		}
 		_, err := h.Repo.CreateMessage(w.GetBotUserID(), w.GetChannelID(), messageBuf.String())
 		if err != nil {
-			return herror.InternalServerError(err)
+			return herror.ChannelWriteError(err)
 		}
 	}
 
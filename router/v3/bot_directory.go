@@ -0,0 +1,141 @@
+package v3
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension/herror"
+)
+
+// GetBotDirectoryRequest GET /bots/directory リクエストクエリ
+type GetBotDirectoryRequest struct {
+	Word   string `query:"q"`
+	Limit  int    `query:"limit"`
+	Offset int    `query:"offset"`
+}
+
+func (r *GetBotDirectoryRequest) Validate() error {
+	if r.Limit == 0 {
+		r.Limit = 30
+	}
+	return vd.ValidateStruct(r,
+		vd.Field(&r.Word, vd.RuneLength(0, 100)),
+		vd.Field(&r.Limit, vd.Min(1), vd.Max(200)),
+		vd.Field(&r.Offset, vd.Min(0)),
+	)
+}
+
+// GetBotDirectory GET /bots/directory
+func (h *Handlers) GetBotDirectory(c echo.Context) error {
+	var req GetBotDirectoryRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	bots, err := h.Repo.GetBots(repository.BotsQuery{}.Active().Search(req.Word))
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	counts, err := h.Repo.GetBotInstallCounts(botIDs(bots))
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	// 参加チャンネル数の多い順
+	sort.SliceStable(bots, func(i, j int) bool {
+		if ci, cj := counts[bots[i].ID], counts[bots[j].ID]; ci != cj {
+			return ci > cj
+		}
+		return bots[i].CreatedAt.Before(bots[j].CreatedAt)
+	})
+
+	more := len(bots) > req.Offset+req.Limit
+	if req.Offset < len(bots) {
+		bots = bots[req.Offset:]
+	} else {
+		bots = nil
+	}
+	if len(bots) > req.Limit {
+		bots = bots[:req.Limit]
+	}
+
+	res := make([]*BotDirectoryEntry, len(bots))
+	for i, b := range bots {
+		user, err := h.Repo.GetUser(b.BotUserID, false)
+		if err != nil {
+			return herror.InternalServerError(err)
+		}
+		res[i] = formatBotDirectoryEntry(b, user, counts[b.ID])
+	}
+
+	c.Response().Header().Set(consts.HeaderMore, strconv.FormatBool(more))
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetBotManifest GET /bots/:botID/manifest
+func (h *Handlers) GetBotManifest(c echo.Context) error {
+	b := getParamBot(c)
+
+	user, err := h.Repo.GetUser(b.BotUserID, false)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	cmds, err := h.Repo.GetBotCommands([]uuid.UUID{b.ID})
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	counts, err := h.Repo.GetBotInstallCounts([]uuid.UUID{b.ID})
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	return c.JSON(http.StatusOK, formatBotManifest(b, user, cmds, counts[b.ID]))
+}
+
+// getChannelBotsDetail チャンネルに参加しているBotの詳細情報を取得します
+func (h *Handlers) getChannelBotsDetail(channelID uuid.UUID, bots []*model.Bot) ([]*ChannelBot, error) {
+	cmds, err := h.Repo.GetBotCommands(botIDs(bots))
+	if err != nil {
+		return nil, err
+	}
+	cmdsByBot := make(map[uuid.UUID][]*model.BotCommand, len(bots))
+	for _, cmd := range cmds {
+		cmdsByBot[cmd.BotID] = append(cmdsByBot[cmd.BotID], cmd)
+	}
+
+	res := make([]*ChannelBot, 0, len(bots))
+	for _, b := range bots {
+		j, err := h.Repo.GetBotJoinChannel(b.ID, channelID)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				continue // 取得中に退出した
+			}
+			return nil, err
+		}
+		user, err := h.Repo.GetUser(b.BotUserID, false)
+		if err != nil {
+			return nil, err
+		}
+		cmds := cmdsByBot[b.ID]
+		if cmds == nil {
+			cmds = make([]*model.BotCommand, 0)
+		}
+		res = append(res, formatChannelBot(b, user, j, cmds))
+	}
+	return res, nil
+}
+
+func botIDs(bots []*model.Bot) []uuid.UUID {
+	ids := make([]uuid.UUID, len(bots))
+	for i, b := range bots {
+		ids[i] = b.ID
+	}
+	return ids
+}
@@ -1,6 +1,7 @@
 package v3
 
 import (
+	"errors"
 	vd "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/gofrs/uuid"
@@ -17,6 +18,7 @@ import (
 	"github.com/traPtitech/traQ/utils/validator"
 	"gopkg.in/guregu/null.v3"
 	"net/http"
+	"time"
 )
 
 // GetBots GET /bots
@@ -36,18 +38,25 @@ func (h *Handlers) GetBots(c echo.Context) error {
 
 // PostBotRequest POST /bots リクエストボディ
 type PostBotRequest struct {
-	Name        string `json:"name"`
-	DisplayName string `json:"displayName"`
-	Description string `json:"description"`
-	Endpoint    string `json:"endpoint"`
+	Name        string               `json:"name"`
+	DisplayName string               `json:"displayName"`
+	Description string               `json:"description"`
+	Mode        string               `json:"mode"`
+	Endpoint    string               `json:"endpoint"`
+	Permissions model.BotPermissions `json:"permissions"`
 }
 
-func (r PostBotRequest) Validate() error {
-	return vd.ValidateStruct(&r,
+func (r *PostBotRequest) Validate() error {
+	if len(r.Mode) == 0 {
+		r.Mode = model.BotModeHTTP.String()
+	}
+	return vd.ValidateStruct(r,
 		vd.Field(&r.Name, validator.BotUserNameRuleRequired...),
 		vd.Field(&r.DisplayName, vd.Required, vd.RuneLength(1, 32)),
 		vd.Field(&r.Description, vd.Required, vd.RuneLength(0, 1000)),
-		vd.Field(&r.Endpoint, vd.Required, is.URL, validator.NotInternalURL),
+		vd.Field(&r.Mode, vd.In(model.BotModeHTTP.String(), model.BotModeWebSocket.String())),
+		vd.Field(&r.Endpoint, vd.When(r.Mode == model.BotModeHTTP.String(), vd.Required), is.URL, validator.NotInternalURL),
+		vd.Field(&r.Permissions),
 	)
 }
 
@@ -58,7 +67,7 @@ func (h *Handlers) CreateBot(c echo.Context) error {
 		return err
 	}
 
-	b, err := h.Repo.CreateBot(req.Name, req.DisplayName, req.Description, getRequestUserID(c), req.Endpoint)
+	b, err := h.Repo.CreateBot(req.Name, req.DisplayName, req.Description, getRequestUserID(c), model.BotMode(req.Mode), req.Endpoint)
 	if err != nil {
 		switch {
 		case err == repository.ErrAlreadyExists:
@@ -67,6 +76,13 @@ func (h *Handlers) CreateBot(c echo.Context) error {
 			return herror.InternalServerError(err)
 		}
 	}
+	if req.Permissions != nil {
+		if err := h.Repo.UpdateBot(b.ID, repository.UpdateBotArgs{Permissions: req.Permissions}); err != nil {
+			return herror.InternalServerError(err)
+		}
+		b.Permissions = req.Permissions
+		b.ChannelScoped = true
+	}
 
 	t, err := h.Repo.GetTokenByID(b.AccessTokenID)
 	if err != nil {
@@ -111,21 +127,31 @@ func (h *Handlers) GetBot(c echo.Context) error {
 
 // PatchBotRequest PATCH /bots/:botID リクエストボディ
 type PatchBotRequest struct {
-	DisplayName     null.String     `json:"displayName"`
-	Description     null.String     `json:"description"`
-	Endpoint        null.String     `json:"endpoint"`
-	Privileged      null.Bool       `json:"privileged"`
-	DeveloperID     uuid.NullUUID   `json:"developerId"`
-	SubscribeEvents model.BotEvents `json:"subscribeEvents"`
+	DisplayName     null.String          `json:"displayName"`
+	Description     null.String          `json:"description"`
+	HelpText        null.String          `json:"helpText"`
+	Homepage        null.String          `json:"homepage"`
+	PrivacyNote     null.String          `json:"privacyNote"`
+	Endpoint        null.String          `json:"endpoint"`
+	Mode            null.String          `json:"mode"`
+	Privileged      null.Bool            `json:"privileged"`
+	DeveloperID     uuid.NullUUID        `json:"developerId"`
+	SubscribeEvents model.BotEvents      `json:"subscribeEvents"`
+	Permissions     model.BotPermissions `json:"permissions"`
 }
 
 func (r PatchBotRequest) Validate() error {
 	return vd.ValidateStruct(&r,
 		vd.Field(&r.DisplayName, vd.RuneLength(1, 32)),
 		vd.Field(&r.Description, vd.RuneLength(0, 1000)),
+		vd.Field(&r.HelpText, vd.RuneLength(0, 10000)),
+		vd.Field(&r.Homepage, is.URL),
+		vd.Field(&r.PrivacyNote, vd.RuneLength(0, 1000)),
 		vd.Field(&r.Endpoint, is.URL, validator.NotInternalURL),
+		vd.Field(&r.Mode, vd.In(model.BotModeHTTP.String(), model.BotModeWebSocket.String())),
 		vd.Field(&r.DeveloperID, validator.NotNilUUID),
 		vd.Field(&r.SubscribeEvents),
+		vd.Field(&r.Permissions),
 	)
 }
 
@@ -145,10 +171,15 @@ func (h *Handlers) EditBot(c echo.Context) error {
 	args := repository.UpdateBotArgs{
 		DisplayName:     req.DisplayName,
 		Description:     req.Description,
+		HelpText:        req.HelpText,
+		Homepage:        req.Homepage,
+		PrivacyNote:     req.PrivacyNote,
 		WebhookURL:      req.Endpoint,
+		Mode:            req.Mode,
 		Privileged:      req.Privileged,
 		CreatorID:       req.DeveloperID,
 		SubscribeEvents: req.SubscribeEvents,
+		Permissions:     req.Permissions,
 	}
 
 	if err := h.Repo.UpdateBot(b.ID, args); err != nil {
@@ -194,8 +225,9 @@ func (h *Handlers) ChangeBotIcon(c echo.Context) error {
 
 // GetBotLogsRequest GET /bots/:botID/logs リクエストクエリ
 type GetBotLogsRequest struct {
-	Limit  int `query:"limit"`
-	Offset int `query:"offset"`
+	Limit      int  `query:"limit"`
+	Offset     int  `query:"offset"`
+	DeadLetter bool `query:"deadLetter"`
 }
 
 func (r *GetBotLogsRequest) Validate() error {
@@ -217,7 +249,7 @@ func (h *Handlers) GetBotLogs(c echo.Context) error {
 		return err
 	}
 
-	logs, err := h.Repo.GetBotEventLogs(b.ID, req.Limit, req.Offset)
+	logs, err := h.Repo.GetBotEventLogs(b.ID, req.DeadLetter, req.Limit, req.Offset)
 	if err != nil {
 		return herror.InternalServerError(err)
 	}
@@ -234,6 +266,14 @@ func (h *Handlers) GetChannelBots(c echo.Context) error {
 		return herror.InternalServerError(err)
 	}
 
+	if isTrue(c.QueryParam("detail")) {
+		res, err := h.getChannelBotsDetail(channelID, bots)
+		if err != nil {
+			return herror.InternalServerError(err)
+		}
+		return c.JSON(http.StatusOK, res)
+	}
+
 	res := make([]echo.Map, len(bots))
 	for i, v := range bots {
 		res[i] = echo.Map{
@@ -244,6 +284,57 @@ func (h *Handlers) GetChannelBots(c echo.Context) error {
 	return c.JSON(http.StatusOK, res)
 }
 
+// GetChannelBotPermissions GET /channels/:channelID/bots/:botID/permissions
+func (h *Handlers) GetChannelBotPermissions(c echo.Context) error {
+	channelID := getParamAsUUID(c, consts.ParamChannelID)
+	b := getParamBot(c)
+
+	j, err := h.Repo.GetBotJoinChannel(b.ID, channelID)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound("the bot has not joined this channel")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.JSON(http.StatusOK, formatBotChannelPermissions(b, j))
+}
+
+// PutChannelBotPermissionsRequest PUT /channels/:channelID/bots/:botID/permissions リクエストボディ
+type PutChannelBotPermissionsRequest struct {
+	Permissions model.BotPermissions `json:"permissions"`
+}
+
+func (r PutChannelBotPermissionsRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Permissions, vd.NotNil),
+	)
+}
+
+// SetChannelBotPermissions PUT /channels/:channelID/bots/:botID/permissions
+func (h *Handlers) SetChannelBotPermissions(c echo.Context) error {
+	channelID := getParamAsUUID(c, consts.ParamChannelID)
+	b := getParamBot(c)
+
+	var req PutChannelBotPermissionsRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.Repo.SetBotChannelPermissions(b.ID, channelID, req.Permissions); err != nil {
+		switch {
+		case err == repository.ErrNotFound:
+			return herror.NotFound("the bot has not joined this channel")
+		case repository.IsArgError(err):
+			return herror.BadRequest(err)
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
 // ActivateBot POST /bots/:botID/actions/activate
 func (h *Handlers) ActivateBot(c echo.Context) error {
 	b := getParamBot(c)
@@ -284,18 +375,126 @@ func (h *Handlers) ReissueBot(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, echo.Map{
 		"verificationCode": b.VerificationToken,
+		"signingSecret":    b.SigningSecret,
 		"accessToken":      t.AccessToken,
 	})
 }
 
+// PostBotActionRedeliverRequest POST /bots/:botID/actions/redeliver リクエストボディ
+type PostBotActionRedeliverRequest struct {
+	RequestID uuid.UUID `json:"requestId"`
+}
+
+func (r PostBotActionRedeliverRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.RequestID, vd.Required, validator.NotNilUUID),
+	)
+}
+
+// RedeliverBotEvent POST /bots/:botID/actions/redeliver
+func (h *Handlers) RedeliverBotEvent(c echo.Context) error {
+	var req PostBotActionRedeliverRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	b := getParamBot(c)
+	if b.State != model.BotActive {
+		return herror.BadRequest("this bot is not active")
+	}
+
+	l, err := h.Repo.GetBotEventLog(req.RequestID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return herror.NotFound("event log not found")
+		}
+		return herror.InternalServerError(err)
+	}
+	if l.BotID != b.ID {
+		return herror.NotFound("event log not found")
+	}
+
+	if err := h.Repo.ScheduleBotEventRetry(l.RequestID, time.Now()); err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+// PostBotActionTestRequest POST /bots/:botID/actions/test リクエストボディ
+type PostBotActionTestRequest struct {
+	RequestID uuid.UUID      `json:"requestId"`
+	Event     model.BotEvent `json:"event"`
+}
+
+func (r PostBotActionTestRequest) Validate() error {
+	// 記録されたイベントの再送かサンプルイベントの送信のどちらか一方
+	fromLog := r.RequestID != uuid.Nil
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.RequestID, vd.When(len(r.Event) == 0, vd.Required)),
+		vd.Field(&r.Event,
+			vd.When(fromLog, vd.In()),
+			vd.By(func(value interface{}) error {
+				if ev, _ := value.(model.BotEvent); len(ev) > 0 && !model.BotEventSet[ev] {
+					return errors.New("must be bot event")
+				}
+				return nil
+			}),
+		),
+	)
+}
+
+// TestBotEvent POST /bots/:botID/actions/test
+func (h *Handlers) TestBotEvent(c echo.Context) error {
+	var req PostBotActionTestRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	b := getParamBot(c)
+	if b.Mode == model.BotModeHTTP && len(b.PostURL) == 0 {
+		return herror.BadRequest("this bot has no endpoint")
+	}
+
+	ev, body := req.Event, ""
+	if req.RequestID != uuid.Nil {
+		l, err := h.Repo.GetBotEventLog(req.RequestID)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return herror.NotFound("event log not found")
+			}
+			return herror.InternalServerError(err)
+		}
+		if l.BotID != b.ID {
+			return herror.NotFound("event log not found")
+		}
+		ev, body = l.Event, l.Body
+	}
+
+	requestID := uuid.Must(uuid.NewV4())
+	h.Hub.Publish(hub.Message{
+		Name: event.BotTestEventRequest,
+		Fields: hub.Fields{
+			"bot_id":     b.ID,
+			"bot":        b,
+			"request_id": requestID,
+			"event":      ev,
+			"body":       body,
+			"user_id":    getRequestUserID(c),
+		},
+	})
+	return c.JSON(http.StatusAccepted, echo.Map{"requestId": requestID})
+}
+
 // PostBotActionJoinRequest POST /bots/:botID/actions/join リクエストボディ
 type PostBotActionJoinRequest struct {
-	ChannelID uuid.UUID `json:"channelId"`
+	ChannelID   uuid.UUID            `json:"channelId"`
+	Permissions model.BotPermissions `json:"permissions"`
 }
 
 func (r PostBotActionJoinRequest) Validate() error {
 	return vd.ValidateStruct(&r,
 		vd.Field(&r.ChannelID, vd.Required, validator.NotNilUUID),
+		vd.Field(&r.Permissions),
 	)
 }
 
@@ -317,13 +516,42 @@ func (h *Handlers) LetBotJoinChannel(c echo.Context) error {
 		return herror.InternalServerError(err)
 	}
 	if !ch.IsPublic {
-		return herror.BadRequest("invalid channel") // 公開チャンネルのみ許可
+		// プライベートチャンネルはBOTユーザーがメンバーの場合のみ許可
+		if ch.IsDMChannel() {
+			return herror.BadRequest("invalid channel")
+		}
+		if ok, err := h.Repo.IsChannelAccessibleToUser(b.BotUserID, ch.ID); err != nil {
+			return herror.InternalServerError(err)
+		} else if !ok {
+			return herror.BadRequest("invalid channel")
+		}
+	}
+
+	if req.Permissions != nil {
+		if !req.Permissions.IsSubsetOf(b.Permissions) {
+			return herror.BadRequest("permissions must be requested by the bot")
+		}
+		// 権限の許可はチャンネルにアクセスできる、BOT権限管理権限を持つユーザーのみ可能
+		user := getRequestUser(c)
+		if !h.RBAC.IsGranted(user.GetRole(), permission.ManageChannelBotPermission) {
+			return herror.Forbidden("you are not permitted to grant permissions to the bot")
+		}
+		if ok, err := h.Repo.IsChannelAccessibleToUser(user.GetID(), ch.ID); err != nil {
+			return herror.InternalServerError(err)
+		} else if !ok {
+			return herror.Forbidden("you are not permitted to grant permissions to the bot")
+		}
 	}
 
 	// 参加
 	if err := h.Repo.AddBotToChannel(b.ID, ch.ID); err != nil {
 		return herror.InternalServerError(err)
 	}
+	if req.Permissions != nil {
+		if err := h.Repo.SetBotChannelPermissions(b.ID, ch.ID, req.Permissions); err != nil {
+			return herror.InternalServerError(err)
+		}
+	}
 
 	return c.NoContent(http.StatusNoContent)
 }
@@ -355,3 +583,27 @@ func (h *Handlers) LetBotLeaveChannel(c echo.Context) error {
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// ConnectBotWS GET /bots/ws
+func (h *Handlers) ConnectBotWS(c echo.Context) error {
+	user := getRequestUser(c)
+	if !user.IsBot() {
+		return herror.Forbidden("only bot users can connect to this stream")
+	}
+
+	b, err := h.Repo.GetBotByBotUserID(user.GetID())
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.Forbidden("only bot users can connect to this stream")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	if b.Mode != model.BotModeWebSocket {
+		return herror.BadRequest("this bot is not in WebSocket mode")
+	}
+
+	h.BotWS.ServeHTTP(c.Response(), c.Request(), b.ID)
+	return nil
+}
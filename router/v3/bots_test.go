@@ -0,0 +1,80 @@
+package v3
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/rbac/permission"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/sessions"
+	"github.com/traPtitech/traQ/utils"
+	"net/http"
+	"testing"
+)
+
+func TestHandlers_LetBotJoinChannel(t *testing.T) {
+	t.Parallel()
+	repo, server := Setup(t, common)
+	owner := CreateUser(t, repo, random)
+	member := CreateUser(t, repo, random)
+	ownerSession := S(t, owner.GetID())
+
+	newBot := func(t *testing.T) *model.Bot {
+		t.Helper()
+		b, err := repo.CreateBot(utils.RandAlphabetAndNumberString(16), "bot", "", owner.GetID(), model.BotModeWebSocket, "")
+		require.NoError(t, err)
+		require.NoError(t, repo.UpdateBot(b.ID, repository.UpdateBotArgs{Permissions: model.BotPermissions{permission.PostMessage: true}}))
+		return b
+	}
+
+	t.Run("no permissions granted on join", func(t *testing.T) {
+		t.Parallel()
+		b := newBot(t)
+		ch, err := repo.CreatePublicChannel(utils.RandAlphabetAndNumberString(20), uuid.Nil, owner.GetID())
+		require.NoError(t, err)
+		e := R(t, server)
+		e.POST("/api/v3/bots/{botID}/actions/join", b.ID).
+			WithCookie(sessions.CookieName, ownerSession).
+			WithJSON(echo.Map{"channelId": ch.ID}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		j, err := repo.GetBotJoinChannel(b.ID, ch.ID)
+		if assert.NoError(t, err) {
+			assert.Len(t, j.Permissions, 0)
+		}
+	})
+
+	t.Run("grant permissions on join", func(t *testing.T) {
+		t.Parallel()
+		b := newBot(t)
+		ch, err := repo.CreatePublicChannel(utils.RandAlphabetAndNumberString(20), uuid.Nil, owner.GetID())
+		require.NoError(t, err)
+		e := R(t, server)
+		e.POST("/api/v3/bots/{botID}/actions/join", b.ID).
+			WithCookie(sessions.CookieName, ownerSession).
+			WithJSON(echo.Map{"channelId": ch.ID, "permissions": []string{"post_message"}}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		j, err := repo.GetBotJoinChannel(b.ID, ch.ID)
+		if assert.NoError(t, err) {
+			assert.True(t, j.Permissions.Contains(permission.PostMessage))
+		}
+	})
+
+	t.Run("cannot grant permissions on inaccessible channel", func(t *testing.T) {
+		t.Parallel()
+		b := newBot(t)
+		ch, err := repo.CreatePrivateChannel(utils.RandAlphabetAndNumberString(20), uuid.Nil, member.GetID(), []uuid.UUID{b.BotUserID})
+		require.NoError(t, err)
+		e := R(t, server)
+		e.POST("/api/v3/bots/{botID}/actions/join", b.ID).
+			WithCookie(sessions.CookieName, ownerSession).
+			WithJSON(echo.Map{"channelId": ch.ID, "permissions": []string{"post_message"}}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+}
@@ -0,0 +1,38 @@
+package v3
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/traPtitech/traQ/router/extension/herror"
+)
+
+// ExportChannel POST /channels/:channelID/export
+func (h *Handlers) ExportChannel(c echo.Context) error {
+	userID := getRequestUserID(c)
+	ch := getParamChannel(c)
+
+	e, err := h.Repo.CreateChannelExport(userID, ch.ID)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	return c.JSON(http.StatusAccepted, formatChannelExport(e))
+}
+
+// GetMyChannelExports GET /users/me/exports
+func (h *Handlers) GetMyChannelExports(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	es, err := h.Repo.GetChannelExportsByUserID(userID)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	return c.JSON(http.StatusOK, formatChannelExports(es))
+}
+
+// GetMyChannelExport GET /users/me/exports/:exportID
+func (h *Handlers) GetMyChannelExport(c echo.Context) error {
+	return c.JSON(http.StatusOK, formatChannelExport(getParamChannelExport(c)))
+}
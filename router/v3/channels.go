@@ -21,13 +21,19 @@ import (
 func (h *Handlers) GetChannels(c echo.Context) error {
 	res := echo.Map{}
 
-	channelList, err := h.Repo.GetChannelsByUserID(uuid.Nil)
+	channelList, err := h.Repo.GetChannelsByUserID(getRequestUserID(c))
 	if err != nil {
 		return herror.InternalServerError(err)
 	}
-	channels := make([]*Channel, 0, len(channelList))
+	public := make([]*Channel, 0, len(channelList))
+	private := make([]*Channel, 0)
 	chMap := make(map[uuid.UUID]*Channel, len(channelList))
+	includeArchived := isTrue(c.QueryParam("include-archived"))
 	for _, ch := range channelList {
+		if ch.IsDMChannel() || (ch.IsArchived && !includeArchived) {
+			continue
+		}
+
 		entry, ok := chMap[ch.ID]
 		if !ok {
 			entry = &Channel{
@@ -41,6 +47,7 @@ func (h *Handlers) GetChannels(c echo.Context) error {
 		entry.Topic = ch.Topic
 		entry.Visibility = ch.IsVisible
 		entry.Force = ch.IsForced
+		entry.Archived = ch.IsArchived
 		if ch.ParentID != uuid.Nil {
 			entry.ParentID = uuid.NullUUID{UUID: ch.ParentID, Valid: true}
 			parent, ok := chMap[ch.ParentID]
@@ -54,9 +61,14 @@ func (h *Handlers) GetChannels(c echo.Context) error {
 			parent.Children = append(parent.Children, ch.ID)
 		}
 
-		channels = append(channels, entry)
+		if ch.IsPublic {
+			public = append(public, entry)
+		} else {
+			private = append(private, entry)
+		}
 	}
-	res["public"] = channels
+	res["public"] = public
+	res["private"] = private
 
 	if isTrue(c.QueryParam("include-dm")) {
 		type dmc struct {
@@ -79,13 +91,16 @@ func (h *Handlers) GetChannels(c echo.Context) error {
 
 // PostChannelRequest POST /channels リクエストボディ
 type PostChannelRequest struct {
-	Name   string        `json:"name"`
-	Parent uuid.NullUUID `json:"parent"`
+	Name    string        `json:"name"`
+	Parent  uuid.NullUUID `json:"parent"`
+	Private bool          `json:"private"`
+	Members []uuid.UUID   `json:"members"`
 }
 
 func (r PostChannelRequest) Validate() error {
 	return vd.ValidateStruct(&r,
 		vd.Field(&r.Name, validator.ChannelNameRuleRequired...),
+		vd.Field(&r.Members, vd.Each(validator.NotNilUUID)),
 	)
 }
 
@@ -98,7 +113,15 @@ func (h *Handlers) CreateChannels(c echo.Context) error {
 		return err
 	}
 
-	ch, err := h.Repo.CreatePublicChannel(req.Name, req.Parent.UUID, userID)
+	var (
+		ch  *model.Channel
+		err error
+	)
+	if req.Private {
+		ch, err = h.Repo.CreatePrivateChannel(req.Name, req.Parent.UUID, userID, req.Members)
+	} else {
+		ch, err = h.Repo.CreatePublicChannel(req.Name, req.Parent.UUID, userID)
+	}
 	if err != nil {
 		switch {
 		case repository.IsArgError(err):
@@ -176,6 +199,102 @@ func (h *Handlers) EditChannel(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// GetChannelMembers GET /channels/:channelID/members
+func (h *Handlers) GetChannelMembers(c echo.Context) error {
+	ch := getParamChannel(c)
+
+	if ch.IsPublic || ch.IsDMChannel() {
+		return herror.Forbidden("this channel is not a private channel")
+	}
+
+	members, err := h.Repo.GetPrivateChannelMemberIDs(ch.ID)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, members)
+}
+
+// PostChannelMemberRequest POST /channels/:channelID/members リクエストボディ
+type PostChannelMemberRequest struct {
+	ID uuid.UUID `json:"id"`
+}
+
+func (r PostChannelMemberRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.ID, vd.Required, validator.NotNilUUID),
+	)
+}
+
+// AddChannelMember POST /channels/:channelID/members
+func (h *Handlers) AddChannelMember(c echo.Context) error {
+	channelID := getParamAsUUID(c, consts.ParamChannelID)
+
+	var req PostChannelMemberRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.Repo.AddPrivateChannelMembers(channelID, getRequestUserID(c), []uuid.UUID{req.ID}); err != nil {
+		switch {
+		case repository.IsArgError(err):
+			return herror.BadRequest("this user doesn't exist")
+		case err == repository.ErrForbidden:
+			return herror.Forbidden("this channel is not a private channel")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RemoveChannelMember DELETE /channels/:channelID/members/:userID
+func (h *Handlers) RemoveChannelMember(c echo.Context) error {
+	channelID := getParamAsUUID(c, consts.ParamChannelID)
+	userID := getParamAsUUID(c, consts.ParamUserID)
+
+	if err := h.Repo.RemovePrivateChannelMembers(channelID, getRequestUserID(c), []uuid.UUID{userID}); err != nil {
+		switch {
+		case repository.IsArgError(err):
+			return herror.BadRequest("the last member cannot be removed")
+		case err == repository.ErrForbidden:
+			return herror.Forbidden("this channel is not a private channel")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ArchiveChannel POST /channels/:channelID/actions/archive
+func (h *Handlers) ArchiveChannel(c echo.Context) error {
+	channelID := getParamAsUUID(c, consts.ParamChannelID)
+
+	if err := h.Repo.ArchiveChannel(channelID, getRequestUserID(c)); err != nil {
+		switch err {
+		case repository.ErrForbidden:
+			return herror.Forbidden()
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// UnarchiveChannel POST /channels/:channelID/actions/unarchive
+func (h *Handlers) UnarchiveChannel(c echo.Context) error {
+	channelID := getParamAsUUID(c, consts.ParamChannelID)
+
+	if err := h.Repo.UnarchiveChannel(channelID, getRequestUserID(c)); err != nil {
+		switch err {
+		case repository.ErrForbidden:
+			return herror.Forbidden()
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
 // GetChannelViewers GET /channels/:channelID/viewers
 func (h *Handlers) GetChannelViewers(c echo.Context) error {
 	channelID := getParamAsUUID(c, consts.ParamChannelID)
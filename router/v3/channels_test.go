@@ -0,0 +1,129 @@
+package v3
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/rbac/permission"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/sessions"
+	"github.com/traPtitech/traQ/utils"
+	"net/http"
+	"testing"
+)
+
+func TestHandlers_ChannelMembers(t *testing.T) {
+	t.Parallel()
+	repo, server := Setup(t, common)
+	member := CreateUser(t, repo, random)
+	user := CreateUser(t, repo, random)
+	outsider := CreateUser(t, repo, random)
+	ch, err := repo.CreatePrivateChannel(utils.RandAlphabetAndNumberString(20), uuid.Nil, member.GetID(), nil)
+	require.NoError(t, err)
+	memberSession := S(t, member.GetID())
+	outsiderSession := S(t, outsider.GetID())
+	path := "/api/v3/channels/" + ch.ID.String() + "/members"
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := R(t, server)
+		e.POST(path).
+			WithJSON(echo.Map{"id": user.GetID()}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("not member", func(t *testing.T) {
+		t.Parallel()
+		e := R(t, server)
+		e.POST(path).
+			WithCookie(sessions.CookieName, outsiderSession).
+			WithJSON(echo.Map{"id": outsider.GetID()}).
+			Expect().
+			Status(http.StatusNotFound)
+		e.DELETE(path+"/{userID}", member.GetID()).
+			WithCookie(sessions.CookieName, outsiderSession).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := R(t, server)
+
+		// 管理者でないメンバーがメンバーを追加・削除できる
+		e.POST(path).
+			WithCookie(sessions.CookieName, memberSession).
+			WithJSON(echo.Map{"id": user.GetID()}).
+			Expect().
+			Status(http.StatusNoContent)
+		ok, err := repo.IsChannelAccessibleToUser(user.GetID(), ch.ID)
+		if assert.NoError(t, err) {
+			assert.True(t, ok)
+		}
+
+		e.DELETE(path+"/{userID}", user.GetID()).
+			WithCookie(sessions.CookieName, memberSession).
+			Expect().
+			Status(http.StatusNoContent)
+		ok, err = repo.IsChannelAccessibleToUser(user.GetID(), ch.ID)
+		if assert.NoError(t, err) {
+			assert.False(t, ok)
+		}
+	})
+}
+
+func TestHandlers_SetChannelBotPermissions(t *testing.T) {
+	t.Parallel()
+	repo, server := Setup(t, common)
+	owner := CreateUser(t, repo, random)
+	member := CreateUser(t, repo, random)
+	outsider := CreateUser(t, repo, random)
+	b, err := repo.CreateBot(utils.RandAlphabetAndNumberString(16), "bot", "", owner.GetID(), model.BotModeWebSocket, "")
+	require.NoError(t, err)
+	require.NoError(t, repo.UpdateBot(b.ID, repository.UpdateBotArgs{Permissions: model.BotPermissions{permission.PostMessage: true}}))
+	ch, err := repo.CreatePrivateChannel(utils.RandAlphabetAndNumberString(20), uuid.Nil, member.GetID(), []uuid.UUID{b.BotUserID})
+	require.NoError(t, err)
+	require.NoError(t, repo.AddBotToChannel(b.ID, ch.ID))
+	memberSession := S(t, member.GetID())
+	outsiderSession := S(t, outsider.GetID())
+	path := "/api/v3/channels/" + ch.ID.String() + "/bots/" + b.ID.String() + "/permissions"
+
+	t.Run("not member", func(t *testing.T) {
+		t.Parallel()
+		e := R(t, server)
+		e.PUT(path).
+			WithCookie(sessions.CookieName, outsiderSession).
+			WithJSON(echo.Map{"permissions": []string{"post_message"}}).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("not requested permission", func(t *testing.T) {
+		t.Parallel()
+		e := R(t, server)
+		e.PUT(path).
+			WithCookie(sessions.CookieName, memberSession).
+			WithJSON(echo.Map{"permissions": []string{"delete_message"}}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := R(t, server)
+
+		// 管理者でないメンバーが権限を許可できる
+		e.PUT(path).
+			WithCookie(sessions.CookieName, memberSession).
+			WithJSON(echo.Map{"permissions": []string{"post_message"}}).
+			Expect().
+			Status(http.StatusNoContent)
+		j, err := repo.GetBotJoinChannel(b.ID, ch.ID)
+		if assert.NoError(t, err) {
+			assert.True(t, j.Permissions.Contains(permission.PostMessage))
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package v3
+
+import (
+	"net/http"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/extension/herror"
+)
+
+// GetBotCommands GET /bots/:botID/commands
+func (h *Handlers) GetBotCommands(c echo.Context) error {
+	b := getParamBot(c)
+
+	cmds, err := h.Repo.GetBotCommands([]uuid.UUID{b.ID})
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, cmds)
+}
+
+// BotCommandRequest Botコマンド定義
+type BotCommandRequest struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	Arguments   model.BotCommandArguments `json:"arguments"`
+	HideMessage bool                      `json:"hideMessage"`
+}
+
+func (r BotCommandRequest) toModel() *model.BotCommand {
+	return &model.BotCommand{
+		Name:        r.Name,
+		Description: r.Description,
+		Arguments:   r.Arguments,
+		HideMessage: r.HideMessage,
+	}
+}
+
+// PutBotCommandsRequest PUT /bots/:botID/commands リクエストボディ
+type PutBotCommandsRequest struct {
+	Commands []*BotCommandRequest `json:"commands"`
+}
+
+func (r PutBotCommandsRequest) Validate() error {
+	if err := vd.ValidateStruct(&r,
+		vd.Field(&r.Commands, vd.Length(0, 50), vd.Each(vd.NotNil)),
+	); err != nil {
+		return err
+	}
+	for _, cmd := range r.Commands {
+		if err := cmd.toModel().Validate(); err != nil {
+			return vd.Errors{"commands": err}
+		}
+	}
+	return nil
+}
+
+// SetBotCommands PUT /bots/:botID/commands
+func (h *Handlers) SetBotCommands(c echo.Context) error {
+	b := getParamBot(c)
+
+	var req PutBotCommandsRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	cmds := make([]*model.BotCommand, len(req.Commands))
+	for i, cmd := range req.Commands {
+		cmds[i] = cmd.toModel()
+	}
+	if err := h.Repo.SetBotCommands(b.ID, cmds); err != nil {
+		switch {
+		case repository.IsArgError(err):
+			return herror.BadRequest(err)
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetChannelCommands GET /channels/:channelID/commands
+func (h *Handlers) GetChannelCommands(c echo.Context) error {
+	ch := getParamChannel(c)
+
+	cmds, err := h.getChannelCommands(ch)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, cmds)
+}
+
+// PostChannelCommandRequest POST /channels/:channelID/commands リクエストボディ
+type PostChannelCommandRequest struct {
+	Text  string    `json:"text"`
+	BotID uuid.UUID `json:"botId"`
+}
+
+func (r PostChannelCommandRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Text, vd.Required, vd.RuneLength(1, 10000)),
+	)
+}
+
+// InvokeChannelCommand POST /channels/:channelID/commands
+func (h *Handlers) InvokeChannelCommand(c echo.Context) error {
+	userID := getRequestUserID(c)
+	ch := getParamChannel(c)
+
+	var req PostChannelCommandRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	name, argText, ok := model.ParseBotCommandText(req.Text)
+	if !ok {
+		return herror.BadRequest("invalid command")
+	}
+
+	// 実行するコマンドを探す
+	cmds, err := h.getChannelCommands(ch)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	var cmd *model.BotCommand
+	for _, v := range cmds {
+		if v.Name != name || (req.BotID != uuid.Nil && v.BotID != req.BotID) {
+			continue
+		}
+		if cmd != nil {
+			return herror.BadRequest("ambiguous command: botId must be specified")
+		}
+		cmd = v
+	}
+	if cmd == nil {
+		return herror.NotFound("command not found")
+	}
+
+	args, err := cmd.ParseArguments(argText)
+	if err != nil {
+		return herror.BadRequest(err)
+	}
+
+	var m *model.Message
+	if cmd.HideMessage {
+		if ch.IsArchived {
+			return herror.ChannelWriteError(repository.ErrChannelArchived)
+		}
+	} else {
+		m, err = h.Repo.CreateMessage(userID, ch.ID, req.Text)
+		if err != nil {
+			return herror.ChannelWriteError(err)
+		}
+	}
+
+	messageID := uuid.Nil
+	if m != nil {
+		messageID = m.ID
+	}
+	h.Hub.Publish(hub.Message{
+		Name: event.BotCommandInvoked,
+		Fields: hub.Fields{
+			"bot_id":     cmd.BotID,
+			"channel_id": ch.ID,
+			"user_id":    userID,
+			"message_id": messageID,
+			"command":    cmd,
+			"arguments":  args,
+			"text":       req.Text,
+		},
+	})
+
+	if m == nil {
+		return c.NoContent(http.StatusNoContent)
+	}
+	return c.JSON(http.StatusCreated, formatMessage(m))
+}
+
+// getChannelCommands チャンネルで実行可能なコマンドを取得します
+//
+// チャンネルに参加している有効なBotのコマンドが実行可能です。ダイレクトメッセージの場合は相手のBotのコマンドが実行可能です。
+func (h *Handlers) getChannelCommands(ch *model.Channel) ([]*model.BotCommand, error) {
+	var bots []*model.Bot
+	if ch.IsDMChannel() {
+		ids, err := h.Repo.GetPrivateChannelMemberIDs(ch.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			b, err := h.Repo.GetBotByBotUserID(id)
+			if err != nil {
+				if err == repository.ErrNotFound {
+					continue
+				}
+				return nil, err
+			}
+			if b.State == model.BotActive {
+				bots = append(bots, b)
+			}
+		}
+	} else {
+		var err error
+		bots, err = h.Repo.GetBots(repository.BotsQuery{}.CMemberOf(ch.ID).Active())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return h.Repo.GetBotCommands(botIDs(bots))
+}
@@ -0,0 +1,82 @@
+package v3
+
+import (
+	"net/http"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension/herror"
+)
+
+// GetMyDrafts GET /users/me/drafts
+func (h *Handlers) GetMyDrafts(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	drafts, err := h.Repo.GetDraftsByUserID(userID)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	return c.JSON(http.StatusOK, formatDrafts(drafts))
+}
+
+// GetMyDraft GET /users/me/drafts/:channelID
+func (h *Handlers) GetMyDraft(c echo.Context) error {
+	userID := getRequestUserID(c)
+	channelID := getParamAsUUID(c, consts.ParamChannelID)
+
+	d, err := h.Repo.GetDraft(userID, channelID)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound()
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, formatDraft(d))
+}
+
+// PutDraftRequest PUT /users/me/drafts/:channelID リクエストボディ
+type PutDraftRequest struct {
+	Content string `json:"content"`
+}
+
+func (r PutDraftRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Content, vd.Required, vd.RuneLength(1, 10000)),
+	)
+}
+
+// PutMyDraft PUT /users/me/drafts/:channelID
+func (h *Handlers) PutMyDraft(c echo.Context) error {
+	userID := getRequestUserID(c)
+	channelID := getParamAsUUID(c, consts.ParamChannelID)
+
+	var req PutDraftRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	d, err := h.Repo.SaveDraft(userID, channelID, req.Content, getRequestClientKey(c))
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	return c.JSON(http.StatusOK, formatDraft(d))
+}
+
+// DeleteMyDraft DELETE /users/me/drafts/:channelID
+func (h *Handlers) DeleteMyDraft(c echo.Context) error {
+	userID := getRequestUserID(c)
+	channelID := getParamAsUUID(c, consts.ParamChannelID)
+
+	if err := h.Repo.DeleteDraft(userID, channelID, getRequestClientKey(c)); err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
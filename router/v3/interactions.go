@@ -0,0 +1,82 @@
+package v3
+
+import (
+	"net/http"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/extension/herror"
+)
+
+// PostMessageInteractionRequest POST /messages/:messageID/interactions リクエストボディ
+type PostMessageInteractionRequest struct {
+	ComponentID string `json:"componentId"`
+	Value       string `json:"value"`
+}
+
+func (r PostMessageInteractionRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.ComponentID, vd.Required, vd.Match(model.MessageComponentIDRegex)),
+		vd.Field(&r.Value, vd.RuneLength(0, 100)),
+	)
+}
+
+// PostMessageInteraction POST /messages/:messageID/interactions
+func (h *Handlers) PostMessageInteraction(c echo.Context) error {
+	userID := getRequestUserID(c)
+	m := getParamMessage(c)
+
+	var req PostMessageInteractionRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	var component *model.MessageComponent
+	if m.Components != nil {
+		component = m.Components.Components.Find(req.ComponentID)
+	}
+	if component == nil {
+		return herror.NotFound("component not found")
+	}
+	if component.Disabled {
+		return herror.BadRequest("component is disabled")
+	}
+
+	// ボタンの場合はボタンに設定された値を、セレクトメニューの場合は選択された値を送信する
+	value := component.Value
+	if component.Type == model.MessageComponentSelect {
+		if component.FindOption(req.Value) == nil {
+			return herror.BadRequest("invalid value")
+		}
+		value = req.Value
+	}
+
+	b, err := h.Repo.GetBotByBotUserID(m.UserID)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.BadRequest("this message is not posted by bot")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	if b.State != model.BotActive {
+		return herror.BadRequest("bot is not active")
+	}
+
+	h.Hub.Publish(hub.Message{
+		Name: event.BotInteraction,
+		Fields: hub.Fields{
+			"bot_id":    b.ID,
+			"user_id":   userID,
+			"message":   m,
+			"component": component,
+			"value":     value,
+		},
+	})
+	return c.NoContent(http.StatusNoContent)
+}
@@ -2,13 +2,16 @@ package v3
 
 import (
 	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/traPtitech/traQ/model"
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router/consts"
 	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/search"
 	"github.com/traPtitech/traQ/utils/message"
 	"net/http"
+	"time"
 )
 
 // GetMyUnreadChannels GET /users/me/unread
@@ -35,6 +38,30 @@ func (h *Handlers) ReadChannel(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// GetMyUnreadThreads GET /users/me/unread/threads
+func (h *Handlers) GetMyUnreadThreads(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	list, err := h.Repo.GetUserUnreadThreads(userID)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// ReadThread DELETE /users/me/unread/threads/:messageID
+func (h *Handlers) ReadThread(c echo.Context) error {
+	userID := getRequestUserID(c)
+	threadID := getParamAsUUID(c, consts.ParamMessageID)
+
+	if err := h.Repo.DeleteUnreadsByThreadID(threadID, userID); err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
 // GetMessage GET /messages/:messageID
 func (h *Handlers) GetMessage(c echo.Context) error {
 	return c.JSON(http.StatusOK, formatMessage(getParamMessage(c)))
@@ -42,16 +69,28 @@ func (h *Handlers) GetMessage(c echo.Context) error {
 
 // PostMessageRequest POST /channels/:channelID/messages等リクエストボディ
 type PostMessageRequest struct {
-	Content string `json:"content"`
-	Embed   bool   `json:"embed" query:"embed"`
+	Content    string                     `json:"content"`
+	Embed      bool                       `json:"embed" query:"embed"`
+	Components model.MessageComponentList `json:"components"`
 }
 
 func (r PostMessageRequest) Validate() error {
 	return vd.ValidateStruct(&r,
 		vd.Field(&r.Content, vd.Required, vd.RuneLength(1, 10000)),
+		vd.Field(&r.Components),
 	)
 }
 
+// checkComponents コンポーネントを指定できるユーザーかどうかを確認します
+//
+// コンポーネントはBotのみ指定できます。
+func (r PostMessageRequest) checkComponents(c echo.Context) error {
+	if r.Components != nil && !getRequestUser(c).IsBot() {
+		return herror.Forbidden("only bots can attach components")
+	}
+	return nil
+}
+
 // EditMessage PUT /messages/:messageID
 func (h *Handlers) EditMessage(c echo.Context) error {
 	userID := getRequestUserID(c)
@@ -66,18 +105,37 @@ func (h *Handlers) EditMessage(c echo.Context) error {
 	if userID != m.UserID {
 		return herror.Forbidden("This is not your message")
 	}
+	if err := req.checkComponents(c); err != nil {
+		return err
+	}
+
+	// 編集可能期間を過ぎたメッセージは編集できない
+	if h.MessageEditLimit > 0 && time.Since(m.CreatedAt) > h.MessageEditLimit {
+		return herror.BadRequest("the message can no longer be edited")
+	}
 
 	if req.Embed {
 		req.Content = message.NewReplacer(h.Repo).Replace(req.Content)
 	}
 
-	if err := h.Repo.UpdateMessage(m.ID, req.Content); err != nil {
-		return herror.InternalServerError(err)
+	if err := h.Repo.UpdateMessageWithComponents(m.ID, req.Content, req.Components); err != nil {
+		return herror.ChannelWriteError(err)
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
+// GetMessageHistory GET /messages/:messageID/history
+func (h *Handlers) GetMessageHistory(c echo.Context) error {
+	m := getParamMessage(c)
+
+	ams, err := h.Repo.GetArchivedMessagesByID(m.ID)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, formatMessageRevisions(ams))
+}
+
 // DeleteMessage DELETE /messages/:messageID
 func (h *Handlers) DeleteMessage(c echo.Context) error {
 	userID := getRequestUserID(c)
@@ -125,7 +183,7 @@ func (h *Handlers) DeleteMessage(c echo.Context) error {
 		}
 	}
 
-	if err := h.Repo.DeleteMessage(m.ID); err != nil {
+	if err := h.Repo.DeleteMessage(m.ID, userID); err != nil {
 		return herror.InternalServerError(err)
 	}
 
@@ -150,7 +208,7 @@ func (h *Handlers) CreatePin(c echo.Context) error {
 
 	p, err := h.Repo.CreatePin(m.ID, getRequestUserID(c))
 	if err != nil {
-		return herror.InternalServerError(err)
+		return herror.ChannelWriteError(err)
 	}
 	return c.JSON(http.StatusCreated, formatMessagePin(p))
 }
@@ -201,7 +259,7 @@ func (h *Handlers) AddMessageStamp(c echo.Context) error {
 
 	// スタンプをメッセージに押す
 	if _, err := h.Repo.AddStampToMessage(messageID, stampID, userID, req.Count); err != nil {
-		return herror.InternalServerError(err)
+		return herror.ChannelWriteError(err)
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -230,7 +288,52 @@ func (h *Handlers) GetMessages(c echo.Context) error {
 		return err
 	}
 
-	return serveMessages(c, h.Repo, req.convertC(channelID))
+	q := req.convertC(channelID)
+	q.ExcludeReplies = true
+	return serveMessages(c, h.Repo, q)
+}
+
+// GetMessageReplies GET /messages/:messageID/replies
+func (h *Handlers) GetMessageReplies(c echo.Context) error {
+	m := getParamMessage(c)
+
+	var req MessagesQuery
+	if err := req.bind(c); err != nil {
+		return err
+	}
+
+	threadID := m.ID
+	if m.IsReply() {
+		threadID = m.ThreadID.UUID
+	}
+	q := req.convert()
+	q.Thread = threadID
+	return serveMessages(c, h.Repo, q)
+}
+
+// PostMessageReply POST /messages/:messageID/replies
+func (h *Handlers) PostMessageReply(c echo.Context) error {
+	userID := getRequestUserID(c)
+	parent := getParamMessage(c)
+
+	var req PostMessageRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := req.checkComponents(c); err != nil {
+		return err
+	}
+
+	if req.Embed {
+		req.Content = message.NewReplacer(h.Repo).Replace(req.Content)
+	}
+
+	m, err := h.Repo.CreateReplyMessageWithComponents(userID, parent.ID, req.Content, req.Components)
+	if err != nil {
+		return herror.ChannelWriteError(err)
+	}
+
+	return c.JSON(http.StatusCreated, formatMessage(m))
 }
 
 // PostMessage POST /channels/:channelID/messages
@@ -242,14 +345,17 @@ func (h *Handlers) PostMessage(c echo.Context) error {
 	if err := bindAndValidate(c, &req); err != nil {
 		return err
 	}
+	if err := req.checkComponents(c); err != nil {
+		return err
+	}
 
 	if req.Embed {
 		req.Content = message.NewReplacer(h.Repo).Replace(req.Content)
 	}
 
-	m, err := h.Repo.CreateMessage(userID, channelID, req.Content)
+	m, err := h.Repo.CreateMessageWithComponents(userID, channelID, req.Content, req.Components)
 	if err != nil {
-		return herror.InternalServerError(err)
+		return herror.ChannelWriteError(err)
 	}
 
 	return c.JSON(http.StatusCreated, formatMessage(m))
@@ -271,7 +377,9 @@ func (h *Handlers) GetDirectMessages(c echo.Context) error {
 		return herror.InternalServerError(err)
 	}
 
-	return serveMessages(c, h.Repo, req.convertC(ch.ID))
+	q := req.convertC(ch.ID)
+	q.ExcludeReplies = true
+	return serveMessages(c, h.Repo, q)
 }
 
 // PostDirectMessage POST /users/:userId/messages
@@ -283,6 +391,9 @@ func (h *Handlers) PostDirectMessage(c echo.Context) error {
 	if err := bindAndValidate(c, &req); err != nil {
 		return err
 	}
+	if err := req.checkComponents(c); err != nil {
+		return err
+	}
 
 	// DMチャンネルを取得
 	ch, err := h.Repo.GetDirectMessageChannel(myID, targetID)
@@ -294,10 +405,93 @@ func (h *Handlers) PostDirectMessage(c echo.Context) error {
 		req.Content = message.NewReplacer(h.Repo).Replace(req.Content)
 	}
 
-	m, err := h.Repo.CreateMessage(myID, ch.ID, req.Content)
+	m, err := h.Repo.CreateMessageWithComponents(myID, ch.ID, req.Content, req.Components)
 	if err != nil {
 		return herror.InternalServerError(err)
 	}
 
 	return c.JSON(http.StatusCreated, formatMessage(m))
 }
+
+// SearchMessagesRequest GET /messages リクエストクエリ
+type SearchMessagesRequest struct {
+	Query  string `query:"q"`
+	Limit  int    `query:"limit"`
+	Offset int    `query:"offset"`
+}
+
+func (r *SearchMessagesRequest) Validate() error {
+	if r.Limit == 0 {
+		r.Limit = 20
+	}
+	return vd.ValidateStruct(r,
+		vd.Field(&r.Query, vd.Required, vd.RuneLength(1, 200)),
+		vd.Field(&r.Limit, vd.Min(1), vd.Max(100)),
+		vd.Field(&r.Offset, vd.Min(0)),
+	)
+}
+
+// SearchMessages GET /messages
+func (h *Handlers) SearchMessages(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	if !h.SearchEngine.Available() {
+		return herror.HTTPError(http.StatusServiceUnavailable, search.ErrServiceUnavailable)
+	}
+
+	var req SearchMessagesRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	pq, err := search.ParseQuery(req.Query)
+	if err != nil {
+		return herror.BadRequest(err)
+	}
+	q := &search.Query{
+		Words:   pq.Words,
+		HasFile: pq.HasFile,
+		Before:  pq.Before,
+		After:   pq.After,
+		Limit:   req.Limit,
+		Offset:  req.Offset,
+	}
+	if len(pq.In) > 0 {
+		id, ok := h.Repo.Channel(pq.In)
+		if !ok {
+			return herror.BadRequest("unknown channel: " + pq.In)
+		}
+		q.In = id
+	}
+	if len(pq.From) > 0 {
+		id, ok := h.Repo.User(pq.From)
+		if !ok {
+			return herror.BadRequest("unknown user: " + pq.From)
+		}
+		q.From = id
+	}
+
+	r, err := h.SearchEngine.Do(q, func(channelID uuid.UUID) (bool, error) {
+		return h.Repo.IsChannelAccessibleToUser(userID, channelID)
+	})
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	hits := make([]*model.Message, 0, len(r.Hits))
+	for _, id := range r.Hits {
+		m, err := h.Repo.GetMessageByID(id)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				continue // インデックスの更新が遅れている
+			}
+			return herror.InternalServerError(err)
+		}
+		hits = append(hits, m)
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"totalHits": r.TotalHits,
+		"hits":      formatMessages(hits),
+	})
+}
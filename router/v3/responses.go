@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofrs/uuid"
 	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
 )
 
 type Channel struct {
@@ -15,6 +16,7 @@ type Channel struct {
 	Children   []uuid.UUID   `json:"children"`
 	Visibility bool          `json:"visibility"`
 	Force      bool          `json:"force"`
+	Archived   bool          `json:"archived"`
 }
 
 func formatChannel(channel *model.Channel, childrenID []uuid.UUID) *Channel {
@@ -26,6 +28,7 @@ func formatChannel(channel *model.Channel, childrenID []uuid.UUID) *Channel {
 		Children:   childrenID,
 		Visibility: channel.IsVisible,
 		Force:      channel.IsForced,
+		Archived:   channel.IsArchived,
 	}
 }
 
@@ -146,14 +149,16 @@ func formatWebhooks(ws []model.Webhook) []*Webhook {
 }
 
 type Bot struct {
-	ID              uuid.UUID       `json:"id"`
-	BotUserID       uuid.UUID       `json:"botUserId"`
-	Description     string          `json:"description"`
-	DeveloperID     uuid.UUID       `json:"developerId"`
-	SubscribeEvents model.BotEvents `json:"subscribeEvents"`
-	State           model.BotState  `json:"state"`
-	CreatedAt       time.Time       `json:"createdAt"`
-	UpdatedAt       time.Time       `json:"updatedAt"`
+	ID              uuid.UUID            `json:"id"`
+	BotUserID       uuid.UUID            `json:"botUserId"`
+	Description     string               `json:"description"`
+	DeveloperID     uuid.UUID            `json:"developerId"`
+	SubscribeEvents model.BotEvents      `json:"subscribeEvents"`
+	Permissions     model.BotPermissions `json:"permissions"`
+	Mode            model.BotMode        `json:"mode"`
+	State           model.BotState       `json:"state"`
+	CreatedAt       time.Time            `json:"createdAt"`
+	UpdatedAt       time.Time            `json:"updatedAt"`
 }
 
 func formatBot(b *model.Bot) *Bot {
@@ -162,6 +167,8 @@ func formatBot(b *model.Bot) *Bot {
 		BotUserID:       b.BotUserID,
 		Description:     b.Description,
 		SubscribeEvents: b.SubscribeEvents,
+		Permissions:     b.Permissions,
+		Mode:            b.Mode,
 		State:           b.State,
 		DeveloperID:     b.CreatorID,
 		CreatedAt:       b.CreatedAt,
@@ -179,22 +186,25 @@ func formatBots(bs []*model.Bot) []*Bot {
 
 type BotTokens struct {
 	VerificationToken string `json:"verificationToken"`
+	SigningSecret     string `json:"signingSecret"`
 	AccessToken       string `json:"accessToken"`
 }
 
 type BotDetail struct {
-	ID              uuid.UUID       `json:"id"`
-	BotUserID       uuid.UUID       `json:"botUserId"`
-	Description     string          `json:"description"`
-	DeveloperID     uuid.UUID       `json:"developerId"`
-	SubscribeEvents model.BotEvents `json:"subscribeEvents"`
-	State           model.BotState  `json:"state"`
-	CreatedAt       time.Time       `json:"createdAt"`
-	UpdatedAt       time.Time       `json:"updatedAt"`
-	Tokens          BotTokens       `json:"tokens"`
-	Endpoint        string          `json:"endpoint"`
-	Privileged      bool            `json:"privileged"`
-	Channels        []uuid.UUID     `json:"channels"`
+	ID              uuid.UUID            `json:"id"`
+	BotUserID       uuid.UUID            `json:"botUserId"`
+	Description     string               `json:"description"`
+	DeveloperID     uuid.UUID            `json:"developerId"`
+	SubscribeEvents model.BotEvents      `json:"subscribeEvents"`
+	Permissions     model.BotPermissions `json:"permissions"`
+	Mode            model.BotMode        `json:"mode"`
+	State           model.BotState       `json:"state"`
+	CreatedAt       time.Time            `json:"createdAt"`
+	UpdatedAt       time.Time            `json:"updatedAt"`
+	Tokens          BotTokens            `json:"tokens"`
+	Endpoint        string               `json:"endpoint"`
+	Privileged      bool                 `json:"privileged"`
+	Channels        []uuid.UUID          `json:"channels"`
 }
 
 func formatBotDetail(b *model.Bot, t *model.OAuth2Token, channels []uuid.UUID) *BotDetail {
@@ -203,12 +213,15 @@ func formatBotDetail(b *model.Bot, t *model.OAuth2Token, channels []uuid.UUID) *
 		BotUserID:       b.BotUserID,
 		Description:     b.Description,
 		SubscribeEvents: b.SubscribeEvents,
+		Permissions:     b.Permissions,
+		Mode:            b.Mode,
 		State:           b.State,
 		DeveloperID:     b.CreatorID,
 		CreatedAt:       b.CreatedAt,
 		UpdatedAt:       b.UpdatedAt,
 		Tokens: BotTokens{
 			VerificationToken: b.VerificationToken,
+			SigningSecret:     b.SigningSecret,
 			AccessToken:       t.AccessToken,
 		},
 		Endpoint:   b.PostURL,
@@ -217,29 +230,144 @@ func formatBotDetail(b *model.Bot, t *model.OAuth2Token, channels []uuid.UUID) *
 	}
 }
 
-type Message struct {
-	ID        uuid.UUID            `json:"id"`
-	UserID    uuid.UUID            `json:"userId"`
+type BotChannelPermissions struct {
+	BotID     uuid.UUID            `json:"botId"`
 	ChannelID uuid.UUID            `json:"channelId"`
-	Content   string               `json:"content"`
-	CreatedAt time.Time            `json:"createdAt"`
-	UpdatedAt time.Time            `json:"updatedAt"`
-	Pinned    bool                 `json:"pinned"`
-	Stamps    []model.MessageStamp `json:"stamps"`
-	ThreadID  uuid.NullUUID        `json:"threadId"` // TODO
+	Scoped    bool                 `json:"scoped"`
+	Requested model.BotPermissions `json:"requested"`
+	Granted   model.BotPermissions `json:"granted"`
+}
+
+func formatBotChannelPermissions(b *model.Bot, j *model.BotJoinChannel) *BotChannelPermissions {
+	return &BotChannelPermissions{
+		BotID:     b.ID,
+		ChannelID: j.ChannelID,
+		Scoped:    b.ChannelScoped,
+		Requested: b.Permissions,
+		Granted:   j.Permissions,
+	}
+}
+
+type BotDirectoryEntry struct {
+	ID           uuid.UUID `json:"id"`
+	BotUserID    uuid.UUID `json:"botUserId"`
+	Name         string    `json:"name"`
+	DisplayName  string    `json:"displayName"`
+	Description  string    `json:"description"`
+	Homepage     string    `json:"homepage"`
+	InstallCount int       `json:"installCount"`
+}
+
+func formatBotDirectoryEntry(b *model.Bot, user model.UserInfo, installCount int) *BotDirectoryEntry {
+	return &BotDirectoryEntry{
+		ID:           b.ID,
+		BotUserID:    b.BotUserID,
+		Name:         user.GetName(),
+		DisplayName:  user.GetResponseDisplayName(),
+		Description:  b.Description,
+		Homepage:     b.Homepage,
+		InstallCount: installCount,
+	}
+}
+
+type BotManifest struct {
+	ID              uuid.UUID            `json:"id"`
+	BotUserID       uuid.UUID            `json:"botUserId"`
+	Name            string               `json:"name"`
+	DisplayName     string               `json:"displayName"`
+	DeveloperID     uuid.UUID            `json:"developerId"`
+	Description     string               `json:"description"`
+	HelpText        string               `json:"helpText"`
+	Homepage        string               `json:"homepage"`
+	PrivacyNote     string               `json:"privacyNote"`
+	SubscribeEvents model.BotEvents      `json:"subscribeEvents"`
+	Permissions     model.BotPermissions `json:"permissions"`
+	Commands        []*model.BotCommand  `json:"commands"`
+	InstallCount    int                  `json:"installCount"`
+}
+
+func formatBotManifest(b *model.Bot, user model.UserInfo, commands []*model.BotCommand, installCount int) *BotManifest {
+	return &BotManifest{
+		ID:              b.ID,
+		BotUserID:       b.BotUserID,
+		Name:            user.GetName(),
+		DisplayName:     user.GetResponseDisplayName(),
+		DeveloperID:     b.CreatorID,
+		Description:     b.Description,
+		HelpText:        b.HelpText,
+		Homepage:        b.Homepage,
+		PrivacyNote:     b.PrivacyNote,
+		SubscribeEvents: b.SubscribeEvents,
+		Permissions:     b.Permissions,
+		Commands:        commands,
+		InstallCount:    installCount,
+	}
+}
+
+type ChannelBot struct {
+	BotID       uuid.UUID            `json:"botId"`
+	BotUserID   uuid.UUID            `json:"botUserId"`
+	Name        string               `json:"name"`
+	DisplayName string               `json:"displayName"`
+	Description string               `json:"description"`
+	State       model.BotState       `json:"state"`
+	Permissions model.BotPermissions `json:"permissions"`
+	Commands    []*model.BotCommand  `json:"commands"`
+}
+
+func formatChannelBot(b *model.Bot, user model.UserInfo, j *model.BotJoinChannel, commands []*model.BotCommand) *ChannelBot {
+	return &ChannelBot{
+		BotID:       b.ID,
+		BotUserID:   b.BotUserID,
+		Name:        user.GetName(),
+		DisplayName: user.GetResponseDisplayName(),
+		Description: b.Description,
+		State:       b.State,
+		Permissions: j.Permissions,
+		Commands:    commands,
+	}
+}
+
+type Message struct {
+	ID          uuid.UUID                  `json:"id"`
+	UserID      uuid.UUID                  `json:"userId"`
+	ChannelID   uuid.UUID                  `json:"channelId"`
+	Content     string                     `json:"content"`
+	CreatedAt   time.Time                  `json:"createdAt"`
+	UpdatedAt   time.Time                  `json:"updatedAt"`
+	Pinned      bool                       `json:"pinned"`
+	Stamps      []model.MessageStamp       `json:"stamps"`
+	Edited      bool                       `json:"edited"`
+	EditCount   int                        `json:"editCount"`
+	ThreadID    uuid.NullUUID              `json:"threadId"`
+	ReplyCount  int                        `json:"replyCount"`
+	LastReplyAt null.Time                  `json:"lastReplyAt"`
+	Components  model.MessageComponentList `json:"components"`
 }
 
 func formatMessage(m *model.Message) *Message {
-	return &Message{
-		ID:        m.ID,
-		UserID:    m.UserID,
-		ChannelID: m.ChannelID,
-		Content:   m.Text,
-		CreatedAt: m.CreatedAt,
-		UpdatedAt: m.UpdatedAt,
-		Pinned:    m.Pin != nil,
-		Stamps:    m.Stamps,
+	res := &Message{
+		ID:         m.ID,
+		UserID:     m.UserID,
+		ChannelID:  m.ChannelID,
+		Content:    m.Text,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+		Pinned:     m.Pin != nil,
+		Stamps:     m.Stamps,
+		Edited:     m.IsEdited(),
+		EditCount:  m.EditCount,
+		ThreadID:   m.ThreadID,
+		Components: model.MessageComponentList{},
+	}
+	if m.Thread != nil {
+		res.ReplyCount = m.Thread.ReplyCount
+		res.LastReplyAt = null.TimeFrom(m.Thread.LastReplyAt)
 	}
+	if m.Components != nil {
+		res.Components = m.Components.Components
+	}
+	return res
 }
 
 func formatMessages(ms []*model.Message) []*Message {
@@ -250,6 +378,42 @@ func formatMessages(ms []*model.Message) []*Message {
 	return res
 }
 
+type DeletedMessage struct {
+	Message   *Message      `json:"message"`
+	DeletedAt time.Time     `json:"deletedAt"`
+	DeletedBy uuid.NullUUID `json:"deletedBy"`
+}
+
+func formatDeletedMessages(ms []*model.Message) []*DeletedMessage {
+	res := make([]*DeletedMessage, len(ms))
+	for i, m := range ms {
+		res[i] = &DeletedMessage{
+			Message:   formatMessage(m),
+			DeletedAt: *m.DeletedAt,
+			DeletedBy: m.DeletedBy,
+		}
+	}
+	return res
+}
+
+type MessageRevision struct {
+	UserID    uuid.UUID `json:"userId"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func formatMessageRevisions(ams []*model.ArchivedMessage) []*MessageRevision {
+	res := make([]*MessageRevision, len(ams))
+	for i, am := range ams {
+		res[i] = &MessageRevision{
+			UserID:    am.UserID,
+			Content:   am.Text,
+			CreatedAt: am.DateTime,
+		}
+	}
+	return res
+}
+
 type Pin struct {
 	UserID   uuid.UUID `json:"userId"`
 	PinnedAt time.Time `json:"pinnedAt"`
@@ -512,3 +676,87 @@ func formatStampPalettes(cfs []*model.StampPalette) []*StampPalette {
 	}
 	return res
 }
+
+type ScheduledMessage struct {
+	ID                uuid.UUID     `json:"id"`
+	UserID            uuid.UUID     `json:"userId"`
+	ChannelID         uuid.UUID     `json:"channelId"`
+	Content           string        `json:"content"`
+	ReminderMessageID uuid.NullUUID `json:"reminderMessageId"`
+	ScheduledAt       time.Time     `json:"scheduledAt"`
+	CreatedAt         time.Time     `json:"createdAt"`
+	UpdatedAt         time.Time     `json:"updatedAt"`
+}
+
+func formatScheduledMessage(m *model.ScheduledMessage) *ScheduledMessage {
+	return &ScheduledMessage{
+		ID:                m.ID,
+		UserID:            m.UserID,
+		ChannelID:         m.ChannelID,
+		Content:           m.Text,
+		ReminderMessageID: m.ReminderMessageID,
+		ScheduledAt:       m.ScheduledAt,
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
+	}
+}
+
+func formatScheduledMessages(ms []*model.ScheduledMessage) []*ScheduledMessage {
+	res := make([]*ScheduledMessage, len(ms))
+	for i, m := range ms {
+		res[i] = formatScheduledMessage(m)
+	}
+	return res
+}
+
+type ChannelExport struct {
+	ID        uuid.UUID                 `json:"id"`
+	ChannelID uuid.UUID                 `json:"channelId"`
+	Status    model.ChannelExportStatus `json:"status"`
+	FileID    uuid.NullUUID             `json:"fileId"`
+	Error     string                    `json:"error"`
+	CreatedAt time.Time                 `json:"createdAt"`
+	UpdatedAt time.Time                 `json:"updatedAt"`
+}
+
+func formatChannelExport(e *model.ChannelExport) *ChannelExport {
+	return &ChannelExport{
+		ID:        e.ID,
+		ChannelID: e.ChannelID,
+		Status:    e.Status,
+		FileID:    e.FileID,
+		Error:     e.Error,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+	}
+}
+
+func formatChannelExports(es []*model.ChannelExport) []*ChannelExport {
+	res := make([]*ChannelExport, len(es))
+	for i, e := range es {
+		res[i] = formatChannelExport(e)
+	}
+	return res
+}
+
+type Draft struct {
+	ChannelID uuid.UUID `json:"channelId"`
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func formatDraft(d *model.Draft) *Draft {
+	return &Draft{
+		ChannelID: d.ChannelID,
+		Content:   d.Text,
+		UpdatedAt: d.UpdatedAt,
+	}
+}
+
+func formatDrafts(ds []*model.Draft) []*Draft {
+	res := make([]*Draft, len(ds))
+	for i, d := range ds {
+		res[i] = formatDraft(d)
+	}
+	return res
+}
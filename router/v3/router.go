@@ -3,28 +3,38 @@ package v3
 import (
 	"github.com/labstack/echo/v4"
 	"github.com/leandro-lugaresi/hub"
+	botws "github.com/traPtitech/traQ/bot/ws"
 	"github.com/traPtitech/traQ/rbac"
 	"github.com/traPtitech/traQ/rbac/permission"
 	"github.com/traPtitech/traQ/realtime"
 	"github.com/traPtitech/traQ/realtime/ws"
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router/middlewares"
+	"github.com/traPtitech/traQ/search"
 	"go.uber.org/zap"
+	"time"
 )
 
 type Handlers struct {
 	RBAC     rbac.RBAC
 	Repo     repository.Repository
 	WS       *ws.Streamer
+	BotWS    *botws.Streamer
 	Hub      *hub.Hub
 	Logger   *zap.Logger
 	Realtime *realtime.Service
+	// SearchEngine メッセージ検索エンジン
+	SearchEngine search.Engine
 
 	Version  string
 	Revision string
 
 	// SkyWaySecretKey SkyWayクレデンシャル用シークレットキー
 	SkyWaySecretKey string
+	// MessageEditLimit メッセージを投稿後に編集可能な期間 0の場合は無制限
+	MessageEditLimit time.Duration
+	// RateLimiter Bot・Webhookのレート制限器 nilの場合は制限しない
+	RateLimiter *middlewares.RateLimiter
 
 	// EnabledExternalAccountLink リンク可能な外部認証アカウントのプロバイダ
 	EnabledExternalAccountProviders map[string]bool
@@ -33,11 +43,13 @@ type Handlers struct {
 // Setup APIルーティングを行います
 func (h *Handlers) Setup(e *echo.Group) {
 	// middleware preparation
-	requires := middlewares.AccessControlMiddlewareGenerator(h.RBAC)
+	requires := middlewares.AccessControlMiddlewareGenerator(h.RBAC, h.Repo)
 	bodyLimit := middlewares.RequestBodyLengthLimit
 	retrieve := middlewares.NewParamRetriever(h.Repo)
 	blockBot := middlewares.BlockBot(h.Repo)
 	nologin := middlewares.NoLogin()
+	limitMessage := h.RateLimiter.Message()
+	limitStamp := h.RateLimiter.Stamp()
 
 	requiresBotAccessPerm := middlewares.CheckBotAccessPerm(h.RBAC, h.Repo)
 	requiresWebhookAccessPerm := middlewares.CheckWebhookAccessPerm(h.RBAC, h.Repo)
@@ -45,10 +57,13 @@ func (h *Handlers) Setup(e *echo.Group) {
 	requiresClientAccessPerm := middlewares.CheckClientAccessPerm(h.RBAC, h.Repo)
 	requiresMessageAccessPerm := middlewares.CheckMessageAccessPerm(h.RBAC, h.Repo)
 	requiresChannelAccessPerm := middlewares.CheckChannelAccessPerm(h.RBAC, h.Repo)
+	requiresPrivateChannelManagePerm := middlewares.CheckPrivateChannelManagePerm(h.RBAC, h.Repo)
 	requiresGroupAdminPerm := middlewares.CheckUserGroupAdminPerm(h.RBAC, h.Repo)
 	requiresClipFolderAccessPerm := middlewares.CheckClipFolderAccessPerm(h.RBAC, h.Repo)
+	requiresScheduledMessageAccessPerm := middlewares.CheckScheduledMessageAccessPerm(h.RBAC, h.Repo)
+	requiresChannelExportAccessPerm := middlewares.CheckChannelExportAccessPerm(h.RBAC, h.Repo)
 
-	api := e.Group("/v3", middlewares.UserAuthenticate(h.Repo))
+	api := e.Group("/v3", middlewares.UserAuthenticate(h.Repo), h.RateLimiter.APICall())
 	{
 		apiUsers := api.Group("/users")
 		{
@@ -59,7 +74,7 @@ func (h *Handlers) Setup(e *echo.Group) {
 				apiUsersUID.GET("", h.GetUser, requires(permission.GetUser))
 				apiUsersUID.PATCH("", h.EditUser, requires(permission.EditOtherUsers))
 				apiUsersUID.GET("/messages", h.GetDirectMessages, requires(permission.GetMessage))
-				apiUsersUID.POST("/messages", h.PostDirectMessage, bodyLimit(100), requires(permission.PostMessage))
+				apiUsersUID.POST("/messages", h.PostDirectMessage, bodyLimit(100), requires(permission.PostMessage), limitMessage)
 				apiUsersUID.GET("/icon", h.GetUserIcon, requires(permission.DownloadFile))
 				apiUsersUID.PUT("/icon", h.ChangeUserIcon, requires(permission.EditOtherUsers))
 				apiUsersUID.PUT("/password", h.ChangeUserPassword, requires(permission.EditOtherUsers))
@@ -104,6 +119,34 @@ func (h *Handlers) Setup(e *echo.Group) {
 				{
 					apiUsersMeUnread.GET("", h.GetMyUnreadChannels, requires(permission.GetUnread))
 					apiUsersMeUnread.DELETE("/:channelID", h.ReadChannel, requires(permission.DeleteUnread))
+					apiUsersMeUnread.GET("/threads", h.GetMyUnreadThreads, requires(permission.GetUnread))
+					apiUsersMeUnread.DELETE("/threads/:messageID", h.ReadThread, requires(permission.DeleteUnread))
+				}
+				apiUsersMeScheduledMessages := apiUsersMe.Group("/scheduled-messages")
+				{
+					apiUsersMeScheduledMessages.GET("", h.GetMyScheduledMessages, requires(permission.GetMessage))
+					apiUsersMeScheduledMessages.POST("", h.CreateScheduledMessage, bodyLimit(100), requires(permission.PostMessage))
+					apiUsersMeScheduledMessagesSMID := apiUsersMeScheduledMessages.Group("/:scheduledMessageID", retrieve.ScheduledMessageID(), requiresScheduledMessageAccessPerm)
+					{
+						apiUsersMeScheduledMessagesSMID.GET("", h.GetScheduledMessage, requires(permission.GetMessage))
+						apiUsersMeScheduledMessagesSMID.PATCH("", h.EditScheduledMessage, bodyLimit(100), requires(permission.PostMessage))
+						apiUsersMeScheduledMessagesSMID.DELETE("", h.DeleteScheduledMessage, requires(permission.PostMessage))
+					}
+				}
+				apiUsersMeDrafts := apiUsersMe.Group("/drafts", blockBot)
+				{
+					apiUsersMeDrafts.GET("", h.GetMyDrafts, requires(permission.GetMessage))
+					apiUsersMeDraftsCID := apiUsersMeDrafts.Group("/:channelID", retrieve.ChannelID(), requiresChannelAccessPerm)
+					{
+						apiUsersMeDraftsCID.GET("", h.GetMyDraft, requires(permission.GetMessage))
+						apiUsersMeDraftsCID.PUT("", h.PutMyDraft, bodyLimit(100), requires(permission.PostMessage))
+						apiUsersMeDraftsCID.DELETE("", h.DeleteMyDraft, requires(permission.PostMessage))
+					}
+				}
+				apiUsersMeExports := apiUsersMe.Group("/exports", blockBot)
+				{
+					apiUsersMeExports.GET("", h.GetMyChannelExports, requires(permission.GetMessage))
+					apiUsersMeExports.GET("/:exportID", h.GetMyChannelExport, retrieve.ChannelExportID(), requiresChannelExportAccessPerm, requires(permission.GetMessage))
 				}
 				apiUsersMeSubscriptions := apiUsersMe.Group("/subscriptions", blockBot)
 				{
@@ -137,26 +180,50 @@ func (h *Handlers) Setup(e *echo.Group) {
 				apiChannelsCID.GET("", h.GetChannel, requires(permission.GetChannel))
 				apiChannelsCID.PATCH("", h.EditChannel, requires(permission.EditChannel))
 				apiChannelsCID.GET("/messages", h.GetMessages, requires(permission.GetMessage))
-				apiChannelsCID.POST("/messages", h.PostMessage, bodyLimit(100), requires(permission.PostMessage))
+				apiChannelsCID.POST("/messages", h.PostMessage, bodyLimit(100), requires(permission.PostMessage), limitMessage)
 				apiChannelsCID.GET("/stats", h.GetChannelStats, requires(permission.GetChannel))
 				apiChannelsCID.GET("/topic", h.GetChannelTopic, requires(permission.GetChannel))
 				apiChannelsCID.PUT("/topic", h.EditChannelTopic, requires(permission.EditChannelTopic))
 				apiChannelsCID.GET("/viewers", h.GetChannelViewers, requires(permission.GetChannel))
 				apiChannelsCID.GET("/pins", h.GetChannelPins, requires(permission.GetMessage))
+				apiChannelsCID.GET("/trash", h.GetChannelTrash, requires(permission.ManageMessageTrash))
 				apiChannelsCID.GET("/subscribers", h.GetChannelSubscribers, requires(permission.GetChannelSubscription))
 				apiChannelsCID.PUT("/subscribers", h.SetChannelSubscribers, requires(permission.EditChannelSubscription))
 				apiChannelsCID.PATCH("/subscribers", h.EditChannelSubscribers, requires(permission.EditChannelSubscription))
 				apiChannelsCID.GET("/bots", h.GetChannelBots, requires(permission.GetChannel))
+				apiChannelsCID.GET("/bots/:botID/permissions", h.GetChannelBotPermissions, requires(permission.GetChannel), retrieve.BotID())
+				apiChannelsCID.PUT("/bots/:botID/permissions", h.SetChannelBotPermissions, requires(permission.ManageChannelBotPermission), blockBot, retrieve.BotID())
+				apiChannelsCID.GET("/commands", h.GetChannelCommands, requires(permission.GetChannel))
+				apiChannelsCID.POST("/commands", h.InvokeChannelCommand, requires(permission.PostMessage), limitMessage)
 				apiChannelsCID.GET("/events", h.GetChannelEvents, requires(permission.GetChannel))
+				apiChannelsCID.POST("/export", h.ExportChannel, blockBot, requires(permission.GetMessage))
+				apiChannelsCIDActions := apiChannelsCID.Group("/actions")
+				{
+					apiChannelsCIDActions.POST("/archive", h.ArchiveChannel, requires(permission.EditChannel))
+					apiChannelsCIDActions.POST("/unarchive", h.UnarchiveChannel, requires(permission.EditChannel))
+				}
+			}
+			apiChannelsCIDMembers := apiChannels.Group("/:channelID/members", retrieve.ChannelID(), requiresPrivateChannelManagePerm)
+			{
+				apiChannelsCIDMembers.GET("", h.GetChannelMembers, requires(permission.GetChannel))
+				apiChannelsCIDMembers.POST("", h.AddChannelMember, requires(permission.ManagePrivateChannelMember))
+				apiChannelsCIDMembers.DELETE("/:userID", h.RemoveChannelMember, requires(permission.ManagePrivateChannelMember))
 			}
 		}
 		apiMessages := api.Group("/messages")
 		{
+			apiMessages.GET("", h.SearchMessages, requires(permission.GetMessage))
+			apiMessages.POST("/:messageID/restore", h.RestoreMessage, requires(permission.ManageMessageTrash))
 			apiMessagesMID := apiMessages.Group("/:messageID", retrieve.MessageID(), requiresMessageAccessPerm)
 			{
 				apiMessagesMID.GET("", h.GetMessage, requires(permission.GetMessage))
 				apiMessagesMID.PUT("", h.EditMessage, bodyLimit(100), requires(permission.EditMessage))
 				apiMessagesMID.DELETE("", h.DeleteMessage, requires(permission.DeleteMessage))
+				apiMessagesMID.GET("/history", h.GetMessageHistory, requires(permission.GetMessage))
+				apiMessagesMID.GET("/replies", h.GetMessageReplies, requires(permission.GetMessage))
+				apiMessagesMID.POST("/replies", h.PostMessageReply, bodyLimit(100), requires(permission.PostMessage), limitMessage)
+				apiMessagesMID.POST("/reminders", h.CreateMessageReminder, requires(permission.PostMessage))
+				apiMessagesMID.POST("/interactions", h.PostMessageInteraction, requires(permission.PostMessage), blockBot)
 				apiMessagesMID.GET("/pin", h.GetPin, requires(permission.GetMessage))
 				apiMessagesMID.POST("/pin", h.CreatePin, requires(permission.CreateMessagePin))
 				apiMessagesMID.DELETE("/pin", h.RemovePin, requires(permission.DeleteMessagePin))
@@ -165,7 +232,7 @@ func (h *Handlers) Setup(e *echo.Group) {
 					apiMessagesMIDStamps.GET("", h.GetMessageStamps, requires(permission.GetMessage))
 					apiMessagesMIDStampsSID := apiMessagesMIDStamps.Group("/:stampID", retrieve.StampID(true))
 					{
-						apiMessagesMIDStampsSID.POST("", h.AddMessageStamp, requires(permission.AddMessageStamp))
+						apiMessagesMIDStampsSID.POST("", h.AddMessageStamp, requires(permission.AddMessageStamp), limitStamp)
 						apiMessagesMIDStampsSID.DELETE("", h.RemoveMessageStamp, requires(permission.RemoveMessageStamp))
 					}
 				}
@@ -278,19 +345,26 @@ func (h *Handlers) Setup(e *echo.Group) {
 		{
 			apiBots.GET("", h.GetBots, requires(permission.GetBot))
 			apiBots.POST("", h.CreateBot, requires(permission.CreateBot))
+			apiBots.GET("/ws", h.ConnectBotWS, requires(permission.BotConnectWS))
+			apiBots.GET("/directory", h.GetBotDirectory, requires(permission.GetBot))
 			apiBotsBID := apiBots.Group("/:botID", retrieve.BotID())
 			{
 				apiBotsBID.GET("", h.GetBot, requires(permission.GetBot))
+				apiBotsBID.GET("/manifest", h.GetBotManifest, requires(permission.GetBot))
 				apiBotsBID.PATCH("", h.EditBot, requiresBotAccessPerm, requires(permission.EditBot))
 				apiBotsBID.DELETE("", h.DeleteBot, requiresBotAccessPerm, requires(permission.DeleteBot))
 				apiBotsBID.GET("/icon", h.GetBotIcon, requires(permission.GetBot, permission.DownloadFile))
 				apiBotsBID.PUT("/icon", h.ChangeBotIcon, requiresBotAccessPerm, requires(permission.EditBot))
 				apiBotsBID.GET("/logs", h.GetBotLogs, requiresBotAccessPerm, requires(permission.GetBot))
+				apiBotsBID.GET("/commands", h.GetBotCommands, requires(permission.GetBot))
+				apiBotsBID.PUT("/commands", h.SetBotCommands, requiresBotAccessPerm, requires(permission.EditBot))
 				apiBotsBIDActions := apiBotsBID.Group("/actions", requiresBotAccessPerm)
 				{
 					apiBotsBIDActions.POST("/activate", h.ActivateBot, requires(permission.EditBot))
 					apiBotsBIDActions.POST("/inactivate", h.InactivateBot, requires(permission.EditBot))
 					apiBotsBIDActions.POST("/reissue", h.ReissueBot, requires(permission.EditBot))
+					apiBotsBIDActions.POST("/redeliver", h.RedeliverBotEvent, requires(permission.EditBot))
+					apiBotsBIDActions.POST("/test", h.TestBotEvent, blockBot, requires(permission.EditBot))
 					apiBotsBIDActions.POST("/join", h.LetBotJoinChannel, requires(permission.BotActionJoinChannel))
 					apiBotsBIDActions.POST("/leave", h.LetBotLeaveChannel, requires(permission.BotActionLeaveChannel))
 				}
@@ -326,7 +400,7 @@ func (h *Handlers) Setup(e *echo.Group) {
 		apiNoAuth.GET("/version", h.GetVersion)
 		apiNoAuth.POST("/login", h.Login, nologin)
 		apiNoAuth.POST("/logout", h.Logout)
-		apiNoAuth.POST("/webhooks/:webhookID", h.PostWebhook)
+		apiNoAuth.POST("/webhooks/:webhookID", h.PostWebhook, retrieve.WebhookID())
 		apiNoAuthPublic := apiNoAuth.Group("/public")
 		{
 			apiNoAuthPublic.GET("/icon/:username", h.GetPublicUserIcon)
@@ -14,6 +14,7 @@ import (
 	"github.com/traPtitech/traQ/rbac/role"
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router/extension"
+	"github.com/traPtitech/traQ/router/middlewares"
 	"github.com/traPtitech/traQ/router/sessions"
 	"github.com/traPtitech/traQ/utils"
 	"github.com/traPtitech/traQ/utils/storage"
@@ -31,6 +32,9 @@ const (
 	dbPrefix = "traq-test-router-v3-"
 	common   = "common"
 	random   = "random"
+
+	// webhookRateLimit テストサーバーのWebhookのチャンネル毎の投稿数制限
+	webhookRateLimit = 1
 )
 
 var (
@@ -97,6 +101,9 @@ func TestMain(m *testing.M) {
 			Realtime: nil,
 			Version:  "version",
 			Revision: "revision",
+			RateLimiter: middlewares.NewRateLimiter(repo, middlewares.RateLimitConfig{}, middlewares.RateLimitConfig{}, middlewares.RateLimitConfig{
+				MessagesPerChannel: webhookRateLimit,
+			}),
 		}
 		handlers.Setup(e.Group("/api"))
 		servers[key] = httptest.NewServer(e)
@@ -0,0 +1,196 @@
+package v3
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/utils/message"
+	"gopkg.in/guregu/null.v3"
+)
+
+// GetMyScheduledMessages GET /users/me/scheduled-messages
+func (h *Handlers) GetMyScheduledMessages(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	ms, err := h.Repo.GetScheduledMessagesByUserID(userID)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	return c.JSON(http.StatusOK, formatScheduledMessages(ms))
+}
+
+// PostScheduledMessageRequest POST /users/me/scheduled-messages リクエストボディ
+type PostScheduledMessageRequest struct {
+	ChannelID   uuid.NullUUID `json:"channelId"`
+	UserID      uuid.NullUUID `json:"userId"`
+	Content     string        `json:"content"`
+	Embed       bool          `json:"embed"`
+	ScheduledAt time.Time     `json:"scheduledAt"`
+}
+
+func (r PostScheduledMessageRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.ChannelID, vd.When(!r.UserID.Valid, vd.Required.Error("either channelId or userId is required"))),
+		vd.Field(&r.UserID, vd.By(func(interface{}) error {
+			if r.ChannelID.Valid && r.UserID.Valid {
+				return errors.New("channelId and userId cannot be specified at the same time")
+			}
+			return nil
+		})),
+		vd.Field(&r.Content, vd.Required, vd.RuneLength(1, 10000)),
+		vd.Field(&r.ScheduledAt, vd.Required, vd.Min(time.Now()).Error("scheduledAt must be in the future")),
+	)
+}
+
+// CreateScheduledMessage POST /users/me/scheduled-messages
+func (h *Handlers) CreateScheduledMessage(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	var req PostScheduledMessageRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	var channelID uuid.UUID
+	if req.UserID.Valid {
+		// DMチャンネルを取得
+		if ok, err := h.Repo.UserExists(req.UserID.UUID); err != nil {
+			return herror.InternalServerError(err)
+		} else if !ok {
+			return herror.BadRequest("invalid userId")
+		}
+		ch, err := h.Repo.GetDirectMessageChannel(userID, req.UserID.UUID)
+		if err != nil {
+			return herror.InternalServerError(err)
+		}
+		channelID = ch.ID
+	} else {
+		// ユーザーがアクセスできるか
+		if ok, err := h.Repo.IsChannelAccessibleToUser(userID, req.ChannelID.UUID); err != nil {
+			return herror.InternalServerError(err)
+		} else if !ok {
+			return herror.BadRequest("invalid channelId")
+		}
+		channelID = req.ChannelID.UUID
+	}
+
+	if req.Embed {
+		req.Content = message.NewReplacer(h.Repo).Replace(req.Content)
+	}
+
+	m, err := h.Repo.CreateScheduledMessage(repository.CreateScheduledMessageArgs{
+		UserID:      userID,
+		ChannelID:   channelID,
+		Text:        req.Content,
+		ScheduledAt: req.ScheduledAt,
+	})
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	return c.JSON(http.StatusCreated, formatScheduledMessage(m))
+}
+
+// GetScheduledMessage GET /users/me/scheduled-messages/:scheduledMessageID
+func (h *Handlers) GetScheduledMessage(c echo.Context) error {
+	return c.JSON(http.StatusOK, formatScheduledMessage(getParamScheduledMessage(c)))
+}
+
+// PatchScheduledMessageRequest PATCH /users/me/scheduled-messages/:scheduledMessageID リクエストボディ
+type PatchScheduledMessageRequest struct {
+	Content     null.String `json:"content"`
+	Embed       bool        `json:"embed"`
+	ScheduledAt null.Time   `json:"scheduledAt"`
+}
+
+func (r PatchScheduledMessageRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Content, vd.RuneLength(1, 10000)),
+		vd.Field(&r.ScheduledAt, vd.Min(time.Now()).Error("scheduledAt must be in the future")),
+	)
+}
+
+// EditScheduledMessage PATCH /users/me/scheduled-messages/:scheduledMessageID
+func (h *Handlers) EditScheduledMessage(c echo.Context) error {
+	m := getParamScheduledMessage(c)
+
+	var req PatchScheduledMessageRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if req.Content.Valid && req.Embed {
+		req.Content.String = message.NewReplacer(h.Repo).Replace(req.Content.String)
+	}
+
+	if err := h.Repo.UpdateScheduledMessage(m.ID, req.Content, req.ScheduledAt); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound()
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteScheduledMessage DELETE /users/me/scheduled-messages/:scheduledMessageID
+func (h *Handlers) DeleteScheduledMessage(c echo.Context) error {
+	m := getParamScheduledMessage(c)
+
+	if err := h.Repo.DeleteScheduledMessage(m.ID); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound()
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// PostMessageReminderRequest POST /messages/:messageID/reminders リクエストボディ
+type PostMessageReminderRequest struct {
+	Content     string    `json:"content"`
+	ScheduledAt time.Time `json:"scheduledAt"`
+}
+
+func (r PostMessageReminderRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Content, vd.RuneLength(0, 1000)),
+		vd.Field(&r.ScheduledAt, vd.Required, vd.Min(time.Now()).Error("scheduledAt must be in the future")),
+	)
+}
+
+// CreateMessageReminder POST /messages/:messageID/reminders
+func (h *Handlers) CreateMessageReminder(c echo.Context) error {
+	userID := getRequestUserID(c)
+	m := getParamMessage(c)
+
+	var req PostMessageReminderRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	r, err := h.Repo.CreateScheduledMessage(repository.CreateScheduledMessageArgs{
+		UserID:            userID,
+		ChannelID:         m.ChannelID,
+		Text:              req.Content,
+		ReminderMessageID: uuid.NullUUID{UUID: m.ID, Valid: true},
+		ScheduledAt:       req.ScheduledAt,
+	})
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	return c.JSON(http.StatusCreated, formatScheduledMessage(r))
+}
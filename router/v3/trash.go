@@ -0,0 +1,60 @@
+package v3
+
+import (
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"net/http"
+	"strconv"
+)
+
+type trashQuery struct {
+	Limit  int `query:"limit"`
+	Offset int `query:"offset"`
+}
+
+func (q *trashQuery) Validate() error {
+	if q.Limit == 0 {
+		q.Limit = 20
+	}
+	return vd.ValidateStruct(q,
+		vd.Field(&q.Limit, vd.Min(1), vd.Max(200)),
+		vd.Field(&q.Offset, vd.Min(0)),
+	)
+}
+
+// GetChannelTrash GET /channels/:channelID/trash
+func (h *Handlers) GetChannelTrash(c echo.Context) error {
+	channelID := getParamAsUUID(c, consts.ParamChannelID)
+
+	var req trashQuery
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	messages, more, err := h.Repo.GetDeletedMessages(channelID, req.Limit, req.Offset)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	c.Response().Header().Set(consts.HeaderMore, strconv.FormatBool(more))
+	return c.JSON(http.StatusOK, formatDeletedMessages(messages))
+}
+
+// RestoreMessage POST /messages/:messageID/restore
+func (h *Handlers) RestoreMessage(c echo.Context) error {
+	messageID := getParamAsUUID(c, consts.ParamMessageID)
+
+	m, err := h.Repo.RestoreMessage(messageID)
+	if err != nil {
+		switch err {
+		case repository.ErrNilID, repository.ErrNotFound:
+			return herror.NotFound("deleted message not found")
+		default:
+			return herror.ChannelWriteError(err)
+		}
+	}
+	return c.JSON(http.StatusOK, formatMessage(m))
+}
@@ -42,6 +42,12 @@ func getRequestUserID(c echo.Context) uuid.UUID {
 	return getRequestUser(c).GetID()
 }
 
+// getRequestClientKey リクエストしてきたクライアントの識別キーを取得
+func getRequestClientKey(c echo.Context) string {
+	key, _ := c.Get(consts.KeyClientKey).(string)
+	return key
+}
+
 // getParamUser URLの:userIDに対応するユーザー構造体を取得
 func getParamUser(c echo.Context) model.UserInfo {
 	return c.Get(consts.KeyParamUser).(model.UserInfo)
@@ -77,6 +83,16 @@ func getParamStampPalette(c echo.Context) *model.StampPalette {
 	return c.Get(consts.KeyParamStampPalette).(*model.StampPalette)
 }
 
+// getParamScheduledMessage URLの:scheduledMessageIDに対応するScheduledMessageを取得
+func getParamScheduledMessage(c echo.Context) *model.ScheduledMessage {
+	return c.Get(consts.KeyParamScheduledMessage).(*model.ScheduledMessage)
+}
+
+// getParamChannelExport URLの:exportIDに対応するChannelExportを取得
+func getParamChannelExport(c echo.Context) *model.ChannelExport {
+	return c.Get(consts.KeyParamChannelExport).(*model.ChannelExport)
+}
+
 // getParamChannel URLの:channelIDに対応するChannelを取得
 func getParamChannel(c echo.Context) *model.Channel {
 	return c.Get(consts.KeyParamChannel).(*model.Channel)
@@ -192,7 +192,15 @@ func (h *Handlers) PostWebhook(c echo.Context) error {
 			}
 		}
 		if !ch.IsPublic {
-			return herror.BadRequest("invalid channel")
+			// プライベートチャンネルはWebhookのBOTユーザーがメンバーの場合のみ許可
+			if ch.IsDMChannel() {
+				return herror.BadRequest("invalid channel")
+			}
+			if ok, err := h.Repo.IsChannelAccessibleToUser(w.GetBotUserID(), ch.ID); err != nil {
+				return herror.InternalServerError(err)
+			} else if !ok {
+				return herror.BadRequest("invalid channel")
+			}
 		}
 		channelID = id
 	}
@@ -202,9 +210,14 @@ func (h *Handlers) PostWebhook(c echo.Context) error {
 		body = []byte(message.NewReplacer(h.Repo).Replace(string(body)))
 	}
 
+	// 認証・投稿先の検証後にレート制限
+	if err := h.RateLimiter.TakeWebhookMessage(c, w.GetID(), channelID); err != nil {
+		return err
+	}
+
 	// メッセージ投稿
 	if _, err := h.Repo.CreateMessage(w.GetBotUserID(), channelID, string(body)); err != nil {
-		return herror.InternalServerError(err)
+		return herror.ChannelWriteError(err)
 	}
 
 	return c.NoContent(http.StatusNoContent)
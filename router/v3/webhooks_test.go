@@ -0,0 +1,98 @@
+package v3
+
+import (
+	"encoding/hex"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/utils"
+	"github.com/traPtitech/traQ/utils/hmac"
+	"net/http"
+	"testing"
+)
+
+func TestHandlers_PostWebhook(t *testing.T) {
+	t.Parallel()
+	repo, server := Setup(t, common)
+	user := CreateUser(t, repo, random)
+	ch, err := repo.CreatePublicChannel(utils.RandAlphabetAndNumberString(20), uuid.Nil, user.GetID())
+	require.NoError(t, err)
+	ch2, err := repo.CreatePublicChannel(utils.RandAlphabetAndNumberString(20), uuid.Nil, user.GetID())
+	require.NoError(t, err)
+
+	const (
+		secret = "secret"
+		body   = "test message"
+	)
+	sig := hex.EncodeToString(hmac.SHA1([]byte(body), secret))
+
+	t.Run("unauthenticated requests do not consume rate limit", func(t *testing.T) {
+		t.Parallel()
+		w, err := repo.CreateWebhook(utils.RandAlphabetAndNumberString(20), "", ch.ID, user.GetID(), secret)
+		require.NoError(t, err)
+		e := R(t, server)
+		path := "/api/v3/webhooks/" + w.GetID().String()
+
+		for i := 0; i < webhookRateLimit+2; i++ {
+			e.POST(path).
+				WithHeader(consts.HeaderSignature, hex.EncodeToString([]byte("wrong"))).
+				WithText(body).
+				Expect().
+				Status(http.StatusBadRequest)
+		}
+
+		for i := 0; i < webhookRateLimit; i++ {
+			e.POST(path).
+				WithHeader(consts.HeaderSignature, sig).
+				WithText(body).
+				Expect().
+				Status(http.StatusNoContent)
+		}
+		e.POST(path).
+			WithHeader(consts.HeaderSignature, sig).
+			WithText(body).
+			Expect().
+			Status(http.StatusTooManyRequests).
+			Header(consts.HeaderRetryAfter).NotEmpty()
+	})
+
+	t.Run("rate limit is keyed on validated channel", func(t *testing.T) {
+		t.Parallel()
+		w, err := repo.CreateWebhook(utils.RandAlphabetAndNumberString(20), "", ch.ID, user.GetID(), secret)
+		require.NoError(t, err)
+		e := R(t, server)
+		path := "/api/v3/webhooks/" + w.GetID().String()
+
+		// 存在しないチャンネルは枠を消費しない
+		for i := 0; i < webhookRateLimit+2; i++ {
+			e.POST(path).
+				WithHeader(consts.HeaderSignature, sig).
+				WithHeader(consts.HeaderChannelID, uuid.Must(uuid.NewV4()).String()).
+				WithText(body).
+				Expect().
+				Status(http.StatusBadRequest)
+		}
+
+		for i := 0; i < webhookRateLimit; i++ {
+			e.POST(path).
+				WithHeader(consts.HeaderSignature, sig).
+				WithHeader(consts.HeaderChannelID, ch2.ID.String()).
+				WithText(body).
+				Expect().
+				Status(http.StatusNoContent)
+		}
+		e.POST(path).
+			WithHeader(consts.HeaderSignature, sig).
+			WithHeader(consts.HeaderChannelID, ch2.ID.String()).
+			WithText(body).
+			Expect().
+			Status(http.StatusTooManyRequests)
+
+		// デフォルトの投稿先チャンネルは別枠
+		e.POST(path).
+			WithHeader(consts.HeaderSignature, sig).
+			WithText(body).
+			Expect().
+			Status(http.StatusNoContent)
+	})
+}
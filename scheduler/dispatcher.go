@@ -0,0 +1,172 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// dispatchInterval 予約投稿メッセージの確認間隔
+	dispatchInterval = 10 * time.Second
+	// dispatchBatchSize 一度に取得する予約投稿メッセージの最大数
+	dispatchBatchSize = 100
+	// systemUserName リマインダーの送信者となるユーザーの名前
+	systemUserName = "traq"
+)
+
+// Dispatcher 予約投稿メッセージディスパッチャー
+//
+// 予約投稿メッセージはDBに保存されているため、サーバーの再起動を跨いでも投稿されます。
+// サーバー停止中に予定日時を過ぎたメッセージは、起動直後に投稿されます。
+// 投稿前に予約投稿メッセージを削除して確保するため、複数のインスタンスで動かしても二重に投稿されることはありません。
+type Dispatcher struct {
+	repo   repository.Repository
+	logger *zap.Logger
+	origin string
+
+	closer chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher 予約投稿メッセージディスパッチャーを生成し、起動します
+//
+// originはリマインダーに含めるメッセージURLの生成に使用します。
+func NewDispatcher(repo repository.Repository, logger *zap.Logger, origin string) *Dispatcher {
+	d := &Dispatcher{
+		repo:   repo,
+		logger: logger,
+		origin: origin,
+		closer: make(chan struct{}),
+	}
+	d.wg.Add(1)
+	go d.run()
+	return d
+}
+
+// Close ディスパッチャーを停止します
+func (d *Dispatcher) Close() {
+	close(d.closer)
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) run() {
+	defer d.wg.Done()
+	ticker := time.NewTicker(dispatchInterval)
+	defer ticker.Stop()
+	for {
+		d.dispatch()
+		select {
+		case <-ticker.C:
+		case <-d.closer:
+			return
+		}
+	}
+}
+
+// dispatch 予定日時を過ぎた予約投稿メッセージを全て投稿します
+func (d *Dispatcher) dispatch() {
+	for {
+		messages, err := d.repo.GetDueScheduledMessages(time.Now(), dispatchBatchSize)
+		if err != nil {
+			d.logger.Error("failed to GetDueScheduledMessages", zap.Error(err))
+			return
+		}
+
+		for _, m := range messages {
+			select {
+			case <-d.closer:
+				return
+			default:
+			}
+
+			// 投稿前に削除して予約投稿メッセージを確保する. 他インスタンスが既に確保していた場合は投稿しない
+			if err := d.repo.DeleteScheduledMessage(m.ID); err != nil {
+				if err == repository.ErrNotFound {
+					continue
+				}
+				d.logger.Error("failed to DeleteScheduledMessage", zap.Error(err), zap.Stringer("scheduledMessageId", m.ID))
+				return
+			}
+
+			if err := d.post(m); err != nil {
+				if err != repository.ErrNotFound && err != repository.ErrChannelArchived && !repository.IsArgError(err) {
+					// DBエラー等の場合は予約し直して次回に再試行する
+					d.logger.Error("failed to post scheduled message", zap.Error(err), zap.Stringer("scheduledMessageId", m.ID))
+					d.reschedule(m)
+					return
+				}
+				d.logger.Warn("scheduled message was discarded", zap.Error(err), zap.Stringer("scheduledMessageId", m.ID))
+			}
+		}
+
+		if len(messages) < dispatchBatchSize {
+			return
+		}
+	}
+}
+
+// reschedule 投稿に失敗した予約投稿メッセージを予約し直します
+func (d *Dispatcher) reschedule(m *model.ScheduledMessage) {
+	if _, err := d.repo.CreateScheduledMessage(repository.CreateScheduledMessageArgs{
+		UserID:            m.UserID,
+		ChannelID:         m.ChannelID,
+		Text:              m.Text,
+		ReminderMessageID: m.ReminderMessageID,
+		ScheduledAt:       m.ScheduledAt,
+	}); err != nil {
+		d.logger.Error("failed to reschedule scheduled message", zap.Error(err), zap.Stringer("scheduledMessageId", m.ID))
+	}
+}
+
+// post 予約投稿メッセージを投稿します
+//
+// 投稿者が無効化されている、或いはチャンネルにアクセスできなくなっている場合は投稿せずにErrNotFoundを返します。
+func (d *Dispatcher) post(m *model.ScheduledMessage) error {
+	user, err := d.repo.GetUser(m.UserID, false)
+	if err != nil {
+		return err
+	}
+	if !user.IsActive() {
+		return repository.ErrNotFound
+	}
+
+	if m.IsReminder() {
+		sys, err := d.repo.GetUserByName(systemUserName, false)
+		if err != nil {
+			return err
+		}
+		ch, err := d.repo.GetDirectMessageChannel(sys.GetID(), m.UserID)
+		if err != nil {
+			return err
+		}
+		_, err = d.repo.CreateMessage(sys.GetID(), ch.ID, reminderText(d.origin, m.ReminderMessageID.UUID, m.Text))
+		return err
+	}
+
+	if ok, err := d.repo.IsChannelAccessibleToUser(m.UserID, m.ChannelID); err != nil {
+		return err
+	} else if !ok {
+		return repository.ErrNotFound
+	}
+	_, err = d.repo.CreateMessage(m.UserID, m.ChannelID, m.Text)
+	return err
+}
+
+// reminderText リマインダーとして投稿するメッセージ本文を生成します
+func reminderText(origin string, messageID uuid.UUID, note string) string {
+	var sb strings.Builder
+	sb.WriteString(":bell: リマインダー\n")
+	if len(note) > 0 {
+		sb.WriteString(note)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf("%s/messages/%s", strings.TrimSuffix(origin, "/"), messageID))
+	return sb.String()
+}
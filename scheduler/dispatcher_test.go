@@ -0,0 +1,145 @@
+package scheduler
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"go.uber.org/zap"
+)
+
+// dispatcherRepository 予約投稿メッセージを保持するだけのリポジトリ
+type dispatcherRepository struct {
+	repository.Repository
+	scheduled map[uuid.UUID]*model.ScheduledMessage
+	posted    []string
+	postErr   error
+	mu        sync.Mutex
+}
+
+func (repo *dispatcherRepository) GetDueScheduledMessages(until time.Time, limit int) ([]*model.ScheduledMessage, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	result := make([]*model.ScheduledMessage, 0)
+	for _, m := range repo.scheduled {
+		if !m.ScheduledAt.After(until) {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+func (repo *dispatcherRepository) CreateScheduledMessage(args repository.CreateScheduledMessageArgs) (*model.ScheduledMessage, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	m := &model.ScheduledMessage{
+		ID:          uuid.Must(uuid.NewV4()),
+		UserID:      args.UserID,
+		ChannelID:   args.ChannelID,
+		Text:        args.Text,
+		ScheduledAt: args.ScheduledAt,
+	}
+	repo.scheduled[m.ID] = m
+	return m, nil
+}
+
+func (repo *dispatcherRepository) DeleteScheduledMessage(id uuid.UUID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if _, ok := repo.scheduled[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(repo.scheduled, id)
+	return nil
+}
+
+func (repo *dispatcherRepository) GetUser(id uuid.UUID, _ bool) (model.UserInfo, error) {
+	return &model.User{ID: id, Status: model.UserAccountStatusActive}, nil
+}
+
+func (repo *dispatcherRepository) IsChannelAccessibleToUser(_, _ uuid.UUID) (bool, error) {
+	return true, nil
+}
+
+func (repo *dispatcherRepository) CreateMessage(userID, channelID uuid.UUID, text string) (*model.Message, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if _, ok := repo.scheduled[repo.findScheduled(text)]; ok {
+		panic("posted before claiming scheduled message")
+	}
+	if repo.postErr != nil {
+		return nil, repo.postErr
+	}
+	repo.posted = append(repo.posted, text)
+	return &model.Message{ID: uuid.Must(uuid.NewV4()), UserID: userID, ChannelID: channelID, Text: text}, nil
+}
+
+func (repo *dispatcherRepository) findScheduled(text string) uuid.UUID {
+	for id, m := range repo.scheduled {
+		if m.Text == text {
+			return id
+		}
+	}
+	return uuid.Nil
+}
+
+func TestDispatcher_dispatch(t *testing.T) {
+	t.Parallel()
+
+	repo := &dispatcherRepository{scheduled: map[uuid.UUID]*model.ScheduledMessage{}}
+	d := &Dispatcher{repo: repo, logger: zap.NewNop(), closer: make(chan struct{})}
+	user := uuid.Must(uuid.NewV4())
+	channel := uuid.Must(uuid.NewV4())
+	schedule := func(text string) {
+		_, _ = repo.CreateScheduledMessage(repository.CreateScheduledMessageArgs{UserID: user, ChannelID: channel, Text: text, ScheduledAt: time.Now().Add(-time.Minute)})
+	}
+
+	// 複数インスタンスが同時に処理しても一度だけ投稿される
+	for i := 0; i < 10; i++ {
+		schedule("a")
+		var wg sync.WaitGroup
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.dispatch()
+			}()
+		}
+		wg.Wait()
+	}
+	assert.Len(t, repo.posted, 10)
+	assert.Empty(t, repo.scheduled)
+
+	// 投稿に失敗した場合は予約し直される
+	repo.postErr = errors.New("db error")
+	schedule("b")
+	d.dispatch()
+	assert.Len(t, repo.posted, 10)
+	if assert.Len(t, repo.scheduled, 1) {
+		for _, m := range repo.scheduled {
+			assert.Equal(t, "b", m.Text)
+		}
+	}
+
+	repo.postErr = nil
+	d.dispatch()
+	assert.Equal(t, "b", repo.posted[len(repo.posted)-1])
+	assert.Empty(t, repo.scheduled)
+}
+
+func TestReminderText(t *testing.T) {
+	t.Parallel()
+	id := uuid.Must(uuid.FromString("7ac2a6a3-fd42-4d6b-8a3a-6a9f1c7a2a0e"))
+
+	assert.Equal(t,
+		":bell: リマインダー\nhttps://q.trap.jp/messages/7ac2a6a3-fd42-4d6b-8a3a-6a9f1c7a2a0e",
+		reminderText("https://q.trap.jp", id, ""))
+	assert.Equal(t,
+		":bell: リマインダー\n確認する\nhttps://q.trap.jp/messages/7ac2a6a3-fd42-4d6b-8a3a-6a9f1c7a2a0e",
+		reminderText("https://q.trap.jp/", id, "確認する"))
+}
@@ -0,0 +1,75 @@
+package search
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"gopkg.in/guregu/null.v3"
+)
+
+// ErrServiceUnavailable 検索サービスが利用不可能です
+var ErrServiceUnavailable = errors.New("search service is unavailable")
+
+// Engine メッセージ検索エンジン
+type Engine interface {
+	// Do 検索を実行します
+	//
+	// accessibleで閲覧不可能と判定されたチャンネルのメッセージは結果に含まれません。
+	Do(q *Query, accessible AccessibleFunc) (*Result, error)
+	// Available 検索サービスが利用可能かどうか
+	Available() bool
+	// Close 検索エンジンを停止します
+	Close() error
+}
+
+// AccessibleFunc 指定したチャンネルのメッセージを結果に含めて良いかどうかを返す関数
+type AccessibleFunc func(channelID uuid.UUID) (bool, error)
+
+// Query 検索クエリ
+type Query struct {
+	// Words 検索語 (AND検索)
+	Words []string
+	// In 検索対象チャンネルID
+	In uuid.UUID
+	// From 投稿者ユーザーID
+	From uuid.UUID
+	// HasFile ファイルが添付されているメッセージのみ
+	HasFile bool
+	// Before この日時より前に投稿されたメッセージのみ
+	Before null.Time
+	// After この日時より後に投稿されたメッセージのみ
+	After null.Time
+	// Limit 最大取得件数
+	Limit int
+	// Offset 取得オフセット
+	Offset int
+}
+
+// Result 検索結果
+type Result struct {
+	// TotalHits 総ヒット件数
+	TotalHits int
+	// Hits ヒットしたメッセージのID (投稿日時降順)
+	Hits []uuid.UUID
+}
+
+// NewNullEngine 何もしない検索エンジンを返します
+func NewNullEngine() Engine {
+	return &nullEngine{}
+}
+
+type nullEngine struct{}
+
+// Do implements Engine interface.
+func (e *nullEngine) Do(*Query, AccessibleFunc) (*Result, error) {
+	return nil, ErrServiceUnavailable
+}
+
+// Available implements Engine interface.
+func (e *nullEngine) Available() bool {
+	return false
+}
+
+// Close implements Engine interface.
+func (e *nullEngine) Close() error {
+	return nil
+}
@@ -0,0 +1,263 @@
+package search
+
+import (
+	"encoding/gob"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils/message"
+)
+
+// document インデックスされたメッセージ
+type document struct {
+	ID        uuid.UUID
+	ChannelID uuid.UUID
+	UserID    uuid.UUID
+	Text      string
+	HasFile   bool
+	CreatedAt time.Time
+}
+
+// index 文字bi-gramによる転置インデックス
+type index struct {
+	docs     map[uuid.UUID]*document
+	postings map[string]map[uuid.UUID]struct{}
+	mu       sync.RWMutex
+}
+
+func newIndex() *index {
+	return &index{
+		docs:     map[uuid.UUID]*document{},
+		postings: map[string]map[uuid.UUID]struct{}{},
+	}
+}
+
+func newDocument(m *model.Message) *document {
+	embedded, plain := message.Parse(m.Text)
+	hasFile := false
+	for _, e := range embedded {
+		if e.Type == "file" {
+			hasFile = true
+			break
+		}
+	}
+	return &document{
+		ID:        m.ID,
+		ChannelID: m.ChannelID,
+		UserID:    m.UserID,
+		Text:      strings.ToLower(plain),
+		HasFile:   hasFile,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// Len インデックスされているメッセージ数
+func (idx *index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Add メッセージをインデックスに追加します。既に存在する場合は置き換えます
+func (idx *index) Add(m *model.Message) {
+	doc := newDocument(m)
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(doc.ID)
+	idx.add(doc)
+}
+
+// Remove メッセージをインデックスから削除します
+func (idx *index) Remove(id uuid.UUID) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(id)
+}
+
+func (idx *index) add(doc *document) {
+	idx.docs[doc.ID] = doc
+	for _, term := range terms(doc.Text) {
+		p, ok := idx.postings[term]
+		if !ok {
+			p = map[uuid.UUID]struct{}{}
+			idx.postings[term] = p
+		}
+		p[doc.ID] = struct{}{}
+	}
+}
+
+func (idx *index) remove(id uuid.UUID) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range terms(doc.Text) {
+		if p, ok := idx.postings[term]; ok {
+			delete(p, id)
+			if len(p) == 0 {
+				delete(idx.postings, term)
+			}
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// Search 検索を実行します
+func (idx *index) Search(q *Query, accessible AccessibleFunc) (*Result, error) {
+	idx.mu.RLock()
+	candidates := idx.candidates(q)
+	idx.mu.RUnlock()
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].CreatedAt.After(candidates[j].CreatedAt)
+	})
+
+	checked := map[uuid.UUID]bool{}
+	res := &Result{Hits: make([]uuid.UUID, 0)}
+	for _, doc := range candidates {
+		ok, checkedBefore := checked[doc.ChannelID]
+		if !checkedBefore {
+			var err error
+			ok, err = accessible(doc.ChannelID)
+			if err != nil {
+				return nil, err
+			}
+			checked[doc.ChannelID] = ok
+		}
+		if !ok {
+			continue
+		}
+
+		if res.TotalHits >= q.Offset && (q.Limit <= 0 || len(res.Hits) < q.Limit) {
+			res.Hits = append(res.Hits, doc.ID)
+		}
+		res.TotalHits++
+	}
+	return res, nil
+}
+
+func (idx *index) candidates(q *Query) []*document {
+	words := make([]string, 0, len(q.Words))
+	for _, w := range q.Words {
+		if w = strings.ToLower(strings.TrimSpace(w)); len(w) > 0 {
+			words = append(words, w)
+		}
+	}
+
+	// 検索語を構成するタームを全て含むメッセージに絞り込む
+	var set map[uuid.UUID]struct{}
+	for _, w := range words {
+		for _, term := range terms(w) {
+			p := idx.postings[term]
+			if set == nil {
+				set = make(map[uuid.UUID]struct{}, len(p))
+				for id := range p {
+					set[id] = struct{}{}
+				}
+				continue
+			}
+			for id := range set {
+				if _, ok := p[id]; !ok {
+					delete(set, id)
+				}
+			}
+		}
+	}
+
+	result := make([]*document, 0)
+	match := func(doc *document) bool {
+		if q.In != uuid.Nil && doc.ChannelID != q.In {
+			return false
+		}
+		if q.From != uuid.Nil && doc.UserID != q.From {
+			return false
+		}
+		if q.HasFile && !doc.HasFile {
+			return false
+		}
+		if q.Before.Valid && !doc.CreatedAt.Before(q.Before.Time) {
+			return false
+		}
+		if q.After.Valid && !doc.CreatedAt.After(q.After.Time) {
+			return false
+		}
+		for _, w := range words {
+			if !strings.Contains(doc.Text, w) {
+				return false
+			}
+		}
+		return true
+	}
+	if set == nil {
+		for _, doc := range idx.docs {
+			if match(doc) {
+				result = append(result, doc)
+			}
+		}
+	} else {
+		for id := range set {
+			if doc := idx.docs[id]; match(doc) {
+				result = append(result, doc)
+			}
+		}
+	}
+	return result
+}
+
+// Save インデックスをwに書き出します
+func (idx *index) Save(w io.Writer) error {
+	idx.mu.RLock()
+	docs := make([]*document, 0, len(idx.docs))
+	for _, doc := range idx.docs {
+		docs = append(docs, doc)
+	}
+	idx.mu.RUnlock()
+	return gob.NewEncoder(w).Encode(docs)
+}
+
+// Load rからインデックスを読み込みます。既存のインデックスは破棄されます
+func (idx *index) Load(r io.Reader) error {
+	var docs []*document
+	if err := gob.NewDecoder(r).Decode(&docs); err != nil {
+		return err
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.docs = make(map[uuid.UUID]*document, len(docs))
+	idx.postings = map[string]map[uuid.UUID]struct{}{}
+	for _, doc := range docs {
+		idx.add(doc)
+	}
+	return nil
+}
+
+// terms 文字列を索引語に分割します
+//
+// 英数字・かな漢字等の連続をひとかたまりとし、その文字bi-gramを索引語とします。
+// 1文字のみのかたまりは索引語になりません。
+func terms(s string) []string {
+	result := make([]string, 0)
+	seen := map[string]struct{}{}
+	appendTerm := func(t string) {
+		if _, ok := seen[t]; !ok {
+			seen[t] = struct{}{}
+			result = append(result, t)
+		}
+	}
+
+	for _, f := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		runes := []rune(f)
+		for i := 0; i < len(runes)-1; i++ {
+			appendTerm(string(runes[i : i+2]))
+		}
+	}
+	return result
+}
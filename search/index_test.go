@@ -0,0 +1,150 @@
+package search
+
+import (
+	"bytes"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
+	"testing"
+	"time"
+)
+
+func allAccessible(uuid.UUID) (bool, error) {
+	return true, nil
+}
+
+func makeMessage(channelID, userID uuid.UUID, text string, createdAt time.Time) *model.Message {
+	return &model.Message{
+		ID:        uuid.Must(uuid.NewV4()),
+		UserID:    userID,
+		ChannelID: channelID,
+		Text:      text,
+		CreatedAt: createdAt,
+	}
+}
+
+func TestTerms(t *testing.T) {
+	t.Parallel()
+
+	assert.EqualValues(t, []string{"he", "el", "ll", "lo"}, terms("Hello"))
+	assert.EqualValues(t, []string{"東京", "京都"}, terms("東京都"))
+	assert.EqualValues(t, []string{"ab", "cd"}, terms("ab, cd! e"))
+	assert.Empty(t, terms("a b c"))
+}
+
+func TestIndex_Search(t *testing.T) {
+	t.Parallel()
+
+	ch1 := uuid.Must(uuid.NewV4())
+	ch2 := uuid.Must(uuid.NewV4())
+	u1 := uuid.Must(uuid.NewV4())
+	u2 := uuid.Must(uuid.NewV4())
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	m1 := makeMessage(ch1, u1, "今日はいい天気ですね", base)
+	m2 := makeMessage(ch1, u2, "Hello World", base.Add(time.Hour))
+	m3 := makeMessage(ch2, u1, `天気予報 !{"type":"file","raw":"","id":"7b3e9a5c-1b2f-4d6e-8a9b-0c1d2e3f4a5b"}`, base.Add(2*time.Hour))
+	m4 := makeMessage(ch2, u2, "hello, traQ", base.Add(3*time.Hour))
+
+	idx := newIndex()
+	for _, m := range []*model.Message{m1, m2, m3, m4} {
+		idx.Add(m)
+	}
+	require.Equal(t, 4, idx.Len())
+
+	search := func(t *testing.T, q *Query) []uuid.UUID {
+		t.Helper()
+		res, err := idx.Search(q, allAccessible)
+		require.NoError(t, err)
+		return res.Hits
+	}
+
+	t.Run("word", func(t *testing.T) {
+		t.Parallel()
+		assert.EqualValues(t, []uuid.UUID{m3.ID, m1.ID}, search(t, &Query{Words: []string{"天気"}}))
+		assert.EqualValues(t, []uuid.UUID{m4.ID, m2.ID}, search(t, &Query{Words: []string{"HELLO"}}))
+		assert.EqualValues(t, []uuid.UUID{m2.ID}, search(t, &Query{Words: []string{"hello", "world"}}))
+		assert.Empty(t, search(t, &Query{Words: []string{"worlds"}}))
+	})
+
+	t.Run("single character", func(t *testing.T) {
+		t.Parallel()
+		assert.EqualValues(t, []uuid.UUID{m1.ID}, search(t, &Query{Words: []string{"今"}}))
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		t.Parallel()
+		assert.EqualValues(t, []uuid.UUID{m2.ID, m1.ID}, search(t, &Query{In: ch1}))
+		assert.EqualValues(t, []uuid.UUID{m3.ID, m1.ID}, search(t, &Query{From: u1}))
+		assert.EqualValues(t, []uuid.UUID{m3.ID}, search(t, &Query{HasFile: true}))
+		assert.EqualValues(t, []uuid.UUID{m2.ID}, search(t, &Query{
+			After:  null.TimeFrom(base),
+			Before: null.TimeFrom(base.Add(2 * time.Hour)),
+		}))
+	})
+
+	t.Run("limit and offset", func(t *testing.T) {
+		t.Parallel()
+		res, err := idx.Search(&Query{Limit: 2, Offset: 1}, allAccessible)
+		require.NoError(t, err)
+		assert.Equal(t, 4, res.TotalHits)
+		assert.EqualValues(t, []uuid.UUID{m3.ID, m2.ID}, res.Hits)
+	})
+
+	t.Run("accessible", func(t *testing.T) {
+		t.Parallel()
+		res, err := idx.Search(&Query{}, func(channelID uuid.UUID) (bool, error) {
+			return channelID == ch1, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, res.TotalHits)
+		assert.EqualValues(t, []uuid.UUID{m2.ID, m1.ID}, res.Hits)
+	})
+}
+
+func TestIndex_Update(t *testing.T) {
+	t.Parallel()
+
+	ch := uuid.Must(uuid.NewV4())
+	u := uuid.Must(uuid.NewV4())
+	m := makeMessage(ch, u, "before edit", time.Now())
+
+	idx := newIndex()
+	idx.Add(m)
+	m.Text = "after edit"
+	idx.Add(m)
+
+	res, err := idx.Search(&Query{Words: []string{"before"}}, allAccessible)
+	require.NoError(t, err)
+	assert.Empty(t, res.Hits)
+	res, err = idx.Search(&Query{Words: []string{"after"}}, allAccessible)
+	require.NoError(t, err)
+	assert.EqualValues(t, []uuid.UUID{m.ID}, res.Hits)
+
+	idx.Remove(m.ID)
+	assert.Equal(t, 0, idx.Len())
+	assert.Empty(t, idx.postings)
+}
+
+func TestIndex_SaveLoad(t *testing.T) {
+	t.Parallel()
+
+	ch := uuid.Must(uuid.NewV4())
+	u := uuid.Must(uuid.NewV4())
+	m := makeMessage(ch, u, "persistent message", time.Now())
+
+	idx := newIndex()
+	idx.Add(m)
+
+	var buf bytes.Buffer
+	require.NoError(t, idx.Save(&buf))
+
+	loaded := newIndex()
+	require.NoError(t, loaded.Load(&buf))
+	assert.Equal(t, 1, loaded.Len())
+	res, err := loaded.Search(&Query{Words: []string{"persistent"}}, allAccessible)
+	require.NoError(t, err)
+	assert.EqualValues(t, []uuid.UUID{m.ID}, res.Hits)
+}
@@ -0,0 +1,191 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	// memoryIndexSaveInterval インデックスファイルへの定期保存間隔
+	memoryIndexSaveInterval = 5 * time.Minute
+	// rebuildBatchSize インデックス再構築時のメッセージ取得単位
+	rebuildBatchSize = 1000
+)
+
+// memoryEngine プロセス内転置インデックスによる検索エンジン
+type memoryEngine struct {
+	idx    *index
+	hub    *hub.Hub
+	sub    hub.Subscription
+	logger *zap.Logger
+	file   string
+
+	dirty  bool
+	mu     sync.Mutex
+	closer chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewMemoryEngine プロセス内転置インデックスによる検索エンジンを生成します
+//
+// indexFileが指定されている場合、起動時にそのファイルからインデックスを読み込み、定期的及び終了時にファイルに書き出します。
+// インデックスはevent.MessageCreated, event.MessageUpdated, event.MessageDeleted, event.MessageRestoredイベントによって更新されます。
+func NewMemoryEngine(hub *hub.Hub, logger *zap.Logger, indexFile string) (Engine, error) {
+	e := &memoryEngine{
+		idx:    newIndex(),
+		hub:    hub,
+		logger: logger,
+		file:   indexFile,
+		closer: make(chan struct{}),
+	}
+	if len(indexFile) > 0 {
+		if err := e.load(); err != nil {
+			return nil, err
+		}
+		logger.Info("search index loaded", zap.String("file", indexFile), zap.Int("messages", e.idx.Len()))
+	}
+
+	e.sub = hub.Subscribe(1000, event.MessageCreated, event.MessageUpdated, event.MessageDeleted, event.MessageRestored)
+	e.wg.Add(1)
+	go e.run()
+	return e, nil
+}
+
+func (e *memoryEngine) run() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(memoryIndexSaveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg, ok := <-e.sub.Receiver:
+			if !ok {
+				return
+			}
+			switch msg.Topic() {
+			case event.MessageCreated, event.MessageUpdated, event.MessageRestored:
+				e.idx.Add(msg.Fields["message"].(*model.Message))
+			case event.MessageDeleted:
+				e.idx.Remove(msg.Fields["message_id"].(uuid.UUID))
+			}
+			e.mu.Lock()
+			e.dirty = true
+			e.mu.Unlock()
+		case <-ticker.C:
+			if err := e.save(); err != nil {
+				e.logger.Error("failed to save search index", zap.Error(err))
+			}
+		case <-e.closer:
+			return
+		}
+	}
+}
+
+// Do implements Engine interface.
+func (e *memoryEngine) Do(q *Query, accessible AccessibleFunc) (*Result, error) {
+	return e.idx.Search(q, accessible)
+}
+
+// Available implements Engine interface.
+func (e *memoryEngine) Available() bool {
+	return true
+}
+
+// Close implements Engine interface.
+func (e *memoryEngine) Close() error {
+	e.hub.Unsubscribe(e.sub)
+	close(e.closer)
+	e.wg.Wait()
+	return e.save()
+}
+
+func (e *memoryEngine) load() error {
+	f, err := os.Open(e.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	return e.idx.Load(f)
+}
+
+func (e *memoryEngine) save() error {
+	if len(e.file) == 0 {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.dirty {
+		return nil
+	}
+	if err := writeIndexFile(e.idx, e.file); err != nil {
+		return err
+	}
+	e.dirty = false
+	return nil
+}
+
+// writeIndexFile インデックスをファイルに書き出します
+func writeIndexFile(idx *index, file string) error {
+	// 書き込み途中のファイルを読まれないように一時ファイルに書き出してから置き換える
+	tmp, err := os.Create(filepath.Join(filepath.Dir(file), "."+filepath.Base(file)+".tmp"))
+	if err != nil {
+		return err
+	}
+	if err := idx.Save(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// RebuildMemoryIndex messagesテーブルからプロセス内検索エンジンのインデックスを再構築し、indexFileに書き出します
+//
+// 成功した場合、インデックスしたメッセージ数とnilを返します。
+// DBによるエラーを返すことがあります。
+func RebuildMemoryIndex(repo repository.Repository, indexFile string) (int, error) {
+	idx := newIndex()
+	since := null.Time{}
+	sinceID := uuid.Nil
+	for {
+		messages, more, err := repo.GetMessages(repository.MessagesQuery{
+			Since:          since,
+			SinceID:        sinceID,
+			Inclusive:      true,
+			Limit:          rebuildBatchSize,
+			Asc:            true,
+			DisablePreload: true,
+		})
+		if err != nil {
+			return 0, err
+		}
+		for _, m := range messages {
+			idx.Add(m)
+		}
+		if !more || len(messages) == 0 {
+			break
+		}
+
+		// 同一時刻のメッセージがバッチサイズ以上存在しても取りこぼさないように(created_at, id)でページングする
+		last := messages[len(messages)-1]
+		since = null.TimeFrom(last.CreatedAt)
+		sinceID = last.ID
+	}
+	return idx.Len(), writeIndexFile(idx, indexFile)
+}
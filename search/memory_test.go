@@ -0,0 +1,85 @@
+package search
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// messagesRepository (created_at, id)順に並んだメッセージを返すRepository
+type messagesRepository struct {
+	repository.Repository
+	messages []*model.Message
+}
+
+func newMessagesRepository(messages []*model.Message) *messagesRepository {
+	sort.Slice(messages, func(i, j int) bool {
+		return lessMessage(messages[i], messages[j].CreatedAt, messages[j].ID)
+	})
+	return &messagesRepository{messages: messages}
+}
+
+func lessMessage(m *model.Message, createdAt time.Time, id uuid.UUID) bool {
+	if !m.CreatedAt.Equal(createdAt) {
+		return m.CreatedAt.Before(createdAt)
+	}
+	return m.ID.String() < id.String()
+}
+
+func (repo *messagesRepository) GetMessages(query repository.MessagesQuery) ([]*model.Message, bool, error) {
+	var result []*model.Message
+	for _, m := range repo.messages {
+		if query.Since.Valid {
+			switch {
+			case query.SinceID != uuid.Nil:
+				if !lessMessage(&model.Message{CreatedAt: query.Since.Time, ID: query.SinceID}, m.CreatedAt, m.ID) {
+					continue
+				}
+			case query.Inclusive:
+				if m.CreatedAt.Before(query.Since.Time) {
+					continue
+				}
+			default:
+				if !m.CreatedAt.After(query.Since.Time) {
+					continue
+				}
+			}
+		}
+		result = append(result, m)
+	}
+	if query.Limit > 0 && len(result) > query.Limit {
+		return result[:query.Limit], true, nil
+	}
+	return result, false, nil
+}
+
+func TestRebuildMemoryIndex(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "traq-search")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// 同一時刻のメッセージがバッチサイズより多い
+	channel := uuid.Must(uuid.NewV4())
+	user := uuid.Must(uuid.NewV4())
+	at := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var messages []*model.Message
+	for i := 0; i < rebuildBatchSize*2+10; i++ {
+		messages = append(messages, makeMessage(channel, user, "same", at))
+	}
+	messages = append(messages, makeMessage(channel, user, "later", at.Add(time.Microsecond)))
+
+	n, err := RebuildMemoryIndex(newMessagesRepository(messages), filepath.Join(dir, "search.idx"))
+	require.NoError(t, err)
+	assert.Equal(t, len(messages), n)
+}
@@ -0,0 +1,76 @@
+package search
+
+import (
+	"fmt"
+	"gopkg.in/guregu/null.v3"
+	"strings"
+	"time"
+)
+
+// ParsedQuery 文字列から解析された検索クエリ
+//
+// チャンネルパス・ユーザー名はまだIDに解決されていません。
+type ParsedQuery struct {
+	// Words 検索語
+	Words []string
+	// In `in:#channel` で指定されたチャンネルパス (先頭の#を含まない)
+	In string
+	// From `from:@user` で指定されたユーザー名 (先頭の@を含まない)
+	From string
+	// HasFile `has:file` が指定されたかどうか
+	HasFile bool
+	// Before `before:` で指定された日時
+	Before null.Time
+	// After `after:` で指定された日時
+	After null.Time
+}
+
+// ParseQuery 検索文字列を解析します
+//
+// 対応しているフィルタは `in:#channel`, `from:@user`, `has:file`, `before:<date>`, `after:<date>` です。
+// 日時はRFC3339形式または `2006-01-02` 形式で指定します。
+func ParseQuery(s string) (*ParsedQuery, error) {
+	q := &ParsedQuery{Words: make([]string, 0)}
+	for _, f := range strings.Fields(s) {
+		i := strings.IndexRune(f, ':')
+		if i <= 0 || i == len(f)-1 {
+			q.Words = append(q.Words, f)
+			continue
+		}
+
+		key, value := strings.ToLower(f[:i]), f[i+1:]
+		switch key {
+		case "in":
+			q.In = strings.TrimPrefix(value, "#")
+		case "from":
+			q.From = strings.TrimPrefix(value, "@")
+		case "has":
+			if strings.ToLower(value) != "file" {
+				return nil, fmt.Errorf("unknown has filter: %s", value)
+			}
+			q.HasFile = true
+		case "before":
+			t, err := parseTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid before filter: %s", value)
+			}
+			q.Before = null.TimeFrom(t)
+		case "after":
+			t, err := parseTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid after filter: %s", value)
+			}
+			q.After = null.TimeFrom(t)
+		default:
+			q.Words = append(q.Words, f)
+		}
+	}
+	return q, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
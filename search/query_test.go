@@ -0,0 +1,59 @@
+package search
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	t.Parallel()
+
+	t.Run("words only", func(t *testing.T) {
+		t.Parallel()
+		q, err := ParseQuery("  hello   world ")
+		if assert.NoError(t, err) {
+			assert.EqualValues(t, []string{"hello", "world"}, q.Words)
+			assert.Empty(t, q.In)
+			assert.Empty(t, q.From)
+			assert.False(t, q.HasFile)
+			assert.False(t, q.Before.Valid)
+			assert.False(t, q.After.Valid)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		t.Parallel()
+		q, err := ParseQuery("in:#general/random from:@traq has:file after:2020-01-01 before:2020-02-01T00:00:00Z test")
+		if assert.NoError(t, err) {
+			assert.EqualValues(t, []string{"test"}, q.Words)
+			assert.Equal(t, "general/random", q.In)
+			assert.Equal(t, "traq", q.From)
+			assert.True(t, q.HasFile)
+			assert.True(t, q.Before.Time.Equal(time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)))
+			assert.True(t, q.After.Time.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)))
+		}
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		t.Parallel()
+		q, err := ParseQuery("http://example.com to: :a")
+		if assert.NoError(t, err) {
+			assert.EqualValues(t, []string{"http://example.com", "to:", ":a"}, q.Words)
+		}
+	})
+
+	t.Run("invalid has", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseQuery("has:image")
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseQuery("before:yesterday")
+		assert.Error(t, err)
+		_, err = ParseQuery("after:2020/01/01")
+		assert.Error(t, err)
+	})
+}
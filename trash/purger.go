@@ -0,0 +1,91 @@
+package trash
+
+import (
+	"sync"
+	"time"
+
+	"github.com/traPtitech/traQ/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// purgeInterval 削除されたメッセージの完全削除の実行間隔
+	purgeInterval = time.Hour
+	// purgeBatchSize 一度に完全に削除するメッセージの最大数
+	purgeBatchSize = 500
+)
+
+// Purger 削除されたメッセージの完全削除ワーカー
+//
+// 削除から保存期間が経過したメッセージを、未読・スタンプ等と共にDBから完全に削除します。
+type Purger struct {
+	repo      repository.Repository
+	logger    *zap.Logger
+	retention time.Duration
+
+	closer chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewPurger 削除されたメッセージの完全削除ワーカーを生成し、起動します
+//
+// retentionは削除されたメッセージの保存期間です。0以下の場合は何もしません。
+func NewPurger(repo repository.Repository, logger *zap.Logger, retention time.Duration) *Purger {
+	p := &Purger{
+		repo:      repo,
+		logger:    logger,
+		retention: retention,
+		closer:    make(chan struct{}),
+	}
+	if retention > 0 {
+		p.wg.Add(1)
+		go p.run()
+	}
+	return p
+}
+
+// Close ワーカーを停止します
+func (p *Purger) Close() {
+	close(p.closer)
+	p.wg.Wait()
+}
+
+func (p *Purger) run() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+	for {
+		p.purge()
+		select {
+		case <-ticker.C:
+		case <-p.closer:
+			return
+		}
+	}
+}
+
+// purge 保存期間が経過した削除されたメッセージを全て完全に削除します
+func (p *Purger) purge() {
+	before := time.Now().Add(-p.retention)
+	total := 0
+	for {
+		select {
+		case <-p.closer:
+			return
+		default:
+		}
+
+		n, err := p.repo.PurgeDeletedMessages(before, purgeBatchSize)
+		if err != nil {
+			p.logger.Error("failed to PurgeDeletedMessages", zap.Error(err))
+			return
+		}
+		total += n
+		if n < purgeBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		p.logger.Info("purged deleted messages", zap.Int("count", total))
+	}
+}
@@ -62,6 +62,16 @@ func (set *StringSet) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// GobEncode encoding/gob.GobEncoder 実装
+func (set StringSet) GobEncode() ([]byte, error) {
+	return set.MarshalJSON()
+}
+
+// GobDecode encoding/gob.GobDecoder 実装
+func (set *StringSet) GobDecode(data []byte) error {
+	return set.UnmarshalJSON(data)
+}
+
 // Clone 集合を複製します
 func (set StringSet) Clone() StringSet {
 	a := StringSet{}
@@ -63,6 +63,16 @@ func (set *UUIDSet) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// GobEncode encoding/gob.GobEncoder 実装
+func (set UUIDSet) GobEncode() ([]byte, error) {
+	return set.MarshalJSON()
+}
+
+// GobDecode encoding/gob.GobDecoder 実装
+func (set *UUIDSet) GobDecode(data []byte) error {
+	return set.UnmarshalJSON(data)
+}
+
 // Clone 集合を複製します
 func (set UUIDSet) Clone() UUIDSet {
 	a := UUIDSet{}